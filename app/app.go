@@ -2,52 +2,104 @@ package app
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sync"
 	"time"
 	"todo-lits-DMARK/app/pkg/config"
 	"todo-lits-DMARK/app/pkg/database"
 	"todo-lits-DMARK/app/pkg/models"
-	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/notify"
 	"todo-lits-DMARK/app/pkg/service"
 	"todo-lits-DMARK/app/pkg/usecase"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type App struct {
+	// родитель контекстов всех операций, отменяется при завершении приложения
 	ctx         context.Context
+	cancel      context.CancelFunc
+	timeouts    config.TimeoutConfig
+	mu          sync.RWMutex
 	taskUsecase usecase.TaskUsecase
 	db          *database.Database
+	storage     StorageStatus
+	storageErr  error
+	// зона пользователя: от нее считаются «сегодня», «неделя» и сроки без смещения
+	location *time.Location
+	// файл, в котором SetTimezone запоминает выбранный пояс, пусто — не запоминать
+	settingsPath string
+
+	// напоминания: системные уведомления и сигнал планировщику пересчитать ожидание
+	notifier      notify.Notifier
+	remindersWake chan struct{}
+	// напоминания, показанные в окне, но еще не доставленные уведомлением; только для планировщика
+	undelivered map[int]struct{}
+
+	openDatabase func(ctx context.Context, cfg *config.Config) (*database.Database, error)
+	emit         func(event string, data ...interface{})
 }
 
 func NewApp() *App {
-	return &App{}
+	return &App{
+		openDatabase:  database.New,
+		emit:          func(string, ...interface{}) {},
+		remindersWake: make(chan struct{}, 1),
+		location:      time.Local,
+		notifier:      notify.NewNopNotifier(),
+		undelivered:   make(map[int]struct{}),
+	}
 }
 
 func (a *App) OnStartup(ctx context.Context) {
-	a.ctx = ctx
 	log.Println("TodoApp is starting...")
 
+	a.emit = func(event string, data ...interface{}) {
+		runtime.EventsEmit(ctx, event, data...)
+	}
+
+	// отменяется в OnShutdown: останавливает фоновые горутины и прерывает запросы к базе
+	loopCtx, cancel := context.WithCancel(ctx)
+	a.ctx = loopCtx
+	a.cancel = cancel
+
 	cfg := config.New()
-	log.Printf("Connecting to database: %s:%s", cfg.Database.Host, cfg.Database.Port)
+	a.timeouts = cfg.Timeouts
+	a.loadTimezone(cfg)
+	a.notifier = newDesktopNotifier(cfg)
+	a.storage.Driver = cfg.Storage.Driver
+	if cfg.Storage.Driver == config.DriverSQLite {
+		log.Printf("Opening embedded database: %s", cfg.Storage.SQLitePath)
+	} else {
+		log.Printf("Connecting to database: %s:%s", cfg.Database.Host, cfg.Database.Port)
+	}
 
-	db, err := database.New(cfg)
-	if err != nil {
+	if err := a.connect(loopCtx, cfg); err != nil {
 		log.Printf("Failed to connect to database: %v", err)
-		log.Println("Application will continue without database persistence")
+		log.Println("Application will continue in read-only mode and keep reconnecting")
+		go a.reconnectLoop(loopCtx, cfg)
 		return
 	}
 
-	a.db = db
 	log.Println("Database connection established")
-
-	taskRepo := repository.NewTaskRepository(db.DB)
-	taskService := service.NewTaskService(taskRepo)
-	a.taskUsecase = usecase.NewTaskUsecase(taskService)
-
+	a.startChangeFeed(loopCtx, cfg)
+	a.startTrashPurge(loopCtx, cfg)
+	a.startReminders(loopCtx)
 	log.Println("Application started successfully")
 }
 
 func (a *App) OnShutdown(ctx context.Context) {
 	log.Println("TodoApp is shutting down...")
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	a.notifier.Close()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	if a.db != nil {
 		if err := a.db.Close(); err != nil {
 			log.Printf("Error closing database connection: %v", err)
@@ -62,97 +114,127 @@ func (a *App) Greet(name string) string {
 	return "Hello " + name + " from TodoApp!"
 }
 
-func (a *App) CreateTask(title, description, priority string, dueDate string) (map[string]interface{}, error) {
-	if a.taskUsecase == nil {
-		return nil, nil // Graceful fallback если DB недоступна
+// projectID 0 кладет задачу во Inbox, recurrence — правило RRULE, например "FREQ=WEEKLY;BYDAY=MO".
+// dueDate — время RFC 3339, время без смещения в зоне пользователя или дата YYYY-MM-DD на весь день.
+func (a *App) CreateTask(title, description, priority string, dueDate string, tags []string, projectID int, recurrence string) (*TaskResponse, error) {
+	var project *int
+	if projectID != 0 {
+		project = &projectID
 	}
+	return a.createTask(nil, project, title, description, priority, dueDate, tags, recurrence)
+}
+
+// подзадача создается в проекте родителя
+func (a *App) CreateSubtask(parentID int, title, description, priority string, dueDate string, tags []string) (*TaskResponse, error) {
+	return a.createTask(&parentID, nil, title, description, priority, dueDate, tags, "")
+}
+
+func (a *App) createTask(parentID, projectID *int, title, description, priority string, dueDate string, tags []string, recurrence string) (*TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
 
 	req := &models.CreateTaskRequest{
+		ParentID:    parentID,
+		ProjectID:   projectID,
 		Title:       title,
 		Description: description,
 		Priority:    models.TaskPriority(priority),
+		Recurrence:  recurrence,
+		Tags:        tags,
 	}
 
 	if dueDate != "" {
-		if parsedDate, err := time.Parse("2006-01-02T15:04:05Z", dueDate); err == nil {
-			req.DueDate = &parsedDate
+		due, err := a.parseDueDate(dueDate)
+		if err != nil {
+			return nil, err
 		}
+		req.DueDate = &due.Time
+		req.AllDay = due.AllDay
 	}
 
-	task, err := a.taskUsecase.CreateTask(req)
+	task, err := uc.CreateTask(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"id":          task.ID,
-		"title":       task.Title,
-		"description": task.Description,
-		"status":      task.Status,
-		"priority":    task.Priority,
-		"due_date":    task.DueDate,
-		"created_at":  task.CreatedAt,
-		"updated_at":  task.UpdatedAt,
-		"is_overdue":  task.IsOverdue(),
-	}, nil
+	return newTaskResponse(task), nil
 }
 
-func (a *App) GetTasks(status, priority, sortBy, sortOrder string) ([]map[string]interface{}, error) {
-	if a.taskUsecase == nil {
-		return []map[string]interface{}{}, nil
+// tags фильтрует по тегам, tagMatch "all" требует все теги сразу, иначе достаточно любого.
+// projectID 0 показывает задачи всех неархивных проектов.
+// query — строка языка фильтров, ошибка разбора содержит позицию и причину.
+func (a *App) GetTasks(status, priority, sortBy, sortOrder string, tags []string, tagMatch string, projectID int, query string) ([]TaskResponse, error) {
+	// без базы показываем пустой список, статус хранилища фронтенд берет из GetStorageStatus
+	uc, err := a.usecase()
+	if err != nil {
+		return []TaskResponse{}, nil
 	}
 
-	tasks, err := a.taskUsecase.GetTasks(status, priority, sortBy, sortOrder)
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	tasks, err := uc.GetTasks(ctx, status, priority, sortBy, sortOrder, tags, tagMatch, projectID, query)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]map[string]interface{}, len(tasks))
-	for i, task := range tasks {
-		result[i] = map[string]interface{}{
-			"id":          task.ID,
-			"title":       task.Title,
-			"description": task.Description,
-			"status":      task.Status,
-			"priority":    task.Priority,
-			"due_date":    task.DueDate,
-			"created_at":  task.CreatedAt,
-			"updated_at":  task.UpdatedAt,
-			"is_overdue":  task.IsOverdue(),
-		}
+	return newTaskResponses(tasks), nil
+}
+
+// GetTasksPage — постраничный GetTasks для бесконечной прокрутки. limit <= 0 — 50 задач,
+// больше 200 не отдается. cursor — next_cursor предыдущей страницы, пустой для первой;
+// курсор действителен только для тех же фильтров и сортировки.
+func (a *App) GetTasksPage(status, priority, sortBy, sortOrder string, tags []string, tagMatch string, projectID int, query string, limit int, cursor string) (*TaskPageResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return newTaskPageResponse(nil), nil
 	}
 
-	return result, nil
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	page, err := uc.GetTasksPage(ctx, status, priority, sortBy, sortOrder, tags, tagMatch, projectID, query, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskPageResponse(page), nil
 }
 
-func (a *App) GetTask(id int) (map[string]interface{}, error) {
-	if a.taskUsecase == nil {
-		return nil, nil
+func (a *App) GetTask(id int) (*TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
 	}
 
-	task, err := a.taskUsecase.GetTask(id)
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	task, err := uc.GetTask(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"id":          task.ID,
-		"title":       task.Title,
-		"description": task.Description,
-		"status":      task.Status,
-		"priority":    task.Priority,
-		"due_date":    task.DueDate,
-		"created_at":  task.CreatedAt,
-		"updated_at":  task.UpdatedAt,
-		"is_overdue":  task.IsOverdue(),
-	}, nil
+	return newTaskResponse(task), nil
 }
 
-func (a *App) UpdateTask(id int, title, description, status, priority string, dueDate string) (map[string]interface{}, error) {
-	if a.taskUsecase == nil {
-		return nil, nil
+// tags равный null оставляет теги без изменений, пустой массив снимает все теги.
+// recurrence равный null оставляет правило повторения, пустая строка отключает повторение.
+// version — версия задачи, которую редактировал клиент, 0 отключает проверку
+func (a *App) UpdateTask(id int, title, description, status, priority string, dueDate string, tags []string, recurrence *string, version int) (*UpdateTaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
 	}
 
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
 	updates := &models.UpdateTaskRequest{}
 
 	if title != "" {
@@ -170,161 +252,645 @@ func (a *App) UpdateTask(id int, title, description, status, priority string, du
 		updates.Priority = &taskPriority
 	}
 	if dueDate != "" {
-		if parsedDate, err := time.Parse("2006-01-02T15:04:05Z", dueDate); err == nil {
-			updates.DueDate = &parsedDate
+		due, err := a.parseDueDate(dueDate)
+		if err != nil {
+			return nil, err
 		}
+		updates.DueDate = &due.Time
+		updates.AllDay = &due.AllDay
+	}
+	if tags != nil {
+		updates.Tags = &tags
+	}
+	updates.Recurrence = recurrence
+	if version > 0 {
+		updates.Version = &version
 	}
 
-	task, err := a.taskUsecase.UpdateTask(id, updates)
+	task, err := uc.UpdateTask(ctx, id, updates)
+	var conflict *service.ConflictError
+	if errors.As(err, &conflict) {
+		return &UpdateTaskResponse{Task: *newTaskResponse(conflict.Current), Conflict: true}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"id":          task.ID,
-		"title":       task.Title,
-		"description": task.Description,
-		"status":      task.Status,
-		"priority":    task.Priority,
-		"due_date":    task.DueDate,
-		"created_at":  task.CreatedAt,
-		"updated_at":  task.UpdatedAt,
-		"is_overdue":  task.IsOverdue(),
-	}, nil
+	return &UpdateTaskResponse{Task: *newTaskResponse(task)}, nil
 }
 
 func (a *App) DeleteTask(id int) error {
-	if a.taskUsecase == nil {
-		return nil
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+	return uc.DeleteTask(ctx, id)
+}
+
+// GetTrash возвращает удаленные задачи, последние удаленные первыми.
+// Подзадачи, удаленные вместе с родителем, отдельно не показываются.
+func (a *App) GetTrash() ([]TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return []TaskResponse{}, nil
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	tasks, err := uc.GetTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskResponses(tasks), nil
+}
+
+// RestoreTask возвращает задачу из корзины с подзадачами, удаленными вместе с ней.
+// Если родитель остается в корзине, задача становится корневой.
+func (a *App) RestoreTask(id int) (*TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	task, err := uc.RestoreTask(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	return a.taskUsecase.DeleteTask(id)
+
+	return newTaskResponse(task), nil
 }
 
-func (a *App) ToggleTaskComplete(id int) (map[string]interface{}, error) {
-	if a.taskUsecase == nil {
+// PurgeTask удаляет задачу из корзины навсегда
+func (a *App) PurgeTask(id int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+	return uc.PurgeTask(ctx, id)
+}
+
+// EmptyTrash очищает корзину и возвращает число удаленных задач
+func (a *App) EmptyTrash() (int, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := a.bulkContext()
+	defer cancel()
+	return uc.EmptyTrash(ctx)
+}
+
+// Undo отменяет последнее создание, изменение, удаление или переключение задач в этой сессии.
+// Если отменять нечего, возвращает null.
+func (a *App) Undo() (*HistoryResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	result, err := uc.Undo(ctx)
+	if errors.Is(err, usecase.ErrNothingToUndo) {
 		return nil, nil
 	}
+	if err != nil {
+		return nil, err
+	}
 
-	task, err := a.taskUsecase.ToggleTaskComplete(id)
+	return newHistoryResponse(result), nil
+}
+
+// Redo повторяет последнюю отмененную операцию, null — если повторять нечего
+func (a *App) Redo() (*HistoryResponse, error) {
+	uc, err := a.usecase()
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"id":          task.ID,
-		"title":       task.Title,
-		"description": task.Description,
-		"status":      task.Status,
-		"priority":    task.Priority,
-		"due_date":    task.DueDate,
-		"created_at":  task.CreatedAt,
-		"updated_at":  task.UpdatedAt,
-		"is_overdue":  task.IsOverdue(),
-	}, nil
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	result, err := uc.Redo(ctx)
+	if errors.Is(err, usecase.ErrNothingToRedo) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newHistoryResponse(result), nil
 }
 
-func (a *App) GetDashboardData() (map[string]interface{}, error) {
-	if a.taskUsecase == nil {
-		return map[string]interface{}{
-			"stats": map[string]interface{}{
-				"total": 0, "pending": 0, "completed": 0, "overdue": 0,
-			},
-			"recent_tasks":   []map[string]interface{}{},
-			"overdue_tasks":  []map[string]interface{}{},
-			"today_tasks":    []map[string]interface{}{},
-			"upcoming_tasks": []map[string]interface{}{},
-		}, nil
+// GetTaskHistory возвращает журнал изменений задачи, последние изменения первыми
+func (a *App) GetTaskHistory(id int) ([]TaskRevisionResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	revisions, err := uc.GetTaskHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskRevisionResponses(revisions), nil
+}
+
+// RevertTask возвращает задачу к состоянию после ревизии revisionID
+func (a *App) RevertTask(taskID, revisionID int) (*TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := a.taskUsecase.GetDashboardData()
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	task, err := uc.RevertTask(ctx, taskID, revisionID)
 	if err != nil {
 		return nil, err
 	}
 
-	convertTasks := func(tasks []*models.Task) []map[string]interface{} {
-		result := make([]map[string]interface{}, len(tasks))
-		for i, task := range tasks {
-			result[i] = map[string]interface{}{
-				"id":          task.ID,
-				"title":       task.Title,
-				"description": task.Description,
-				"status":      task.Status,
-				"priority":    task.Priority,
-				"due_date":    task.DueDate,
-				"created_at":  task.CreatedAt,
-				"updated_at":  task.UpdatedAt,
-				"is_overdue":  task.IsOverdue(),
-			}
+	return newTaskResponse(task), nil
+}
+
+// DeleteTaskKeepSubtasks удаляет задачу, а ее подзадачи поднимает на уровень выше
+func (a *App) DeleteTaskKeepSubtasks(id int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+	return uc.DeleteTaskKeepSubtasks(ctx, id)
+}
+
+// BulkUpdateTasks меняет приоритет и/или срок у выбранных задач; пустые значения не меняются.
+// Операция выполняется целиком: при ошибке ни одна задача не изменится.
+func (a *App) BulkUpdateTasks(ids []int, priority, dueDate string) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.bulkContext()
+	defer cancel()
+
+	updates := &models.UpdateTaskRequest{}
+	if priority != "" {
+		taskPriority := models.TaskPriority(priority)
+		updates.Priority = &taskPriority
+	}
+	if dueDate != "" {
+		due, err := a.parseDueDate(dueDate)
+		if err != nil {
+			return err
 		}
-		return result
+		updates.DueDate = &due.Time
+		updates.AllDay = &due.AllDay
+	}
+
+	return uc.BulkUpdateTasks(ctx, ids, updates)
+}
+
+// BulkSetStatus завершает или возобновляет выбранные задачи
+func (a *App) BulkSetStatus(ids []int, status string) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
 	}
 
-	return map[string]interface{}{
-		"stats": map[string]interface{}{
-			"total":     data.Stats.Total,
-			"pending":   data.Stats.Pending,
-			"completed": data.Stats.Completed,
-			"overdue":   data.Stats.Overdue,
-		},
-		"recent_tasks":   convertTasks(data.RecentTasks),
-		"overdue_tasks":  convertTasks(data.OverdueTasks),
-		"today_tasks":    convertTasks(data.TodayTasks),
-		"upcoming_tasks": convertTasks(data.UpcomingTasks),
-	}, nil
+	ctx, cancel := a.bulkContext()
+	defer cancel()
+	return uc.BulkSetStatus(ctx, ids, models.TaskStatus(status))
 }
 
-func (a *App) SearchTasks(query string) ([]map[string]interface{}, error) {
-	if a.taskUsecase == nil {
-		return []map[string]interface{}{}, nil
+// BulkTagTasks добавляет теги add и снимает теги remove у выбранных задач
+func (a *App) BulkTagTasks(ids []int, add, remove []string) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
 	}
 
-	tasks, err := a.taskUsecase.SearchTasks(query)
+	ctx, cancel := a.bulkContext()
+	defer cancel()
+	return uc.BulkTagTasks(ctx, ids, add, remove)
+}
+
+// BulkMoveToProject переносит выбранные задачи вместе с подзадачами в проект
+func (a *App) BulkMoveToProject(ids []int, projectID int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.bulkContext()
+	defer cancel()
+	return uc.BulkMoveToProject(ctx, ids, projectID)
+}
+
+// BulkDeleteTasks переносит выбранные задачи вместе с подзадачами в корзину
+func (a *App) BulkDeleteTasks(ids []int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.bulkContext()
+	defer cancel()
+	return uc.BulkDeleteTasks(ctx, ids)
+}
+
+// subtaskMode: "" или "none" — подзадачи не трогаются, "complete" — завершаются вместе с задачей,
+// "block" — задачу нельзя завершить, пока есть открытые подзадачи
+func (a *App) ToggleTaskComplete(id int, subtaskMode string) (*TaskResponse, error) {
+	uc, err := a.usecase()
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]map[string]interface{}, len(tasks))
-	for i, task := range tasks {
-		result[i] = map[string]interface{}{
-			"id":          task.ID,
-			"title":       task.Title,
-			"description": task.Description,
-			"status":      task.Status,
-			"priority":    task.Priority,
-			"due_date":    task.DueDate,
-			"created_at":  task.CreatedAt,
-			"updated_at":  task.UpdatedAt,
-			"is_overdue":  task.IsOverdue(),
-		}
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	task, err := uc.ToggleTaskComplete(ctx, id, models.SubtaskMode(subtaskMode))
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskResponse(task), nil
+}
+
+func (a *App) GetDashboardData() (*DashboardResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return newDashboardResponse(nil), nil
 	}
 
-	return result, nil
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	data, err := uc.GetDashboardData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDashboardResponse(data), nil
 }
 
-func (a *App) GetTasksByDateFilter(filter string) ([]map[string]interface{}, error) {
-	if a.taskUsecase == nil {
-		return []map[string]interface{}{}, nil
+// SearchTasks ищет по заголовку и описанию: "фраза", префикс*, -исключение.
+// Результаты отсортированы по релевантности, в snippet совпадения обрамлены <mark>.
+// limit и cursor работают так же, как в GetTasksPage.
+func (a *App) SearchTasks(query string, limit int, cursor string) (*SearchPageResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return newSearchPageResponse(nil), nil
 	}
 
-	tasks, err := a.taskUsecase.GetTasksByDateRange(filter)
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	page, err := uc.SearchTasks(ctx, query, limit, cursor)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]map[string]interface{}, len(tasks))
-	for i, task := range tasks {
-		result[i] = map[string]interface{}{
-			"id":          task.ID,
-			"title":       task.Title,
-			"description": task.Description,
-			"status":      task.Status,
-			"priority":    task.Priority,
-			"due_date":    task.DueDate,
-			"created_at":  task.CreatedAt,
-			"updated_at":  task.UpdatedAt,
-			"is_overdue":  task.IsOverdue(),
-		}
+	return newSearchPageResponse(page), nil
+}
+
+func (a *App) GetTasksByDateFilter(filter string) ([]TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return []TaskResponse{}, nil
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	tasks, err := uc.GetTasksByDateRange(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskResponses(tasks), nil
+}
+
+func (a *App) GetTaskTree(id int) (*TaskNodeResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	node, err := uc.GetTaskTree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newTaskNodeResponse(node)
+	return &response, nil
+}
+
+func (a *App) GetTasksTree(status, priority, sortBy, sortOrder string, tags []string, tagMatch string, projectID int, query string) ([]TaskNodeResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return []TaskNodeResponse{}, nil
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	nodes, err := uc.GetTasksTree(ctx, status, priority, sortBy, sortOrder, tags, tagMatch, projectID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskNodeResponses(nodes), nil
+}
+
+// MoveTask переносит задачу к другому родителю, parentID 0 делает ее корневой
+func (a *App) MoveTask(id int, parentID int) (*TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	var parent *int
+	if parentID != 0 {
+		parent = &parentID
+	}
+
+	task, err := uc.MoveTask(ctx, id, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskResponse(task), nil
+}
+
+func (a *App) GetTags() ([]TagResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return []TagResponse{}, nil
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	tags, err := uc.GetTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTagResponses(tags), nil
+}
+
+func (a *App) CreateTag(name string) (*TagResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	tag, err := uc.CreateTag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTagResponse(tag), nil
+}
+
+func (a *App) RenameTag(id int, name string) (*TagResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	tag, err := uc.RenameTag(ctx, id, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTagResponse(tag), nil
+}
+
+func (a *App) DeleteTag(id int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+	return uc.DeleteTag(ctx, id)
+}
+
+func (a *App) GetProjects() ([]ProjectResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return []ProjectResponse{}, nil
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	projects, err := uc.GetProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newProjectResponses(projects), nil
+}
+
+// пустой color заменяется цветом по умолчанию
+func (a *App) CreateProject(name, color string) (*ProjectResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	project, err := uc.CreateProject(ctx, &models.CreateProjectRequest{Name: name, Color: color})
+	if err != nil {
+		return nil, err
+	}
+
+	return newProjectResponse(project), nil
+}
+
+func (a *App) UpdateProject(id int, name, color string) (*ProjectResponse, error) {
+	updates := &models.UpdateProjectRequest{}
+	if name != "" {
+		updates.Name = &name
+	}
+	if color != "" {
+		updates.Color = &color
+	}
+	return a.updateProject(id, updates)
+}
+
+// задачи архивного проекта не удаляются, а только скрываются из общего списка
+func (a *App) ArchiveProject(id int, archived bool) (*ProjectResponse, error) {
+	return a.updateProject(id, &models.UpdateProjectRequest{Archived: &archived})
+}
+
+func (a *App) updateProject(id int, updates *models.UpdateProjectRequest) (*ProjectResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	project, err := uc.UpdateProject(ctx, id, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	return newProjectResponse(project), nil
+}
+
+// ReorderProjects принимает id проектов в новом порядке
+func (a *App) ReorderProjects(ids []int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+	return uc.ReorderProjects(ctx, ids)
+}
+
+// задачи удаляемого проекта переносятся во Inbox
+func (a *App) DeleteProject(id int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+	return uc.DeleteProject(ctx, id)
+}
+
+func (a *App) MoveTaskToProject(taskID int, projectID int) (*TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	task, err := uc.MoveTaskToProject(ctx, taskID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskResponse(task), nil
+}
+
+// GetSavedFilters возвращает умные списки без счетчиков, счетчики есть в GetDashboardData
+func (a *App) GetSavedFilters() ([]SavedFilterResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return []SavedFilterResponse{}, nil
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	filters, err := uc.GetSavedFilters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSavedFilterResponses(filters), nil
+}
+
+// CreateSavedFilter сохраняет запрос языка фильтров под именем.
+// Список открывается через GetTasks с query filter:"имя".
+func (a *App) CreateSavedFilter(name, query string) (*SavedFilterResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	filter, err := uc.CreateSavedFilter(ctx, &models.CreateSavedFilterRequest{Name: name, Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	return newSavedFilterResponse(filter), nil
+}
+
+// пустые name и query оставляют поле без изменений
+func (a *App) UpdateSavedFilter(id int, name, query string) (*SavedFilterResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	updates := &models.UpdateSavedFilterRequest{}
+	if name != "" {
+		updates.Name = &name
+	}
+	if query != "" {
+		updates.Query = &query
+	}
+
+	filter, err := uc.UpdateSavedFilter(ctx, id, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSavedFilterResponse(filter), nil
+}
+
+func (a *App) DeleteSavedFilter(id int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
 	}
 
-	return result, nil
+	ctx, cancel := a.writeContext()
+	defer cancel()
+	return uc.DeleteSavedFilter(ctx, id)
 }
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestUpdateTaskReturnsServerCopyOnConflict(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestApp(t)
+
+	task, err := a.taskUsecase.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	first, err := a.UpdateTask(task.ID, "theirs", "", "", "", "", nil, nil, task.Version)
+	if err != nil || first.Conflict {
+		t.Fatalf("UpdateTask: %+v, %v", first, err)
+	}
+
+	second, err := a.UpdateTask(task.ID, "mine", "", "", "", "", nil, nil, task.Version)
+	if err != nil {
+		t.Fatalf("UpdateTask with stale version: %v", err)
+	}
+	if !second.Conflict || second.Task.Title != "theirs" || second.Task.Version != first.Task.Version {
+		t.Errorf("expected conflict with the server copy, got %+v", second)
+	}
+}
+
+func TestOperationContextIsCancelledOnShutdown(t *testing.T) {
+	a, _ := newTestApp(t)
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	a.timeouts = config.TimeoutConfig{Read: time.Minute}
+
+	read, cancelRead := a.readContext()
+	defer cancelRead()
+	if _, ok := read.Deadline(); !ok {
+		t.Error("expected read operation to have a deadline")
+	}
+
+	// нулевой таймаут не ограничивает операцию
+	write, cancelWrite := a.writeContext()
+	defer cancelWrite()
+	if _, ok := write.Deadline(); ok {
+		t.Error("expected write operation without a deadline")
+	}
+
+	a.OnShutdown(context.Background())
+	if !errors.Is(read.Err(), context.Canceled) || !errors.Is(write.Err(), context.Canceled) {
+		t.Errorf("expected shutdown to cancel operations, got %v and %v", read.Err(), write.Err())
+	}
+}
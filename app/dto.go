@@ -0,0 +1,498 @@
+package app
+
+import (
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/service"
+	"todo-lits-DMARK/app/pkg/usecase"
+)
+
+// Структуры ответов для фронтенда. Wails генерирует по ним TypeScript модели,
+// поэтому json теги должны совпадать с тем, что ожидает main.js.
+
+type TaskResponse struct {
+	ID          int        `json:"id"`
+	ParentID    *int       `json:"parent_id"`
+	ProjectID   int        `json:"project_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"due_date" ts_type:"string"`
+	AllDay      bool       `json:"all_day"`
+	Recurrence  string     `json:"recurrence"`
+	CreatedAt   time.Time  `json:"created_at" ts_type:"string"`
+	UpdatedAt   time.Time  `json:"updated_at" ts_type:"string"`
+	IsOverdue   bool       `json:"is_overdue"`
+	Tags        []string   `json:"tags"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" ts_type:"string"`
+	Version     int        `json:"version"`
+	SnoozeCount int        `json:"snooze_count"`
+}
+
+// UpdateTaskResponse — результат сохранения задачи. conflict = true, если задачу успели
+// изменить после чтения версии: изменения не применены, task — актуальная копия с сервера.
+type UpdateTaskResponse struct {
+	Task     TaskResponse `json:"task"`
+	Conflict bool         `json:"conflict"`
+}
+
+// SearchResultResponse — найденная задача; snippet не экранирован, совпадения обрамлены <mark>
+type SearchResultResponse struct {
+	Task    TaskResponse `json:"task"`
+	Rank    float64      `json:"rank"`
+	Snippet string       `json:"snippet"`
+}
+
+// TaskPageResponse — страница задач; next_cursor пустой на последней странице,
+// total — число задач под фильтром без учета курсора
+type TaskPageResponse struct {
+	Tasks      []TaskResponse `json:"tasks"`
+	NextCursor string         `json:"next_cursor"`
+	Total      int            `json:"total"`
+}
+
+// HistoryResponse — отмененная или повторенная операция: create, update, delete, toggle, bulk_update, revert или snooze.
+// Задачи, ушедшие в корзину, приходят с deleted_at.
+type HistoryResponse struct {
+	Action string         `json:"action"`
+	Tasks  []TaskResponse `json:"tasks"`
+}
+
+// TaskRevisionResponse — запись журнала изменений задачи. task — состояние задачи после изменения,
+// к нему возвращает RevertTask. action: create, update, delete, restore, toggle, revert, snooze, undo или redo.
+type TaskRevisionResponse struct {
+	ID        int                   `json:"id"`
+	TaskID    int                   `json:"task_id"`
+	Action    string                `json:"action"`
+	Actor     string                `json:"actor"`
+	Changes   []FieldChangeResponse `json:"changes"`
+	Task      TaskResponse          `json:"task"`
+	CreatedAt time.Time             `json:"created_at" ts_type:"string"`
+}
+
+// FieldChangeResponse — поле до и после изменения, пустая строка означает отсутствие значения
+type FieldChangeResponse struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// SearchPageResponse — страница результатов поиска в порядке релевантности
+type SearchPageResponse struct {
+	Results    []SearchResultResponse `json:"results"`
+	NextCursor string                 `json:"next_cursor"`
+	Total      int                    `json:"total"`
+}
+
+type TagResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	TaskCount int    `json:"task_count"`
+}
+
+// TaskNodeResponse — задача с подзадачами и прогрессом по прямым потомкам
+type TaskNodeResponse struct {
+	Task              TaskResponse       `json:"task"`
+	Children          []TaskNodeResponse `json:"children"`
+	CompletedChildren int                `json:"completed_children"`
+	TotalChildren     int                `json:"total_children"`
+}
+
+// OccurrenceResponse — будущее повторение задачи task_id, которое еще не создано
+type OccurrenceResponse struct {
+	TaskID    int       `json:"task_id"`
+	Title     string    `json:"title"`
+	Priority  string    `json:"priority"`
+	ProjectID int       `json:"project_id"`
+	DueDate   time.Time `json:"due_date" ts_type:"string"`
+	AllDay    bool      `json:"all_day"`
+}
+
+type ProjectResponse struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Archived bool   `json:"archived"`
+	Position int    `json:"position"`
+	Inbox    bool   `json:"inbox"`
+}
+
+// SavedFilterResponse — умный список, TaskCount и Error заполнены только в дашборде
+type SavedFilterResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Query     string `json:"query"`
+	TaskCount int    `json:"task_count"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ProjectStatsResponse struct {
+	ProjectID int    `json:"project_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Archived  bool   `json:"archived"`
+	Total     int    `json:"total"`
+	Pending   int    `json:"pending"`
+	Completed int    `json:"completed"`
+	Overdue   int    `json:"overdue"`
+}
+
+// итоги без архивных проектов, Projects содержит все проекты в пользовательском порядке
+type TaskStatsResponse struct {
+	Total       int                    `json:"total"`
+	Pending     int                    `json:"pending"`
+	Completed   int                    `json:"completed"`
+	Overdue     int                    `json:"overdue"`
+	DueToday    int                    `json:"due_today"`
+	DueThisWeek int                    `json:"due_this_week"`
+	ByPriority  map[string]int         `json:"by_priority"`
+	Projects    []ProjectStatsResponse `json:"projects"`
+}
+
+type DashboardResponse struct {
+	Stats         TaskStatsResponse `json:"stats"`
+	RecentTasks   []TaskResponse    `json:"recent_tasks"`
+	OverdueTasks  []TaskResponse    `json:"overdue_tasks"`
+	TodayTasks    []TaskResponse    `json:"today_tasks"`
+	UpcomingTasks []TaskResponse    `json:"upcoming_tasks"`
+	Tags          []TagResponse     `json:"tags"`
+
+	UpcomingOccurrences []OccurrenceResponse  `json:"upcoming_occurrences"`
+	SavedFilters        []SavedFilterResponse `json:"saved_filters"`
+}
+
+// ReminderResponse — напоминание в момент at или за offset_minutes до срока задачи.
+// remind_at — когда оно сработает, fired_at — когда сработало.
+type ReminderResponse struct {
+	ID            int        `json:"id"`
+	TaskID        int        `json:"task_id"`
+	At            *time.Time `json:"at" ts_type:"string"`
+	OffsetMinutes *int       `json:"offset_minutes"`
+	RemindAt      time.Time  `json:"remind_at" ts_type:"string"`
+	FiredAt       *time.Time `json:"fired_at" ts_type:"string"`
+}
+
+// ReminderFiredResponse приходит с событием reminder:fired.
+// missed = true, если время напоминания прошло, пока приложение было закрыто.
+type ReminderFiredResponse struct {
+	Reminder ReminderResponse `json:"reminder"`
+	Task     TaskResponse     `json:"task"`
+	Missed   bool             `json:"missed"`
+}
+
+// QuickAddPreviewResponse — задача, которую создаст QuickAdd из той же строки
+type QuickAddPreviewResponse struct {
+	Title      string     `json:"title"`
+	Priority   string     `json:"priority"`
+	DueDate    *time.Time `json:"due_date" ts_type:"string"`
+	AllDay     bool       `json:"all_day"`
+	Tags       []string   `json:"tags"`
+	Recurrence string     `json:"recurrence"`
+}
+
+// TaskEventResponse отправляется во фронтенд через runtime.EventsEmit
+type TaskEventResponse struct {
+	Type       string                    `json:"type"`
+	TaskIDs    []int                     `json:"task_ids"`
+	Tasks      []TaskResponse            `json:"tasks"`
+	Changes    *models.UpdateTaskRequest `json:"changes,omitempty"`
+	OccurredAt time.Time                 `json:"occurred_at" ts_type:"string"`
+	Remote     bool                      `json:"remote"`
+}
+
+func newTaskResponse(task *models.Task) *TaskResponse {
+	tags := task.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &TaskResponse{
+		ID:          task.ID,
+		ParentID:    task.ParentID,
+		ProjectID:   task.ProjectID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      string(task.Status),
+		Priority:    string(task.Priority),
+		DueDate:     task.DueDate,
+		AllDay:      task.AllDay,
+		Recurrence:  task.Recurrence,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+		IsOverdue:   task.IsOverdue(),
+		Tags:        tags,
+		DeletedAt:   task.DeletedAt,
+		Version:     task.Version,
+		SnoozeCount: task.SnoozeCount,
+	}
+}
+
+// всегда возвращает не nil срез, чтобы фронтенд получал [] вместо null
+func newTaskResponses(tasks []*models.Task) []TaskResponse {
+	result := make([]TaskResponse, len(tasks))
+	for i, task := range tasks {
+		result[i] = *newTaskResponse(task)
+	}
+	return result
+}
+
+func newTaskPageResponse(page *models.TaskPage) *TaskPageResponse {
+	if page == nil {
+		return &TaskPageResponse{Tasks: []TaskResponse{}}
+	}
+	return &TaskPageResponse{
+		Tasks:      newTaskResponses(page.Tasks),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+}
+
+func newHistoryResponse(result *usecase.HistoryResult) *HistoryResponse {
+	return &HistoryResponse{
+		Action: string(result.Action),
+		Tasks:  newTaskResponses(result.Tasks),
+	}
+}
+
+func newTaskRevisionResponses(revisions []*models.TaskRevision) []TaskRevisionResponse {
+	result := make([]TaskRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		changes := make([]FieldChangeResponse, len(revision.Changes))
+		for j, change := range revision.Changes {
+			changes[j] = FieldChangeResponse(change)
+		}
+		result[i] = TaskRevisionResponse{
+			ID:        revision.ID,
+			TaskID:    revision.TaskID,
+			Action:    string(revision.Action),
+			Actor:     revision.Actor,
+			Changes:   changes,
+			Task:      *newTaskResponse(revision.Task),
+			CreatedAt: revision.CreatedAt,
+		}
+	}
+	return result
+}
+
+func newSearchPageResponse(page *models.SearchPage) *SearchPageResponse {
+	if page == nil {
+		return &SearchPageResponse{Results: []SearchResultResponse{}}
+	}
+	return &SearchPageResponse{
+		Results:    newSearchResultResponses(page.Results),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+}
+
+func newSearchResultResponses(results []*models.SearchResult) []SearchResultResponse {
+	result := make([]SearchResultResponse, len(results))
+	for i, found := range results {
+		result[i] = SearchResultResponse{
+			Task:    *newTaskResponse(found.Task),
+			Rank:    found.Rank,
+			Snippet: found.Snippet,
+		}
+	}
+	return result
+}
+
+func newTaskNodeResponse(node *models.TaskNode) TaskNodeResponse {
+	return TaskNodeResponse{
+		Task:              *newTaskResponse(node.Task),
+		Children:          newTaskNodeResponses(node.Children),
+		CompletedChildren: node.CompletedChildren,
+		TotalChildren:     node.TotalChildren,
+	}
+}
+
+func newTaskNodeResponses(nodes []*models.TaskNode) []TaskNodeResponse {
+	result := make([]TaskNodeResponse, len(nodes))
+	for i, node := range nodes {
+		result[i] = newTaskNodeResponse(node)
+	}
+	return result
+}
+
+func newTagResponse(tag *models.Tag) *TagResponse {
+	return &TagResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		TaskCount: tag.TaskCount,
+	}
+}
+
+func newTagResponses(tags []*models.Tag) []TagResponse {
+	result := make([]TagResponse, len(tags))
+	for i, tag := range tags {
+		result[i] = *newTagResponse(tag)
+	}
+	return result
+}
+
+func newTaskStatsResponse(stats *service.TaskStats) TaskStatsResponse {
+	if stats == nil {
+		return TaskStatsResponse{ByPriority: map[string]int{}, Projects: []ProjectStatsResponse{}}
+	}
+
+	projects := make([]ProjectStatsResponse, len(stats.Projects))
+	for i, project := range stats.Projects {
+		projects[i] = ProjectStatsResponse{
+			ProjectID: project.ProjectID,
+			Name:      project.Name,
+			Color:     project.Color,
+			Archived:  project.Archived,
+			Total:     project.Total,
+			Pending:   project.Pending,
+			Completed: project.Completed,
+			Overdue:   project.Overdue,
+		}
+	}
+
+	byPriority := make(map[string]int, len(stats.ByPriority))
+	for priority, count := range stats.ByPriority {
+		byPriority[string(priority)] = count
+	}
+
+	return TaskStatsResponse{
+		Total:       stats.Total,
+		Pending:     stats.Pending,
+		Completed:   stats.Completed,
+		Overdue:     stats.Overdue,
+		DueToday:    stats.DueToday,
+		DueThisWeek: stats.DueThisWeek,
+		ByPriority:  byPriority,
+		Projects:    projects,
+	}
+}
+
+func newProjectResponse(project *models.Project) *ProjectResponse {
+	return &ProjectResponse{
+		ID:       project.ID,
+		Name:     project.Name,
+		Color:    project.Color,
+		Archived: project.Archived,
+		Position: project.Position,
+		Inbox:    project.Inbox,
+	}
+}
+
+func newProjectResponses(projects []*models.Project) []ProjectResponse {
+	result := make([]ProjectResponse, len(projects))
+	for i, project := range projects {
+		result[i] = *newProjectResponse(project)
+	}
+	return result
+}
+
+func newSavedFilterResponse(filter *models.SavedFilter) *SavedFilterResponse {
+	return &SavedFilterResponse{
+		ID:        filter.ID,
+		Name:      filter.Name,
+		Query:     filter.Query,
+		TaskCount: filter.TaskCount,
+		Error:     filter.Error,
+	}
+}
+
+func newSavedFilterResponses(filters []*models.SavedFilter) []SavedFilterResponse {
+	result := make([]SavedFilterResponse, len(filters))
+	for i, filter := range filters {
+		result[i] = *newSavedFilterResponse(filter)
+	}
+	return result
+}
+
+func newDashboardResponse(data *usecase.DashboardData) *DashboardResponse {
+	if data == nil {
+		return &DashboardResponse{
+			Stats:         newTaskStatsResponse(nil),
+			RecentTasks:   []TaskResponse{},
+			OverdueTasks:  []TaskResponse{},
+			TodayTasks:    []TaskResponse{},
+			UpcomingTasks: []TaskResponse{},
+			Tags:          []TagResponse{},
+
+			UpcomingOccurrences: []OccurrenceResponse{},
+			SavedFilters:        []SavedFilterResponse{},
+		}
+	}
+	return &DashboardResponse{
+		Stats:         newTaskStatsResponse(data.Stats),
+		RecentTasks:   newTaskResponses(data.RecentTasks),
+		OverdueTasks:  newTaskResponses(data.OverdueTasks),
+		TodayTasks:    newTaskResponses(data.TodayTasks),
+		UpcomingTasks: newTaskResponses(data.UpcomingTasks),
+		Tags:          newTagResponses(data.Tags),
+
+		UpcomingOccurrences: newOccurrenceResponses(data.UpcomingOccurrences),
+		SavedFilters:        newSavedFilterResponses(data.SavedFilters),
+	}
+}
+
+func newOccurrenceResponses(occurrences []*models.Occurrence) []OccurrenceResponse {
+	result := make([]OccurrenceResponse, len(occurrences))
+	for i, occurrence := range occurrences {
+		result[i] = OccurrenceResponse{
+			TaskID:    occurrence.TaskID,
+			Title:     occurrence.Title,
+			Priority:  string(occurrence.Priority),
+			ProjectID: occurrence.ProjectID,
+			DueDate:   occurrence.DueDate,
+			AllDay:    occurrence.AllDay,
+		}
+	}
+	return result
+}
+
+func newReminderResponse(reminder *models.Reminder) *ReminderResponse {
+	return &ReminderResponse{
+		ID:            reminder.ID,
+		TaskID:        reminder.TaskID,
+		At:            reminder.At,
+		OffsetMinutes: reminder.OffsetMinutes,
+		RemindAt:      reminder.RemindAt,
+		FiredAt:       reminder.FiredAt,
+	}
+}
+
+func newReminderResponses(reminders []*models.Reminder) []ReminderResponse {
+	result := make([]ReminderResponse, len(reminders))
+	for i, reminder := range reminders {
+		result[i] = *newReminderResponse(reminder)
+	}
+	return result
+}
+
+func newReminderFiredResponse(due *models.DueReminder) ReminderFiredResponse {
+	return ReminderFiredResponse{
+		Reminder: *newReminderResponse(due.Reminder),
+		Task:     *newTaskResponse(due.Task),
+		Missed:   due.Missed,
+	}
+}
+
+func newQuickAddPreviewResponse(req *models.CreateTaskRequest) *QuickAddPreviewResponse {
+	tags := req.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &QuickAddPreviewResponse{
+		Title:      req.Title,
+		Priority:   string(req.Priority),
+		DueDate:    req.DueDate,
+		AllDay:     req.AllDay,
+		Tags:       tags,
+		Recurrence: req.Recurrence,
+	}
+}
+
+func newTaskEventResponse(event usecase.TaskEvent) TaskEventResponse {
+	return TaskEventResponse{
+		Type:       string(event.Type),
+		TaskIDs:    event.TaskIDs,
+		Tasks:      newTaskResponses(event.Tasks),
+		Changes:    event.Changes,
+		OccurredAt: event.OccurredAt,
+	}
+}
@@ -1,13 +1,27 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// поддерживаемые драйверы хранилища
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 )
 
 type Config struct {
 	Database DatabaseConfig `json:"database"`
+	Storage  StorageConfig  `json:"storage"`
 	App      AppConfig      `json:"app"`
+	Timeouts TimeoutConfig  `json:"timeouts"`
 }
 
 type DatabaseConfig struct {
@@ -19,10 +33,40 @@ type DatabaseConfig struct {
 	SSLMode  string `json:"ssl_mode"`
 }
 
+type StorageConfig struct {
+	Driver     string `json:"driver"`
+	SQLitePath string `json:"sqlite_path"`
+}
+
+// TimeoutConfig — сколько ждать базу в одной операции, 0 — без ограничения.
+// Превышение отменяет запрос, и биндинг возвращает ошибку вместо зависания интерфейса.
+type TimeoutConfig struct {
+	// списки, поиск, дашборд, журнал
+	Read time.Duration `json:"read"`
+	// изменение одной задачи, тега, проекта или фильтра, отмена и повтор
+	Write time.Duration `json:"write"`
+	// пакетные операции и очистка корзины
+	Bulk time.Duration `json:"bulk"`
+	// одна попытка подключения: ping и миграции
+	Connect time.Duration `json:"connect"`
+}
+
 type AppConfig struct {
 	Name        string `json:"name"`
 	Version     string `json:"version"`
 	Environment string `json:"environment"`
+	InstanceID  string `json:"instance_id"`
+	// сколько дней задачи лежат в корзине до автоочистки, 0 - хранить всегда
+	TrashRetentionDays int `json:"trash_retention_days"`
+	// автор изменений в журнале задач, по умолчанию user@host
+	Actor string `json:"actor"`
+	// показывать напоминания системными уведомлениями, а не только в окне приложения
+	DesktopNotifications bool `json:"desktop_notifications"`
+	// часовой пояс IANA для «сегодня», «недели» и сроков без смещения, пусто - системный.
+	// Пояс, выбранный в приложении и сохраненный в SettingsPath, важнее
+	Timezone string `json:"timezone"`
+	// файл настроек, которые пользователь меняет в приложении
+	SettingsPath string `json:"settings_path"`
 }
 
 // reading an env file
@@ -36,23 +80,55 @@ func New() *Config {
 			Database: getEnv("DB_NAME", "todoapp"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
+		Storage: StorageConfig{
+			Driver:     getEnv("STORAGE_DRIVER", DriverPostgres),
+			SQLitePath: getEnv("SQLITE_PATH", defaultSQLitePath()),
+		},
 		App: AppConfig{
-			Name:        "TodoApp",
-			Version:     "1.0.0",
-			Environment: getEnv("APP_ENV", "development"),
+			Name:               "TodoApp",
+			Version:            "1.0.0",
+			Environment:        getEnv("APP_ENV", "development"),
+			InstanceID:         newInstanceID(),
+			TrashRetentionDays: getEnvInt("TRASH_RETENTION_DAYS", 30),
+			Actor:              getEnv("TODO_ACTOR", defaultActor()),
+
+			DesktopNotifications: getEnvBool("DESKTOP_NOTIFICATIONS", true),
+			Timezone:             getEnv("APP_TIMEZONE", ""),
+			SettingsPath:         getEnv("SETTINGS_PATH", defaultSettingsPath()),
+		},
+		Timeouts: TimeoutConfig{
+			Read:  getEnvDuration("DB_READ_TIMEOUT", 5*time.Second),
+			Write: getEnvDuration("DB_WRITE_TIMEOUT", 10*time.Second),
+			Bulk:  getEnvDuration("DB_BULK_TIMEOUT", time.Minute),
+
+			Connect: getEnvDuration("DB_CONNECT_TIMEOUT", 30*time.Second),
 		},
 	}
 }
 
 // dns parsin for db connection
 func (c *Config) GetDSN() string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s application_name=%s",
 		c.Database.Host,
 		c.Database.Port,
 		c.Database.Username,
 		c.Database.Password,
 		c.Database.Database,
 		c.Database.SSLMode,
+		c.ApplicationName(),
+	)
+}
+
+// имя подключения в postgres, по нему триггеры отмечают источник изменений
+func (c *Config) ApplicationName() string {
+	return "todoapp-" + c.App.InstanceID
+}
+
+// DSN для sqlite: время храним в текстовом формате sqlite, чтобы сравнения работали
+func (c *Config) GetSQLiteDSN() string {
+	return fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite&_txlock=immediate",
+		c.Storage.SQLitePath,
 	)
 }
 
@@ -63,3 +139,69 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// числовая переменная окружения, некорректное или отрицательное значение заменяется значением по умолчанию
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// логическая переменная: 1, true, 0, false и т.п., некорректное значение заменяется значением по умолчанию
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// длительность вида "5s" или "1m30s", некорректное или отрицательное значение заменяется значением по умолчанию
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// файл базы в пользовательской директории конфигов (~/.config/TodoApp/todo.db)
+func defaultSQLitePath() string {
+	return filepath.Join(appConfigDir(), "todo.db")
+}
+
+// настройки рядом с базой (~/.config/TodoApp/settings.json)
+func defaultSettingsPath() string {
+	return filepath.Join(appConfigDir(), "settings.json")
+}
+
+func appConfigDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "TodoApp")
+}
+
+// случайный id запущенного экземпляра приложения
+func newInstanceID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "local"
+	}
+	return hex.EncodeToString(b)
+}
+
+// пользователь системы и имя машины, например "ivan@laptop"
+func defaultActor() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return name + "@" + host
+	}
+	return name
+}
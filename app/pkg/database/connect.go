@@ -1,11 +1,13 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 	"todo-lits-DMARK/app/pkg/config"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -15,11 +17,23 @@ import (
 )
 
 type Database struct {
-	DB *sql.DB
+	DB     *sql.DB
+	Driver string
 }
 
-// connecting to db
-func New(cfg *config.Config) (*Database, error) {
+// connecting to db, ctx ограничивает ping и миграции
+func New(ctx context.Context, cfg *config.Config) (*Database, error) {
+	switch cfg.Storage.Driver {
+	case config.DriverSQLite:
+		return newSQLite(ctx, cfg)
+	case config.DriverPostgres, "":
+		return newPostgres(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Storage.Driver)
+	}
+}
+
+func newPostgres(ctx context.Context, cfg *config.Config) (*Database, error) {
 	db, err := sql.Open("postgres", cfg.GetDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -28,23 +42,45 @@ func New(cfg *config.Config) (*Database, error) {
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(25)
 
-	if err := db.Ping(); err != nil {
+	// фоновое переподключение вызывает New снова и снова: незакрытый пул копил бы горутины
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &Database{DB: db}
+	database := &Database{DB: db, Driver: config.DriverPostgres}
 
-	if err := database.RunMigrations(); err != nil {
+	if err := database.RunMigrations(ctx); err != nil {
+		// не успели за отведенное время: попытка неудачна, переподключение повторит ее.
+		// Close ждет начатые запросы, поэтому пул закрывается в фоне после прерванной миграции.
+		if ctx.Err() != nil {
+			go db.Close()
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
 		log.Printf("Warning: failed to run migrations: %v", err)
 	}
 
 	return database, nil
 }
 
-// migration
-func (d *Database) RunMigrations() error {
-	driver, err := postgres.WithInstance(d.DB, &postgres.Config{})
+// migration, ctx ограничивает время: после отмены миграции останавливаются между шагами
+func (d *Database) RunMigrations(ctx context.Context) error {
+	if d.Driver == config.DriverSQLite {
+		return d.runSQLiteMigrations(ctx)
+	}
+
+	conn, err := d.DB.Conn(ctx)
 	if err != nil {
+		return fmt.Errorf("could not get connection for migrations: %w", err)
+	}
+
+	migrateConfig := &postgres.Config{}
+	if deadline, ok := ctx.Deadline(); ok {
+		migrateConfig.StatementTimeout = time.Until(deadline)
+	}
+	driver, err := postgres.WithConnection(ctx, conn, migrateConfig)
+	if err != nil {
+		conn.Close()
 		return fmt.Errorf("could not create postgres driver: %w", err)
 	}
 
@@ -72,17 +108,47 @@ func (d *Database) RunMigrations() error {
 		"postgres", driver,
 	)
 	if err != nil {
+		driver.Close()
 		return fmt.Errorf("could not create migrate instance: %w", err)
 	}
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("could not run migrations: %w", err)
+	// m.Close возвращает в пул соединение, взятое под миграции, и закрывает файлы миграций
+	if err := runUp(ctx, m, func() { m.Close() }); err != nil {
+		return err
 	}
 
 	log.Println("Migrations completed successfully")
 	return nil
 }
 
+// runUp применяет миграции, но не ждет дольше ctx. migrate не принимает контекст,
+// поэтому при отмене он получает сигнал остановиться и дорабатывает текущий шаг в фоне.
+// release, если задан, вызывается после завершения migrate, в том числе в фоне.
+func runUp(ctx context.Context, m *migrate.Migrate, release func()) error {
+	done := make(chan error, 1)
+	go func() {
+		err := m.Up()
+		if release != nil {
+			release()
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("could not run migrations: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		select {
+		case m.GracefulStop <- true:
+		default:
+		}
+		return fmt.Errorf("could not run migrations: %w", ctx.Err())
+	}
+}
+
 func (d *Database) Close() error {
 	return d.DB.Close()
 }
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"todo-lits-DMARK/app/pkg/config"
+)
+
+// отмененный контекст прерывает подключение, а не ждет сервер
+func TestNewCancelledContext(t *testing.T) {
+	cfg := config.New()
+	cfg.Storage.Driver = config.DriverSQLite
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "todo.db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if db, err := New(ctx, cfg); !errors.Is(err, context.Canceled) {
+		if db != nil {
+			db.Close()
+		}
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
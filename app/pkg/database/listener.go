@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+
+	"github.com/lib/pq"
+)
+
+// канал, в который пишет триггер notify_task_change
+const TaskChangesChannel = "tasks_changed"
+
+// операции из триггера и служебная resync после потери соединения
+const (
+	TaskChangeInsert = "insert"
+	TaskChangeUpdate = "update"
+	TaskChangeDelete = "delete"
+	TaskChangeResync = "resync"
+)
+
+// TaskChange — изменение задачи, сделанное любым клиентом общей базы
+type TaskChange struct {
+	Op     string `json:"op"`
+	TaskID int    `json:"id"`
+	Source string `json:"source"`
+}
+
+// ChangeListener слушает LISTEN tasks_changed на отдельном соединении
+type ChangeListener struct {
+	listener *pq.Listener
+	source   string
+}
+
+func NewChangeListener(cfg *config.Config) (*ChangeListener, error) {
+	listener := pq.NewListener(cfg.GetDSN(), time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Change listener connection event %d: %v", event, err)
+		}
+	})
+
+	if err := listener.Listen(TaskChangesChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", TaskChangesChannel, err)
+	}
+
+	return &ChangeListener{
+		listener: listener,
+		source:   cfg.ApplicationName(),
+	}, nil
+}
+
+// Listen блокируется до отмены ctx и передает в handle изменения других клиентов.
+// После переподключения уведомления могли потеряться, поэтому отправляется resync.
+func (l *ChangeListener) Listen(ctx context.Context, handle func(change TaskChange)) {
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case notification := <-l.listener.Notify:
+			if notification == nil {
+				handle(TaskChange{Op: TaskChangeResync})
+				continue
+			}
+
+			change, err := ParseTaskChange(notification.Extra)
+			if err != nil {
+				log.Printf("Skipping malformed task notification: %v", err)
+				continue
+			}
+			if change.Source == l.source {
+				continue
+			}
+			handle(change)
+
+		case <-ping.C:
+			if err := l.listener.Ping(); err != nil {
+				log.Printf("Change listener ping failed: %v", err)
+			}
+		}
+	}
+}
+
+func (l *ChangeListener) Close() error {
+	return l.listener.Close()
+}
+
+func ParseTaskChange(payload string) (TaskChange, error) {
+	var change TaskChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		return TaskChange{}, fmt.Errorf("invalid payload %q: %w", payload, err)
+	}
+
+	switch change.Op {
+	case TaskChangeInsert, TaskChangeUpdate, TaskChangeDelete:
+	default:
+		return TaskChange{}, fmt.Errorf("unknown operation %q", change.Op)
+	}
+	if change.TaskID <= 0 {
+		return TaskChange{}, fmt.Errorf("invalid task id %d", change.TaskID)
+	}
+
+	return change, nil
+}
@@ -0,0 +1,44 @@
+package database
+
+import "testing"
+
+func TestParseTaskChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    TaskChange
+		wantErr bool
+	}{
+		{
+			name:    "update",
+			payload: `{"op":"update","id":7,"source":"todoapp-abc"}`,
+			want:    TaskChange{Op: TaskChangeUpdate, TaskID: 7, Source: "todoapp-abc"},
+		},
+		{
+			name:    "insert without source",
+			payload: `{"op":"insert","id":1,"source":null}`,
+			want:    TaskChange{Op: TaskChangeInsert, TaskID: 1},
+		},
+		{name: "unknown op", payload: `{"op":"truncate","id":1}`, wantErr: true},
+		{name: "missing id", payload: `{"op":"delete"}`, wantErr: true},
+		{name: "not json", payload: `delete 1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTaskChange(tt.payload)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTaskChange: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	// драйвер "sqlite" для database/sql, чистый Go без cgo
+	_ "modernc.org/sqlite"
+)
+
+// встроенная база в файле, без внешних сервисов
+func newSQLite(ctx context.Context, cfg *config.Config) (*Database, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Storage.SQLitePath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create sqlite directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", cfg.GetSQLiteDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	// sqlite допускает только одного писателя, одно соединение избавляет от SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping sqlite database: %w", err)
+	}
+
+	database := &Database{DB: db, Driver: config.DriverSQLite}
+
+	if err := database.RunMigrations(ctx); err != nil {
+		// миграция, прерванная по ctx, еще дорабатывает шаг, а Close ждет начатые запросы
+		go db.Close()
+		return nil, fmt.Errorf("failed to run sqlite migrations: %w", err)
+	}
+
+	return database, nil
+}
+
+func (d *Database) runSQLiteMigrations(ctx context.Context) error {
+	driver, err := sqlite.WithInstance(d.DB, &sqlite.Config{})
+	if err != nil {
+		return fmt.Errorf("could not create sqlite driver: %w", err)
+	}
+
+	source, err := iofs.New(migrations.SQLite, "sqlite")
+	if err != nil {
+		return fmt.Errorf("could not open embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite", driver)
+	if err != nil {
+		return fmt.Errorf("could not create migrate instance: %w", err)
+	}
+
+	// m.Close закрыл бы и саму базу: драйвер создан поверх d.DB
+	if err := runUp(ctx, m, nil); err != nil {
+		return err
+	}
+
+	log.Println("SQLite migrations completed successfully")
+	return nil
+}
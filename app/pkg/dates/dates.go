@@ -0,0 +1,98 @@
+// Package dates разбирает сроки задач и считает границы дней и недель в зоне пользователя
+package dates
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	// база часовых поясов вшита в бинарник: на Windows системной нет
+	_ "time/tzdata"
+)
+
+// ErrInvalidDate возвращается для срока в неизвестном формате
+var ErrInvalidDate = errors.New("invalid date")
+
+// ErrInvalidTimezone возвращается для неизвестного имени часового пояса
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// DateLayout — срок на весь день без времени
+const DateLayout = "2006-01-02"
+
+// форматы без смещения, например из <input type="datetime-local">, читаются в зоне пользователя
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// Due — разобранный срок. У срока на весь день нет времени:
+// Time — последняя секунда этого дня в зоне пользователя.
+type Due struct {
+	Time   time.Time
+	AllDay bool
+}
+
+// Parse разбирает срок: RFC 3339 со смещением или Z, дату и время без смещения
+// в зоне loc или дату YYYY-MM-DD, которая означает весь день
+func Parse(value string, loc *time.Location) (*Due, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &Due{Time: t}, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return &Due{Time: t}, nil
+		}
+	}
+	if day, err := time.ParseInLocation(DateLayout, value, loc); err == nil {
+		return &Due{Time: EndOfDay(day), AllDay: true}, nil
+	}
+
+	return nil, fmt.Errorf("%w %q: expected RFC 3339 time or %s date", ErrInvalidDate, value, DateLayout)
+}
+
+// LoadLocation возвращает зону по имени IANA, например Europe/Moscow.
+// Пустое имя и Local означают системную зону.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidTimezone, name, err)
+	}
+	return loc, nil
+}
+
+// StartOfDay возвращает полночь дня t в его зоне
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// EndOfDay возвращает последнюю секунду дня t в его зоне.
+// День отсчитывается календарно, поэтому переход на летнее время его не сдвигает.
+func EndOfDay(t time.Time) time.Time {
+	return StartOfDay(t).AddDate(0, 0, 1).Add(-time.Second)
+}
+
+// DayRange возвращает границы дня now в его зоне
+func DayRange(now time.Time) (from, to time.Time) {
+	return StartOfDay(now), EndOfDay(now)
+}
+
+// WeekRange возвращает границы недели now с понедельника по воскресенье в его зоне
+func WeekRange(now time.Time) (from, to time.Time) {
+	weekday := int(now.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	from = StartOfDay(now.AddDate(0, 0, -(weekday - 1)))
+	to = from.AddDate(0, 0, 7).Add(-time.Second)
+	return from, to
+}
@@ -0,0 +1,89 @@
+package dates
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	moscow := mustLoad(t, "Europe/Moscow")
+
+	tests := []struct {
+		input  string
+		want   time.Time
+		allDay bool
+	}{
+		{"2025-03-15T09:30:00Z", time.Date(2025, 3, 15, 9, 30, 0, 0, time.UTC), false},
+		{"2025-03-15T09:30:00.123Z", time.Date(2025, 3, 15, 9, 30, 0, 123000000, time.UTC), false},
+		{"2025-03-15T09:30:00+05:00", time.Date(2025, 3, 15, 4, 30, 0, 0, time.UTC), false},
+		{"2025-03-15T09:30", time.Date(2025, 3, 15, 9, 30, 0, 0, moscow), false},
+		{"2025-03-15 09:30:15", time.Date(2025, 3, 15, 9, 30, 15, 0, moscow), false},
+		{" 2025-03-15 ", time.Date(2025, 3, 15, 23, 59, 59, 0, moscow), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			due, err := Parse(tt.input, moscow)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !due.Time.Equal(tt.want) || due.AllDay != tt.allDay {
+				t.Errorf("expected %v (all day %v), got %v (all day %v)", tt.want, tt.allDay, due.Time, due.AllDay)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "tomorrow", "15.03.2025", "2025-02-30", "2025-03-15T25:00:00Z"} {
+		if _, err := Parse(input, moscow); !errors.Is(err, ErrInvalidDate) {
+			t.Errorf("Parse(%q): expected ErrInvalidDate, got %v", input, err)
+		}
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	if loc := mustLoad(t, ""); loc != time.Local {
+		t.Errorf("expected local zone for empty name, got %v", loc)
+	}
+	if _, err := LoadLocation("Mars/Olympus"); !errors.Is(err, ErrInvalidTimezone) {
+		t.Errorf("expected ErrInvalidTimezone, got %v", err)
+	}
+}
+
+func TestRangesFollowZone(t *testing.T) {
+	// 23:30 воскресенья по UTC — уже понедельник в Токио
+	now := time.Date(2025, 3, 16, 23, 30, 0, 0, time.UTC)
+	tokyo := mustLoad(t, "Asia/Tokyo")
+
+	from, to := DayRange(now.In(tokyo))
+	if want := time.Date(2025, 3, 17, 0, 0, 0, 0, tokyo); !from.Equal(want) || !to.Equal(want.Add(24*time.Hour-time.Second)) {
+		t.Errorf("unexpected Tokyo day %v - %v", from, to)
+	}
+
+	from, to = WeekRange(now)
+	if want := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC); !from.Equal(want) || !to.Equal(want.AddDate(0, 0, 7).Add(-time.Second)) {
+		t.Errorf("unexpected UTC week %v - %v", from, to)
+	}
+	from, _ = WeekRange(now.In(tokyo))
+	if want := time.Date(2025, 3, 17, 0, 0, 0, 0, tokyo); !from.Equal(want) {
+		t.Errorf("expected Tokyo week to start %v, got %v", want, from)
+	}
+}
+
+func TestEndOfDayAcrossDST(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	// 30 марта 2025 в Берлине длится 23 часа
+	day := time.Date(2025, 3, 30, 12, 0, 0, 0, berlin)
+	if got, want := EndOfDay(day), time.Date(2025, 3, 30, 23, 59, 59, 0, berlin); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -19,19 +19,32 @@ const (
 
 type Task struct {
 	ID          int          `json:"id" db:"id"`
+	ParentID    *int         `json:"parent_id" db:"parent_id"`
+	ProjectID   int          `json:"project_id" db:"project_id"`
 	Title       string       `json:"title" db:"title" validate:"required,min=1,max=255"`
 	Description string       `json:"description" db:"description"`
 	Status      TaskStatus   `json:"status" db:"status"`
 	Priority    TaskPriority `json:"priority" db:"priority"`
 	DueDate     *time.Time   `json:"due_date" db:"due_date"`
+	AllDay      bool         `json:"all_day" db:"all_day"` // срок — весь день DueDate без времени
+	Recurrence  string       `json:"recurrence" db:"recurrence"`
 	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty" db:"deleted_at"` // nil, если задача не в корзине
+	Version     int          `json:"version" db:"version"`                 // растет при каждом изменении задачи
+	SnoozeCount int          `json:"snooze_count" db:"snooze_count"`       // сколько раз задачу откладывали
+	Tags        []string     `json:"tags"`
 }
 type CreateTaskRequest struct {
+	ParentID    *int         `json:"parent_id,omitempty" validate:"omitempty,gt=0"`
+	ProjectID   *int         `json:"project_id,omitempty" validate:"omitempty,gt=0"`
 	Title       string       `json:"title" validate:"required,min=1,max=255"`
 	Description string       `json:"description" validate:"max=1000"`
 	Priority    TaskPriority `json:"priority" validate:"oneof=low medium high"`
 	DueDate     *time.Time   `json:"due_date"`
+	AllDay      bool         `json:"all_day,omitempty"` // DueDate сводится к концу своего дня в зоне пользователя
+	Tags        []string     `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
+	Recurrence  string       `json:"recurrence,omitempty" validate:"max=500"`
 }
 type UpdateTaskRequest struct {
 	Title       *string       `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
@@ -39,13 +52,63 @@ type UpdateTaskRequest struct {
 	Status      *TaskStatus   `json:"status,omitempty" validate:"omitempty,oneof=pending completed"`
 	Priority    *TaskPriority `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
 	DueDate     *time.Time    `json:"due_date,omitempty"`
+	AllDay      *bool         `json:"all_day,omitempty"`                                        // nil при новом DueDate — срок со временем
+	Tags        *[]string     `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"` // nil — без изменений, пустой срез снимает теги
+	Recurrence  *string       `json:"recurrence,omitempty" validate:"omitempty,max=500"`        // пустая строка отключает повторение
+	Version     *int          `json:"version,omitempty" validate:"omitempty,gt=0"`              // версия, которую видел клиент; nil — без проверки
 }
+
+// TaskUpdate — изменения одной задачи в пакетной операции
+type TaskUpdate struct {
+	ID      int
+	Changes *UpdateTaskRequest
+}
+
+// HasChanges сообщает, задано ли хотя бы одно поле для обновления
+func (u *UpdateTaskRequest) HasChanges() bool {
+	return u.Title != nil || u.Description != nil || u.Status != nil ||
+		u.Priority != nil || u.DueDate != nil || u.AllDay != nil || u.Tags != nil || u.Recurrence != nil
+}
+
+// TaskFilter — условия выборки задач, HideArchived скрывает задачи архивных проектов
 type TaskFilter struct {
-	Status   *TaskStatus   `json:"status,omitempty"`
-	Priority *TaskPriority `json:"priority,omitempty"`
-	DateFrom *time.Time    `json:"date_from,omitempty"`
-	DateTo   *time.Time    `json:"date_to,omitempty"`
+	Status       *TaskStatus   `json:"status,omitempty"`
+	Priority     *TaskPriority `json:"priority,omitempty"`
+	DateFrom     *time.Time    `json:"date_from,omitempty"`
+	DateTo       *time.Time    `json:"date_to,omitempty"`
+	Tags         []string      `json:"tags,omitempty"`
+	TagMatch     TagMatchMode  `json:"tag_match,omitempty"`
+	ProjectID    *int          `json:"project_id,omitempty"`
+	HideArchived bool          `json:"hide_archived,omitempty"`
+	Query        *TaskQuery    `json:"query,omitempty"`
+}
+
+// TagMatchMode задает, должна ли задача иметь любой из тегов фильтра или все сразу
+type TagMatchMode string
+
+const (
+	TagMatchAny TagMatchMode = "any"
+	TagMatchAll TagMatchMode = "all"
+)
+
+// Tag — метка задачи, имя уникально и хранится в нижнем регистре
+type Tag struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name" validate:"required,min=1,max=50"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// количество задач с этим тегом, заполняется GetTags
+	TaskCount int `json:"task_count"`
 }
+
+// SubtaskMode определяет, что делать с открытыми подзадачами при завершении родителя
+type SubtaskMode string
+
+const (
+	SubtaskModeNone     SubtaskMode = "none"
+	SubtaskModeComplete SubtaskMode = "complete"
+	SubtaskModeBlock    SubtaskMode = "block"
+)
+
 type TaskSort struct {
 	Field string `json:"field" validate:"oneof=created_at due_date priority"`
 	Order string `json:"order" validate:"oneof=asc desc"`
@@ -58,7 +121,12 @@ func (t *Task) IsOverdue() bool {
 	return t.DueDate.Before(time.Now())
 }
 func (t *Task) PriorityValue() int {
-	switch t.Priority {
+	return t.Priority.Rank()
+}
+
+// Rank упорядочивает приоритеты от low к high, неизвестный приоритет дает 0
+func (p TaskPriority) Rank() int {
+	switch p {
 	case TaskPriorityHigh:
 		return 3
 	case TaskPriorityMedium:
@@ -0,0 +1,24 @@
+package models
+
+// PageRequest запрашивает страницу списка. Cursor берется из NextCursor предыдущей
+// страницы, пустой Cursor означает первую страницу. Курсор привязан к сортировке:
+// при смене сортировки или фильтра листать нужно заново.
+type PageRequest struct {
+	Limit  int    `json:"limit"`
+	Cursor string `json:"cursor"`
+}
+
+// TaskPage — страница задач. NextCursor пустой на последней странице,
+// Total — число задач под фильтром без учета курсора.
+type TaskPage struct {
+	Tasks      []*Task `json:"tasks"`
+	NextCursor string  `json:"next_cursor"`
+	Total      int     `json:"total"`
+}
+
+// SearchPage — страница результатов поиска в порядке убывания Rank
+type SearchPage struct {
+	Results    []*SearchResult `json:"results"`
+	NextCursor string          `json:"next_cursor"`
+	Total      int             `json:"total"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Project группирует задачи, каждая задача принадлежит ровно одному проекту.
+// Проект Inbox создается миграцией и получает задачи без явно указанного проекта.
+type Project struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Color     string    `json:"color" db:"color"`
+	Archived  bool      `json:"archived" db:"archived"`
+	Position  int       `json:"position" db:"position"`
+	Inbox     bool      `json:"inbox" db:"is_inbox"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateProjectRequest struct {
+	Name  string `json:"name" validate:"required,min=1,max=100"`
+	Color string `json:"color" validate:"omitempty,hexcolor"`
+}
+
+type UpdateProjectRequest struct {
+	Name     *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Color    *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Archived *bool   `json:"archived,omitempty"`
+}
+
+// DefaultProjectColor используется, если цвет проекта не задан
+const DefaultProjectColor = "#6366f1"
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// TaskQuery — разобранный запрос языка фильтров. Условия и текстовые термины
+// объединяются через AND между собой и с остальными полями TaskFilter.
+type TaskQuery struct {
+	Conditions []QueryCondition `json:"conditions"`
+	Text       []SearchTerm     `json:"text"`
+	// сортировка из запроса заменяет сортировку, переданную отдельно
+	Sort *TaskSort `json:"sort,omitempty"`
+}
+
+type QueryField string
+
+const (
+	QueryFieldStatus   QueryField = "status"
+	QueryFieldPriority QueryField = "priority"
+	QueryFieldTag      QueryField = "tag"
+	QueryFieldProject  QueryField = "project"
+	QueryFieldDue      QueryField = "due"
+	QueryFieldCreated  QueryField = "created"
+)
+
+// QueryOp — операция условия после разбора: сравнения приоритетов сводятся к QueryOpIn,
+// относительные даты вроде 7d или today — к абсолютным границам
+type QueryOp string
+
+const (
+	QueryOpIn     QueryOp = "in"     // значение поля входит в Values, для проектов в ProjectIDs
+	QueryOpBefore QueryOp = "before" // дата раньше From
+	QueryOpAfter  QueryOp = "after"  // дата не раньше From
+	QueryOpRange  QueryOp = "range"  // From <= дата < To
+	QueryOpEmpty  QueryOp = "empty"  // дата не задана
+)
+
+// QueryCondition — одно условие запроса. Negated инвертирует условие целиком,
+// задачи без даты при этом попадают в результат: -due:<7d оставляет и задачи без срока.
+type QueryCondition struct {
+	Field      QueryField `json:"field"`
+	Op         QueryOp    `json:"op"`
+	Values     []string   `json:"values,omitempty"`
+	ProjectIDs []int      `json:"project_ids,omitempty"`
+	From       time.Time  `json:"from,omitempty"`
+	To         time.Time  `json:"to,omitempty"`
+	Negated    bool       `json:"negated"`
+}
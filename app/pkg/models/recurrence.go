@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Occurrence — будущее повторение задачи, которое еще не создано.
+// Следующая задача серии появляется только после завершения текущей.
+type Occurrence struct {
+	TaskID    int          `json:"task_id"`
+	Title     string       `json:"title"`
+	Priority  TaskPriority `json:"priority"`
+	ProjectID int          `json:"project_id"`
+	DueDate   time.Time    `json:"due_date"`
+	AllDay    bool         `json:"all_day"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Reminder — напоминание о задаче в момент At или за OffsetMinutes минут до ее срока.
+// RemindAt вычисляется при создании и пересчитывается, когда у задачи меняется срок.
+type Reminder struct {
+	ID            int        `json:"id" db:"id"`
+	TaskID        int        `json:"task_id" db:"task_id"`
+	At            *time.Time `json:"at,omitempty" db:"at"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty" db:"offset_minutes"`
+	RemindAt      time.Time  `json:"remind_at" db:"remind_at"`
+	FiredAt       *time.Time `json:"fired_at,omitempty" db:"fired_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ReminderTime вычисляет момент срабатывания напоминания со смещением для срока due
+func ReminderTime(due time.Time, offsetMinutes int) time.Time {
+	return due.Add(-time.Duration(offsetMinutes) * time.Minute)
+}
+
+// CreateReminderRequest задает ровно одно из At и OffsetMinutes
+type CreateReminderRequest struct {
+	At            *time.Time `json:"at,omitempty"`
+	OffsetMinutes *int       `json:"offset_minutes,omitempty" validate:"omitempty,gte=0,lte=525600"`
+}
+
+// SnoozeOption — на сколько отложить задачу. Все варианты, кроме SnoozeCustom,
+// отсчитываются от текущего момента.
+type SnoozeOption string
+
+const (
+	Snooze15Minutes SnoozeOption = "15m"
+	Snooze1Hour     SnoozeOption = "1h"
+	SnoozeTomorrow  SnoozeOption = "tomorrow"  // завтра утром
+	SnoozeNextWeek  SnoozeOption = "next_week" // утро следующего понедельника
+	SnoozeCustom    SnoozeOption = "custom"    // до указанного времени
+)
+
+// DueReminder — сработавшее напоминание вместе с задачей.
+// Missed — время напоминания прошло, пока приложение было закрыто.
+type DueReminder struct {
+	Reminder *Reminder `json:"reminder"`
+	Task     *Task     `json:"task"`
+	Missed   bool      `json:"missed"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RevisionAction — операция, записанная в журнал изменений задачи
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"  // перенос в корзину
+	RevisionRestore RevisionAction = "restore" // возврат из корзины
+	RevisionToggle  RevisionAction = "toggle"
+	RevisionRevert  RevisionAction = "revert" // возврат к одной из прошлых ревизий
+	RevisionSnooze  RevisionAction = "snooze" // перенос срока кнопкой «отложить»
+	RevisionUndo    RevisionAction = "undo"
+	RevisionRedo    RevisionAction = "redo"
+)
+
+// TaskRevision — запись журнала в таблице task_events: кто, когда и какие поля изменил.
+// Task — полное состояние задачи после изменения, к нему можно вернуться.
+type TaskRevision struct {
+	ID        int            `json:"id" db:"id"`
+	TaskID    int            `json:"task_id" db:"task_id"`
+	Action    RevisionAction `json:"action" db:"action"`
+	Actor     string         `json:"actor" db:"actor"`
+	Changes   []FieldChange  `json:"changes" db:"changes"`
+	Task      *Task          `json:"task" db:"snapshot"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+}
+
+// FieldChange — значение поля до и после изменения в текстовом виде.
+// Пустая строка означает отсутствие значения: нет срока, родителя или тегов.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// DiffTasks возвращает измененные поля; before nil — задача только что создана
+func DiffTasks(before, after *Task) []FieldChange {
+	if before == nil {
+		before = &Task{}
+	}
+
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"parent_id", formatID(before.ParentID), formatID(after.ParentID)},
+		{"project_id", formatProjectID(before.ProjectID), formatProjectID(after.ProjectID)},
+		{"title", before.Title, after.Title},
+		{"description", before.Description, after.Description},
+		{"status", string(before.Status), string(after.Status)},
+		{"priority", string(before.Priority), string(after.Priority)},
+		{"due_date", formatTime(before.DueDate), formatTime(after.DueDate)},
+		{"all_day", formatFlag(before.AllDay), formatFlag(after.AllDay)},
+		{"recurrence", before.Recurrence, after.Recurrence},
+		{"tags", strings.Join(before.Tags, ", "), strings.Join(after.Tags, ", ")},
+		{"deleted_at", formatTime(before.DeletedAt), formatTime(after.DeletedAt)},
+		{"snooze_count", formatCount(before.SnoozeCount), formatCount(after.SnoozeCount)},
+	}
+
+	changes := []FieldChange{}
+	for _, field := range fields {
+		if field.before != field.after {
+			changes = append(changes, FieldChange{Field: field.name, Before: field.before, After: field.after})
+		}
+	}
+	return changes
+}
+
+func formatID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
+
+func formatProjectID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+func formatCount(count int) string {
+	if count == 0 {
+		return ""
+	}
+	return strconv.Itoa(count)
+}
+
+func formatFlag(flag bool) string {
+	if !flag {
+		return ""
+	}
+	return "true"
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
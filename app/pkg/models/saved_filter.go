@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// SavedFilter — умный список: именованный запрос языка фильтров.
+// Query хранится строкой и разбирается при каждом вычислении, поэтому due:<7d
+// всегда считается от текущего дня.
+type SavedFilter struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Query     string    `json:"query" db:"query"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// заполняются GetDashboardData: число задач в списке или ошибка разбора,
+	// например если проект из запроса удален
+	TaskCount int    `json:"task_count"`
+	Error     string `json:"error,omitempty"`
+}
+
+type CreateSavedFilterRequest struct {
+	Name  string `json:"name" validate:"required,min=1,max=100"`
+	Query string `json:"query" validate:"required,max=1000"`
+}
+
+type UpdateSavedFilterRequest struct {
+	Name  *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Query *string `json:"query,omitempty" validate:"omitempty,min=1,max=1000"`
+}
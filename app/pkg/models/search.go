@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Совпадения в SearchResult.Snippet обрамляются этими метками, остальной текст не экранирован
+const (
+	SnippetMatchStart = "<mark>"
+	SnippetMatchEnd   = "</mark>"
+)
+
+// SearchTerm — слово или фраза поискового запроса.
+// Prefix ищет слова, начинающиеся с Text, Negated исключает задачи с совпадением.
+type SearchTerm struct {
+	Text    string `json:"text"`
+	Phrase  bool   `json:"phrase"`
+	Prefix  bool   `json:"prefix"`
+	Negated bool   `json:"negated"`
+}
+
+// Normalize оставляет в термине только слова из букв и цифр: пунктуация не ищется,
+// а слово с разделителями, например e-mail, ищется как фраза и без префикса.
+// ok == false, если слов не осталось.
+func (t SearchTerm) Normalize() (term SearchTerm, ok bool) {
+	words := strings.FieldsFunc(t.Text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	switch len(words) {
+	case 0:
+		return t, false
+	case 1:
+		t.Text = words[0]
+		t.Phrase = false
+	default:
+		t.Text = strings.Join(words, " ")
+		t.Phrase = true
+		t.Prefix = false
+	}
+
+	return t, true
+}
+
+// SearchQuery — разобранный запрос, все термины объединяются через AND.
+// Хотя бы один термин должен быть без отрицания. Cursor продолжает выдачу
+// с места, где закончилась предыдущая страница.
+type SearchQuery struct {
+	Terms  []SearchTerm `json:"terms"`
+	Limit  int          `json:"limit"`
+	Cursor string       `json:"cursor,omitempty"`
+}
+
+// SearchResult — найденная задача, больший Rank означает лучшее совпадение
+type SearchResult struct {
+	Task    *Task   `json:"task"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// StatsPeriods — границы периодов для счетчиков дашборда. Сервис вычисляет их
+// в локальной зоне, границы включительные, как в GetByDateRange.
+type StatsPeriods struct {
+	Now       time.Time
+	TodayFrom time.Time
+	TodayTo   time.Time
+	WeekFrom  time.Time
+	WeekTo    time.Time
+}
+
+// TaskCountGroup — число задач с одинаковыми проектом, статусом и приоритетом.
+// Overdue, DueToday и DueThisWeek считают только открытые задачи группы.
+type TaskCountGroup struct {
+	ProjectID   int
+	Status      TaskStatus
+	Priority    TaskPriority
+	Count       int
+	Overdue     int
+	DueToday    int
+	DueThisWeek int
+}
@@ -0,0 +1,36 @@
+package models
+
+// TaskNode — задача с вложенными подзадачами и прогрессом по прямым потомкам
+type TaskNode struct {
+	Task              *Task       `json:"task"`
+	Children          []*TaskNode `json:"children"`
+	CompletedChildren int         `json:"completed_children"`
+	TotalChildren     int         `json:"total_children"`
+}
+
+// BuildTaskForest собирает деревья из плоского списка, сохраняя порядок задач.
+// Корнями становятся задачи без родителя или с родителем вне списка.
+func BuildTaskForest(tasks []*Task) []*TaskNode {
+	nodes := make(map[int]*TaskNode, len(tasks))
+	for _, task := range tasks {
+		nodes[task.ID] = &TaskNode{Task: task, Children: []*TaskNode{}}
+	}
+
+	roots := []*TaskNode{}
+	for _, task := range tasks {
+		node := nodes[task.ID]
+		if task.ParentID != nil {
+			if parent, ok := nodes[*task.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				parent.TotalChildren++
+				if task.Status == TaskStatusCompleted {
+					parent.CompletedChildren++
+				}
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	return roots
+}
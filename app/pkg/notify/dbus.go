@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// интерфейс уведомлений freedesktop.org, его реализуют GNOME, KDE, dunst и другие
+const (
+	notificationsService = "org.freedesktop.Notifications"
+	notificationsPath    = dbus.ObjectPath("/org/freedesktop/Notifications")
+	notifyMethod         = notificationsService + ".Notify"
+)
+
+// expireDefault оставляет время показа на усмотрение сервера уведомлений
+const expireDefault = int32(-1)
+
+type dbusNotifier struct {
+	conn    *dbus.Conn
+	appName string
+	icon    string
+}
+
+// NewDBusNotifier отправляет уведомления через org.freedesktop.Notifications на шине conn.
+// Close закрывает conn.
+func NewDBusNotifier(conn *dbus.Conn, appName, icon string) Notifier {
+	return &dbusNotifier{conn: conn, appName: appName, icon: icon}
+}
+
+func (n *dbusNotifier) Notify(ctx context.Context, notification Notification) error {
+	obj := n.conn.Object(notificationsService, notificationsPath)
+
+	var id uint32
+	err := obj.CallWithContext(ctx, notifyMethod, 0,
+		n.appName,
+		uint32(0), // новое уведомление, а не замена существующего
+		n.icon,
+		notification.Title,
+		notification.Body,
+		[]string{},
+		map[string]dbus.Variant{},
+		expireDefault,
+	).Store(&id)
+	if err != nil {
+		return fmt.Errorf("failed to send desktop notification: %w", err)
+	}
+	return nil
+}
+
+func (n *dbusNotifier) Close() error {
+	return n.conn.Close()
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// минимальная сессионная шина, на которой тест сам играет роль сервера уведомлений
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:tmpdir=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>`
+
+type notifyCall struct {
+	appName, summary, body string
+	expire                 int32
+}
+
+// fakeNotifications реализует метод Notify из org.freedesktop.Notifications
+type fakeNotifications struct {
+	calls chan notifyCall
+}
+
+func (f *fakeNotifications) Notify(appName string, replacesID uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, expire int32) (uint32, *dbus.Error) {
+	f.calls <- notifyCall{appName: appName, summary: summary, body: body, expire: expire}
+	return 1, nil
+}
+
+// startSessionBus запускает отдельный dbus-daemon и возвращает его адрес
+func startSessionBus(t *testing.T) string {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	if err := os.WriteFile(config, []byte(fmt.Sprintf(busConfig, dir)), 0o600); err != nil {
+		t.Fatalf("write bus config: %v", err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--print-address", "--nofork")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+func TestDBusNotifierSendsNotification(t *testing.T) {
+	address := startSessionBus(t)
+
+	server, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("connect server: %v", err)
+	}
+	defer server.Close()
+
+	fake := &fakeNotifications{calls: make(chan notifyCall, 1)}
+	if err := server.Export(fake, notificationsPath, notificationsService); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if reply, err := server.RequestName(notificationsService, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("request name: %v, reply %v", err, reply)
+	}
+
+	client, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("connect client: %v", err)
+	}
+	notifier := NewDBusNotifier(client, "TodoApp", "")
+	defer notifier.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifier.Notify(ctx, Notification{Title: "Напоминание", Body: "Позвонить"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	select {
+	case call := <-fake.calls:
+		want := notifyCall{appName: "TodoApp", summary: "Напоминание", body: "Позвонить", expire: expireDefault}
+		if call != want {
+			t.Errorf("expected %+v, got %+v", want, call)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not received")
+	}
+}
+
+func TestDBusNotifierWithoutServer(t *testing.T) {
+	address := startSessionBus(t)
+
+	client, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("connect client: %v", err)
+	}
+	notifier := NewDBusNotifier(client, "TodoApp", "")
+	defer notifier.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := notifier.Notify(ctx, Notification{Title: "title"}); err == nil {
+		t.Error("expected error when no notification server owns the name")
+	}
+}
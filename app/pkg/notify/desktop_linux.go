@@ -0,0 +1,18 @@
+//go:build linux
+
+package notify
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// NewDesktopNotifier подключается к сессионной шине D-Bus (DBUS_SESSION_BUS_ADDRESS)
+func NewDesktopNotifier(appName, icon string) (Notifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+	return NewDBusNotifier(conn, appName, icon), nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package notify
+
+import "errors"
+
+// NewDesktopNotifier пока поддерживает только Linux, на других системах
+// напоминания показываются внутри окна приложения
+func NewDesktopNotifier(appName, icon string) (Notifier, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,27 @@
+package notify
+
+import "context"
+
+// Notification — системное уведомление рабочего стола
+type Notification struct {
+	Title string
+	Body  string
+}
+
+// Notifier показывает уведомления вне окна приложения
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+	Close() error
+}
+
+type nopNotifier struct{}
+
+// NewNopNotifier возвращает Notifier, который ничего не показывает,
+// например когда шины уведомлений нет
+func NewNopNotifier() Notifier {
+	return nopNotifier{}
+}
+
+func (nopNotifier) Notify(context.Context, Notification) error { return nil }
+
+func (nopNotifier) Close() error { return nil }
@@ -0,0 +1,2370 @@
+// Package repotest содержит общий набор тестов, который обязана проходить
+// каждая реализация repository.TaskRepositoryInterface.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+)
+
+// Factory возвращает пустой репозиторий для одного подтеста
+type Factory func(t *testing.T) repository.TaskRepositoryInterface
+
+// RunTaskRepositorySuite прогоняет все проверки семантики репозитория
+func RunTaskRepositorySuite(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.TaskRepositoryInterface)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"GetMissing", testGetMissing},
+		{"FilterByStatusAndPriority", testFilterByStatusAndPriority},
+		{"FilterByDateRange", testFilterByDateRange},
+		{"DefaultSortIsNewestFirst", testDefaultSort},
+		{"SortByDueDate", testSortByDueDate},
+		{"SortByPriority", testSortByPriority},
+		{"Update", testUpdate},
+		{"UpdateWithoutFields", testUpdateWithoutFields},
+		{"UpdateMissing", testUpdateMissing},
+		{"UpdateVersionConflict", testUpdateVersionConflict},
+		{"BulkUpdate", testBulkUpdate},
+		{"BulkUpdateIsAllOrNothing", testBulkUpdateIsAllOrNothing},
+		{"Delete", testDelete},
+		{"Trash", testTrash},
+		{"TrashRestoreDetachesFromTrashedParent", testTrashRestoreDetachesFromTrashedParent},
+		{"PurgeDeleted", testPurgeDeleted},
+		{"Snapshots", testSnapshots},
+		{"Revisions", testRevisions},
+		{"Reminders", testReminders},
+		{"RemindersFollowDueDate", testRemindersFollowDueDate},
+		{"Snooze", testSnooze},
+		{"AllDay", testAllDay},
+		{"GetOverdue", testGetOverdue},
+		{"GetByDateRange", testGetByDateRange},
+		{"CountTasks", testCountTasks},
+		{"CreateSubtask", testCreateSubtask},
+		{"CreateSubtaskWithMissingParent", testCreateSubtaskWithMissingParent},
+		{"GetSubtree", testGetSubtree},
+		{"SetParent", testSetParent},
+		{"SetParentMovesSubtreeToProject", testSetParentMovesSubtreeToProject},
+		{"DeleteKeepingSubtasks", testDeleteKeepingSubtasks},
+		{"DeleteCascadesToSubtasks", testDeleteCascades},
+		{"CreateWithTags", testCreateWithTags},
+		{"UpdateTags", testUpdateTags},
+		{"FilterByTags", testFilterByTags},
+		{"FilterByQuery", testFilterByQuery},
+		{"Pagination", testPagination},
+		{"PaginationIsStable", testPaginationIsStable},
+		{"SearchPagination", testSearchPagination},
+		{"TagCounts", testTagCounts},
+		{"CreateDuplicateTag", testCreateDuplicateTag},
+		{"RenameTag", testRenameTag},
+		{"Recurrence", testRecurrence},
+		{"Search", testSearch},
+		{"SearchFollowsUpdates", testSearchFollowsUpdates},
+		{"NewTaskGoesToInbox", testNewTaskGoesToInbox},
+		{"CreateProjectAppends", testCreateProjectAppends},
+		{"CreateTaskInMissingProject", testCreateTaskInMissingProject},
+		{"UpdateProject", testUpdateProject},
+		{"FilterByProject", testFilterByProject},
+		{"HideArchivedProjects", testHideArchivedProjects},
+		{"ReorderProjects", testReorderProjects},
+		{"DeleteProjectMovesTasks", testDeleteProjectMovesTasks},
+		{"MoveToProjectMovesSubtree", testMoveToProject},
+		{"BulkDelete", testBulkDelete},
+		{"BulkMoveToProject", testBulkMoveToProject},
+		{"DeleteTag", testDeleteTag},
+		{"SavedFilters", testSavedFilters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func testCreateAndGet(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	due := time.Now().Add(48 * time.Hour)
+	task := &models.Task{
+		Title:       "Write tests",
+		Description: "conformance suite",
+		Priority:    models.TaskPriorityHigh,
+		DueDate:     &due,
+	}
+
+	before := time.Now().Add(-time.Second)
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if task.ID <= 0 {
+		t.Fatalf("expected positive id, got %d", task.ID)
+	}
+	if task.Status != models.TaskStatusPending {
+		t.Errorf("expected status pending, got %q", task.Status)
+	}
+	if task.CreatedAt.Before(before) || task.UpdatedAt.Before(before) {
+		t.Errorf("timestamps not set: created %v, updated %v", task.CreatedAt, task.UpdatedAt)
+	}
+
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Title != task.Title || got.Description != task.Description || got.Priority != task.Priority {
+		t.Errorf("stored task differs: got %+v, want %+v", got, task)
+	}
+	if got.Status != models.TaskStatusPending {
+		t.Errorf("expected stored status pending, got %q", got.Status)
+	}
+	assertTimePtr(t, "due_date", got.DueDate, &due)
+	assertTime(t, "created_at", got.CreatedAt, task.CreatedAt)
+
+	second := mustCreate(t, repo, "Second", models.TaskPriorityLow, nil)
+	if second.ID == task.ID {
+		t.Errorf("expected unique ids, both are %d", second.ID)
+	}
+}
+
+func testGetMissing(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	_, err := repo.GetByID(ctx, 999999)
+	if !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func testFilterByStatusAndPriority(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	low := mustCreate(t, repo, "low", models.TaskPriorityLow, nil)
+	high := mustCreate(t, repo, "high", models.TaskPriorityHigh, nil)
+	done := mustCreate(t, repo, "done", models.TaskPriorityHigh, nil)
+	mustComplete(t, repo, done.ID)
+
+	pending := models.TaskStatusPending
+	completed := models.TaskStatusCompleted
+	highPriority := models.TaskPriorityHigh
+
+	tests := []struct {
+		name   string
+		filter *models.TaskFilter
+		want   []int
+	}{
+		{"nil filter", nil, []int{low.ID, high.ID, done.ID}},
+		{"empty filter", &models.TaskFilter{}, []int{low.ID, high.ID, done.ID}},
+		{"pending", &models.TaskFilter{Status: &pending}, []int{low.ID, high.ID}},
+		{"completed", &models.TaskFilter{Status: &completed}, []int{done.ID}},
+		{"high", &models.TaskFilter{Priority: &highPriority}, []int{high.ID, done.ID}},
+		{"pending and high", &models.TaskFilter{Status: &pending, Priority: &highPriority}, []int{high.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := repo.GetAll(ctx, tt.filter, nil)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			assertSameIDs(t, tasks, tt.want)
+		})
+	}
+}
+
+func testFilterByDateRange(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	base := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	day1 := base
+	day2 := base.Add(24 * time.Hour)
+	day3 := base.Add(48 * time.Hour)
+
+	first := mustCreate(t, repo, "first", models.TaskPriorityMedium, &day1)
+	second := mustCreate(t, repo, "second", models.TaskPriorityMedium, &day2)
+	third := mustCreate(t, repo, "third", models.TaskPriorityMedium, &day3)
+	mustCreate(t, repo, "no due date", models.TaskPriorityMedium, nil)
+
+	tests := []struct {
+		name   string
+		filter *models.TaskFilter
+		want   []int
+	}{
+		{"from inclusive", &models.TaskFilter{DateFrom: &day2}, []int{second.ID, third.ID}},
+		{"to inclusive", &models.TaskFilter{DateTo: &day2}, []int{first.ID, second.ID}},
+		{"between", &models.TaskFilter{DateFrom: &day2, DateTo: &day2}, []int{second.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := repo.GetAll(ctx, tt.filter, nil)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			assertSameIDs(t, tasks, tt.want)
+		})
+	}
+}
+
+func testDefaultSort(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	first := mustCreate(t, repo, "first", models.TaskPriorityMedium, nil)
+	time.Sleep(5 * time.Millisecond)
+	second := mustCreate(t, repo, "second", models.TaskPriorityMedium, nil)
+	time.Sleep(5 * time.Millisecond)
+	third := mustCreate(t, repo, "third", models.TaskPriorityMedium, nil)
+
+	tasks, err := repo.GetAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertOrder(t, tasks, []int{third.ID, second.ID, first.ID})
+
+	tasks, err = repo.GetAll(ctx, nil, &models.TaskSort{Field: "created_at", Order: "asc"})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertOrder(t, tasks, []int{first.ID, second.ID, third.ID})
+}
+
+func testSortByDueDate(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	later := time.Now().Add(72 * time.Hour)
+	sooner := time.Now().Add(24 * time.Hour)
+
+	noDue := mustCreate(t, repo, "no due", models.TaskPriorityMedium, nil)
+	late := mustCreate(t, repo, "later", models.TaskPriorityMedium, &later)
+	soon := mustCreate(t, repo, "sooner", models.TaskPriorityMedium, &sooner)
+
+	tasks, err := repo.GetAll(ctx, nil, &models.TaskSort{Field: "due_date", Order: "asc"})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertOrder(t, tasks, []int{soon.ID, late.ID, noDue.ID})
+
+	tasks, err = repo.GetAll(ctx, nil, &models.TaskSort{Field: "due_date", Order: "desc"})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertOrder(t, tasks, []int{noDue.ID, late.ID, soon.ID})
+}
+
+func testSortByPriority(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	medium := mustCreate(t, repo, "medium", models.TaskPriorityMedium, nil)
+	high := mustCreate(t, repo, "high", models.TaskPriorityHigh, nil)
+	low := mustCreate(t, repo, "low", models.TaskPriorityLow, nil)
+
+	tasks, err := repo.GetAll(ctx, nil, &models.TaskSort{Field: "priority", Order: "asc"})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertOrder(t, tasks, []int{low.ID, medium.ID, high.ID})
+
+	tasks, err = repo.GetAll(ctx, nil, &models.TaskSort{Field: "priority", Order: "desc"})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertOrder(t, tasks, []int{high.ID, medium.ID, low.ID})
+}
+
+func testUpdate(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := mustCreate(t, repo, "original", models.TaskPriorityLow, nil)
+	time.Sleep(5 * time.Millisecond)
+
+	title := "renamed"
+	priority := models.TaskPriorityHigh
+	due := time.Now().Add(24 * time.Hour)
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Title: &title, Priority: &priority, DueDate: &due}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Title != title || got.Priority != priority {
+		t.Errorf("fields not updated: %+v", got)
+	}
+	if got.Description != task.Description || got.Status != models.TaskStatusPending {
+		t.Errorf("untouched fields changed: %+v", got)
+	}
+	assertTimePtr(t, "due_date", got.DueDate, &due)
+	if !got.UpdatedAt.After(task.UpdatedAt) {
+		t.Errorf("updated_at not advanced: before %v, after %v", task.UpdatedAt, got.UpdatedAt)
+	}
+}
+
+func testUpdateWithoutFields(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := mustCreate(t, repo, "task", models.TaskPriorityLow, nil)
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{}); err == nil {
+		t.Fatal("expected error for empty update")
+	}
+}
+
+func testUpdateMissing(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	title := "ghost"
+	err := repo.Update(ctx, 999999, &models.UpdateTaskRequest{Title: &title})
+	if !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func testUpdateVersionConflict(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := mustCreate(t, repo, "original", models.TaskPriorityLow, nil)
+	if task.Version != 1 {
+		t.Fatalf("expected new task to have version 1, got %d", task.Version)
+	}
+
+	first := "first"
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Title: &first, Version: &task.Version}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Version != 2 {
+		t.Fatalf("expected version 2 after update, got %d", got.Version)
+	}
+
+	// второй клиент все еще видит версию 1
+	second := "second"
+	err = repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Title: &second, Version: &task.Version})
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	got, _ = repo.GetByID(ctx, task.ID)
+	if got.Title != first || got.Version != 2 {
+		t.Errorf("stale update must not be applied: %+v", got)
+	}
+
+	// обновление без версии не проверяется, а версия растет при любом изменении
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Title: &second}); err != nil {
+		t.Fatalf("Update without version: %v", err)
+	}
+	if err := repo.SetParent(ctx, task.ID, nil); err != nil {
+		t.Fatalf("SetParent: %v", err)
+	}
+	got, _ = repo.GetByID(ctx, task.ID)
+	if got.Version != 4 {
+		t.Errorf("expected version 4, got %d", got.Version)
+	}
+
+	if err := repo.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Title: &first, Version: &got.Version}); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound for trashed task, got %v", err)
+	}
+}
+
+func testBulkUpdate(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	first := mustCreate(t, repo, "first", models.TaskPriorityLow, nil)
+	second := mustCreate(t, repo, "second", models.TaskPriorityLow, nil)
+
+	completed := models.TaskStatusCompleted
+	high := models.TaskPriorityHigh
+	tags := []string{"bulk"}
+	next := &models.Task{Title: "next", Priority: models.TaskPriorityLow}
+	err := repo.BulkUpdate(ctx, []*models.TaskUpdate{
+		{ID: first.ID, Changes: &models.UpdateTaskRequest{Status: &completed, Version: &first.Version}},
+		{ID: second.ID, Changes: &models.UpdateTaskRequest{Priority: &high, Tags: &tags}},
+	}, []*models.Task{next})
+	if err != nil {
+		t.Fatalf("BulkUpdate: %v", err)
+	}
+
+	got, _ := repo.GetByID(ctx, first.ID)
+	if got.Status != models.TaskStatusCompleted {
+		t.Errorf("expected first task completed, got %q", got.Status)
+	}
+	got, _ = repo.GetByID(ctx, second.ID)
+	if got.Priority != models.TaskPriorityHigh || len(got.Tags) != 1 || got.Tags[0] != "bulk" {
+		t.Errorf("expected second task updated, got %+v", got)
+	}
+	if next.ID == 0 {
+		t.Fatal("expected created task to get an id")
+	}
+	if _, err := repo.GetByID(ctx, next.ID); err != nil {
+		t.Errorf("created task not stored: %v", err)
+	}
+}
+
+func testBulkUpdateIsAllOrNothing(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	first := mustCreate(t, repo, "first", models.TaskPriorityLow, nil)
+	second := mustCreate(t, repo, "second", models.TaskPriorityLow, nil)
+
+	high := models.TaskPriorityHigh
+	stale := second.Version
+	title := "changed elsewhere"
+	if err := repo.Update(ctx, second.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		updates []*models.TaskUpdate
+		create  []*models.Task
+		want    error
+	}{
+		{"missing task", []*models.TaskUpdate{
+			{ID: first.ID, Changes: &models.UpdateTaskRequest{Priority: &high}},
+			{ID: 999999, Changes: &models.UpdateTaskRequest{Priority: &high}},
+		}, nil, repository.ErrTaskNotFound},
+		{"stale version", []*models.TaskUpdate{
+			{ID: first.ID, Changes: &models.UpdateTaskRequest{Priority: &high}},
+			{ID: second.ID, Changes: &models.UpdateTaskRequest{Priority: &high, Version: &stale}},
+		}, nil, repository.ErrVersionConflict},
+		{"invalid created task", []*models.TaskUpdate{
+			{ID: first.ID, Changes: &models.UpdateTaskRequest{Priority: &high}},
+		}, []*models.Task{{Title: "orphan", Priority: models.TaskPriorityLow, ProjectID: 999999}}, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := repo.BulkUpdate(ctx, tc.updates, tc.create)
+			if err == nil || (tc.want != nil && !errors.Is(err, tc.want)) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			for _, id := range []int{first.ID, second.ID} {
+				got, _ := repo.GetByID(ctx, id)
+				if got.Priority != models.TaskPriorityLow {
+					t.Errorf("task %d changed by a failed batch: %+v", id, got)
+				}
+			}
+		})
+	}
+}
+
+func testDelete(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	keep := mustCreate(t, repo, "keep", models.TaskPriorityLow, nil)
+	drop := mustCreate(t, repo, "drop", models.TaskPriorityLow, nil)
+
+	if err := repo.Delete(ctx, drop.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, drop.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected deleted task to be gone, got %v", err)
+	}
+	if err := repo.Delete(ctx, drop.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound on second delete, got %v", err)
+	}
+
+	tasks, err := repo.GetAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{keep.ID})
+}
+
+func testTrash(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	yesterday := time.Now().Add(-24 * time.Hour)
+	parent := mustCreateTagged(t, repo, "parent milk", "home")
+	child := mustCreateChild(t, repo, "child", parent.ID)
+	grandchild := mustCreateChild(t, repo, "grandchild", child.ID)
+	other := mustCreate(t, repo, "other milk", models.TaskPriorityLow, &yesterday)
+
+	// подзадача удалена отдельно и раньше родителя
+	if err := repo.Delete(ctx, child.ID); err != nil {
+		t.Fatalf("Delete child: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if err := repo.Delete(ctx, parent.ID); err != nil {
+		t.Fatalf("Delete parent: %v", err)
+	}
+
+	for _, id := range []int{parent.ID, child.ID, grandchild.ID} {
+		if _, err := repo.GetByID(ctx, id); !errors.Is(err, repository.ErrTaskNotFound) {
+			t.Errorf("task %d: expected ErrTaskNotFound from trash, got %v", id, err)
+		}
+	}
+	if err := repo.Delete(ctx, parent.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound on second delete, got %v", err)
+	}
+	title := "renamed"
+	if err := repo.Update(ctx, parent.ID, &models.UpdateTaskRequest{Title: &title}); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound on update in trash, got %v", err)
+	}
+
+	// корзина не видна в чтениях
+	tasks, err := repo.GetAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{other.ID})
+
+	page, err := repo.Search(ctx, &models.SearchQuery{Terms: []models.SearchTerm{{Text: "milk"}}, Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if page.Total != 1 || len(page.Results) != 1 || page.Results[0].Task.ID != other.ID {
+		t.Errorf("expected only live task in search, got %d results of %d", len(page.Results), page.Total)
+	}
+
+	tags, err := repo.GetTags(ctx)
+	if err != nil {
+		t.Fatalf("GetTags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].TaskCount != 0 {
+		t.Errorf("expected tag without live tasks, got %+v", tags)
+	}
+
+	overdue, err := repo.GetOverdue(ctx)
+	if err != nil {
+		t.Fatalf("GetOverdue: %v", err)
+	}
+	assertOrder(t, overdue, []int{other.ID})
+
+	// в корзине видны задачи, удаленные самостоятельно, последние первыми
+	trash, err := repo.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	assertOrder(t, trash, []int{parent.ID, child.ID})
+	if trash[0].DeletedAt == nil {
+		t.Error("expected DeletedAt on trashed task")
+	}
+
+	// родитель возвращается без подзадачи, удаленной раньше него
+	if err := repo.Restore(ctx, parent.ID); err != nil {
+		t.Fatalf("Restore parent: %v", err)
+	}
+	subtree, err := repo.GetSubtree(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("GetSubtree: %v", err)
+	}
+	assertOrder(t, subtree, []int{parent.ID})
+	if subtree[0].DeletedAt != nil || len(subtree[0].Tags) != 1 {
+		t.Errorf("expected restored task with its tags, got %+v", subtree[0])
+	}
+
+	if err := repo.Restore(ctx, child.ID); err != nil {
+		t.Fatalf("Restore child: %v", err)
+	}
+	subtree, err = repo.GetSubtree(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("GetSubtree: %v", err)
+	}
+	assertOrder(t, subtree, []int{parent.ID, child.ID, grandchild.ID})
+
+	if err := repo.Restore(ctx, child.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound restoring live task, got %v", err)
+	}
+	if err := repo.Purge(ctx, other.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound purging live task, got %v", err)
+	}
+
+	// окончательное удаление уносит и подзадачи
+	if err := repo.Delete(ctx, parent.ID); err != nil {
+		t.Fatalf("Delete parent: %v", err)
+	}
+	if err := repo.Purge(ctx, parent.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if err := repo.Restore(ctx, grandchild.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected purged subtask to be gone, got %v", err)
+	}
+	trash, err = repo.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	assertOrder(t, trash, nil)
+}
+
+func testTrashRestoreDetachesFromTrashedParent(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	parent := mustCreate(t, repo, "parent", models.TaskPriorityMedium, nil)
+	child := mustCreateChild(t, repo, "child", parent.ID)
+	grandchild := mustCreateChild(t, repo, "grandchild", child.ID)
+
+	if err := repo.Delete(ctx, parent.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Restore(ctx, child.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	subtree, err := repo.GetSubtree(ctx, child.ID)
+	if err != nil {
+		t.Fatalf("GetSubtree: %v", err)
+	}
+	assertOrder(t, subtree, []int{child.ID, grandchild.ID})
+	if subtree[0].ParentID != nil {
+		t.Errorf("expected restored task to become a root, got parent %d", *subtree[0].ParentID)
+	}
+
+	// родитель в корзине больше не держит восстановленную задачу
+	if err := repo.Purge(ctx, parent.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, grandchild.ID); err != nil {
+		t.Errorf("expected restored subtree to survive purge of old parent: %v", err)
+	}
+}
+
+func testSnapshots(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	parent := mustCreateTagged(t, repo, "parent", "home")
+	child := mustCreateChild(t, repo, "child", parent.ID)
+
+	before, err := repo.GetSnapshots(ctx, []int{child.ID, parent.ID, 999})
+	if err != nil {
+		t.Fatalf("GetSnapshots: %v", err)
+	}
+	assertOrder(t, before, []int{parent.ID, child.ID})
+
+	title := "renamed"
+	tags := []string{"work"}
+	if err := repo.Update(ctx, parent.ID, &models.UpdateTaskRequest{Title: &title, DueDate: &due, Tags: &tags}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := repo.Delete(ctx, parent.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// снимки видят задачи в корзине
+	after, err := repo.GetSnapshots(ctx, []int{parent.ID, child.ID})
+	if err != nil {
+		t.Fatalf("GetSnapshots: %v", err)
+	}
+	if len(after) != 2 || after[0].DeletedAt == nil || after[1].DeletedAt == nil {
+		t.Fatalf("expected trashed snapshots, got %+v", after)
+	}
+	if after[0].Title != title || after[0].DueDate == nil || !after[0].DueDate.Equal(due) || !reflect.DeepEqual(after[0].Tags, tags) {
+		t.Errorf("snapshot lacks updated fields: %+v", after[0])
+	}
+
+	// снимок от устаревшей версии не затирает более поздние изменения
+	if err := repo.ApplySnapshots(ctx, before); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, parent.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("conflicting apply must roll back, got %v", err)
+	}
+
+	if err := repo.ApplySnapshots(ctx, atCurrentVersion(t, repo, before)); err != nil {
+		t.Fatalf("ApplySnapshots: %v", err)
+	}
+	got, err := repo.GetByID(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("GetByID after apply: %v", err)
+	}
+	if got.Title != "parent" || got.DueDate != nil || !reflect.DeepEqual(got.Tags, []string{"home"}) {
+		t.Errorf("snapshot not applied: %+v", got)
+	}
+	if _, err := repo.GetByID(ctx, child.ID); err != nil {
+		t.Errorf("child should be back from trash: %v", err)
+	}
+
+	// повтор возвращает задачи в корзину с тем же временем удаления
+	if err := repo.ApplySnapshots(ctx, atCurrentVersion(t, repo, after)); err != nil {
+		t.Fatalf("ApplySnapshots: %v", err)
+	}
+	trash, err := repo.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	assertOrder(t, trash, []int{parent.ID})
+
+	// ошибка на одной задаче не оставляет изменений в остальных
+	missing := &models.Task{ID: 999, ProjectID: parent.ProjectID, Title: "missing", Status: models.TaskStatusPending, Priority: models.TaskPriorityLow}
+	if err := repo.ApplySnapshots(ctx, append(atCurrentVersion(t, repo, before[:1]), missing)); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, parent.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("failed apply must roll back, got %v", err)
+	}
+}
+
+func testRevisions(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	task := mustCreateTagged(t, repo, "report", "work")
+	other := mustCreate(t, repo, "other", models.TaskPriorityLow, nil)
+
+	created := &models.TaskRevision{
+		TaskID:  task.ID,
+		Action:  models.RevisionCreate,
+		Actor:   "alice@laptop",
+		Changes: models.DiffTasks(nil, task),
+		Task:    task,
+	}
+	updatedTask := *task
+	updatedTask.Title = "final report"
+	updatedTask.DueDate = &due
+	updated := &models.TaskRevision{
+		TaskID:  task.ID,
+		Action:  models.RevisionUpdate,
+		Actor:   "bob@desktop",
+		Changes: models.DiffTasks(task, &updatedTask),
+		Task:    &updatedTask,
+	}
+	if err := repo.AddRevisions(ctx, []*models.TaskRevision{created, updated}); err != nil {
+		t.Fatalf("AddRevisions: %v", err)
+	}
+	if created.ID == 0 || updated.ID <= created.ID || created.CreatedAt.IsZero() {
+		t.Fatalf("expected ids and time to be assigned, got %+v %+v", created, updated)
+	}
+	if err := repo.AddRevisions(ctx, []*models.TaskRevision{{TaskID: other.ID, Action: models.RevisionCreate, Actor: "alice", Task: other}}); err != nil {
+		t.Fatalf("AddRevisions: %v", err)
+	}
+
+	revisions, err := repo.GetRevisions(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetRevisions: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].ID != updated.ID || revisions[1].ID != created.ID {
+		t.Fatalf("expected newest revision first, got %+v", revisions)
+	}
+	latest := revisions[0]
+	if latest.Action != models.RevisionUpdate || latest.Actor != "bob@desktop" || !reflect.DeepEqual(latest.Changes, updated.Changes) {
+		t.Errorf("revision not stored as is: %+v", latest)
+	}
+	if latest.Task.Title != "final report" || latest.Task.DueDate == nil || !latest.Task.DueDate.Equal(due) || !reflect.DeepEqual(latest.Task.Tags, []string{"work"}) {
+		t.Errorf("snapshot not stored as is: %+v", latest.Task)
+	}
+
+	got, err := repo.GetRevision(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetRevision: %v", err)
+	}
+	if got.TaskID != task.ID || len(got.Changes) == 0 || got.Task.Title != "report" {
+		t.Errorf("unexpected revision: %+v", got)
+	}
+	if _, err := repo.GetRevision(ctx, 999); !errors.Is(err, repository.ErrRevisionNotFound) {
+		t.Errorf("expected ErrRevisionNotFound, got %v", err)
+	}
+
+	// журнал удаляется вместе с задачей навсегда
+	if err := repo.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Purge(ctx, task.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	revisions, err = repo.GetRevisions(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetRevisions: %v", err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("expected purged task history to be gone, got %d revisions", len(revisions))
+	}
+}
+
+func testReminders(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	task := mustCreate(t, repo, "task", models.TaskPriorityMedium, nil)
+	done := mustCreate(t, repo, "done", models.TaskPriorityMedium, nil)
+
+	past := mustAddReminder(t, repo, task.ID, now.Add(-time.Hour))
+	future := mustAddReminder(t, repo, task.ID, now.Add(time.Hour))
+	mustAddReminder(t, repo, done.ID, now.Add(-time.Hour))
+	mustComplete(t, repo, done.ID)
+
+	reminders, err := repo.GetReminders(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetReminders: %v", err)
+	}
+	if len(reminders) != 2 || reminders[0].ID != past.ID || reminders[1].ID != future.ID {
+		t.Fatalf("expected reminders in firing order, got %+v", reminders)
+	}
+	if !reminders[0].RemindAt.Equal(past.RemindAt) || reminders[0].At == nil || reminders[0].FiredAt != nil {
+		t.Errorf("unexpected stored reminder: %+v", reminders[0])
+	}
+
+	// напоминания завершенных задач не срабатывают, сработавшие не возвращаются повторно
+	claimed, err := repo.ClaimDueReminders(ctx, now)
+	if err != nil {
+		t.Fatalf("ClaimDueReminders: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != past.ID || claimed[0].FiredAt == nil {
+		t.Fatalf("expected only the past reminder of the open task, got %+v", claimed)
+	}
+	if claimed, _ := repo.ClaimDueReminders(ctx, now); len(claimed) != 0 {
+		t.Errorf("fired reminder claimed twice: %+v", claimed)
+	}
+
+	// недоставленное напоминание после освобождения срабатывает снова
+	if err := repo.ReleaseReminder(ctx, past.ID); err != nil {
+		t.Fatalf("ReleaseReminder: %v", err)
+	}
+	if claimed, _ := repo.ClaimDueReminders(ctx, now); len(claimed) != 1 || claimed[0].ID != past.ID {
+		t.Errorf("expected released reminder to fire again, got %+v", claimed)
+	}
+	if err := repo.ReleaseReminder(ctx, 999999); !errors.Is(err, repository.ErrReminderNotFound) {
+		t.Errorf("expected ErrReminderNotFound, got %v", err)
+	}
+
+	next, err := repo.NextReminderAt(ctx)
+	if err != nil {
+		t.Fatalf("NextReminderAt: %v", err)
+	}
+	if next == nil || !next.Equal(future.RemindAt) {
+		t.Errorf("expected next reminder at %v, got %v", future.RemindAt, next)
+	}
+
+	if err := repo.DeleteReminder(ctx, future.ID); err != nil {
+		t.Fatalf("DeleteReminder: %v", err)
+	}
+	if err := repo.DeleteReminder(ctx, future.ID); !errors.Is(err, repository.ErrReminderNotFound) {
+		t.Errorf("expected ErrReminderNotFound, got %v", err)
+	}
+	if next, _ := repo.NextReminderAt(ctx); next != nil {
+		t.Errorf("expected no pending reminders, got %v", next)
+	}
+
+	if err := repo.AddReminder(ctx, &models.Reminder{TaskID: 999999, At: &now, RemindAt: now}); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound for missing task, got %v", err)
+	}
+}
+
+func testRemindersFollowDueDate(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	due := now.Add(10 * time.Minute)
+	task := mustCreate(t, repo, "task", models.TaskPriorityMedium, &due)
+
+	offset := 30
+	reminder := &models.Reminder{TaskID: task.ID, OffsetMinutes: &offset, RemindAt: models.ReminderTime(due, offset)}
+	if err := repo.AddReminder(ctx, reminder); err != nil {
+		t.Fatalf("AddReminder: %v", err)
+	}
+	if claimed, _ := repo.ClaimDueReminders(ctx, now); len(claimed) != 1 {
+		t.Fatalf("expected reminder 30 minutes before due to fire, got %+v", claimed)
+	}
+
+	// перенос срока пересчитывает напоминание, и оно сработает снова
+	later := now.Add(2 * time.Hour)
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{DueDate: &later}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	reminders, err := repo.GetReminders(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetReminders: %v", err)
+	}
+	want := later.Add(-30 * time.Minute)
+	if len(reminders) != 1 || !reminders[0].RemindAt.Equal(want) || reminders[0].FiredAt != nil {
+		t.Fatalf("expected reminder rescheduled to %v, got %+v", want, reminders)
+	}
+
+	// откат срока снимком тоже пересчитывает напоминание
+	snapshots, err := repo.GetSnapshots(ctx, []int{task.ID})
+	if err != nil {
+		t.Fatalf("GetSnapshots: %v", err)
+	}
+	snapshots[0].DueDate = &due
+	if err := repo.ApplySnapshots(ctx, snapshots); err != nil {
+		t.Fatalf("ApplySnapshots: %v", err)
+	}
+	reminders, _ = repo.GetReminders(ctx, task.ID)
+	if !reminders[0].RemindAt.Equal(models.ReminderTime(due, offset)) {
+		t.Errorf("expected reminder back at %v, got %v", models.ReminderTime(due, offset), reminders[0].RemindAt)
+	}
+
+	// окончательное удаление задачи удаляет и ее напоминания
+	if err := repo.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Purge(ctx, task.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if reminders, _ := repo.GetReminders(ctx, task.ID); len(reminders) != 0 {
+		t.Errorf("expected reminders to be purged with the task, got %+v", reminders)
+	}
+}
+
+func testSnooze(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	overdue := now.Add(-time.Hour)
+	task := mustCreate(t, repo, "overdue", models.TaskPriorityMedium, &overdue)
+
+	offset := 30
+	if err := repo.AddReminder(ctx, &models.Reminder{TaskID: task.ID, OffsetMinutes: &offset, RemindAt: models.ReminderTime(overdue, offset)}); err != nil {
+		t.Fatalf("AddReminder: %v", err)
+	}
+	fired := mustAddReminder(t, repo, task.ID, now.Add(-time.Minute))
+	later := mustAddReminder(t, repo, task.ID, now.Add(48*time.Hour))
+	if claimed, _ := repo.ClaimDueReminders(ctx, now); len(claimed) != 2 {
+		t.Fatalf("expected offset and past reminders to fire, got %+v", claimed)
+	}
+
+	until := now.Add(time.Hour)
+	if err := repo.Snooze(ctx, task.ID, until); err != nil {
+		t.Fatalf("Snooze: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertTimePtr(t, "due_date", got.DueDate, &until)
+	if got.SnoozeCount != 1 || got.Version != task.Version+1 {
+		t.Errorf("expected snooze count 1 and version %d, got %d and %d", task.Version+1, got.SnoozeCount, got.Version)
+	}
+
+	reminders, err := repo.GetReminders(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetReminders: %v", err)
+	}
+	if len(reminders) != 3 {
+		t.Fatalf("expected 3 reminders, got %+v", reminders)
+	}
+	want := map[int]time.Time{fired.ID: until, later.ID: later.RemindAt}
+	for _, reminder := range reminders {
+		if reminder.OffsetMinutes != nil {
+			want[reminder.ID] = until.Add(-30 * time.Minute)
+		}
+		if !reminder.RemindAt.Equal(want[reminder.ID]) || reminder.FiredAt != nil {
+			t.Errorf("reminder %d: expected pending at %v, got %v (fired %v)", reminder.ID, want[reminder.ID], reminder.RemindAt, reminder.FiredAt)
+		}
+	}
+
+	// завершенные и несуществующие задачи не откладываются
+	mustComplete(t, repo, task.ID)
+	for _, id := range []int{task.ID, 999999} {
+		if err := repo.Snooze(ctx, id, until); !errors.Is(err, repository.ErrTaskNotFound) {
+			t.Errorf("Snooze(%d): expected ErrTaskNotFound, got %v", id, err)
+		}
+	}
+}
+
+func testAllDay(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	task := &models.Task{Title: "all day", Priority: models.TaskPriorityMedium, DueDate: &due, AllDay: true}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	assertAllDay := func(want bool) *models.Task {
+		t.Helper()
+		got, err := repo.GetByID(ctx, task.ID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.AllDay != want {
+			t.Errorf("expected all_day %v, got %v", want, got.AllDay)
+		}
+		return got
+	}
+	snapshot := assertAllDay(true)
+
+	timed := false
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{AllDay: &timed}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	assertAllDay(false)
+
+	if err := repo.ApplySnapshots(ctx, atCurrentVersion(t, repo, []*models.Task{snapshot})); err != nil {
+		t.Fatalf("ApplySnapshots: %v", err)
+	}
+	assertAllDay(true)
+
+	// отложенная задача получает точное время
+	if err := repo.Snooze(ctx, task.ID, due.Add(time.Hour)); err != nil {
+		t.Fatalf("Snooze: %v", err)
+	}
+	assertAllDay(false)
+}
+
+func testPurgeDeleted(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	old := mustCreate(t, repo, "old", models.TaskPriorityMedium, nil)
+	mustCreateChild(t, repo, "old child", old.ID)
+	recent := mustCreate(t, repo, "recent", models.TaskPriorityMedium, nil)
+	live := mustCreate(t, repo, "live", models.TaskPriorityMedium, nil)
+
+	if err := repo.Delete(ctx, old.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	if err := repo.Delete(ctx, recent.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := repo.PurgeDeleted(ctx, cutoff); err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	trash, err := repo.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	assertOrder(t, trash, []int{recent.ID})
+
+	count, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 purged task, got %d", count)
+	}
+
+	tasks, err := repo.GetAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{live.ID})
+}
+
+func testGetOverdue(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	longAgo := time.Now().Add(-72 * time.Hour)
+	recently := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	recent := mustCreate(t, repo, "recent", models.TaskPriorityMedium, &recently)
+	old := mustCreate(t, repo, "old", models.TaskPriorityMedium, &longAgo)
+	done := mustCreate(t, repo, "done", models.TaskPriorityMedium, &longAgo)
+	mustComplete(t, repo, done.ID)
+	mustCreate(t, repo, "future", models.TaskPriorityMedium, &future)
+	mustCreate(t, repo, "no due", models.TaskPriorityMedium, nil)
+
+	tasks, err := repo.GetOverdue(ctx)
+	if err != nil {
+		t.Fatalf("GetOverdue: %v", err)
+	}
+	assertOrder(t, tasks, []int{old.ID, recent.ID})
+}
+
+func testGetByDateRange(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	from := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	to := from.Add(48 * time.Hour)
+	middle := from.Add(24 * time.Hour)
+	outside := to.Add(time.Hour)
+
+	atEnd := mustCreate(t, repo, "end", models.TaskPriorityMedium, &to)
+	inMiddle := mustCreate(t, repo, "middle", models.TaskPriorityMedium, &middle)
+	atStart := mustCreate(t, repo, "start", models.TaskPriorityMedium, &from)
+	mustCreate(t, repo, "outside", models.TaskPriorityMedium, &outside)
+	mustCreate(t, repo, "no due", models.TaskPriorityMedium, nil)
+
+	tasks, err := repo.GetByDateRange(ctx, from, to)
+	if err != nil {
+		t.Fatalf("GetByDateRange: %v", err)
+	}
+	assertOrder(t, tasks, []int{atStart.ID, inMiddle.ID, atEnd.ID})
+}
+
+func testCountTasks(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	periods := &models.StatsPeriods{
+		Now:       now,
+		TodayFrom: now.Add(-2 * time.Hour),
+		TodayTo:   now.Add(2 * time.Hour),
+		WeekFrom:  now.Add(-48 * time.Hour),
+		WeekTo:    now.Add(48 * time.Hour),
+	}
+	hourAgo := now.Add(-time.Hour)
+	inHour := now.Add(time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
+	later := now.Add(72 * time.Hour)
+
+	overdue := mustCreate(t, repo, "overdue", models.TaskPriorityHigh, &hourAgo)
+	mustCreate(t, repo, "today", models.TaskPriorityHigh, &inHour)
+	mustCreate(t, repo, "this week", models.TaskPriorityHigh, &tomorrow)
+	done := mustCreate(t, repo, "done", models.TaskPriorityLow, &hourAgo)
+	mustComplete(t, repo, done.ID)
+	mustCreate(t, repo, "no due", models.TaskPriorityLow, nil)
+	mustCreate(t, repo, "later", models.TaskPriorityLow, &later)
+
+	groups, err := repo.CountTasks(ctx, periods)
+	if err != nil {
+		t.Fatalf("CountTasks: %v", err)
+	}
+
+	project := overdue.ProjectID
+	want := []models.TaskCountGroup{
+		{ProjectID: project, Status: models.TaskStatusCompleted, Priority: models.TaskPriorityLow, Count: 1},
+		{ProjectID: project, Status: models.TaskStatusPending, Priority: models.TaskPriorityHigh, Count: 3, Overdue: 1, DueToday: 2, DueThisWeek: 3},
+		{ProjectID: project, Status: models.TaskStatusPending, Priority: models.TaskPriorityLow, Count: 2},
+	}
+	var got []models.TaskCountGroup
+	for _, group := range groups {
+		got = append(got, *group)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v\ngot      %+v", want, got)
+	}
+}
+
+func testCreateSubtask(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	parent := mustCreate(t, repo, "parent", models.TaskPriorityMedium, nil)
+	child := mustCreateChild(t, repo, "child", parent.ID)
+
+	got, err := repo.GetByID(ctx, child.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.ParentID == nil || *got.ParentID != parent.ID {
+		t.Fatalf("expected parent %d, got %v", parent.ID, got.ParentID)
+	}
+
+	got, err = repo.GetByID(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.ParentID != nil {
+		t.Errorf("expected root task, got parent %d", *got.ParentID)
+	}
+}
+
+func testCreateSubtaskWithMissingParent(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	missing := 999999
+	task := &models.Task{Title: "orphan", Priority: models.TaskPriorityLow, ParentID: &missing}
+	if err := repo.Create(ctx, task); err == nil {
+		t.Fatal("expected error for missing parent")
+	}
+}
+
+func testGetSubtree(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	root := mustCreate(t, repo, "root", models.TaskPriorityMedium, nil)
+	first := mustCreateChild(t, repo, "first", root.ID)
+	grandchild := mustCreateChild(t, repo, "grandchild", first.ID)
+	second := mustCreateChild(t, repo, "second", root.ID)
+	mustCreate(t, repo, "unrelated", models.TaskPriorityMedium, nil)
+
+	tasks, err := repo.GetSubtree(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("GetSubtree: %v", err)
+	}
+	assertOrder(t, tasks, []int{root.ID, first.ID, second.ID, grandchild.ID})
+
+	tasks, err = repo.GetSubtree(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetSubtree: %v", err)
+	}
+	assertOrder(t, tasks, []int{first.ID, grandchild.ID})
+
+	if _, err := repo.GetSubtree(ctx, 999999); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func testSetParent(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	parent := mustCreate(t, repo, "parent", models.TaskPriorityMedium, nil)
+	task := mustCreate(t, repo, "task", models.TaskPriorityMedium, nil)
+
+	if err := repo.SetParent(ctx, task.ID, &parent.ID); err != nil {
+		t.Fatalf("SetParent: %v", err)
+	}
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.ParentID == nil || *got.ParentID != parent.ID {
+		t.Fatalf("expected parent %d, got %v", parent.ID, got.ParentID)
+	}
+
+	if err := repo.SetParent(ctx, task.ID, nil); err != nil {
+		t.Fatalf("SetParent(nil): %v", err)
+	}
+	got, err = repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.ParentID != nil {
+		t.Errorf("expected root task, got parent %d", *got.ParentID)
+	}
+
+	if err := repo.SetParent(ctx, task.ID, &task.ID); err == nil {
+		t.Error("expected error when task becomes its own parent")
+	}
+	missing := 999999
+	if err := repo.SetParent(ctx, task.ID, &missing); err == nil {
+		t.Error("expected error for missing parent")
+	}
+	if err := repo.SetParent(ctx, missing, nil); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func testSetParentMovesSubtreeToProject(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	project := mustCreateProject(t, repo, "work")
+	parent := mustCreateInProject(t, repo, "parent", project.ID)
+	task := mustCreate(t, repo, "task", models.TaskPriorityMedium, nil)
+	child := mustCreateChild(t, repo, "child", task.ID)
+
+	if err := repo.SetParent(ctx, task.ID, &parent.ID); err != nil {
+		t.Fatalf("SetParent: %v", err)
+	}
+	for _, id := range []int{task.ID, child.ID} {
+		got, err := repo.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.ProjectID != project.ID {
+			t.Errorf("expected task %d in project %d, got %d", id, project.ID, got.ProjectID)
+		}
+	}
+}
+
+func testDeleteKeepingSubtasks(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	root := mustCreate(t, repo, "root", models.TaskPriorityMedium, nil)
+	middle := mustCreateChild(t, repo, "middle", root.ID)
+	leaf := mustCreateChild(t, repo, "leaf", middle.ID)
+
+	if err := repo.DeleteKeepingSubtasks(ctx, middle.ID); err != nil {
+		t.Fatalf("DeleteKeepingSubtasks: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, middle.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected middle task in trash, got %v", err)
+	}
+	got, err := repo.GetByID(ctx, leaf.ID)
+	if err != nil {
+		t.Fatalf("GetByID(leaf): %v", err)
+	}
+	if got.ParentID == nil || *got.ParentID != root.ID {
+		t.Errorf("expected leaf under root %d, got %v", root.ID, got.ParentID)
+	}
+
+	// задачи нет: потомки не перемещаются
+	if err := repo.DeleteKeepingSubtasks(ctx, middle.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func testDeleteCascades(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	root := mustCreate(t, repo, "root", models.TaskPriorityMedium, nil)
+	child := mustCreateChild(t, repo, "child", root.ID)
+	grandchild := mustCreateChild(t, repo, "grandchild", child.ID)
+	keep := mustCreate(t, repo, "keep", models.TaskPriorityMedium, nil)
+
+	if err := repo.Delete(ctx, root.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, id := range []int{child.ID, grandchild.ID} {
+		if _, err := repo.GetByID(ctx, id); !errors.Is(err, repository.ErrTaskNotFound) {
+			t.Errorf("expected subtask %d to be deleted, got %v", id, err)
+		}
+	}
+
+	tasks, err := repo.GetAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{keep.ID})
+}
+
+func testCreateWithTags(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := mustCreateTagged(t, repo, "tagged", "work", "home")
+	plain := mustCreate(t, repo, "plain", models.TaskPriorityLow, nil)
+
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertTags(t, got.Tags, []string{"home", "work"})
+
+	tasks, err := repo.GetAll(ctx, nil, &models.TaskSort{Field: "created_at", Order: "asc"})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertOrder(t, tasks, []int{task.ID, plain.ID})
+	assertTags(t, tasks[0].Tags, []string{"home", "work"})
+	assertTags(t, tasks[1].Tags, nil)
+}
+
+func testUpdateTags(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := mustCreateTagged(t, repo, "task", "work", "home")
+	time.Sleep(5 * time.Millisecond)
+
+	tags := []string{"urgent", "work"}
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Tags: &tags}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertTags(t, got.Tags, tags)
+	if !got.UpdatedAt.After(task.UpdatedAt) {
+		t.Errorf("updated_at not advanced: before %v, after %v", task.UpdatedAt, got.UpdatedAt)
+	}
+
+	// nil оставляет теги как есть
+	title := "renamed"
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertTags(t, got.Tags, tags)
+
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Tags: &[]string{}}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertTags(t, got.Tags, nil)
+}
+
+func testFilterByTags(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	both := mustCreateTagged(t, repo, "both", "work", "urgent")
+	work := mustCreateTagged(t, repo, "work", "work")
+	home := mustCreateTagged(t, repo, "home", "home")
+	mustCreate(t, repo, "untagged", models.TaskPriorityLow, nil)
+
+	pending := models.TaskStatusPending
+
+	tests := []struct {
+		name   string
+		filter *models.TaskFilter
+		want   []int
+	}{
+		{"single tag", &models.TaskFilter{Tags: []string{"work"}}, []int{both.ID, work.ID}},
+		{"any of", &models.TaskFilter{Tags: []string{"urgent", "home"}, TagMatch: models.TagMatchAny}, []int{both.ID, home.ID}},
+		{"any is default", &models.TaskFilter{Tags: []string{"urgent", "home"}}, []int{both.ID, home.ID}},
+		{"all of", &models.TaskFilter{Tags: []string{"work", "urgent"}, TagMatch: models.TagMatchAll}, []int{both.ID}},
+		{"all of without match", &models.TaskFilter{Tags: []string{"work", "home"}, TagMatch: models.TagMatchAll}, nil},
+		{"unknown tag", &models.TaskFilter{Tags: []string{"missing"}}, nil},
+		{"with status", &models.TaskFilter{Status: &pending, Tags: []string{"home"}}, []int{home.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := repo.GetAll(ctx, tt.filter, nil)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			assertSameIDs(t, tasks, tt.want)
+		})
+	}
+}
+
+func testFilterByQuery(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	today := time.Now().Truncate(24 * time.Hour)
+	yesterday := today.Add(-24 * time.Hour)
+	nextWeek := today.Add(7 * 24 * time.Hour)
+
+	create := func(title string, priority models.TaskPriority, due *time.Time, tags ...string) *models.Task {
+		t.Helper()
+		task := &models.Task{Title: title, Priority: priority, DueDate: due, Tags: tags}
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("Create(%q): %v", title, err)
+		}
+		return task
+	}
+
+	report := create("Quarterly report", models.TaskPriorityHigh, &yesterday, "work")
+	groceries := create("Buy groceries", models.TaskPriorityLow, &nextWeek, "home")
+	draft := create("Report draft", models.TaskPriorityMedium, nil)
+	mustComplete(t, repo, draft.ID)
+
+	completed := models.TaskStatusCompleted
+
+	condition := func(c models.QueryCondition) *models.TaskFilter {
+		return &models.TaskFilter{Query: &models.TaskQuery{Conditions: []models.QueryCondition{c}}}
+	}
+	text := func(terms ...models.SearchTerm) *models.TaskFilter {
+		return &models.TaskFilter{Query: &models.TaskQuery{Text: terms}}
+	}
+
+	tests := []struct {
+		name   string
+		filter *models.TaskFilter
+		want   []int
+	}{
+		{"status", condition(models.QueryCondition{Field: models.QueryFieldStatus, Op: models.QueryOpIn, Values: []string{"pending"}}),
+			[]int{report.ID, groceries.ID}},
+		{"priority in", condition(models.QueryCondition{Field: models.QueryFieldPriority, Op: models.QueryOpIn, Values: []string{"medium", "high"}}),
+			[]int{report.ID, draft.ID}},
+		{"tag", condition(models.QueryCondition{Field: models.QueryFieldTag, Op: models.QueryOpIn, Values: []string{"work"}}),
+			[]int{report.ID}},
+		{"negated tag keeps untagged", condition(models.QueryCondition{Field: models.QueryFieldTag, Op: models.QueryOpIn, Values: []string{"work"}, Negated: true}),
+			[]int{groceries.ID, draft.ID}},
+		{"due before", condition(models.QueryCondition{Field: models.QueryFieldDue, Op: models.QueryOpBefore, From: today}),
+			[]int{report.ID}},
+		{"due after", condition(models.QueryCondition{Field: models.QueryFieldDue, Op: models.QueryOpAfter, From: today}),
+			[]int{groceries.ID}},
+		{"due range", condition(models.QueryCondition{Field: models.QueryFieldDue, Op: models.QueryOpRange, From: yesterday, To: today}),
+			[]int{report.ID}},
+		{"negated due keeps undated", condition(models.QueryCondition{Field: models.QueryFieldDue, Op: models.QueryOpBefore, From: today, Negated: true}),
+			[]int{groceries.ID, draft.ID}},
+		{"due empty", condition(models.QueryCondition{Field: models.QueryFieldDue, Op: models.QueryOpEmpty}),
+			[]int{draft.ID}},
+		{"due not empty", condition(models.QueryCondition{Field: models.QueryFieldDue, Op: models.QueryOpEmpty, Negated: true}),
+			[]int{report.ID, groceries.ID}},
+		{"created after", condition(models.QueryCondition{Field: models.QueryFieldCreated, Op: models.QueryOpAfter, From: yesterday}),
+			[]int{report.ID, groceries.ID, draft.ID}},
+		{"text", text(models.SearchTerm{Text: "report"}),
+			[]int{report.ID, draft.ID}},
+		{"negated text", text(models.SearchTerm{Text: "report"}, models.SearchTerm{Text: "draft", Negated: true}),
+			[]int{report.ID}},
+		{"combined with filter", &models.TaskFilter{
+			Status: &completed,
+			Query:  &models.TaskQuery{Text: []models.SearchTerm{{Text: "report"}}},
+		}, []int{draft.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := repo.GetAll(ctx, tt.filter, nil)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			assertSameIDs(t, tasks, tt.want)
+		})
+	}
+}
+
+func testPagination(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	day1 := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	day2 := day1.Add(24 * time.Hour)
+
+	// одинаковые приоритеты и сроки проверяют, что id разбивает ничьи
+	mustCreate(t, repo, "a", models.TaskPriorityHigh, &day2)
+	mustCreate(t, repo, "b", models.TaskPriorityLow, nil)
+	mustCreate(t, repo, "c", models.TaskPriorityHigh, &day1)
+	mustCreate(t, repo, "d", models.TaskPriorityMedium, &day1)
+	mustCreate(t, repo, "e", models.TaskPriorityLow, nil)
+	mustCreate(t, repo, "f", models.TaskPriorityMedium, &day2)
+	done := mustCreate(t, repo, "g", models.TaskPriorityHigh, nil)
+	mustComplete(t, repo, done.ID)
+
+	pending := models.TaskStatusPending
+	sorts := []*models.TaskSort{
+		nil,
+		{Field: "created_at", Order: "asc"},
+		{Field: "due_date", Order: "asc"},
+		{Field: "due_date", Order: "desc"},
+		{Field: "priority", Order: "asc"},
+		{Field: "priority", Order: "desc"},
+	}
+
+	for _, sort := range sorts {
+		for _, filter := range []*models.TaskFilter{nil, {Status: &pending}} {
+			name := "default"
+			if sort != nil {
+				name = sort.Field + " " + sort.Order
+			}
+			if filter != nil {
+				name += " pending"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				all, err := repo.GetAll(ctx, filter, sort)
+				if err != nil {
+					t.Fatalf("GetAll: %v", err)
+				}
+
+				var paged []*models.Task
+				cursor := ""
+				for i := 0; ; i++ {
+					if i > len(all) {
+						t.Fatal("pagination does not terminate")
+					}
+					page, err := repo.GetPage(ctx, filter, sort, &models.PageRequest{Limit: 3, Cursor: cursor})
+					if err != nil {
+						t.Fatalf("GetPage: %v", err)
+					}
+					if page.Total != len(all) {
+						t.Fatalf("expected total %d, got %d", len(all), page.Total)
+					}
+					paged = append(paged, page.Tasks...)
+					if page.NextCursor == "" {
+						break
+					}
+					cursor = page.NextCursor
+				}
+
+				assertOrder(t, paged, taskIDs(all))
+			})
+		}
+	}
+}
+
+func testPaginationIsStable(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	first := mustCreate(t, repo, "first", models.TaskPriorityMedium, nil)
+	time.Sleep(2 * time.Millisecond)
+	second := mustCreate(t, repo, "second", models.TaskPriorityMedium, nil)
+	time.Sleep(2 * time.Millisecond)
+	third := mustCreate(t, repo, "third", models.TaskPriorityMedium, nil)
+
+	page, err := repo.GetPage(ctx, nil, nil, &models.PageRequest{Limit: 1})
+	if err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	assertOrder(t, page.Tasks, []int{third.ID})
+
+	// новая задача попадает в начало списка и не сдвигает следующие страницы
+	time.Sleep(2 * time.Millisecond)
+	mustCreate(t, repo, "newest", models.TaskPriorityMedium, nil)
+
+	page, err = repo.GetPage(ctx, nil, nil, &models.PageRequest{Limit: 5, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	assertOrder(t, page.Tasks, []int{second.ID, first.ID})
+	if page.NextCursor != "" || page.Total != 4 {
+		t.Errorf("expected last page of 4 tasks, got cursor %q and total %d", page.NextCursor, page.Total)
+	}
+
+	prioritySort := &models.TaskSort{Field: "priority", Order: "desc"}
+	for _, cursor := range []string{"not a cursor", page.NextCursor + "x"} {
+		if _, err := repo.GetPage(ctx, nil, prioritySort, &models.PageRequest{Limit: 1, Cursor: cursor}); !errors.Is(err, repository.ErrInvalidCursor) {
+			t.Errorf("cursor %q: expected ErrInvalidCursor, got %v", cursor, err)
+		}
+	}
+
+	// курсор другой сортировки отклоняется
+	page, err = repo.GetPage(ctx, nil, nil, &models.PageRequest{Limit: 1})
+	if err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	if _, err := repo.GetPage(ctx, nil, prioritySort, &models.PageRequest{Limit: 1, Cursor: page.NextCursor}); !errors.Is(err, repository.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for cursor of another sort, got %v", err)
+	}
+}
+
+func testSearchPagination(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	// одинаковый ранг у всех задач, порядок задает id
+	var want []int
+	for i := 0; i < 5; i++ {
+		want = append(want, mustCreateDescribed(t, repo, "milk", "").ID)
+	}
+	mustCreateDescribed(t, repo, "bread", "")
+
+	var got []*models.Task
+	query := &models.SearchQuery{Terms: []models.SearchTerm{{Text: "milk"}}, Limit: 2}
+	for i := 0; ; i++ {
+		if i > len(want) {
+			t.Fatal("pagination does not terminate")
+		}
+		page, err := repo.Search(ctx, query)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if page.Total != len(want) {
+			t.Fatalf("expected total %d, got %d", len(want), page.Total)
+		}
+		for _, result := range page.Results {
+			got = append(got, result.Task)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	assertOrder(t, got, want)
+}
+
+func testTagCounts(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	mustCreateTagged(t, repo, "first", "work", "home")
+	drop := mustCreateTagged(t, repo, "second", "work")
+	mustCreateTag(t, repo, "unused")
+
+	if err := repo.Delete(ctx, drop.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	mustCreateTagged(t, repo, "third", "work")
+
+	tags, err := repo.GetTags(ctx)
+	if err != nil {
+		t.Fatalf("GetTags: %v", err)
+	}
+
+	want := []struct {
+		name  string
+		count int
+	}{{"home", 1}, {"unused", 0}, {"work", 2}}
+	if len(tags) != len(want) {
+		t.Fatalf("expected %d tags, got %+v", len(want), tags)
+	}
+	for i, w := range want {
+		if tags[i].Name != w.name || tags[i].TaskCount != w.count || tags[i].ID == 0 {
+			t.Errorf("tag %d: expected %s with %d tasks, got %+v", i, w.name, w.count, tags[i])
+		}
+	}
+}
+
+func testCreateDuplicateTag(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	mustCreateTag(t, repo, "work")
+
+	if err := repo.CreateTag(ctx, &models.Tag{Name: "work"}); err == nil {
+		t.Fatal("expected error for duplicate tag")
+	}
+}
+
+func testRenameTag(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := mustCreateTagged(t, repo, "task", "work", "home")
+	tag := findTag(t, repo, "work")
+
+	if err := repo.RenameTag(ctx, tag.ID, "office"); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertTags(t, got.Tags, []string{"home", "office"})
+
+	if err := repo.RenameTag(ctx, tag.ID, "home"); err == nil {
+		t.Error("expected error renaming to an existing tag")
+	}
+	if err := repo.RenameTag(ctx, 999999, "ghost"); !errors.Is(err, repository.ErrTagNotFound) {
+		t.Errorf("expected ErrTagNotFound, got %v", err)
+	}
+}
+
+func testDeleteTag(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := mustCreateTagged(t, repo, "task", "work", "home")
+	tag := findTag(t, repo, "work")
+
+	if err := repo.DeleteTag(ctx, tag.ID); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertTags(t, got.Tags, []string{"home"})
+
+	if err := repo.DeleteTag(ctx, tag.ID); !errors.Is(err, repository.ErrTagNotFound) {
+		t.Errorf("expected ErrTagNotFound, got %v", err)
+	}
+}
+
+func testRecurrence(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	task := &models.Task{Title: "chores", Priority: models.TaskPriorityLow, DueDate: &due, Recurrence: "FREQ=WEEKLY;BYDAY=SA"}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Recurrence != "FREQ=WEEKLY;BYDAY=SA" {
+		t.Errorf("expected stored recurrence, got %q", got.Recurrence)
+	}
+
+	plain := mustCreate(t, repo, "plain", models.TaskPriorityLow, nil)
+	if plain.Recurrence != "" {
+		t.Errorf("expected empty recurrence, got %q", plain.Recurrence)
+	}
+
+	// пустая строка отключает повторение
+	cleared := ""
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Recurrence: &cleared}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Recurrence != "" {
+		t.Errorf("expected recurrence cleared, got %q", got.Recurrence)
+	}
+}
+
+func testSearch(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	milk := mustCreateDescribed(t, repo, "Buy milk", "and bread")
+	call := mustCreateDescribed(t, repo, "Call mom", "ask about milk delivery")
+	report := mustCreateDescribed(t, repo, "Weekly report", "send to the team")
+	bread := mustCreateDescribed(t, repo, "Купить хлеб", "в магазине у дома")
+
+	tests := []struct {
+		name  string
+		terms []models.SearchTerm
+		want  []int
+	}{
+		{"word ranks title first", []models.SearchTerm{{Text: "milk"}}, []int{milk.ID, call.ID}},
+		{"phrase", []models.SearchTerm{{Text: "milk delivery", Phrase: true}}, []int{call.ID}},
+		{"phrase order matters", []models.SearchTerm{{Text: "delivery milk", Phrase: true}}, []int{}},
+		{"prefix", []models.SearchTerm{{Text: "repo", Prefix: true}}, []int{report.ID}},
+		{"negation", []models.SearchTerm{{Text: "milk"}, {Text: "mom", Negated: true}}, []int{milk.ID}},
+		{"all terms required", []models.SearchTerm{{Text: "milk"}, {Text: "team"}}, []int{}},
+		{"cyrillic prefix", []models.SearchTerm{{Text: "магазин", Prefix: true}}, []int{bread.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.Search(ctx, &models.SearchQuery{Terms: tt.terms, Limit: 10})
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			results := page.Results
+			tasks := make([]*models.Task, len(results))
+			for i, result := range results {
+				tasks[i] = result.Task
+			}
+			assertOrder(t, tasks, tt.want)
+		})
+	}
+
+	page, err := repo.Search(ctx, &models.SearchQuery{Terms: []models.SearchTerm{{Text: "milk"}}, Limit: 1})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	results := page.Results
+	if len(results) != 1 {
+		t.Fatalf("expected limit to apply, got %d results", len(results))
+	}
+	if !strings.Contains(results[0].Snippet, models.SnippetMatchStart) || results[0].Rank <= 0 {
+		t.Errorf("expected highlighted snippet and positive rank, got %+v", results[0])
+	}
+	if results[0].Task.Tags == nil {
+		t.Error("search results must carry tags")
+	}
+}
+
+func testSearchFollowsUpdates(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := mustCreateDescribed(t, repo, "draft", "")
+
+	title := "final"
+	if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	for word, want := range map[string]int{"draft": 0, "final": 1} {
+		page, err := repo.Search(ctx, &models.SearchQuery{Terms: []models.SearchTerm{{Text: word}}, Limit: 10})
+		if err != nil {
+			t.Fatalf("Search(%q): %v", word, err)
+		}
+		results := page.Results
+		if len(results) != want {
+			t.Errorf("Search(%q): expected %d results, got %d", word, want, len(results))
+		}
+	}
+
+	if err := repo.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	page, err := repo.Search(ctx, &models.SearchQuery{Terms: []models.SearchTerm{{Text: "final"}}, Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	results := page.Results
+	if len(results) != 0 {
+		t.Errorf("deleted task must not be found, got %+v", results)
+	}
+}
+
+func testNewTaskGoesToInbox(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	inbox := findInbox(t, repo)
+	task := mustCreate(t, repo, "task", models.TaskPriorityMedium, nil)
+
+	if task.ProjectID != inbox.ID {
+		t.Errorf("expected inbox project %d, got %d", inbox.ID, task.ProjectID)
+	}
+	got, err := repo.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.ProjectID != inbox.ID {
+		t.Errorf("expected stored project %d, got %d", inbox.ID, got.ProjectID)
+	}
+}
+
+func testCreateProjectAppends(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	inbox := findInbox(t, repo)
+	work := mustCreateProject(t, repo, "work")
+	home := mustCreateProject(t, repo, "home")
+
+	if work.ID == 0 || work.CreatedAt.IsZero() {
+		t.Fatalf("expected id and created_at to be set, got %+v", work)
+	}
+	if home.Position <= work.Position {
+		t.Errorf("expected home after work, got positions %d and %d", work.Position, home.Position)
+	}
+
+	projects, err := repo.GetProjects(ctx)
+	if err != nil {
+		t.Fatalf("GetProjects: %v", err)
+	}
+	assertProjectOrder(t, projects, []int{inbox.ID, work.ID, home.ID})
+
+	got, err := repo.GetProject(ctx, work.ID)
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if got.Name != "work" || got.Color != models.DefaultProjectColor || got.Archived || got.Inbox {
+		t.Errorf("unexpected project %+v", got)
+	}
+
+	if _, err := repo.GetProject(ctx, 999999); !errors.Is(err, repository.ErrProjectNotFound) {
+		t.Errorf("expected ErrProjectNotFound, got %v", err)
+	}
+}
+
+func testCreateTaskInMissingProject(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	task := &models.Task{Title: "orphan", Priority: models.TaskPriorityMedium, ProjectID: 999999}
+	if err := repo.Create(ctx, task); err == nil {
+		t.Fatal("expected error for missing project")
+	}
+}
+
+func testUpdateProject(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	project := mustCreateProject(t, repo, "work")
+
+	name, color, archived := "office", "#ff0000", true
+	err := repo.UpdateProject(ctx, project.ID, &models.UpdateProjectRequest{Name: &name, Color: &color, Archived: &archived})
+	if err != nil {
+		t.Fatalf("UpdateProject: %v", err)
+	}
+
+	got, err := repo.GetProject(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if got.Name != name || got.Color != color || !got.Archived {
+		t.Errorf("unexpected project after update: %+v", got)
+	}
+
+	if err := repo.UpdateProject(ctx, project.ID, &models.UpdateProjectRequest{}); err == nil {
+		t.Error("expected error for empty update")
+	}
+	if err := repo.UpdateProject(ctx, 999999, &models.UpdateProjectRequest{Name: &name}); !errors.Is(err, repository.ErrProjectNotFound) {
+		t.Errorf("expected ErrProjectNotFound, got %v", err)
+	}
+}
+
+func testFilterByProject(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	inbox := findInbox(t, repo)
+	work := mustCreateProject(t, repo, "work")
+
+	loose := mustCreate(t, repo, "loose", models.TaskPriorityMedium, nil)
+	report := mustCreateInProject(t, repo, "report", work.ID)
+
+	tasks, err := repo.GetAll(ctx, &models.TaskFilter{ProjectID: &work.ID}, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{report.ID})
+
+	tasks, err = repo.GetAll(ctx, &models.TaskFilter{ProjectID: &inbox.ID}, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{loose.ID})
+}
+
+func testHideArchivedProjects(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	work := mustCreateProject(t, repo, "work")
+	loose := mustCreate(t, repo, "loose", models.TaskPriorityMedium, nil)
+	report := mustCreateInProject(t, repo, "report", work.ID)
+
+	archived := true
+	if err := repo.UpdateProject(ctx, work.ID, &models.UpdateProjectRequest{Archived: &archived}); err != nil {
+		t.Fatalf("UpdateProject: %v", err)
+	}
+
+	tasks, err := repo.GetAll(ctx, &models.TaskFilter{HideArchived: true}, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{loose.ID})
+
+	// задачи архивного проекта не удаляются
+	tasks, err = repo.GetAll(ctx, &models.TaskFilter{}, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{loose.ID, report.ID})
+
+	tasks, err = repo.GetAll(ctx, &models.TaskFilter{ProjectID: &work.ID}, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{report.ID})
+}
+
+func testReorderProjects(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	inbox := findInbox(t, repo)
+	a := mustCreateProject(t, repo, "a")
+	b := mustCreateProject(t, repo, "b")
+	c := mustCreateProject(t, repo, "c")
+
+	if err := repo.ReorderProjects(ctx, []int{c.ID, a.ID}); err != nil {
+		t.Fatalf("ReorderProjects: %v", err)
+	}
+
+	projects, err := repo.GetProjects(ctx)
+	if err != nil {
+		t.Fatalf("GetProjects: %v", err)
+	}
+	// не перечисленные проекты сохраняют взаимный порядок после указанных
+	assertProjectOrder(t, projects, []int{c.ID, a.ID, inbox.ID, b.ID})
+
+	if err := repo.ReorderProjects(ctx, []int{b.ID, 999999}); !errors.Is(err, repository.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound, got %v", err)
+	}
+	projects, err = repo.GetProjects(ctx)
+	if err != nil {
+		t.Fatalf("GetProjects: %v", err)
+	}
+	assertProjectOrder(t, projects, []int{c.ID, a.ID, inbox.ID, b.ID})
+}
+
+func testDeleteProjectMovesTasks(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	inbox := findInbox(t, repo)
+	work := mustCreateProject(t, repo, "work")
+	report := mustCreateInProject(t, repo, "report", work.ID)
+
+	if err := repo.DeleteProject(ctx, work.ID, 999999); !errors.Is(err, repository.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound for missing target, got %v", err)
+	}
+	if err := repo.DeleteProject(ctx, work.ID, inbox.ID); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.ProjectID != inbox.ID {
+		t.Errorf("expected task moved to inbox %d, got %d", inbox.ID, got.ProjectID)
+	}
+	if _, err := repo.GetProject(ctx, work.ID); !errors.Is(err, repository.ErrProjectNotFound) {
+		t.Errorf("expected ErrProjectNotFound, got %v", err)
+	}
+	if err := repo.DeleteProject(ctx, work.ID, inbox.ID); !errors.Is(err, repository.ErrProjectNotFound) {
+		t.Errorf("expected ErrProjectNotFound, got %v", err)
+	}
+}
+
+func testMoveToProject(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	work := mustCreateProject(t, repo, "work")
+	root := mustCreate(t, repo, "root", models.TaskPriorityMedium, nil)
+	child := mustCreateChild(t, repo, "child", root.ID)
+	grandchild := mustCreateChild(t, repo, "grandchild", child.ID)
+	other := mustCreate(t, repo, "other", models.TaskPriorityMedium, nil)
+
+	if err := repo.MoveToProject(ctx, root.ID, work.ID); err != nil {
+		t.Fatalf("MoveToProject: %v", err)
+	}
+
+	tasks, err := repo.GetAll(ctx, &models.TaskFilter{ProjectID: &work.ID}, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{root.ID, child.ID, grandchild.ID})
+
+	if err := repo.MoveToProject(ctx, other.ID, 999999); !errors.Is(err, repository.ErrProjectNotFound) {
+		t.Errorf("expected ErrProjectNotFound, got %v", err)
+	}
+	if err := repo.MoveToProject(ctx, 999999, work.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func testBulkDelete(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	root := mustCreate(t, repo, "root", models.TaskPriorityMedium, nil)
+	child := mustCreateChild(t, repo, "child", root.ID)
+	other := mustCreate(t, repo, "other", models.TaskPriorityMedium, nil)
+	keep := mustCreate(t, repo, "keep", models.TaskPriorityMedium, nil)
+
+	if err := repo.BulkDelete(ctx, []int{keep.ID, 999999}); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, keep.ID); err != nil {
+		t.Fatalf("failed batch must not delete tasks: %v", err)
+	}
+
+	// подзадача выбрана вместе с родителем
+	if err := repo.BulkDelete(ctx, []int{root.ID, child.ID, other.ID}); err != nil {
+		t.Fatalf("BulkDelete: %v", err)
+	}
+	tasks, err := repo.GetAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{keep.ID})
+
+	// поддерево удалено одной меткой и восстанавливается вместе
+	if err := repo.Restore(ctx, root.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, child.ID); err != nil {
+		t.Errorf("expected child restored with root: %v", err)
+	}
+}
+
+func testBulkMoveToProject(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	work := mustCreateProject(t, repo, "work")
+	root := mustCreate(t, repo, "root", models.TaskPriorityMedium, nil)
+	child := mustCreateChild(t, repo, "child", root.ID)
+	other := mustCreate(t, repo, "other", models.TaskPriorityMedium, nil)
+	stay := mustCreate(t, repo, "stay", models.TaskPriorityMedium, nil)
+
+	if err := repo.BulkMoveToProject(ctx, []int{other.ID, 999999}, work.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+	if err := repo.BulkMoveToProject(ctx, []int{other.ID}, 999999); !errors.Is(err, repository.ErrProjectNotFound) {
+		t.Fatalf("expected ErrProjectNotFound, got %v", err)
+	}
+
+	if err := repo.BulkMoveToProject(ctx, []int{root.ID, child.ID, other.ID}, work.ID); err != nil {
+		t.Fatalf("BulkMoveToProject: %v", err)
+	}
+	tasks, err := repo.GetAll(ctx, &models.TaskFilter{ProjectID: &work.ID}, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	assertSameIDs(t, tasks, []int{root.ID, child.ID, other.ID})
+
+	got, _ := repo.GetByID(ctx, stay.ID)
+	if got.ProjectID == work.ID {
+		t.Error("unselected task must stay in its project")
+	}
+}
+
+func findInbox(t *testing.T, repo repository.TaskRepositoryInterface) *models.Project {
+	t.Helper()
+	ctx := context.Background()
+
+	projects, err := repo.GetProjects(ctx)
+	if err != nil {
+		t.Fatalf("GetProjects: %v", err)
+	}
+	for _, project := range projects {
+		if project.Inbox {
+			return project
+		}
+	}
+	t.Fatalf("inbox project not found in %+v", projects)
+	return nil
+}
+
+func testSavedFilters(t *testing.T, repo repository.TaskRepositoryInterface) {
+	ctx := context.Background()
+	week := &models.SavedFilter{Name: "This week", Query: "due:<7d priority:high"}
+	if err := repo.CreateSavedFilter(ctx, week); err != nil {
+		t.Fatalf("CreateSavedFilter: %v", err)
+	}
+	waiting := &models.SavedFilter{Name: "Waiting", Query: "tag:waiting"}
+	if err := repo.CreateSavedFilter(ctx, waiting); err != nil {
+		t.Fatalf("CreateSavedFilter: %v", err)
+	}
+	if week.ID == 0 || week.CreatedAt.IsZero() {
+		t.Fatalf("expected id and created_at to be set, got %+v", week)
+	}
+
+	if err := repo.CreateSavedFilter(ctx, &models.SavedFilter{Name: "this WEEK", Query: "status:pending"}); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+
+	name, query := "Next week", "due:>=7d due:<14d"
+	if err := repo.UpdateSavedFilter(ctx, week.ID, &models.UpdateSavedFilterRequest{Name: &name, Query: &query}); err != nil {
+		t.Fatalf("UpdateSavedFilter: %v", err)
+	}
+	got, err := repo.GetSavedFilter(ctx, week.ID)
+	if err != nil {
+		t.Fatalf("GetSavedFilter: %v", err)
+	}
+	if got.Name != name || got.Query != query {
+		t.Errorf("unexpected saved filter after update: %+v", got)
+	}
+
+	if err := repo.UpdateSavedFilter(ctx, week.ID, &models.UpdateSavedFilterRequest{}); err == nil {
+		t.Error("expected error for empty update")
+	}
+	if err := repo.UpdateSavedFilter(ctx, 999999, &models.UpdateSavedFilterRequest{Name: &name}); !errors.Is(err, repository.ErrSavedFilterNotFound) {
+		t.Errorf("expected ErrSavedFilterNotFound, got %v", err)
+	}
+
+	if err := repo.DeleteSavedFilter(ctx, week.ID); err != nil {
+		t.Fatalf("DeleteSavedFilter: %v", err)
+	}
+	if _, err := repo.GetSavedFilter(ctx, week.ID); !errors.Is(err, repository.ErrSavedFilterNotFound) {
+		t.Errorf("expected ErrSavedFilterNotFound, got %v", err)
+	}
+	if err := repo.DeleteSavedFilter(ctx, week.ID); !errors.Is(err, repository.ErrSavedFilterNotFound) {
+		t.Errorf("expected ErrSavedFilterNotFound, got %v", err)
+	}
+
+	filters, err := repo.GetSavedFilters(ctx)
+	if err != nil {
+		t.Fatalf("GetSavedFilters: %v", err)
+	}
+	if len(filters) != 1 || filters[0].ID != waiting.ID || filters[0].Name != "Waiting" {
+		t.Errorf("expected only %+v, got %+v", waiting, filters)
+	}
+}
+
+func mustCreateDescribed(t *testing.T, repo repository.TaskRepositoryInterface, title, description string) *models.Task {
+	t.Helper()
+	ctx := context.Background()
+
+	task := &models.Task{Title: title, Description: description, Priority: models.TaskPriorityMedium}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create(%q): %v", title, err)
+	}
+	return task
+}
+
+func mustCreateProject(t *testing.T, repo repository.TaskRepositoryInterface, name string) *models.Project {
+	t.Helper()
+	ctx := context.Background()
+
+	project := &models.Project{Name: name, Color: models.DefaultProjectColor}
+	if err := repo.CreateProject(ctx, project); err != nil {
+		t.Fatalf("CreateProject(%q): %v", name, err)
+	}
+	return project
+}
+
+func mustCreateInProject(t *testing.T, repo repository.TaskRepositoryInterface, title string, projectID int) *models.Task {
+	t.Helper()
+	ctx := context.Background()
+
+	task := &models.Task{Title: title, Priority: models.TaskPriorityMedium, ProjectID: projectID}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create(%q): %v", title, err)
+	}
+	return task
+}
+
+func assertProjectOrder(t *testing.T, projects []*models.Project, want []int) {
+	t.Helper()
+
+	got := make([]int, len(projects))
+	for i, project := range projects {
+		got[i] = project.ID
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected project ids %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected project ids %v, got %v", want, got)
+		}
+	}
+}
+
+func mustCreateTagged(t *testing.T, repo repository.TaskRepositoryInterface, title string, tags ...string) *models.Task {
+	t.Helper()
+	ctx := context.Background()
+
+	task := &models.Task{Title: title, Priority: models.TaskPriorityMedium, Tags: tags}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create(%q): %v", title, err)
+	}
+	return task
+}
+
+func mustCreateTag(t *testing.T, repo repository.TaskRepositoryInterface, name string) *models.Tag {
+	t.Helper()
+	ctx := context.Background()
+
+	tag := &models.Tag{Name: name}
+	if err := repo.CreateTag(ctx, tag); err != nil {
+		t.Fatalf("CreateTag(%q): %v", name, err)
+	}
+	return tag
+}
+
+func findTag(t *testing.T, repo repository.TaskRepositoryInterface, name string) *models.Tag {
+	t.Helper()
+	ctx := context.Background()
+
+	tags, err := repo.GetTags(ctx)
+	if err != nil {
+		t.Fatalf("GetTags: %v", err)
+	}
+	for _, tag := range tags {
+		if tag.Name == name {
+			return tag
+		}
+	}
+	t.Fatalf("tag %q not found in %+v", name, tags)
+	return nil
+}
+
+func assertTags(t *testing.T, got, want []string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("expected tags %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected tags %v, got %v", want, got)
+		}
+	}
+}
+
+func mustCreateChild(t *testing.T, repo repository.TaskRepositoryInterface, title string, parentID int) *models.Task {
+	t.Helper()
+	ctx := context.Background()
+
+	time.Sleep(2 * time.Millisecond)
+	task := &models.Task{Title: title, Priority: models.TaskPriorityMedium, ParentID: &parentID}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create(%q): %v", title, err)
+	}
+	return task
+}
+
+func mustCreate(t *testing.T, repo repository.TaskRepositoryInterface, title string, priority models.TaskPriority, due *time.Time) *models.Task {
+	t.Helper()
+	ctx := context.Background()
+
+	task := &models.Task{Title: title, Priority: priority, DueDate: due}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create(%q): %v", title, err)
+	}
+	return task
+}
+
+func mustAddReminder(t *testing.T, repo repository.TaskRepositoryInterface, taskID int, at time.Time) *models.Reminder {
+	t.Helper()
+	ctx := context.Background()
+
+	reminder := &models.Reminder{TaskID: taskID, At: &at, RemindAt: at}
+	if err := repo.AddReminder(ctx, reminder); err != nil {
+		t.Fatalf("AddReminder(%d): %v", taskID, err)
+	}
+	return reminder
+}
+
+func mustComplete(t *testing.T, repo repository.TaskRepositoryInterface, id int) {
+	t.Helper()
+	ctx := context.Background()
+
+	status := models.TaskStatusCompleted
+	if err := repo.Update(ctx, id, &models.UpdateTaskRequest{Status: &status}); err != nil {
+		t.Fatalf("complete task %d: %v", id, err)
+	}
+}
+
+// atCurrentVersion копирует снимки с текущими версиями задач, как их применяет отмена операции
+func atCurrentVersion(t *testing.T, repo repository.TaskRepositoryInterface, snapshots []*models.Task) []*models.Task {
+	t.Helper()
+
+	current, err := repo.GetSnapshots(context.Background(), taskIDs(snapshots))
+	if err != nil {
+		t.Fatalf("GetSnapshots: %v", err)
+	}
+	versions := make(map[int]int, len(current))
+	for _, task := range current {
+		versions[task.ID] = task.Version
+	}
+
+	copies := make([]*models.Task, len(snapshots))
+	for i, snapshot := range snapshots {
+		task := *snapshot
+		task.Version = versions[task.ID]
+		copies[i] = &task
+	}
+	return copies
+}
+
+func assertOrder(t *testing.T, tasks []*models.Task, want []int) {
+	t.Helper()
+
+	got := taskIDs(tasks)
+	if len(got) != len(want) {
+		t.Fatalf("expected ids %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected ids %v, got %v", want, got)
+		}
+	}
+}
+
+func assertSameIDs(t *testing.T, tasks []*models.Task, want []int) {
+	t.Helper()
+
+	got := taskIDs(tasks)
+	if len(got) != len(want) {
+		t.Fatalf("expected ids %v in any order, got %v", want, got)
+	}
+	seen := make(map[int]bool, len(got))
+	for _, id := range got {
+		seen[id] = true
+	}
+	for _, id := range want {
+		if !seen[id] {
+			t.Fatalf("expected ids %v in any order, got %v", want, got)
+		}
+	}
+}
+
+func taskIDs(tasks []*models.Task) []int {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+// базы хранят время с разной точностью, сравниваем с допуском
+func assertTime(t *testing.T, field string, got, want time.Time) {
+	t.Helper()
+
+	if diff := got.Sub(want); diff > time.Millisecond || diff < -time.Millisecond {
+		t.Errorf("%s: expected %v, got %v", field, want, got)
+	}
+}
+
+func assertTimePtr(t *testing.T, field string, got, want *time.Time) {
+	t.Helper()
+
+	if got == nil || want == nil {
+		if got != want {
+			t.Errorf("%s: expected %v, got %v", field, want, got)
+		}
+		return
+	}
+	assertTime(t, field, *got, *want)
+}
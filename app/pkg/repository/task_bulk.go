@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Запись задач, общая для postgres и sqlite. Пакетные операции выполняют те же
+// запросы в одной транзакции: ошибка на любой задаче откатывает всю пачку.
+// Время now передает вызывающий: sqlite ожидает UTC.
+
+// createTask вставляет задачу и ее теги, id, проект и версия возвращаются в task
+func createTask(q sqlExecutor, dialect queryDialect, task *models.Task, now time.Time) error {
+	query := `
+        INSERT INTO tasks (parent_id, project_id, title, description, status, priority, due_date, all_day, recurrence, created_at, updated_at)
+        VALUES ($1, COALESCE($2, ` + inboxProjectQuery + `), $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING id, project_id, version
+    `
+
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	task.Status = models.TaskStatusPending
+
+	err := q.QueryRow(
+		query,
+		task.ParentID,
+		nullableID(task.ProjectID),
+		task.Title,
+		task.Description,
+		task.Status,
+		task.Priority,
+		nullableTime(dialect, task.DueDate),
+		task.AllDay,
+		task.Recurrence,
+		now,
+		now,
+	).Scan(&task.ID, &task.ProjectID, &task.Version)
+	if err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+
+	return replaceTaskTags(q, task.ID, task.Tags, now)
+}
+
+// updateTask меняет заданные поля задачи; с updates.Version запись проходит, только если версия совпала
+func updateTask(q sqlExecutor, dialect queryDialect, id int, updates *models.UpdateTaskRequest, now time.Time) error {
+	var setParts []string
+	var args []interface{}
+	argCount := 0
+
+	if updates.Title != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("title = $%d", argCount))
+		args = append(args, *updates.Title)
+	}
+
+	if updates.Description != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("description = $%d", argCount))
+		args = append(args, *updates.Description)
+	}
+
+	if updates.Status != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("status = $%d", argCount))
+		args = append(args, *updates.Status)
+	}
+
+	if updates.Priority != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("priority = $%d", argCount))
+		args = append(args, *updates.Priority)
+	}
+
+	if updates.DueDate != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("due_date = $%d", argCount))
+		args = append(args, dialect.timeArg(*updates.DueDate))
+	}
+
+	if updates.AllDay != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("all_day = $%d", argCount))
+		args = append(args, *updates.AllDay)
+	}
+
+	if updates.Recurrence != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("recurrence = $%d", argCount))
+		args = append(args, *updates.Recurrence)
+	}
+
+	if !updates.HasChanges() {
+		return fmt.Errorf("no fields to update")
+	}
+
+	argCount++
+	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argCount))
+	args = append(args, now)
+
+	argCount++
+	args = append(args, id)
+	conditions := fmt.Sprintf("id = $%d AND deleted_at IS NULL", argCount)
+
+	if updates.Version != nil {
+		argCount++
+		conditions += fmt.Sprintf(" AND version = $%d", argCount)
+		args = append(args, *updates.Version)
+	}
+
+	query := fmt.Sprintf("UPDATE tasks SET %s WHERE %s", strings.Join(setParts, ", "), conditions)
+
+	if err := execVersionedUpdate(q, id, updates.Version, query, args...); err != nil {
+		return err
+	}
+	if updates.DueDate != nil {
+		if err := rescheduleReminders(q, dialect, id, updates.DueDate, now); err != nil {
+			return err
+		}
+	}
+	if updates.Tags == nil {
+		return nil
+	}
+	return replaceTaskTags(q, id, *updates.Tags, now)
+}
+
+// bulkUpdateTasks применяет изменения к каждой задаче и создает задачи create
+func bulkUpdateTasks(q sqlExecutor, dialect queryDialect, updates []*models.TaskUpdate, create []*models.Task, now time.Time) error {
+	for _, update := range updates {
+		if err := updateTask(q, dialect, update.ID, update.Changes, now); err != nil {
+			return fmt.Errorf("task %d: %w", update.ID, err)
+		}
+	}
+	for _, task := range create {
+		if err := createTask(q, dialect, task, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// softDeleteTasks переносит в корзину задачи ids вместе с подзадачами одной меткой времени.
+// Подзадача, выбранная вместе с родителем, удаляется один раз.
+func softDeleteTasks(q sqlExecutor, ids []int, now time.Time) error {
+	if err := requireLiveTasks(q, ids); err != nil {
+		return err
+	}
+
+	roots, args := idPlaceholders(ids, 1)
+	query := fmt.Sprintf(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id IN (%s) AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+		)
+		UPDATE tasks SET deleted_at = $%d
+		WHERE id IN (SELECT id FROM subtree)`, roots, len(args)+1)
+
+	if _, err := q.Exec(query, append(args, now)...); err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", err)
+	}
+	return nil
+}
+
+// moveTasksToProject переносит задачи ids вместе с подзадачами в проект
+func moveTasksToProject(q sqlExecutor, ids []int, projectID int, now time.Time) error {
+	if _, err := getProject(q, projectID); err != nil {
+		return err
+	}
+	if err := requireLiveTasks(q, ids); err != nil {
+		return err
+	}
+
+	roots, args := idPlaceholders(ids, 1)
+	query := fmt.Sprintf(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id IN (%s) AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id
+		)
+		UPDATE tasks SET project_id = $%d, updated_at = $%d
+		WHERE id IN (SELECT id FROM subtree)`, roots, len(args)+1, len(args)+2)
+
+	if _, err := q.Exec(query, append(args, projectID, now)...); err != nil {
+		return fmt.Errorf("failed to move tasks to project: %w", err)
+	}
+	return nil
+}
+
+// requireLiveTasks проверяет, что все задачи ids существуют и не в корзине
+func requireLiveTasks(q sqlExecutor, ids []int) error {
+	placeholders, args := idPlaceholders(ids, 1)
+	rows, err := q.Query("SELECT id FROM tasks WHERE id IN ("+placeholders+") AND deleted_at IS NULL", args...)
+	if err != nil {
+		return fmt.Errorf("failed to check tasks: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[int]bool, len(ids))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to check tasks: %w", err)
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to check tasks: %w", err)
+	}
+
+	for _, id := range ids {
+		if !found[id] {
+			return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+		}
+	}
+	return nil
+}
+
+// idPlaceholders возвращает "$start, $start+1, ..." и аргументы для списка id
+func idPlaceholders(ids []int, start int) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
+		args[i] = id
+	}
+	return strings.Join(placeholders, ", "), args
+}
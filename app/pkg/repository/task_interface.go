@@ -1,16 +1,120 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"time"
 	"todo-lits-DMARK/app/pkg/models"
 )
 
+// ErrTaskNotFound возвращается любой реализацией, если задачи с таким id нет
+var ErrTaskNotFound = errors.New("not found")
+
+// ErrTagNotFound возвращается, если тега с таким id нет
+var ErrTagNotFound = errors.New("not found")
+
+// ErrProjectNotFound возвращается, если проекта с таким id нет
+var ErrProjectNotFound = errors.New("not found")
+
+// ErrSavedFilterNotFound возвращается, если сохраненного фильтра с таким id нет
+var ErrSavedFilterNotFound = errors.New("not found")
+
+// ErrRevisionNotFound возвращается, если записи журнала изменений с таким id нет
+var ErrRevisionNotFound = errors.New("not found")
+
+// ErrReminderNotFound возвращается, если напоминания с таким id нет
+var ErrReminderNotFound = errors.New("not found")
+
+// ErrVersionConflict возвращается Update и ApplySnapshots, если версия задачи в запросе устарела
+var ErrVersionConflict = errors.New("version conflict")
+
+// SQL-реализации TaskRepositoryInterface выполняют запросы с переданным ctx: отмена или истекший
+// таймаут прерывает запрос и откатывает транзакцию, метод возвращает ctx.Err()
 type TaskRepositoryInterface interface {
-	Create(task *models.Task) error
-	GetByID(id int) (*models.Task, error)
-	GetAll(filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error)
-	Update(id int, updates *models.UpdateTaskRequest) error
-	Delete(id int) error
-	GetOverdue() ([]*models.Task, error)
-	GetByDateRange(from, to time.Time) ([]*models.Task, error)
+	Create(ctx context.Context, task *models.Task) error
+	GetByID(ctx context.Context, id int) (*models.Task, error)
+	GetAll(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error)
+	// постраничная выборка с курсором по ключу сортировки и общим числом задач
+	GetPage(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort, page *models.PageRequest) (*models.TaskPage, error)
+	Update(ctx context.Context, id int, updates *models.UpdateTaskRequest) error
+	Delete(ctx context.Context, id int) error
+
+	// пакетные операции выполняются целиком или не выполняются вовсе: если хоть одной
+	// задачи нет или ее версия устарела, ни одна задача не меняется.
+	// BulkUpdate в той же транзакции создает задачи create, например следующие повторения.
+	BulkUpdate(ctx context.Context, updates []*models.TaskUpdate, create []*models.Task) error
+	BulkDelete(ctx context.Context, ids []int) error
+	BulkMoveToProject(ctx context.Context, ids []int, projectID int) error
+
+	// корзина: Delete только помечает задачу с подзадачами удаленной, все чтения ее пропускают
+	GetTrash(ctx context.Context) ([]*models.Task, error)
+	Restore(ctx context.Context, id int) error
+	Purge(ctx context.Context, id int) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+
+	// снимки для отмены операций: GetSnapshots видит и задачи из корзины,
+	// ApplySnapshots одной транзакцией записывает задачи целиком, включая deleted_at и теги,
+	// и возвращает ErrVersionConflict, если Version снимка отстает от задачи
+	GetSnapshots(ctx context.Context, ids []int) ([]*models.Task, error)
+	ApplySnapshots(ctx context.Context, tasks []*models.Task) error
+
+	// журнал изменений: AddRevisions пишет записи одной транзакцией и заполняет ID и CreatedAt,
+	// GetRevisions отдает журнал задачи от последних изменений к первым
+	AddRevisions(ctx context.Context, revisions []*models.TaskRevision) error
+	GetRevisions(ctx context.Context, taskID int) ([]*models.TaskRevision, error)
+	GetRevision(ctx context.Context, id int) (*models.TaskRevision, error)
+
+	// напоминания: RemindAt хранится вычисленным, смена срока задачи пересчитывает напоминания
+	// со смещением. ClaimDueReminders помечает сработавшими неотправленные напоминания открытых
+	// задач со временем до now и возвращает их, поэтому клиенты общей базы не отправят одно
+	// напоминание дважды. ReleaseReminder снимает отметку с недоставленного напоминания,
+	// и оно сработает снова. NextReminderAt возвращает nil, если ждать нечего.
+	AddReminder(ctx context.Context, reminder *models.Reminder) error
+	GetReminders(ctx context.Context, taskID int) ([]*models.Reminder, error)
+	DeleteReminder(ctx context.Context, id int) error
+	ClaimDueReminders(ctx context.Context, now time.Time) ([]*models.Reminder, error)
+	ReleaseReminder(ctx context.Context, id int) error
+	NextReminderAt(ctx context.Context) (*time.Time, error)
+
+	// Snooze одной транзакцией переносит срок открытой задачи на until вместе с напоминаниями
+	// и увеличивает SnoozeCount. Напоминания на конкретное время раньше until переносятся на until.
+	Snooze(ctx context.Context, id int, until time.Time) error
+
+	GetOverdue(ctx context.Context) ([]*models.Task, error)
+	GetByDateRange(ctx context.Context, from, to time.Time) ([]*models.Task, error)
+
+	// счетчики для дашборда, сгруппированные по проекту, статусу и приоритету
+	CountTasks(ctx context.Context, periods *models.StatsPeriods) ([]*models.TaskCountGroup, error)
+
+	// полнотекстовый поиск, результаты отсортированы по убыванию Rank, затем по id
+	Search(ctx context.Context, query *models.SearchQuery) (*models.SearchPage, error)
+
+	// иерархия задач. SetParent переносит поддерево в проект нового родителя,
+	// DeleteKeepingSubtasks поднимает прямых потомков к родителю удаляемой задачи;
+	// обе операции выполняются целиком или не выполняются
+	GetSubtree(ctx context.Context, id int) ([]*models.Task, error)
+	SetParent(ctx context.Context, id int, parentID *int) error
+	DeleteKeepingSubtasks(ctx context.Context, id int) error
+
+	// теги, задачи ссылаются на них по имени через task_tags
+	CreateTag(ctx context.Context, tag *models.Tag) error
+	GetTags(ctx context.Context) ([]*models.Tag, error)
+	RenameTag(ctx context.Context, id int, name string) error
+	DeleteTag(ctx context.Context, id int) error
+
+	// проекты, задача без проекта попадает в Inbox
+	CreateProject(ctx context.Context, project *models.Project) error
+	GetProject(ctx context.Context, id int) (*models.Project, error)
+	GetProjects(ctx context.Context) ([]*models.Project, error)
+	UpdateProject(ctx context.Context, id int, updates *models.UpdateProjectRequest) error
+	ReorderProjects(ctx context.Context, ids []int) error
+	DeleteProject(ctx context.Context, id int, moveTo int) error
+	MoveToProject(ctx context.Context, taskID int, projectID int) error
+
+	// сохраненные фильтры, запрос хранится строкой и разбирается usecase
+	CreateSavedFilter(ctx context.Context, filter *models.SavedFilter) error
+	GetSavedFilter(ctx context.Context, id int) (*models.SavedFilter, error)
+	GetSavedFilters(ctx context.Context) ([]*models.SavedFilter, error)
+	UpdateSavedFilter(ctx context.Context, id int, updates *models.UpdateSavedFilterRequest) error
+	DeleteSavedFilter(ctx context.Context, id int) error
 }
@@ -0,0 +1,1616 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"unicode"
+)
+
+// MemoryTaskRepository хранит задачи в памяти процесса.
+// Используется в тестах сервисов и usecase, семантика совпадает с TaskRepository.
+type MemoryTaskRepository struct {
+	mu        sync.RWMutex
+	tasks     map[int]*models.Task
+	nextID    int
+	tags      map[int]*models.Tag
+	nextTagID int
+
+	projects      map[int]*models.Project
+	nextProjectID int
+
+	savedFilters      map[int]*models.SavedFilter
+	nextSavedFilterID int
+
+	revisions      []*models.TaskRevision
+	nextRevisionID int
+
+	reminders      []*models.Reminder
+	nextReminderID int
+}
+
+func NewMemoryTaskRepository() TaskRepositoryInterface {
+	now := time.Now()
+
+	// Inbox, как и в миграции 005, существует всегда
+	inbox := &models.Project{
+		ID:        1,
+		Name:      "Входящие",
+		Color:     models.DefaultProjectColor,
+		Position:  0,
+		Inbox:     true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return &MemoryTaskRepository{
+		tasks:         make(map[int]*models.Task),
+		nextID:        1,
+		tags:          make(map[int]*models.Tag),
+		nextTagID:     1,
+		projects:      map[int]*models.Project{inbox.ID: inbox},
+		nextProjectID: 2,
+
+		savedFilters:      make(map[int]*models.SavedFilter),
+		nextSavedFilterID: 1,
+
+		nextRevisionID: 1,
+		nextReminderID: 1,
+	}
+}
+
+func (r *MemoryTaskRepository) Create(ctx context.Context, task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkCreate(task); err != nil {
+		return err
+	}
+	r.insert(task, time.Now())
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) checkCreate(task *models.Task) error {
+	if task.ParentID != nil {
+		if _, ok := r.tasks[*task.ParentID]; !ok {
+			return fmt.Errorf("failed to create task: parent task %d does not exist", *task.ParentID)
+		}
+	}
+	if task.ProjectID != 0 {
+		if _, ok := r.projects[task.ProjectID]; !ok {
+			return fmt.Errorf("failed to create task: project %d does not exist", task.ProjectID)
+		}
+	}
+	return nil
+}
+
+// insert сохраняет проверенную checkCreate задачу
+func (r *MemoryTaskRepository) insert(task *models.Task, now time.Time) {
+	if task.ProjectID == 0 {
+		task.ProjectID = r.inbox().ID
+	}
+
+	task.ID = r.nextID
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	task.Status = models.TaskStatusPending
+	task.Version = 1
+
+	r.nextID++
+	r.tasks[task.ID] = copyTask(task)
+	r.setTags(r.tasks[task.ID], task.Tags, now)
+}
+
+func (r *MemoryTaskRepository) GetByID(ctx context.Context, id int) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.live(id)
+	if !ok {
+		return nil, fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+
+	return copyTask(task), nil
+}
+
+func (r *MemoryTaskRepository) GetAll(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error) {
+	return r.filterTasks(filter, sort), nil
+}
+
+// filterTasks возвращает отсортированные копии задач под фильтром
+func (r *MemoryTaskRepository) filterTasks(filter *models.TaskFilter, sort *models.TaskSort) []*models.Task {
+	archived := r.archivedProjects()
+
+	tasks := r.collect(func(task *models.Task) bool {
+		if filter == nil {
+			return true
+		}
+		if filter.Status != nil && task.Status != *filter.Status {
+			return false
+		}
+		if filter.Priority != nil && task.Priority != *filter.Priority {
+			return false
+		}
+		if filter.DateFrom != nil && (task.DueDate == nil || task.DueDate.Before(*filter.DateFrom)) {
+			return false
+		}
+		if filter.DateTo != nil && (task.DueDate == nil || task.DueDate.After(*filter.DateTo)) {
+			return false
+		}
+		if len(filter.Tags) > 0 && !matchTags(task.Tags, filter.Tags, filter.TagMatch) {
+			return false
+		}
+		if filter.ProjectID != nil && task.ProjectID != *filter.ProjectID {
+			return false
+		}
+		if filter.HideArchived && archived[task.ProjectID] {
+			return false
+		}
+		if filter.Query != nil && !matchTaskQuery(task, filter.Query) {
+			return false
+		}
+		return true
+	})
+
+	field, desc := normalizeTaskSort(sort)
+	sortTasks(tasks, field, desc)
+
+	return tasks
+}
+
+func (r *MemoryTaskRepository) GetPage(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort, page *models.PageRequest) (*models.TaskPage, error) {
+	if page == nil || page.Limit <= 0 {
+		return nil, fmt.Errorf("page limit must be positive")
+	}
+
+	tasks := r.filterTasks(filter, sort)
+	result := &models.TaskPage{Tasks: []*models.Task{}, Total: len(tasks)}
+
+	if page.Cursor != "" {
+		cursor, err := decodePageCursor(page.Cursor, taskSortKey(sort))
+		if err != nil {
+			return nil, err
+		}
+
+		// курсор превращается в задачу с теми же ключами сортировки
+		last := &models.Task{ID: cursor.ID, DueDate: cursor.Time, Priority: priorityForRank(int(cursor.Rank))}
+		if cursor.Time != nil {
+			last.CreatedAt = *cursor.Time
+		}
+
+		field, desc := normalizeTaskSort(sort)
+		start := len(tasks)
+		for i, task := range tasks {
+			if compareTasks(task, last, field, desc) > 0 {
+				start = i
+				break
+			}
+		}
+		tasks = tasks[start:]
+	}
+
+	if len(tasks) > page.Limit {
+		tasks = tasks[:page.Limit]
+		result.NextCursor = newTaskCursor(tasks[len(tasks)-1], sort).encode()
+	}
+	result.Tasks = append(result.Tasks, tasks...)
+
+	return result, nil
+}
+
+func (r *MemoryTaskRepository) Update(ctx context.Context, id int, updates *models.UpdateTaskRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, err := r.checkUpdate(id, updates)
+	if err != nil {
+		return err
+	}
+	r.applyUpdate(task, updates, time.Now())
+
+	return nil
+}
+
+// BulkUpdate проверяет все изменения до записи, чтобы ошибка не оставила часть из них
+func (r *MemoryTaskRepository) BulkUpdate(ctx context.Context, updates []*models.TaskUpdate, create []*models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make([]*models.Task, len(updates))
+	for i, update := range updates {
+		task, err := r.checkUpdate(update.ID, update.Changes)
+		if err != nil {
+			return fmt.Errorf("task %d: %w", update.ID, err)
+		}
+		tasks[i] = task
+	}
+	for _, task := range create {
+		if err := r.checkCreate(task); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	for i, update := range updates {
+		r.applyUpdate(tasks[i], update.Changes, now)
+	}
+	for _, task := range create {
+		r.insert(task, now)
+	}
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) checkUpdate(id int, updates *models.UpdateTaskRequest) (*models.Task, error) {
+	if !updates.HasChanges() {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	task, ok := r.live(id)
+	if !ok {
+		return nil, fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+	if updates.Version != nil && *updates.Version != task.Version {
+		return nil, fmt.Errorf("task %d has version %d, expected %d: %w", id, task.Version, *updates.Version, ErrVersionConflict)
+	}
+	return task, nil
+}
+
+// applyUpdate записывает проверенные checkUpdate изменения
+func (r *MemoryTaskRepository) applyUpdate(task *models.Task, updates *models.UpdateTaskRequest, now time.Time) {
+	if updates.Title != nil {
+		task.Title = *updates.Title
+	}
+	if updates.Description != nil {
+		task.Description = *updates.Description
+	}
+	if updates.Status != nil {
+		task.Status = *updates.Status
+	}
+	if updates.Priority != nil {
+		task.Priority = *updates.Priority
+	}
+	if updates.DueDate != nil {
+		dueDate := *updates.DueDate
+		task.DueDate = &dueDate
+		r.rescheduleReminders(task.ID, task.DueDate, now)
+	}
+	if updates.AllDay != nil {
+		task.AllDay = *updates.AllDay
+	}
+	if updates.Recurrence != nil {
+		task.Recurrence = *updates.Recurrence
+	}
+	task.UpdatedAt = now
+	task.Version++
+	if updates.Tags != nil {
+		r.setTags(task, *updates.Tags, now)
+	}
+}
+
+// Delete переносит задачу и ее подзадачи, еще не попавшие в корзину, в корзину
+func (r *MemoryTaskRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.live(id); !ok {
+		return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+
+	r.trash(id, time.Now())
+
+	return nil
+}
+
+// BulkDelete переносит в корзину все задачи ids одной меткой времени или ни одну
+func (r *MemoryTaskRepository) BulkDelete(ctx context.Context, ids []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.requireLive(ids); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		// подзадача, выбранная вместе с родителем, уже в корзине
+		if _, ok := r.live(id); ok {
+			r.trash(id, now)
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) trash(id int, now time.Time) {
+	for _, task := range r.subtree(id, followLive) {
+		deletedAt := now
+		task.DeletedAt = &deletedAt
+		task.Version++
+	}
+}
+
+// Restore возвращает задачу и подзадачи, удаленные вместе с ней
+func (r *MemoryTaskRepository) Restore(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok || task.DeletedAt == nil {
+		return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+
+	if task.ParentID != nil && r.tasks[*task.ParentID].DeletedAt != nil {
+		task.ParentID = nil
+	}
+
+	sameBatch := func(parent, child *models.Task) bool {
+		return child.DeletedAt != nil && child.DeletedAt.Equal(*parent.DeletedAt)
+	}
+	for _, task := range r.subtree(id, sameBatch) {
+		task.DeletedAt = nil
+		task.Version++
+	}
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) GetTrash(ctx context.Context) ([]*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []*models.Task
+	for _, task := range r.tasks {
+		if task.DeletedAt == nil {
+			continue
+		}
+		if task.ParentID != nil {
+			parent := r.tasks[*task.ParentID]
+			if parent.DeletedAt != nil && parent.DeletedAt.Equal(*task.DeletedAt) {
+				continue
+			}
+		}
+		tasks = append(tasks, copyTask(task))
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+		return tasks[i].ID > tasks[j].ID
+	})
+
+	return tasks, nil
+}
+
+func (r *MemoryTaskRepository) Purge(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok || task.DeletedAt == nil {
+		return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+
+	r.purge(id)
+	return nil
+}
+
+func (r *MemoryTaskRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []int
+	for _, task := range r.tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Before(before) {
+			expired = append(expired, task.ID)
+		}
+	}
+	for _, id := range expired {
+		if _, ok := r.tasks[id]; ok {
+			r.purge(id)
+		}
+	}
+
+	return len(expired), nil
+}
+
+func (r *MemoryTaskRepository) GetSnapshots(ctx context.Context, ids []int) ([]*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []*models.Task
+	for _, task := range r.tasks {
+		if slices.Contains(ids, task.ID) {
+			tasks = append(tasks, copyTask(task))
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+// ApplySnapshots проверяет все задачи до записи, чтобы ошибка не оставила часть изменений
+func (r *MemoryTaskRepository) ApplySnapshots(ctx context.Context, tasks []*models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, task := range tasks {
+		current, ok := r.tasks[task.ID]
+		if !ok {
+			return fmt.Errorf("task with id %d %w", task.ID, ErrTaskNotFound)
+		}
+		if current.Version != task.Version {
+			return fmt.Errorf("task %d has version %d, expected %d: %w", task.ID, current.Version, task.Version, ErrVersionConflict)
+		}
+		if task.ParentID != nil {
+			if _, ok := r.tasks[*task.ParentID]; !ok {
+				return fmt.Errorf("failed to apply task snapshot: parent task %d does not exist", *task.ParentID)
+			}
+		}
+		if _, ok := r.projects[task.ProjectID]; !ok {
+			return fmt.Errorf("failed to apply task snapshot: project %d does not exist", task.ProjectID)
+		}
+	}
+
+	now := time.Now()
+	for _, task := range tasks {
+		stored := copyTask(task)
+		stored.CreatedAt = r.tasks[task.ID].CreatedAt
+		stored.UpdatedAt = now
+		stored.Version = r.tasks[task.ID].Version + 1
+		r.tasks[task.ID] = stored
+		r.setTags(stored, task.Tags, now)
+		r.rescheduleReminders(task.ID, stored.DueDate, now)
+	}
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) AddRevisions(ctx context.Context, revisions []*models.TaskRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, revision := range revisions {
+		if _, ok := r.tasks[revision.TaskID]; !ok {
+			return fmt.Errorf("failed to add task revision: task %d does not exist", revision.TaskID)
+		}
+	}
+
+	now := time.Now()
+	for _, revision := range revisions {
+		revision.ID = r.nextRevisionID
+		revision.CreatedAt = now
+		r.nextRevisionID++
+		r.revisions = append(r.revisions, copyRevision(revision))
+	}
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) GetRevisions(ctx context.Context, taskID int) ([]*models.TaskRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revisions := []*models.TaskRevision{}
+	for i := len(r.revisions) - 1; i >= 0; i-- {
+		if r.revisions[i].TaskID == taskID {
+			revisions = append(revisions, copyRevision(r.revisions[i]))
+		}
+	}
+
+	return revisions, nil
+}
+
+func (r *MemoryTaskRepository) GetRevision(ctx context.Context, id int) (*models.TaskRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, revision := range r.revisions {
+		if revision.ID == id {
+			return copyRevision(revision), nil
+		}
+	}
+
+	return nil, fmt.Errorf("task revision with id %d %w", id, ErrRevisionNotFound)
+}
+
+func (r *MemoryTaskRepository) AddReminder(ctx context.Context, reminder *models.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.requireLive([]int{reminder.TaskID}); err != nil {
+		return err
+	}
+
+	reminder.ID = r.nextReminderID
+	reminder.CreatedAt = time.Now()
+	r.nextReminderID++
+	r.reminders = append(r.reminders, copyReminder(reminder))
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) GetReminders(ctx context.Context, taskID int) ([]*models.Reminder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reminders := []*models.Reminder{}
+	for _, reminder := range r.reminders {
+		if reminder.TaskID == taskID {
+			reminders = append(reminders, copyReminder(reminder))
+		}
+	}
+	sortReminders(reminders)
+
+	return reminders, nil
+}
+
+func (r *MemoryTaskRepository) DeleteReminder(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := len(r.reminders)
+	r.reminders = slices.DeleteFunc(r.reminders, func(reminder *models.Reminder) bool {
+		return reminder.ID == id
+	})
+	if len(r.reminders) == count {
+		return fmt.Errorf("reminder with id %d %w", id, ErrReminderNotFound)
+	}
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) ClaimDueReminders(ctx context.Context, now time.Time) ([]*models.Reminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimed := []*models.Reminder{}
+	for _, reminder := range r.reminders {
+		if r.reminderPending(reminder) && !reminder.RemindAt.After(now) {
+			firedAt := now
+			reminder.FiredAt = &firedAt
+			claimed = append(claimed, copyReminder(reminder))
+		}
+	}
+	sortReminders(claimed)
+
+	return claimed, nil
+}
+
+func (r *MemoryTaskRepository) ReleaseReminder(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, reminder := range r.reminders {
+		if reminder.ID == id {
+			reminder.FiredAt = nil
+			return nil
+		}
+	}
+	return fmt.Errorf("reminder with id %d %w", id, ErrReminderNotFound)
+}
+
+func (r *MemoryTaskRepository) NextReminderAt(ctx context.Context) (*time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var next *time.Time
+	for _, reminder := range r.reminders {
+		if r.reminderPending(reminder) && (next == nil || reminder.RemindAt.Before(*next)) {
+			remindAt := reminder.RemindAt
+			next = &remindAt
+		}
+	}
+
+	return next, nil
+}
+
+func (r *MemoryTaskRepository) Snooze(ctx context.Context, id int, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.live(id)
+	if !ok || task.Status != models.TaskStatusPending {
+		return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+
+	now := time.Now()
+	due := until
+	task.DueDate = &due
+	task.AllDay = false
+	task.SnoozeCount++
+	task.UpdatedAt = now
+	task.Version++
+	r.rescheduleReminders(id, task.DueDate, now)
+
+	for _, reminder := range r.reminders {
+		if reminder.TaskID == id && reminder.At != nil && reminder.RemindAt.Before(until) {
+			at := until
+			reminder.At = &at
+			reminder.RemindAt = until
+			reminder.FiredAt = nil
+		}
+	}
+
+	return nil
+}
+
+// reminderPending — напоминание еще не отправлено, а задача открыта и не в корзине; вызывать под блокировкой
+func (r *MemoryTaskRepository) reminderPending(reminder *models.Reminder) bool {
+	task, ok := r.live(reminder.TaskID)
+	return ok && reminder.FiredAt == nil && task.Status == models.TaskStatusPending
+}
+
+// rescheduleReminders повторяет одноименную функцию SQL-репозиториев; вызывать под блокировкой
+func (r *MemoryTaskRepository) rescheduleReminders(taskID int, due *time.Time, now time.Time) {
+	for _, reminder := range r.reminders {
+		if reminder.TaskID != taskID || reminder.OffsetMinutes == nil {
+			continue
+		}
+		if due == nil {
+			if reminder.FiredAt == nil {
+				firedAt := now
+				reminder.FiredAt = &firedAt
+			}
+			continue
+		}
+		reminder.RemindAt = models.ReminderTime(*due, *reminder.OffsetMinutes)
+		if reminder.RemindAt.After(now) {
+			reminder.FiredAt = nil
+		}
+	}
+}
+
+func (r *MemoryTaskRepository) GetOverdue(ctx context.Context) ([]*models.Task, error) {
+	now := time.Now()
+	tasks := r.collect(func(task *models.Task) bool {
+		return task.Status == models.TaskStatusPending && task.DueDate != nil && task.DueDate.Before(now)
+	})
+	sortTasks(tasks, "due_date", false)
+
+	return tasks, nil
+}
+
+func (r *MemoryTaskRepository) GetByDateRange(ctx context.Context, from, to time.Time) ([]*models.Task, error) {
+	tasks := r.collect(func(task *models.Task) bool {
+		return task.DueDate != nil && !task.DueDate.Before(from) && !task.DueDate.After(to)
+	})
+	sortTasks(tasks, "due_date", false)
+
+	return tasks, nil
+}
+
+func (r *MemoryTaskRepository) CountTasks(ctx context.Context, periods *models.StatsPeriods) ([]*models.TaskCountGroup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type groupKey struct {
+		projectID int
+		status    models.TaskStatus
+		priority  models.TaskPriority
+	}
+	within := func(t, from, to time.Time) bool { return !t.Before(from) && !t.After(to) }
+
+	byKey := make(map[groupKey]*models.TaskCountGroup)
+	for _, task := range r.tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+		key := groupKey{task.ProjectID, task.Status, task.Priority}
+		group := byKey[key]
+		if group == nil {
+			group = &models.TaskCountGroup{ProjectID: task.ProjectID, Status: task.Status, Priority: task.Priority}
+			byKey[key] = group
+		}
+		group.Count++
+
+		if task.Status != models.TaskStatusPending || task.DueDate == nil {
+			continue
+		}
+		if task.DueDate.Before(periods.Now) {
+			group.Overdue++
+		}
+		if within(*task.DueDate, periods.TodayFrom, periods.TodayTo) {
+			group.DueToday++
+		}
+		if within(*task.DueDate, periods.WeekFrom, periods.WeekTo) {
+			group.DueThisWeek++
+		}
+	}
+
+	groups := make([]*models.TaskCountGroup, 0, len(byKey))
+	for _, group := range byKey {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.ProjectID != b.ProjectID {
+			return a.ProjectID < b.ProjectID
+		}
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		return a.Priority < b.Priority
+	})
+
+	return groups, nil
+}
+
+// Search без стемминга: слово совпадает подстрокой, префикс — началом слова,
+// фраза — подряд идущими словами. Совпадение в заголовке весит вдвое больше.
+func (r *MemoryTaskRepository) Search(ctx context.Context, query *models.SearchQuery) (*models.SearchPage, error) {
+	tasks := r.collect(func(task *models.Task) bool {
+		for _, term := range query.Terms {
+			if matchSearchTerm(task.Title, term) || matchSearchTerm(task.Description, term) {
+				if term.Negated {
+					return false
+				}
+			} else if !term.Negated {
+				return false
+			}
+		}
+		return true
+	})
+
+	results := make([]*models.SearchResult, len(tasks))
+	for i, task := range tasks {
+		result := &models.SearchResult{Task: task}
+		for _, term := range query.Terms {
+			if term.Negated {
+				continue
+			}
+			if matchSearchTerm(task.Title, term) {
+				result.Rank += 2
+			}
+			if matchSearchTerm(task.Description, term) {
+				result.Rank++
+			}
+		}
+		result.Snippet = highlightSearchTerms(strings.TrimSpace(task.Title+" "+task.Description), query.Terms)
+		results[i] = result
+	}
+
+	// как в sql: rank по убыванию, затем id по возрастанию, задачи уже упорядочены по id
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank > results[j].Rank })
+	page := &models.SearchPage{Results: []*models.SearchResult{}, Total: len(results)}
+
+	if query.Cursor != "" {
+		cursor, err := decodePageCursor(query.Cursor, searchCursorSort)
+		if err != nil {
+			return nil, err
+		}
+		start := len(results)
+		for i, result := range results {
+			if result.Rank < cursor.Rank || (result.Rank == cursor.Rank && result.Task.ID > cursor.ID) {
+				start = i
+				break
+			}
+		}
+		results = results[start:]
+	}
+
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+		page.NextCursor = newSearchCursor(results[len(results)-1]).encode()
+	}
+	page.Results = append(page.Results, results...)
+
+	return page, nil
+}
+
+func (r *MemoryTaskRepository) GetSubtree(ctx context.Context, id int) ([]*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.live(id); !ok {
+		return nil, fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+
+	subtree := r.subtree(id, followLive)
+	tasks := make([]*models.Task, len(subtree))
+	for i, task := range subtree {
+		tasks[i] = copyTask(task)
+	}
+
+	return tasks, nil
+}
+
+func (r *MemoryTaskRepository) SetParent(ctx context.Context, id int, parentID *int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.live(id)
+	if !ok {
+		return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+	if parentID != nil {
+		if *parentID == id {
+			return fmt.Errorf("failed to move task: task cannot be its own parent")
+		}
+		if _, ok := r.tasks[*parentID]; !ok {
+			return fmt.Errorf("failed to move task: parent task %d does not exist", *parentID)
+		}
+		parent := *parentID
+		task.ParentID = &parent
+	} else {
+		task.ParentID = nil
+	}
+	now := time.Now()
+	task.UpdatedAt = now
+	task.Version++
+
+	// поддерево переезжает в проект нового родителя
+	if parentID != nil && r.tasks[*parentID].ProjectID != task.ProjectID {
+		r.moveSubtree(id, r.tasks[*parentID].ProjectID, now)
+	}
+
+	return nil
+}
+
+// DeleteKeepingSubtasks поднимает прямых потомков id к его родителю и переносит id в корзину
+func (r *MemoryTaskRepository) DeleteKeepingSubtasks(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.live(id)
+	if !ok {
+		return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+	}
+
+	now := time.Now()
+	for _, child := range r.tasks {
+		if child.DeletedAt == nil && child.ParentID != nil && *child.ParentID == id {
+			child.ParentID = nil
+			if task.ParentID != nil {
+				parentID := *task.ParentID
+				child.ParentID = &parentID
+			}
+			child.UpdatedAt = now
+			child.Version++
+		}
+	}
+	r.trash(id, now)
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tagByName(tag.Name) != nil {
+		return fmt.Errorf("failed to create tag: tag %q already exists", tag.Name)
+	}
+
+	tag.ID = r.nextTagID
+	tag.CreatedAt = time.Now()
+	r.nextTagID++
+
+	stored := *tag
+	r.tags[tag.ID] = &stored
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) GetTags(ctx context.Context) ([]*models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, task := range r.tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+		for _, name := range task.Tags {
+			counts[name]++
+		}
+	}
+
+	var tags []*models.Tag
+	for _, tag := range r.tags {
+		clone := *tag
+		clone.TaskCount = counts[tag.Name]
+		tags = append(tags, &clone)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags, nil
+}
+
+func (r *MemoryTaskRepository) RenameTag(ctx context.Context, id int, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tag, ok := r.tags[id]
+	if !ok {
+		return fmt.Errorf("tag with id %d %w", id, ErrTagNotFound)
+	}
+	if existing := r.tagByName(name); existing != nil && existing.ID != id {
+		return fmt.Errorf("failed to rename tag: tag %q already exists", name)
+	}
+
+	for _, task := range r.tasks {
+		for i, tagName := range task.Tags {
+			if tagName == tag.Name {
+				task.Tags[i] = name
+				sort.Strings(task.Tags)
+				break
+			}
+		}
+	}
+	tag.Name = name
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) DeleteTag(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tag, ok := r.tags[id]
+	if !ok {
+		return fmt.Errorf("tag with id %d %w", id, ErrTagNotFound)
+	}
+
+	for _, task := range r.tasks {
+		task.Tags = removeString(task.Tags, tag.Name)
+	}
+	delete(r.tags, id)
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) CreateProject(ctx context.Context, project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project.ID = r.nextProjectID
+	project.Position = 1
+	for _, p := range r.projects {
+		if p.Position >= project.Position {
+			project.Position = p.Position + 1
+		}
+	}
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = project.CreatedAt
+	r.nextProjectID++
+
+	stored := *project
+	r.projects[project.ID] = &stored
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, fmt.Errorf("project with id %d %w", id, ErrProjectNotFound)
+	}
+
+	clone := *project
+	return &clone, nil
+}
+
+func (r *MemoryTaskRepository) GetProjects(ctx context.Context) ([]*models.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedProjects(), nil
+}
+
+func (r *MemoryTaskRepository) UpdateProject(ctx context.Context, id int, updates *models.UpdateProjectRequest) error {
+	if updates.Name == nil && updates.Color == nil && updates.Archived == nil {
+		return fmt.Errorf("no fields to update")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return fmt.Errorf("project with id %d %w", id, ErrProjectNotFound)
+	}
+
+	if updates.Name != nil {
+		project.Name = *updates.Name
+	}
+	if updates.Color != nil {
+		project.Color = *updates.Color
+	}
+	if updates.Archived != nil {
+		project.Archived = *updates.Archived
+	}
+	project.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) ReorderProjects(ctx context.Context, ids []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := r.projects[id]; !ok {
+			return fmt.Errorf("project with id %d %w", id, ErrProjectNotFound)
+		}
+	}
+
+	// как в sql: остальные проекты сохраняют порядок и уходят в конец
+	for _, project := range r.sortedProjects() {
+		r.projects[project.ID].Position = project.Position + len(ids) + 1
+	}
+	now := time.Now()
+	for i, id := range ids {
+		r.projects[id].Position = i + 1
+		r.projects[id].UpdatedAt = now
+	}
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) DeleteProject(ctx context.Context, id int, moveTo int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[moveTo]; !ok {
+		return fmt.Errorf("project with id %d %w", moveTo, ErrProjectNotFound)
+	}
+	if _, ok := r.projects[id]; !ok {
+		return fmt.Errorf("project with id %d %w", id, ErrProjectNotFound)
+	}
+
+	now := time.Now()
+	for _, task := range r.tasks {
+		if task.ProjectID == id {
+			task.ProjectID = moveTo
+			task.UpdatedAt = now
+			task.Version++
+		}
+	}
+	delete(r.projects, id)
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) MoveToProject(ctx context.Context, taskID int, projectID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[projectID]; !ok {
+		return fmt.Errorf("project with id %d %w", projectID, ErrProjectNotFound)
+	}
+	if _, ok := r.live(taskID); !ok {
+		return fmt.Errorf("task with id %d %w", taskID, ErrTaskNotFound)
+	}
+
+	r.moveSubtree(taskID, projectID, time.Now())
+
+	return nil
+}
+
+// BulkMoveToProject переносит задачи ids с подзадачами в проект, если все они существуют
+func (r *MemoryTaskRepository) BulkMoveToProject(ctx context.Context, ids []int, projectID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[projectID]; !ok {
+		return fmt.Errorf("project with id %d %w", projectID, ErrProjectNotFound)
+	}
+	if err := r.requireLive(ids); err != nil {
+		return err
+	}
+
+	// подзадача, выбранная вместе с родителем, переносится один раз
+	now := time.Now()
+	moved := make(map[int]bool)
+	for _, id := range ids {
+		for _, task := range r.subtree(id, nil) {
+			if !moved[task.ID] {
+				moved[task.ID] = true
+				task.ProjectID = projectID
+				task.UpdatedAt = now
+				task.Version++
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) moveSubtree(id, projectID int, now time.Time) {
+	for _, task := range r.subtree(id, nil) {
+		task.ProjectID = projectID
+		task.UpdatedAt = now
+		task.Version++
+	}
+}
+
+func (r *MemoryTaskRepository) CreateSavedFilter(ctx context.Context, filter *models.SavedFilter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// уникальный индекс в sql сравнивает имена через LOWER
+	for _, existing := range r.savedFilters {
+		if strings.EqualFold(existing.Name, filter.Name) {
+			return fmt.Errorf("failed to create saved filter: name %q is taken", filter.Name)
+		}
+	}
+
+	filter.ID = r.nextSavedFilterID
+	filter.CreatedAt = time.Now()
+	filter.UpdatedAt = filter.CreatedAt
+	r.nextSavedFilterID++
+
+	stored := *filter
+	r.savedFilters[filter.ID] = &stored
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) GetSavedFilter(ctx context.Context, id int) (*models.SavedFilter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	filter, ok := r.savedFilters[id]
+	if !ok {
+		return nil, fmt.Errorf("saved filter with id %d %w", id, ErrSavedFilterNotFound)
+	}
+
+	clone := *filter
+	return &clone, nil
+}
+
+func (r *MemoryTaskRepository) GetSavedFilters(ctx context.Context) ([]*models.SavedFilter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	filters := make([]*models.SavedFilter, 0, len(r.savedFilters))
+	for _, filter := range r.savedFilters {
+		clone := *filter
+		filters = append(filters, &clone)
+	}
+	sort.Slice(filters, func(i, j int) bool { return filters[i].ID < filters[j].ID })
+
+	return filters, nil
+}
+
+func (r *MemoryTaskRepository) UpdateSavedFilter(ctx context.Context, id int, updates *models.UpdateSavedFilterRequest) error {
+	if updates.Name == nil && updates.Query == nil {
+		return fmt.Errorf("no fields to update")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	filter, ok := r.savedFilters[id]
+	if !ok {
+		return fmt.Errorf("saved filter with id %d %w", id, ErrSavedFilterNotFound)
+	}
+
+	if updates.Name != nil {
+		for _, existing := range r.savedFilters {
+			if existing.ID != id && strings.EqualFold(existing.Name, *updates.Name) {
+				return fmt.Errorf("failed to update saved filter: name %q is taken", *updates.Name)
+			}
+		}
+		filter.Name = *updates.Name
+	}
+	if updates.Query != nil {
+		filter.Query = *updates.Query
+	}
+	filter.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (r *MemoryTaskRepository) DeleteSavedFilter(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.savedFilters[id]; !ok {
+		return fmt.Errorf("saved filter with id %d %w", id, ErrSavedFilterNotFound)
+	}
+	delete(r.savedFilters, id)
+
+	return nil
+}
+
+// inbox возвращает проект по умолчанию, вызывать под блокировкой
+func (r *MemoryTaskRepository) inbox() *models.Project {
+	for _, project := range r.projects {
+		if project.Inbox {
+			return project
+		}
+	}
+	return nil
+}
+
+// sortedProjects возвращает копии проектов в порядке position, вызывать под блокировкой
+func (r *MemoryTaskRepository) sortedProjects() []*models.Project {
+	var projects []*models.Project
+	for _, project := range r.projects {
+		clone := *project
+		projects = append(projects, &clone)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Position != projects[j].Position {
+			return projects[i].Position < projects[j].Position
+		}
+		return projects[i].ID < projects[j].ID
+	})
+	return projects
+}
+
+func (r *MemoryTaskRepository) archivedProjects() map[int]bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	archived := make(map[int]bool)
+	for _, project := range r.projects {
+		if project.Archived {
+			archived[project.ID] = true
+		}
+	}
+	return archived
+}
+
+// setTags заменяет теги задачи и создает недостающие, вызывать под блокировкой
+func (r *MemoryTaskRepository) setTags(task *models.Task, names []string, now time.Time) {
+	tags := []string{}
+	for _, name := range names {
+		if r.tagByName(name) == nil {
+			r.tags[r.nextTagID] = &models.Tag{ID: r.nextTagID, Name: name, CreatedAt: now}
+			r.nextTagID++
+		}
+		if !containsString(tags, name) {
+			tags = append(tags, name)
+		}
+	}
+	sort.Strings(tags)
+	task.Tags = tags
+}
+
+func (r *MemoryTaskRepository) tagByName(name string) *models.Tag {
+	for _, tag := range r.tags {
+		if tag.Name == name {
+			return tag
+		}
+	}
+	return nil
+}
+
+func matchTags(taskTags, filter []string, match models.TagMatchMode) bool {
+	matched := 0
+	for _, name := range filter {
+		if containsString(taskTags, name) {
+			matched++
+		}
+	}
+	if match == models.TagMatchAll {
+		return matched == len(filter)
+	}
+	return matched > 0
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, value string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// live возвращает задачу, если она есть и не в корзине; вызывать под блокировкой
+func (r *MemoryTaskRepository) live(id int) (*models.Task, bool) {
+	task, ok := r.tasks[id]
+	if !ok || task.DeletedAt != nil {
+		return nil, false
+	}
+	return task, true
+}
+
+// followLive спускается только к подзадачам не из корзины
+func (r *MemoryTaskRepository) requireLive(ids []int) error {
+	for _, id := range ids {
+		if _, ok := r.live(id); !ok {
+			return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
+		}
+	}
+	return nil
+}
+
+func followLive(_, child *models.Task) bool {
+	return child.DeletedAt == nil
+}
+
+// purge удаляет поддерево навсегда, как ON DELETE CASCADE; вызывать под блокировкой
+func (r *MemoryTaskRepository) purge(id int) {
+	for _, task := range r.subtree(id, nil) {
+		delete(r.tasks, task.ID)
+		r.revisions = slices.DeleteFunc(r.revisions, func(revision *models.TaskRevision) bool {
+			return revision.TaskID == task.ID
+		})
+		r.reminders = slices.DeleteFunc(r.reminders, func(reminder *models.Reminder) bool {
+			return reminder.TaskID == task.ID
+		})
+	}
+}
+
+// subtree обходит потомков в ширину, follow отбирает подзадачи для обхода (nil — все).
+// Вызывать под блокировкой.
+func (r *MemoryTaskRepository) subtree(id int, follow func(parent, child *models.Task) bool) []*models.Task {
+	result := []*models.Task{r.tasks[id]}
+	for i := 0; i < len(result); i++ {
+		var children []*models.Task
+		for _, task := range r.tasks {
+			if task.ParentID != nil && *task.ParentID == result[i].ID && (follow == nil || follow(result[i], task)) {
+				children = append(children, task)
+			}
+		}
+		sort.Slice(children, func(a, b int) bool { return children[a].ID < children[b].ID })
+		result = append(result, children...)
+	}
+	return result
+}
+
+// collect возвращает копии задач не из корзины, подходящих под условие, в порядке создания
+func (r *MemoryTaskRepository) collect(match func(task *models.Task) bool) []*models.Task {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []*models.Task
+	for _, task := range r.tasks {
+		if task.DeletedAt == nil && match(task) {
+			tasks = append(tasks, copyTask(task))
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks
+}
+
+// сортировка повторяет taskOrderBy: NULLS LAST для ASC, NULLS FIRST для DESC и id последним ключом
+func sortTasks(tasks []*models.Task, field string, desc bool) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return compareTasks(tasks[i], tasks[j], field, desc) < 0
+	})
+}
+
+// compareTasks возвращает -1, если a идет раньше b в заданном порядке
+func compareTasks(a, b *models.Task, field string, desc bool) int {
+	result := 0
+	switch field {
+	case "due_date":
+		switch {
+		case a.DueDate == nil && b.DueDate == nil:
+		case a.DueDate == nil:
+			result = 1
+		case b.DueDate == nil:
+			result = -1
+		default:
+			result = a.DueDate.Compare(*b.DueDate)
+		}
+	case "priority":
+		result = a.PriorityValue() - b.PriorityValue()
+	default:
+		result = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if result == 0 {
+		result = a.ID - b.ID
+	}
+
+	switch {
+	case result == 0:
+		return 0
+	case (result < 0) != desc:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// priorityForRank обратна models.TaskPriority.Rank
+func priorityForRank(rank int) models.TaskPriority {
+	for _, priority := range []models.TaskPriority{models.TaskPriorityLow, models.TaskPriorityMedium, models.TaskPriorityHigh} {
+		if priority.Rank() == rank {
+			return priority
+		}
+	}
+	return ""
+}
+
+func copyTask(task *models.Task) *models.Task {
+	clone := *task
+	if task.ParentID != nil {
+		parentID := *task.ParentID
+		clone.ParentID = &parentID
+	}
+	if task.DueDate != nil {
+		dueDate := *task.DueDate
+		clone.DueDate = &dueDate
+	}
+	if task.DeletedAt != nil {
+		deletedAt := *task.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	clone.Tags = append([]string{}, task.Tags...)
+	return &clone
+}
+
+func copyReminder(reminder *models.Reminder) *models.Reminder {
+	clone := *reminder
+	if reminder.At != nil {
+		at := *reminder.At
+		clone.At = &at
+	}
+	if reminder.OffsetMinutes != nil {
+		offset := *reminder.OffsetMinutes
+		clone.OffsetMinutes = &offset
+	}
+	if reminder.FiredAt != nil {
+		firedAt := *reminder.FiredAt
+		clone.FiredAt = &firedAt
+	}
+	return &clone
+}
+
+// sortReminders упорядочивает напоминания по времени срабатывания, затем по id
+func sortReminders(reminders []*models.Reminder) {
+	sort.Slice(reminders, func(i, j int) bool {
+		if !reminders[i].RemindAt.Equal(reminders[j].RemindAt) {
+			return reminders[i].RemindAt.Before(reminders[j].RemindAt)
+		}
+		return reminders[i].ID < reminders[j].ID
+	})
+}
+
+func copyRevision(revision *models.TaskRevision) *models.TaskRevision {
+	clone := *revision
+	clone.Changes = append([]models.FieldChange{}, revision.Changes...)
+	if revision.Task != nil {
+		clone.Task = copyTask(revision.Task)
+	}
+	return &clone
+}
+
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func matchSearchTerm(text string, term models.SearchTerm) bool {
+	words := searchWords(text)
+	termWords := searchWords(term.Text)
+	if len(termWords) == 0 {
+		return false
+	}
+
+	for i := range words {
+		switch {
+		case term.Phrase:
+			if i+len(termWords) <= len(words) && slices.Equal(words[i:i+len(termWords)], termWords) {
+				return true
+			}
+		case term.Prefix:
+			if strings.HasPrefix(words[i], termWords[0]) {
+				return true
+			}
+		default:
+			if strings.Contains(words[i], strings.ToLower(term.Text)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// highlightSearchTerms обрамляет метками слова, совпавшие с терминами без отрицания
+func highlightSearchTerms(text string, terms []models.SearchTerm) string {
+	var b strings.Builder
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+			j++
+		}
+		word := string(runes[i:j])
+
+		matched := false
+		for _, term := range terms {
+			single := term
+			single.Phrase = false
+			for _, part := range searchWords(term.Text) {
+				single.Text = part
+				if !term.Negated && matchSearchTerm(word, single) {
+					matched = true
+				}
+			}
+		}
+
+		if matched {
+			b.WriteString(models.SnippetMatchStart + word + models.SnippetMatchEnd)
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+
+	return b.String()
+}
+
+// matchTaskQuery повторяет taskQueryConditions: отрицание инвертирует условие целиком
+func matchTaskQuery(task *models.Task, query *models.TaskQuery) bool {
+	for _, c := range query.Conditions {
+		if matchQueryCondition(task, c) == c.Negated {
+			return false
+		}
+	}
+
+	for _, term := range query.Text {
+		matched := matchSearchTerm(task.Title, term) || matchSearchTerm(task.Description, term)
+		if matched == term.Negated {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchQueryCondition(task *models.Task, c models.QueryCondition) bool {
+	switch c.Field {
+	case models.QueryFieldStatus:
+		return slices.Contains(c.Values, string(task.Status))
+	case models.QueryFieldPriority:
+		return slices.Contains(c.Values, string(task.Priority))
+	case models.QueryFieldProject:
+		return slices.Contains(c.ProjectIDs, task.ProjectID)
+	case models.QueryFieldTag:
+		return matchTags(task.Tags, c.Values, models.TagMatchAny)
+	case models.QueryFieldDue, models.QueryFieldCreated:
+		date := task.DueDate
+		if c.Field == models.QueryFieldCreated {
+			date = &task.CreatedAt
+		}
+
+		switch c.Op {
+		case models.QueryOpEmpty:
+			return date == nil
+		case models.QueryOpBefore:
+			return date != nil && date.Before(c.From)
+		case models.QueryOpAfter:
+			return date != nil && !date.Before(c.From)
+		case models.QueryOpRange:
+			return date != nil && !date.Before(c.From) && date.Before(c.To)
+		}
+	}
+
+	return false
+}
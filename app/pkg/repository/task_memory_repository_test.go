@@ -0,0 +1,13 @@
+package repository_test
+
+import (
+	"testing"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/repository/repotest"
+)
+
+func TestMemoryTaskRepository(t *testing.T) {
+	repotest.RunTaskRepositorySuite(t, func(t *testing.T) repository.TaskRepositoryInterface {
+		return repository.NewMemoryTaskRepository()
+	})
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// ErrInvalidCursor возвращается для поврежденного курсора или курсора другой сортировки
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// priorityRankExpr упорядочивает приоритеты так же, как models.TaskPriority.Rank
+const priorityRankExpr = "CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 END"
+
+// searchCursorSort — сортировка выдачи поиска: Rank по убыванию, затем id по возрастанию
+const searchCursorSort = "rank"
+
+// pageCursor — ключ последней строки страницы. Time хранит created_at или due_date
+// (nil для задачи без срока), Rank — приоритет или релевантность поиска.
+// id добавлен к каждой сортировке, поэтому порядок строгий и курсор стабилен.
+type pageCursor struct {
+	Sort string     `json:"s"`
+	Time *time.Time `json:"t,omitempty"`
+	Rank float64    `json:"r,omitempty"`
+	ID   int        `json:"id"`
+}
+
+func (c *pageCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageCursor проверяет, что курсор выдан для той же сортировки
+func decodePageCursor(value, sortKey string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	cursor := &pageCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if cursor.Sort != sortKey {
+		return nil, fmt.Errorf("%w: cursor is for sort %q, not %q", ErrInvalidCursor, cursor.Sort, sortKey)
+	}
+	if sortKey == "created_at:asc" || sortKey == "created_at:desc" {
+		if cursor.Time == nil {
+			return nil, fmt.Errorf("%w: missing created_at", ErrInvalidCursor)
+		}
+	}
+
+	return cursor, nil
+}
+
+// normalizeTaskSort возвращает поле и направление, по умолчанию новые задачи первыми
+func normalizeTaskSort(sort *models.TaskSort) (field string, desc bool) {
+	if sort == nil || sort.Field == "" {
+		return "created_at", true
+	}
+	return sort.Field, sort.Order == "desc"
+}
+
+func taskSortKey(sort *models.TaskSort) string {
+	field, desc := normalizeTaskSort(sort)
+	if desc {
+		return field + ":desc"
+	}
+	return field + ":asc"
+}
+
+// taskOrderBy — ORDER BY для postgres и sqlite. Задачи без срока идут последними
+// при ASC и первыми при DESC, как по умолчанию в postgres.
+func taskOrderBy(sort *models.TaskSort) string {
+	field, desc := normalizeTaskSort(sort)
+
+	direction := "ASC"
+	nulls := " NULLS LAST"
+	if desc {
+		direction = "DESC"
+		nulls = " NULLS FIRST"
+	}
+
+	switch field {
+	case "priority":
+		return priorityRankExpr + " " + direction + ", id " + direction
+	case "due_date":
+		return "due_date " + direction + nulls + ", id " + direction
+	default:
+		return "created_at " + direction + ", id " + direction
+	}
+}
+
+func newTaskCursor(task *models.Task, sort *models.TaskSort) *pageCursor {
+	cursor := &pageCursor{Sort: taskSortKey(sort), ID: task.ID}
+
+	field, _ := normalizeTaskSort(sort)
+	switch field {
+	case "priority":
+		cursor.Rank = float64(task.PriorityValue())
+	case "due_date":
+		if task.DueDate != nil {
+			due := *task.DueDate
+			cursor.Time = &due
+		}
+	default:
+		created := task.CreatedAt
+		cursor.Time = &created
+	}
+
+	return cursor
+}
+
+// taskCursorCondition выбирает строки строго после курсора в порядке taskOrderBy
+func taskCursorCondition(sort *models.TaskSort, cursor *pageCursor, argCount int, dialect queryDialect) (string, []interface{}) {
+	field, desc := normalizeTaskSort(sort)
+
+	cmp := ">"
+	if desc {
+		cmp = "<"
+	}
+
+	args := []interface{}{cursor.ID}
+	id := fmt.Sprintf("$%d", argCount+1)
+	after := func(expr string, value interface{}) string {
+		args = append(args, value)
+		param := fmt.Sprintf("$%d", argCount+2)
+		return fmt.Sprintf("(%s %s %s OR (%s = %s AND id %s %s))", expr, cmp, param, expr, param, cmp, id)
+	}
+
+	switch field {
+	case "priority":
+		return after(priorityRankExpr, int(cursor.Rank)), args
+	case "due_date":
+		if cursor.Time == nil {
+			if desc {
+				return fmt.Sprintf("(due_date IS NOT NULL OR id < %s)", id), args
+			}
+			return fmt.Sprintf("(due_date IS NULL AND id > %s)", id), args
+		}
+		condition := after("due_date", dialect.timeArg(*cursor.Time))
+		if desc {
+			return "(due_date IS NOT NULL AND " + condition + ")", args
+		}
+		return "(due_date IS NULL OR " + condition + ")", args
+	default:
+		return after("created_at", dialect.timeArg(*cursor.Time)), args
+	}
+}
+
+// getTaskPage читает limit+1 строк, чтобы понять, есть ли следующая страница
+func getTaskPage(q sqlExecutor, dialect queryDialect, filter *models.TaskFilter, sort *models.TaskSort, page *models.PageRequest) (*models.TaskPage, error) {
+	if page == nil || page.Limit <= 0 {
+		return nil, fmt.Errorf("page limit must be positive")
+	}
+
+	conditions, args, err := taskFilterConditions(filter, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.TaskPage{Tasks: []*models.Task{}}
+	countQuery := "SELECT COUNT(*) FROM tasks" + whereClause(conditions)
+	if err := q.QueryRow(countQuery, args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	if page.Cursor != "" {
+		cursor, err := decodePageCursor(page.Cursor, taskSortKey(sort))
+		if err != nil {
+			return nil, err
+		}
+		condition, cursorArgs := taskCursorCondition(sort, cursor, len(args), dialect)
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
+	args = append(args, page.Limit+1)
+	query := fmt.Sprintf("SELECT %s FROM tasks%s ORDER BY %s LIMIT $%d",
+		taskColumns, whereClause(conditions), taskOrderBy(sort), len(args))
+
+	tasks, err := queryTasks(q, "failed to get tasks", query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) > page.Limit {
+		tasks = tasks[:page.Limit]
+		result.NextCursor = newTaskCursor(tasks[len(tasks)-1], sort).encode()
+	}
+	if tasks != nil {
+		result.Tasks = tasks
+	}
+
+	return result, nil
+}
+
+// searchCursorCondition продолжает выдачу после курсора: rank по убыванию, id по возрастанию.
+// rank и id — имена колонок подзапроса с результатами.
+func searchCursorCondition(cursor *pageCursor, argCount int) (string, []interface{}) {
+	condition := fmt.Sprintf("(rank < $%d OR (rank = $%d AND id > $%d))", argCount+1, argCount+1, argCount+2)
+	return condition, []interface{}{cursor.Rank, cursor.ID}
+}
+
+func newSearchCursor(result *models.SearchResult) *pageCursor {
+	return &pageCursor{Sort: searchCursorSort, Rank: result.Rank, ID: result.Task.ID}
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Как и теги, проекты устроены одинаково в postgres и sqlite.
+// Время передает вызывающий: sqlite ожидает UTC.
+
+const projectColumns = "id, name, color, archived, position, is_inbox, created_at, updated_at"
+
+// подзапрос для задач, создаваемых без проекта
+const inboxProjectQuery = "(SELECT id FROM projects WHERE is_inbox)"
+
+func scanProject(row rowScanner) (*models.Project, error) {
+	project := &models.Project{}
+	err := row.Scan(
+		&project.ID,
+		&project.Name,
+		&project.Color,
+		&project.Archived,
+		&project.Position,
+		&project.Inbox,
+		&project.CreatedAt,
+		&project.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// новый проект добавляется в конец списка
+func createProject(q sqlExecutor, project *models.Project, now time.Time) error {
+	query := `
+		INSERT INTO projects (name, color, archived, position, created_at, updated_at)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position), 0) + 1 FROM projects), $4, $4)
+		RETURNING id, position`
+
+	project.CreatedAt = now
+	project.UpdatedAt = now
+
+	err := q.QueryRow(query, project.Name, project.Color, project.Archived, now).Scan(&project.ID, &project.Position)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return nil
+}
+
+func getProject(q sqlExecutor, id int) (*models.Project, error) {
+	project, err := scanProject(q.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("project with id %d %w", id, ErrProjectNotFound)
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+func getProjects(q sqlExecutor) ([]*models.Project, error) {
+	rows, err := q.Query("SELECT " + projectColumns + " FROM projects ORDER BY position, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []*models.Project
+
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return projects, nil
+}
+
+func updateProject(q sqlExecutor, id int, updates *models.UpdateProjectRequest, now time.Time) error {
+	var setParts []string
+	var args []interface{}
+	argCount := 0
+
+	if updates.Name != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("name = $%d", argCount))
+		args = append(args, *updates.Name)
+	}
+
+	if updates.Color != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("color = $%d", argCount))
+		args = append(args, *updates.Color)
+	}
+
+	if updates.Archived != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("archived = $%d", argCount))
+		args = append(args, *updates.Archived)
+	}
+
+	if len(setParts) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	argCount++
+	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argCount))
+	args = append(args, now)
+
+	argCount++
+	args = append(args, id)
+
+	query := fmt.Sprintf(
+		"UPDATE projects SET %s WHERE id = $%d",
+		strings.Join(setParts, ", "),
+		argCount,
+	)
+
+	return execProject(q, "failed to update project", id, query, args...)
+}
+
+// reorderProjects выставляет position по порядку ids, остальные проекты уходят в конец
+func reorderProjects(q sqlExecutor, ids []int, now time.Time) error {
+	if _, err := q.Exec("UPDATE projects SET position = position + $1", len(ids)+1); err != nil {
+		return fmt.Errorf("failed to reorder projects: %w", err)
+	}
+
+	for i, id := range ids {
+		query := "UPDATE projects SET position = $1, updated_at = $2 WHERE id = $3"
+		if err := execProject(q, "failed to reorder projects", id, query, i+1, now, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteProject переносит задачи проекта в moveTo и удаляет проект
+func deleteProject(q sqlExecutor, id, moveTo int, now time.Time) error {
+	if _, err := getProject(q, moveTo); err != nil {
+		return err
+	}
+
+	_, err := q.Exec("UPDATE tasks SET project_id = $1, updated_at = $2 WHERE project_id = $3", moveTo, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to move project tasks: %w", err)
+	}
+
+	return execProject(q, "failed to delete project", id, "DELETE FROM projects WHERE id = $1", id)
+}
+
+// moveToProject переносит задачу вместе со всеми подзадачами
+func moveToProject(q sqlExecutor, taskID, projectID int, now time.Time) error {
+	if _, err := getProject(q, projectID); err != nil {
+		return err
+	}
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id
+		)
+		UPDATE tasks SET project_id = $2, updated_at = $3
+		WHERE id IN (SELECT id FROM subtree)`
+
+	return execTask(q, "failed to move task to project", taskID, query, taskID, projectID, now)
+}
+
+// projectFilterConditions строит условия для ProjectID и HideArchived
+func projectFilterConditions(filter *models.TaskFilter, argCount int) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ProjectID != nil {
+		conditions = append(conditions, fmt.Sprintf("project_id = $%d", argCount+1))
+		args = append(args, *filter.ProjectID)
+	}
+
+	if filter.HideArchived {
+		conditions = append(conditions, "project_id IN (SELECT id FROM projects WHERE NOT archived)")
+	}
+
+	return conditions, args
+}
+
+func execProject(q sqlExecutor, errMsg string, id int, query string, args ...interface{}) error {
+	result, err := q.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMsg, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("project with id %d %w", id, ErrProjectNotFound)
+	}
+
+	return nil
+}
+
+// nullableID превращает нулевой id в NULL
+func nullableID(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// queryDialect — то, чем SQL для postgres отличается от sqlite
+type queryDialect struct {
+	// textCondition возвращает условие для одного термина без отрицания
+	textCondition func(term models.SearchTerm, argCount int) (string, []interface{})
+	// search строит запросы Search: matches с колонками rank и snippet и countQuery по первым countArgs параметрам
+	search  func(query *models.SearchQuery) (matches string, args []interface{}, countQuery string, countArgs int)
+	timeArg func(t time.Time) time.Time
+}
+
+var postgresQueryDialect = queryDialect{
+	textCondition: func(term models.SearchTerm, argCount int) (string, []interface{}) {
+		tsquery, args := postgresSearchQuery([]models.SearchTerm{term}, argCount)
+		return "search_vector @@ " + tsquery, args
+	},
+	search:  postgresSearch,
+	timeArg: func(t time.Time) time.Time { return t },
+}
+
+var sqliteQueryDialect = queryDialect{
+	textCondition: func(term models.SearchTerm, argCount int) (string, []interface{}) {
+		condition := fmt.Sprintf("id IN (SELECT rowid FROM tasks_fts WHERE tasks_fts MATCH $%d)", argCount+1)
+		return condition, []interface{}{sqliteSearchQuery([]models.SearchTerm{term})}
+	},
+	search: sqliteSearch,
+	// sqlite хранит даты текстом: в UTC текстовое сравнение совпадает с хронологическим
+	timeArg: func(t time.Time) time.Time { return t.UTC() },
+}
+
+// taskFilterConditions строит WHERE для TaskFilter, общий для GetAll и GetPage.
+// Задачи из корзины отсекаются всегда.
+// Параметры нумеруются с $1.
+func taskFilterConditions(filter *models.TaskFilter, dialect queryDialect) ([]string, []interface{}, error) {
+	conditions := []string{liveTaskCondition}
+	var args []interface{}
+	argCount := 0
+
+	if filter == nil {
+		return conditions, args, nil
+	}
+
+	if filter.Status != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argCount))
+		args = append(args, *filter.Status)
+	}
+
+	if filter.Priority != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("priority = $%d", argCount))
+		args = append(args, *filter.Priority)
+	}
+
+	if filter.DateFrom != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("due_date >= $%d", argCount))
+		args = append(args, dialect.timeArg(*filter.DateFrom))
+	}
+
+	if filter.DateTo != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("due_date <= $%d", argCount))
+		args = append(args, dialect.timeArg(*filter.DateTo))
+	}
+
+	if len(filter.Tags) > 0 {
+		condition, tagArgs := tagFilterCondition(filter.Tags, filter.TagMatch, argCount)
+		conditions = append(conditions, condition)
+		args = append(args, tagArgs...)
+		argCount += len(tagArgs)
+	}
+
+	projectConditions, projectArgs := projectFilterConditions(filter, argCount)
+	conditions = append(conditions, projectConditions...)
+	args = append(args, projectArgs...)
+	argCount += len(projectArgs)
+
+	if filter.Query != nil {
+		queryConditions, queryArgs, err := taskQueryConditions(filter.Query, argCount, dialect)
+		if err != nil {
+			return nil, nil, err
+		}
+		conditions = append(conditions, queryConditions...)
+		args = append(args, queryArgs...)
+	}
+
+	return conditions, args, nil
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// taskQueryConditions компилирует условия запроса, параметры нумеруются после argCount
+func taskQueryConditions(query *models.TaskQuery, argCount int, dialect queryDialect) ([]string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", argCount+len(args))
+	}
+
+	for _, c := range query.Conditions {
+		var condition string
+
+		switch c.Field {
+		case models.QueryFieldStatus, models.QueryFieldPriority:
+			if c.Op != models.QueryOpIn || len(c.Values) == 0 {
+				return nil, nil, fmt.Errorf("unsupported query condition %s %s", c.Field, c.Op)
+			}
+			placeholders := make([]string, len(c.Values))
+			for i, value := range c.Values {
+				placeholders[i] = param(value)
+			}
+			condition = fmt.Sprintf("%s IN (%s)", c.Field, strings.Join(placeholders, ", "))
+
+		case models.QueryFieldProject:
+			if c.Op != models.QueryOpIn || len(c.ProjectIDs) == 0 {
+				return nil, nil, fmt.Errorf("unsupported query condition %s %s", c.Field, c.Op)
+			}
+			placeholders := make([]string, len(c.ProjectIDs))
+			for i, id := range c.ProjectIDs {
+				placeholders[i] = param(id)
+			}
+			condition = fmt.Sprintf("project_id IN (%s)", strings.Join(placeholders, ", "))
+
+		case models.QueryFieldTag:
+			if c.Op != models.QueryOpIn || len(c.Values) == 0 {
+				return nil, nil, fmt.Errorf("unsupported query condition %s %s", c.Field, c.Op)
+			}
+			tagCondition, tagArgs := tagFilterCondition(c.Values, models.TagMatchAny, argCount+len(args))
+			condition = tagCondition
+			args = append(args, tagArgs...)
+
+		case models.QueryFieldDue, models.QueryFieldCreated:
+			column := "due_date"
+			if c.Field == models.QueryFieldCreated {
+				column = "created_at"
+			}
+
+			// задачи без даты не проходят сравнения, поэтому после NOT они попадают в результат
+			switch c.Op {
+			case models.QueryOpBefore:
+				condition = fmt.Sprintf("(%s IS NOT NULL AND %s < %s)", column, column, param(dialect.timeArg(c.From)))
+			case models.QueryOpAfter:
+				condition = fmt.Sprintf("(%s IS NOT NULL AND %s >= %s)", column, column, param(dialect.timeArg(c.From)))
+			case models.QueryOpRange:
+				from, to := param(dialect.timeArg(c.From)), param(dialect.timeArg(c.To))
+				condition = fmt.Sprintf("(%s IS NOT NULL AND %s >= %s AND %s < %s)", column, column, from, column, to)
+			case models.QueryOpEmpty:
+				condition = column + " IS NULL"
+			default:
+				return nil, nil, fmt.Errorf("unsupported query condition %s %s", c.Field, c.Op)
+			}
+
+		default:
+			return nil, nil, fmt.Errorf("unsupported query field %q", c.Field)
+		}
+
+		if c.Negated {
+			condition = "NOT (" + condition + ")"
+		}
+		conditions = append(conditions, condition)
+	}
+
+	for _, term := range query.Text {
+		negated := term.Negated
+		term.Negated = false
+
+		condition, termArgs := dialect.textCondition(term, argCount+len(args))
+		args = append(args, termArgs...)
+		if negated {
+			condition = "NOT (" + condition + ")"
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, args, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Напоминания (task_reminders) одинаковы в postgres и sqlite.
+// Время now передает вызывающий: sqlite ожидает UTC.
+
+const reminderColumns = "id, task_id, at, offset_minutes, remind_at, fired_at, created_at"
+
+func scanReminder(row rowScanner) (*models.Reminder, error) {
+	reminder := &models.Reminder{}
+	err := row.Scan(
+		&reminder.ID,
+		&reminder.TaskID,
+		&reminder.At,
+		&reminder.OffsetMinutes,
+		&reminder.RemindAt,
+		&reminder.FiredAt,
+		&reminder.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+func queryReminders(q sqlExecutor, errMsg, query string, args ...interface{}) ([]*models.Reminder, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errMsg, err)
+	}
+	defer rows.Close()
+
+	reminders := []*models.Reminder{}
+	for rows.Next() {
+		reminder, err := scanReminder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// addReminder добавляет напоминание к задаче, которая не в корзине
+func addReminder(q sqlExecutor, dialect queryDialect, reminder *models.Reminder, now time.Time) error {
+	if err := requireLiveTasks(q, []int{reminder.TaskID}); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO task_reminders (task_id, at, offset_minutes, remind_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	reminder.CreatedAt = now
+	err := q.QueryRow(query,
+		reminder.TaskID,
+		nullableTime(dialect, reminder.At),
+		reminder.OffsetMinutes,
+		dialect.timeArg(reminder.RemindAt),
+		now,
+	).Scan(&reminder.ID)
+	if err != nil {
+		return fmt.Errorf("failed to add reminder: %w", err)
+	}
+	return nil
+}
+
+// getReminders возвращает напоминания задачи в порядке срабатывания
+func getReminders(q sqlExecutor, taskID int) ([]*models.Reminder, error) {
+	query := "SELECT " + reminderColumns + " FROM task_reminders WHERE task_id = $1 ORDER BY remind_at, id"
+	return queryReminders(q, "failed to get reminders", query, taskID)
+}
+
+func deleteReminder(q sqlExecutor, id int) error {
+	result, err := q.Exec("DELETE FROM task_reminders WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete reminder: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("reminder with id %d %w", id, ErrReminderNotFound)
+	}
+	return nil
+}
+
+// claimDueReminders одним UPDATE помечает сработавшими напоминания открытых задач,
+// поэтому клиенты общей базы не отправят одно напоминание дважды
+func claimDueReminders(q sqlExecutor, now time.Time) ([]*models.Reminder, error) {
+	query := `
+		UPDATE task_reminders SET fired_at = $1
+		WHERE fired_at IS NULL AND remind_at <= $1
+			AND task_id IN (SELECT id FROM tasks WHERE status = 'pending' AND deleted_at IS NULL)
+		RETURNING ` + reminderColumns
+
+	reminders, err := queryReminders(q, "failed to claim due reminders", query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING не гарантирует порядок
+	sortReminders(reminders)
+	return reminders, nil
+}
+
+// releaseReminder возвращает в ожидание напоминание, которое не удалось доставить
+func releaseReminder(q sqlExecutor, id int) error {
+	result, err := q.Exec("UPDATE task_reminders SET fired_at = NULL WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to release reminder: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("reminder with id %d %w", id, ErrReminderNotFound)
+	}
+	return nil
+}
+
+// nextReminderAt возвращает время ближайшего неотправленного напоминания открытой задачи
+func nextReminderAt(q sqlExecutor) (*time.Time, error) {
+	query := `
+		SELECT r.remind_at FROM task_reminders r JOIN tasks t ON t.id = r.task_id
+		WHERE r.fired_at IS NULL AND t.status = 'pending' AND t.deleted_at IS NULL
+		ORDER BY r.remind_at
+		LIMIT 1`
+
+	var next time.Time
+	err := q.QueryRow(query).Scan(&next)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next reminder: %w", err)
+	}
+	return &next, nil
+}
+
+// rescheduleReminders пересчитывает напоминания со смещением после смены срока задачи.
+// Напоминание, перенесенное в будущее, сработает снова; без срока такие напоминания не срабатывают.
+func rescheduleReminders(q sqlExecutor, dialect queryDialect, taskID int, due *time.Time, now time.Time) error {
+	reminders, err := queryReminders(q, "failed to get reminders",
+		"SELECT "+reminderColumns+" FROM task_reminders WHERE task_id = $1 AND offset_minutes IS NOT NULL", taskID)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		remindAt, firedAt := reminder.RemindAt, reminder.FiredAt
+		if due == nil {
+			if firedAt == nil {
+				firedAt = &now
+			}
+		} else {
+			remindAt = models.ReminderTime(*due, *reminder.OffsetMinutes)
+			if remindAt.After(now) {
+				firedAt = nil
+			}
+		}
+
+		_, err := q.Exec("UPDATE task_reminders SET remind_at = $1, fired_at = $2 WHERE id = $3",
+			dialect.timeArg(remindAt), nullableTime(dialect, firedAt), reminder.ID)
+		if err != nil {
+			return fmt.Errorf("failed to reschedule reminder: %w", err)
+		}
+	}
+	return nil
+}
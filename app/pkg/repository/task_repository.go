@@ -1,242 +1,385 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 	"todo-lits-DMARK/app/pkg/models"
 )
 
+// TaskRepository хранит задачи в postgres или во встроенной sqlite. SQL у них общий,
+// а то, что различается, спрятано в dialect.
 type TaskRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect queryDialect
+	// текущее время для created_at, updated_at и просрочки
+	now func() time.Time
 }
 
 func NewTaskRepository(db *sql.DB) TaskRepositoryInterface {
 	return &TaskRepository{
-		db: db,
+		db:      db,
+		dialect: postgresQueryDialect,
+		now:     time.Now,
 	}
 }
 
-func (r *TaskRepository) Create(task *models.Task) error {
-	query := `
-        INSERT INTO tasks (title, description, status, priority, due_date, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
-        RETURNING id
-    `
-
-	task.CreatedAt = time.Now()
-	task.UpdatedAt = time.Now()
-	task.Status = models.TaskStatusPending
-
-	err := r.db.QueryRow(
-		query,
-		task.Title,
-		task.Description,
-		task.Status,
-		task.Priority,
-		task.DueDate,
-		task.CreatedAt,
-		task.UpdatedAt,
-	).Scan(&task.ID)
-	if err != nil {
-		return fmt.Errorf("failed to create task: %w", err)
-	}
+const taskColumns = "id, parent_id, project_id, title, description, status, priority, due_date, recurrence, created_at, updated_at, deleted_at, version, snooze_count, all_day"
 
-	return nil
+func (r *TaskRepository) Create(ctx context.Context, task *models.Task) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return createTask(tx, r.dialect, task, r.now())
+	})
 }
-func (r *TaskRepository) GetByID(id int) (*models.Task, error) {
-	task := &models.Task{}
 
+func (r *TaskRepository) GetByID(ctx context.Context, id int) (*models.Task, error) {
 	query := `
-       SELECT id, title, description, status, priority, due_date, created_at, updated_at
+       SELECT ` + taskColumns + `
         FROM tasks
-        WHERE id = $1
+        WHERE id = $1 AND deleted_at IS NULL
     `
 
-	row := r.db.QueryRow(query, id)
-	err := row.Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.Priority,
-		&task.DueDate,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+	task, err := scanTask(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("task with id %d not found", id)
+			return nil, fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
 		}
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
+	if err := loadTaskTags(withContext(ctx, r.db), []*models.Task{task}); err != nil {
+		return nil, err
+	}
+
 	return task, nil
 }
-func (r *TaskRepository) GetAll(filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error) {
-	query := `	SELECT id, title, description, status, priority, due_date, created_at, updated_at
-		FROM tasks`
+func (r *TaskRepository) GetAll(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error) {
+	conditions, args, err := taskFilterConditions(filter, r.dialect)
+	if err != nil {
+		return nil, err
+	}
 
-	var tasks []*models.Task
-	var conditions []string
-	var args []interface{}
-	argCount := 0
-	//filtesr
-	if filter != nil {
-		if filter.Status != nil {
-			argCount++
-			conditions = append(conditions, fmt.Sprintf("status = $%d", argCount))
-			args = append(args, *filter.Status)
-		}
+	query := "SELECT " + taskColumns + " FROM tasks" + whereClause(conditions) + " ORDER BY " + taskOrderBy(sort)
 
-		if filter.Priority != nil {
-			argCount++
-			conditions = append(conditions, fmt.Sprintf("priority = $%d", argCount))
-			args = append(args, *filter.Priority)
-		}
-		if filter.DateFrom != nil {
-			argCount++
-			conditions = append(conditions, fmt.Sprintf("due_date >= $%d", argCount))
-			args = append(args, *filter.DateFrom)
-		}
+	return queryTasks(withContext(ctx, r.db), "failed to get tasks", query, args...)
+}
 
-		if filter.DateTo != nil {
-			argCount++
-			conditions = append(conditions, fmt.Sprintf("due_date <= $%d", argCount))
-			args = append(args, *filter.DateTo)
-		}
-	}
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-	if sort != nil && sort.Field != "" {
-		orderBy := sort.Field
-		if sort.Order == "desc" {
-			orderBy += " DESC"
-		} else {
-			orderBy += " ASC"
-		}
+// GetPage возвращает страницу задач с курсором на следующую
+func (r *TaskRepository) GetPage(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort, page *models.PageRequest) (*models.TaskPage, error) {
+	return getTaskPage(withContext(ctx, r.db), r.dialect, filter, sort, page)
+}
+func (r *TaskRepository) Update(ctx context.Context, id int, updates *models.UpdateTaskRequest) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return updateTask(tx, r.dialect, id, updates, r.now())
+	})
+}
+
+// BulkUpdate применяет изменения всех задач и создает задачи create одной транзакцией
+func (r *TaskRepository) BulkUpdate(ctx context.Context, updates []*models.TaskUpdate, create []*models.Task) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return bulkUpdateTasks(tx, r.dialect, updates, create, r.now())
+	})
+}
+
+// Delete переносит задачу с подзадачами в корзину
+func (r *TaskRepository) Delete(ctx context.Context, id int) error {
+	return softDeleteTask(withContext(ctx, r.db), id, r.now())
+}
+
+// DeleteKeepingSubtasks переносит в корзину только саму задачу
+func (r *TaskRepository) DeleteKeepingSubtasks(ctx context.Context, id int) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return deleteKeepingSubtasks(tx, id, r.now())
+	})
+}
 
-		if sort.Field == "priority" {
+// BulkDelete переносит в корзину все задачи ids или, если хоть одной нет, ни одну
+func (r *TaskRepository) BulkDelete(ctx context.Context, ids []int) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return softDeleteTasks(tx, ids, r.now())
+	})
+}
+
+func (r *TaskRepository) Restore(ctx context.Context, id int) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return restoreTask(tx, id)
+	})
+}
+
+func (r *TaskRepository) GetTrash(ctx context.Context) ([]*models.Task, error) {
+	return getTrash(withContext(ctx, r.db))
+}
+
+func (r *TaskRepository) Purge(ctx context.Context, id int) error {
+	return purgeTask(withContext(ctx, r.db), id)
+}
+
+func (r *TaskRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	return purgeDeleted(withContext(ctx, r.db), r.dialect.timeArg(before))
+}
+func (r *TaskRepository) GetSnapshots(ctx context.Context, ids []int) ([]*models.Task, error) {
+	return getTaskSnapshots(withContext(ctx, r.db), ids)
+}
 
-			orderBy = "CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 END"
-			if sort.Order == "desc" {
-				orderBy += " DESC"
-			} else {
-				orderBy += " ASC"
+func (r *TaskRepository) ApplySnapshots(ctx context.Context, tasks []*models.Task) error {
+	now := r.now()
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		for _, task := range tasks {
+			if err := applyTaskSnapshot(tx, r.dialect, task, now); err != nil {
+				return err
 			}
 		}
+		return nil
+	})
+}
 
-		query += " ORDER BY " + orderBy
-	} else {
-		query += " ORDER BY created_at DESC"
-	}
+func (r *TaskRepository) AddRevisions(ctx context.Context, revisions []*models.TaskRevision) error {
+	now := r.now()
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return addRevisions(tx, revisions, now)
+	})
+}
 
-	rows, err := r.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks: %w", err)
-	}
-	defer rows.Close()
+func (r *TaskRepository) GetRevisions(ctx context.Context, taskID int) ([]*models.TaskRevision, error) {
+	return getRevisions(withContext(ctx, r.db), taskID)
+}
 
-	for rows.Next() {
-		task := &models.Task{}
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.DueDate,
-			&task.CreatedAt,
-			&task.UpdatedAt,
+func (r *TaskRepository) GetRevision(ctx context.Context, id int) (*models.TaskRevision, error) {
+	return getRevision(withContext(ctx, r.db), id)
+}
+
+func (r *TaskRepository) Snooze(ctx context.Context, id int, until time.Time) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return snoozeTask(tx, r.dialect, id, until, r.now())
+	})
+}
+
+func (r *TaskRepository) AddReminder(ctx context.Context, reminder *models.Reminder) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return addReminder(tx, r.dialect, reminder, r.now())
+	})
+}
+
+func (r *TaskRepository) GetReminders(ctx context.Context, taskID int) ([]*models.Reminder, error) {
+	return getReminders(withContext(ctx, r.db), taskID)
+}
+
+func (r *TaskRepository) DeleteReminder(ctx context.Context, id int) error {
+	return deleteReminder(withContext(ctx, r.db), id)
+}
+
+func (r *TaskRepository) ClaimDueReminders(ctx context.Context, now time.Time) ([]*models.Reminder, error) {
+	return claimDueReminders(withContext(ctx, r.db), r.dialect.timeArg(now))
+}
+
+func (r *TaskRepository) ReleaseReminder(ctx context.Context, id int) error {
+	return releaseReminder(withContext(ctx, r.db), id)
+}
+
+func (r *TaskRepository) NextReminderAt(ctx context.Context) (*time.Time, error) {
+	return nextReminderAt(withContext(ctx, r.db))
+}
+
+func (r *TaskRepository) GetOverdue(ctx context.Context) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE due_date < $1 AND status = 'pending' AND deleted_at IS NULL
+		ORDER BY due_date ASC`
+
+	return queryTasks(withContext(ctx, r.db), "failed to get overdue tasks", query, r.now())
+}
+func (r *TaskRepository) GetByDateRange(ctx context.Context, from, to time.Time) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE due_date BETWEEN $1 AND $2 AND deleted_at IS NULL
+		ORDER BY due_date ASC`
+
+	return queryTasks(withContext(ctx, r.db), "failed to get tasks by date range", query, r.dialect.timeArg(from), r.dialect.timeArg(to))
+}
+
+func (r *TaskRepository) CountTasks(ctx context.Context, periods *models.StatsPeriods) ([]*models.TaskCountGroup, error) {
+	return countTaskGroups(withContext(ctx, r.db), r.dialect, periods)
+}
+
+// Search ищет по полнотекстовому индексу диалекта, заголовок весит больше описания
+func (r *TaskRepository) Search(ctx context.Context, query *models.SearchQuery) (*models.SearchPage, error) {
+	matches, args, countQuery, countArgs := r.dialect.search(query)
+	return searchPage(withContext(ctx, r.db), query, matches, args, countQuery, countArgs)
+}
+
+// GetSubtree возвращает задачу и всех ее потомков, родители идут раньше детей
+func (r *TaskRepository) GetSubtree(ctx context.Context, id int) ([]*models.Task, error) {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, 0 AS depth FROM tasks WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, s.depth + 1 FROM tasks t JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NULL
 		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
-		}
-		tasks = append(tasks, task)
-	}
+		SELECT ` + prefixColumns("t", taskColumns) + `
+		FROM subtree s JOIN tasks t ON t.id = s.id
+		ORDER BY s.depth, t.created_at`
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows error: %w", err)
+	tasks, err := queryTasks(withContext(ctx, r.db), "failed to get subtree", query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
 	}
 
 	return tasks, nil
 }
-func (r *TaskRepository) Update(id int, updates *models.UpdateTaskRequest) error {
-	var setParts []string
-	var args []interface{}
-	argCount := 0
 
-	if updates.Title != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("title = $%d", argCount))
-		args = append(args, *updates.Title)
-	}
+// SetParent перемещает задачу, nil делает ее корневой
+func (r *TaskRepository) SetParent(ctx context.Context, id int, parentID *int) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return setParent(tx, id, parentID, r.now())
+	})
+}
 
-	if updates.Description != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("description = $%d", argCount))
-		args = append(args, *updates.Description)
-	}
+func (r *TaskRepository) CreateTag(ctx context.Context, tag *models.Tag) error {
+	return createTag(withContext(ctx, r.db), tag, r.now())
+}
 
-	if updates.Status != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("status = $%d", argCount))
-		args = append(args, *updates.Status)
-	}
+// GetTags возвращает все теги по алфавиту вместе с количеством задач
+func (r *TaskRepository) GetTags(ctx context.Context) ([]*models.Tag, error) {
+	return getTags(withContext(ctx, r.db))
+}
 
-	if updates.Priority != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("priority = $%d", argCount))
-		args = append(args, *updates.Priority)
-	}
+func (r *TaskRepository) RenameTag(ctx context.Context, id int, name string) error {
+	return execTag(withContext(ctx, r.db), "failed to rename tag", id, "UPDATE tags SET name = $1 WHERE id = $2", name, id)
+}
 
-	if updates.DueDate != nil {
-		argCount++
-		setParts = append(setParts, fmt.Sprintf("due_date = $%d", argCount))
-		args = append(args, *updates.DueDate)
-	}
+// связи с задачами удаляются через ON DELETE CASCADE
+func (r *TaskRepository) DeleteTag(ctx context.Context, id int) error {
+	return execTag(withContext(ctx, r.db), "failed to delete tag", id, "DELETE FROM tags WHERE id = $1", id)
+}
 
-	if len(setParts) == 0 {
-		return fmt.Errorf("no fields to update")
-	}
+func (r *TaskRepository) CreateProject(ctx context.Context, project *models.Project) error {
+	return createProject(withContext(ctx, r.db), project, r.now())
+}
+
+func (r *TaskRepository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	return getProject(withContext(ctx, r.db), id)
+}
 
-	argCount++
-	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argCount))
-	args = append(args, time.Now())
+// GetProjects возвращает все проекты, включая архивные, в порядке position
+func (r *TaskRepository) GetProjects(ctx context.Context) ([]*models.Project, error) {
+	return getProjects(withContext(ctx, r.db))
+}
 
-	argCount++
-	args = append(args, id)
+func (r *TaskRepository) UpdateProject(ctx context.Context, id int, updates *models.UpdateProjectRequest) error {
+	return updateProject(withContext(ctx, r.db), id, updates, r.now())
+}
 
-	query := fmt.Sprintf(
-		"UPDATE tasks SET %s WHERE id = $%d",
-		strings.Join(setParts, ", "),
-		argCount,
-	)
+func (r *TaskRepository) ReorderProjects(ctx context.Context, ids []int) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return reorderProjects(tx, ids, r.now())
+	})
+}
 
-	result, err := r.db.Exec(query, args...)
-	if err != nil {
-		return fmt.Errorf("failed to update task: %w", err)
-	}
+func (r *TaskRepository) DeleteProject(ctx context.Context, id int, moveTo int) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return deleteProject(tx, id, moveTo, r.now())
+	})
+}
 
-	rowsAffected, err := result.RowsAffected()
+func (r *TaskRepository) MoveToProject(ctx context.Context, taskID int, projectID int) error {
+	return moveToProject(withContext(ctx, r.db), taskID, projectID, r.now())
+}
+
+// BulkMoveToProject переносит задачи ids с подзадачами в проект одной транзакцией
+func (r *TaskRepository) BulkMoveToProject(ctx context.Context, ids []int, projectID int) error {
+	return inTx(ctx, r.db, func(tx sqlExecutor) error {
+		return moveTasksToProject(tx, ids, projectID, r.now())
+	})
+}
+
+func (r *TaskRepository) CreateSavedFilter(ctx context.Context, filter *models.SavedFilter) error {
+	return createSavedFilter(withContext(ctx, r.db), filter, r.now())
+}
+
+func (r *TaskRepository) GetSavedFilter(ctx context.Context, id int) (*models.SavedFilter, error) {
+	return getSavedFilter(withContext(ctx, r.db), id)
+}
+
+func (r *TaskRepository) GetSavedFilters(ctx context.Context) ([]*models.SavedFilter, error) {
+	return getSavedFilters(withContext(ctx, r.db))
+}
+
+func (r *TaskRepository) UpdateSavedFilter(ctx context.Context, id int, updates *models.UpdateSavedFilterRequest) error {
+	return updateSavedFilter(withContext(ctx, r.db), id, updates, r.now())
+}
+
+func (r *TaskRepository) DeleteSavedFilter(ctx context.Context, id int) error {
+	return deleteSavedFilter(withContext(ctx, r.db), id)
+}
+
+// sqlExecutor — запросы к *sql.DB или *sql.Tx, привязанные к контексту через withContext
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// contextExecutor — общее у *sql.DB и *sql.Tx
+type contextExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// withContext выполняет все запросы q с ctx: общим функциям репозиториев
+// не нужно передавать контекст в каждый вызов, а отмена ctx прерывает запрос
+func withContext(ctx context.Context, q contextExecutor) sqlExecutor {
+	return boundExecutor{ctx: ctx, q: q}
+}
+
+type boundExecutor struct {
+	ctx context.Context
+	q   contextExecutor
+}
+
+func (b boundExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return b.q.ExecContext(b.ctx, query, args...)
+}
+
+func (b boundExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return b.q.QueryContext(b.ctx, query, args...)
+}
+
+func (b boundExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	return b.q.QueryRowContext(b.ctx, query, args...)
+}
+
+// inTx выполняет fn в транзакции, отмена ctx откатывает ее
+func inTx(ctx context.Context, db *sql.DB, fn func(tx sqlExecutor) error) error {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("task with id %d not found", id)
+	if err := fn(withContext(ctx, tx)); err != nil {
+		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
-func (r *TaskRepository) Delete(id int) error {
-	query := "DELETE FROM tasks WHERE id = $1"
 
-	result, err := r.db.Exec(query, id)
+func execTask(q sqlExecutor, errMsg string, id int, query string, args ...interface{}) error {
+	result, err := q.Exec(query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
+		return fmt.Errorf("%s: %w", errMsg, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -245,77 +388,43 @@ func (r *TaskRepository) Delete(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("task with id %d not found", id)
+		return fmt.Errorf("task with id %d %w", id, ErrTaskNotFound)
 	}
 
 	return nil
 }
-func (r *TaskRepository) GetOverdue() ([]*models.Task, error) {
-	query := `
-		SELECT id, title, description, status, priority, due_date, created_at, updated_at
-		FROM tasks
-		WHERE due_date < NOW() AND status = 'pending'
-		ORDER BY due_date ASC`
 
-	rows, err := r.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get overdue tasks: %w", err)
+// execVersionedUpdate выполняет UPDATE задачи с условием на версию. Если ни одна строка
+// не изменилась, а задача на месте, значит ее версия уже другая
+func execVersionedUpdate(q sqlExecutor, id int, version *int, query string, args ...interface{}) error {
+	err := execTask(q, "failed to update task", id, query, args...)
+	if version == nil || !errors.Is(err, ErrTaskNotFound) {
+		return err
 	}
-	defer rows.Close()
 
-	var tasks []*models.Task
-
-	for rows.Next() {
-		task := &models.Task{}
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.DueDate,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
+	var current int
+	row := q.QueryRow("SELECT version FROM tasks WHERE id = $1 AND deleted_at IS NULL", id)
+	if scanErr := row.Scan(&current); scanErr != nil {
+		if scanErr == sql.ErrNoRows {
+			return err
 		}
-		tasks = append(tasks, task)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows iteration error: %w", err)
+		return fmt.Errorf("failed to check task version: %w", scanErr)
 	}
-
-	return tasks, nil
+	return fmt.Errorf("task %d has version %d, expected %d: %w", id, current, *version, ErrVersionConflict)
 }
-func (r *TaskRepository) GetByDateRange(from, to time.Time) ([]*models.Task, error) {
-	query := `
-		SELECT id, title, description, status, priority, due_date, created_at, updated_at
-		FROM tasks
-		WHERE due_date BETWEEN $1 AND $2
-		ORDER BY due_date ASC`
 
-	rows, err := r.db.Query(query, from, to)
+// queryTasks читает задачи и догружает их теги отдельным запросом
+func queryTasks(q sqlExecutor, errMsg, query string, args ...interface{}) ([]*models.Task, error) {
+	rows, err := q.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks by date range: %w", err)
+		return nil, fmt.Errorf("%s: %w", errMsg, err)
 	}
 	defer rows.Close()
 
 	var tasks []*models.Task
 
 	for rows.Next() {
-		task := &models.Task{}
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.DueDate,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan task: %w", err)
 		}
@@ -325,6 +434,49 @@ func (r *TaskRepository) GetByDateRange(from, to time.Time) ([]*models.Task, err
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
+	rows.Close()
+
+	if err := loadTaskTags(q, tasks); err != nil {
+		return nil, err
+	}
 
 	return tasks, nil
 }
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// порядок полей совпадает с taskColumns, extra сканирует колонки после них
+func scanTask(row rowScanner, extra ...interface{}) (*models.Task, error) {
+	task := &models.Task{}
+	dest := []interface{}{
+		&task.ID,
+		&task.ParentID,
+		&task.ProjectID,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.Priority,
+		&task.DueDate,
+		&task.Recurrence,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.DeletedAt,
+		&task.Version,
+		&task.SnoozeCount,
+		&task.AllDay,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func prefixColumns(alias, columns string) string {
+	parts := strings.Split(columns, ", ")
+	for i, column := range parts {
+		parts[i] = alias + "." + column
+	}
+	return strings.Join(parts, ", ")
+}
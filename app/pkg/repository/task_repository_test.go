@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/repository/repotest"
+
+	_ "github.com/lib/pq"
+)
+
+// Тест идет против настоящего postgres с примененными миграциями, например:
+// TEST_POSTGRES_DSN="host=localhost port=5432 user=postgres password=postgres dbname=todoapp_test sslmode=disable"
+func TestTaskRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repotest.RunTaskRepositorySuite(t, func(t *testing.T) repository.TaskRepositoryInterface {
+		if _, err := db.Exec("TRUNCATE tasks, tags RESTART IDENTITY CASCADE"); err != nil {
+			t.Fatalf("truncate tasks: %v", err)
+		}
+		if _, err := db.Exec("DELETE FROM projects WHERE NOT is_inbox"); err != nil {
+			t.Fatalf("clear projects: %v", err)
+		}
+		return repository.NewTaskRepository(db)
+	})
+}
+
+// стемминг есть только в postgres, поэтому проверяется отдельно от общего набора
+func TestTaskRepositorySearchStemming(t *testing.T) {
+	ctx := context.Background()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec("TRUNCATE tasks, tags RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatalf("truncate tasks: %v", err)
+	}
+	repo := repository.NewTaskRepository(db)
+
+	ru := &models.Task{Title: "Проверить задачи", Description: "перед релизом", Priority: models.TaskPriorityMedium}
+	en := &models.Task{Title: "Running errands", Priority: models.TaskPriorityMedium}
+	for _, task := range []*models.Task{ru, en} {
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	tests := []struct {
+		word string
+		want int
+	}{
+		{"задача", ru.ID},
+		{"релиз", ru.ID},
+		{"run", en.ID},
+		{"errand", en.ID},
+	}
+
+	for _, tt := range tests {
+		page, err := repo.Search(ctx, &models.SearchQuery{Terms: []models.SearchTerm{{Text: tt.word}}, Limit: 10})
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.word, err)
+		}
+		results := page.Results
+		if len(results) != 1 || results[0].Task.ID != tt.want {
+			t.Errorf("Search(%q): expected task %d, got %+v", tt.word, tt.want, results)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Журнал изменений задач (task_events) одинаков в postgres и sqlite:
+// измененные поля и состояние задачи хранятся в JSON.
+// Время передает вызывающий: sqlite ожидает UTC.
+
+const revisionColumns = "id, task_id, action, actor, changes, snapshot, created_at"
+
+func scanRevision(row rowScanner) (*models.TaskRevision, error) {
+	revision := &models.TaskRevision{}
+	var changes, snapshot []byte
+	err := row.Scan(
+		&revision.ID,
+		&revision.TaskID,
+		&revision.Action,
+		&revision.Actor,
+		&changes,
+		&snapshot,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode revision changes: %w", err)
+	}
+	if err := json.Unmarshal(snapshot, &revision.Task); err != nil {
+		return nil, fmt.Errorf("failed to decode revision snapshot: %w", err)
+	}
+	return revision, nil
+}
+
+func addRevisions(q sqlExecutor, revisions []*models.TaskRevision, now time.Time) error {
+	query := `
+		INSERT INTO task_events (task_id, action, actor, changes, snapshot, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	for _, revision := range revisions {
+		changes, err := json.Marshal(revision.Changes)
+		if err != nil {
+			return fmt.Errorf("failed to encode revision changes: %w", err)
+		}
+		snapshot, err := json.Marshal(revision.Task)
+		if err != nil {
+			return fmt.Errorf("failed to encode revision snapshot: %w", err)
+		}
+
+		revision.CreatedAt = now
+		err = q.QueryRow(query, revision.TaskID, revision.Action, revision.Actor, string(changes), string(snapshot), now).Scan(&revision.ID)
+		if err != nil {
+			return fmt.Errorf("failed to add task revision: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getRevisions возвращает журнал задачи, последние изменения первыми
+func getRevisions(q sqlExecutor, taskID int) ([]*models.TaskRevision, error) {
+	rows, err := q.Query("SELECT "+revisionColumns+" FROM task_events WHERE task_id = $1 ORDER BY id DESC", taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*models.TaskRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func getRevision(q sqlExecutor, id int) (*models.TaskRevision, error) {
+	revision, err := scanRevision(q.QueryRow("SELECT "+revisionColumns+" FROM task_events WHERE id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task revision with id %d %w", id, ErrRevisionNotFound)
+		}
+		return nil, fmt.Errorf("failed to get task revision: %w", err)
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Сохраненные фильтры устроены одинаково в postgres и sqlite.
+// Время передает вызывающий: sqlite ожидает UTC.
+
+const savedFilterColumns = "id, name, query, created_at, updated_at"
+
+func scanSavedFilter(row rowScanner) (*models.SavedFilter, error) {
+	filter := &models.SavedFilter{}
+	err := row.Scan(
+		&filter.ID,
+		&filter.Name,
+		&filter.Query,
+		&filter.CreatedAt,
+		&filter.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func createSavedFilter(q sqlExecutor, filter *models.SavedFilter, now time.Time) error {
+	query := `
+		INSERT INTO saved_filters (name, query, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		RETURNING id`
+
+	filter.CreatedAt = now
+	filter.UpdatedAt = now
+
+	if err := q.QueryRow(query, filter.Name, filter.Query, now).Scan(&filter.ID); err != nil {
+		return fmt.Errorf("failed to create saved filter: %w", err)
+	}
+
+	return nil
+}
+
+func getSavedFilter(q sqlExecutor, id int) (*models.SavedFilter, error) {
+	filter, err := scanSavedFilter(q.QueryRow("SELECT "+savedFilterColumns+" FROM saved_filters WHERE id = $1", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("saved filter with id %d %w", id, ErrSavedFilterNotFound)
+		}
+		return nil, fmt.Errorf("failed to get saved filter: %w", err)
+	}
+
+	return filter, nil
+}
+
+// getSavedFilters возвращает фильтры в порядке создания
+func getSavedFilters(q sqlExecutor) ([]*models.SavedFilter, error) {
+	rows, err := q.Query("SELECT " + savedFilterColumns + " FROM saved_filters ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved filters: %w", err)
+	}
+	defer rows.Close()
+
+	filters := []*models.SavedFilter{}
+
+	for rows.Next() {
+		filter, err := scanSavedFilter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan saved filter: %w", err)
+		}
+		filters = append(filters, filter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return filters, nil
+}
+
+func updateSavedFilter(q sqlExecutor, id int, updates *models.UpdateSavedFilterRequest, now time.Time) error {
+	var setParts []string
+	var args []interface{}
+	argCount := 0
+
+	if updates.Name != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("name = $%d", argCount))
+		args = append(args, *updates.Name)
+	}
+
+	if updates.Query != nil {
+		argCount++
+		setParts = append(setParts, fmt.Sprintf("query = $%d", argCount))
+		args = append(args, *updates.Query)
+	}
+
+	if len(setParts) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	argCount++
+	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argCount))
+	args = append(args, now)
+
+	argCount++
+	args = append(args, id)
+
+	query := fmt.Sprintf(
+		"UPDATE saved_filters SET %s WHERE id = $%d",
+		strings.Join(setParts, ", "),
+		argCount,
+	)
+
+	return execSavedFilter(q, "failed to update saved filter", id, query, args...)
+}
+
+func deleteSavedFilter(q sqlExecutor, id int) error {
+	return execSavedFilter(q, "failed to delete saved filter", id, "DELETE FROM saved_filters WHERE id = $1", id)
+}
+
+func execSavedFilter(q sqlExecutor, errMsg string, id int, query string, args ...interface{}) error {
+	result, err := q.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMsg, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("saved filter with id %d %w", id, ErrSavedFilterNotFound)
+	}
+
+	return nil
+}
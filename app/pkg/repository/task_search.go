@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Конфигурация russian отдает кириллицу стеммеру russian_stem, а латиницу english_stem,
+// поэтому одна колонка search_vector покрывает задачи на обоих языках.
+const searchConfig = "russian"
+
+const searchHeadlineOptions = `StartSel=` + models.SnippetMatchStart + `, StopSel=` + models.SnippetMatchEnd +
+	`, MaxWords=20, MinWords=5, MaxFragments=2, FragmentDelimiter=" … "`
+
+// postgresSearchQuery собирает tsquery из терминов, текст терминов передается параметрами
+// с номерами после argCount
+func postgresSearchQuery(terms []models.SearchTerm, argCount int) (string, []interface{}) {
+	parts := make([]string, len(terms))
+	args := make([]interface{}, len(terms))
+
+	for i, term := range terms {
+		param := fmt.Sprintf("$%d", argCount+i+1)
+		args[i] = term.Text
+
+		var part string
+		switch {
+		case term.Phrase:
+			part = fmt.Sprintf("phraseto_tsquery('%s', %s)", searchConfig, param)
+		case term.Prefix:
+			part = fmt.Sprintf("to_tsquery('%s', %s::text || ':*')", searchConfig, param)
+		default:
+			part = fmt.Sprintf("plainto_tsquery('%s', %s)", searchConfig, param)
+		}
+
+		if term.Negated {
+			part = "!!" + part
+		}
+		parts[i] = part
+	}
+
+	return strings.Join(parts, " && "), args
+}
+
+// postgresSearch ищет по колонке search_vector
+func postgresSearch(query *models.SearchQuery) (string, []interface{}, string, int) {
+	tsquery, args := postgresSearchQuery(query.Terms, 0)
+	countArgs := len(args)
+	args = append(args, searchHeadlineOptions)
+
+	// rank приводится к float8: курсор сравнивает его на равенство, а real теряет точность в Go
+	matches := fmt.Sprintf(`
+		WITH q AS (SELECT %s AS query)
+		SELECT %s,
+			ts_rank_cd(t.search_vector, q.query)::float8 AS rank,
+			ts_headline('%s', t.title || ' ' || COALESCE(t.description, ''), q.query, $%d) AS snippet
+		FROM tasks t, q
+		WHERE t.search_vector @@ q.query AND t.deleted_at IS NULL`, tsquery, prefixColumns("t", taskColumns), searchConfig, len(args))
+
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM tasks WHERE search_vector @@ (%s) AND deleted_at IS NULL", tsquery)
+
+	return matches, args, countQuery, countArgs
+}
+
+// sqliteSearch идет по fts5 индексу tasks_fts. Стемминг только английский (porter),
+// русские слова находятся целиком или по префиксу.
+func sqliteSearch(query *models.SearchQuery) (string, []interface{}, string, int) {
+	matches := `
+		SELECT ` + prefixColumns("t", taskColumns) + `,
+			-bm25(tasks_fts, 10.0, 1.0) AS rank,
+			snippet(tasks_fts, -1, $2, $3, ' … ', 12) AS snippet
+		FROM tasks_fts JOIN tasks t ON t.id = tasks_fts.rowid
+		WHERE tasks_fts MATCH $1 AND t.deleted_at IS NULL`
+
+	countQuery := `
+		SELECT COUNT(*) FROM tasks_fts JOIN tasks t ON t.id = tasks_fts.rowid
+		WHERE tasks_fts MATCH $1 AND t.deleted_at IS NULL`
+	args := []interface{}{sqliteSearchQuery(query.Terms), models.SnippetMatchStart, models.SnippetMatchEnd}
+
+	return matches, args, countQuery, 1
+}
+
+// sqliteSearchQuery переводит термины в синтаксис MATCH для fts5, каждый термин берется в кавычки
+func sqliteSearchQuery(terms []models.SearchTerm) string {
+	var positive, negative []string
+
+	for _, term := range terms {
+		part := `"` + strings.ReplaceAll(term.Text, `"`, `""`) + `"`
+		if term.Prefix {
+			part += "*"
+		}
+
+		if term.Negated {
+			negative = append(negative, part)
+		} else {
+			positive = append(positive, part)
+		}
+	}
+
+	match := "(" + strings.Join(positive, " AND ") + ")"
+	for _, part := range negative {
+		match += " NOT " + part
+	}
+	return match
+}
+
+// searchPage выбирает страницу из matches — подзапроса с колонками taskColumns, rank и snippet.
+// args — параметры matches, countQuery использует только первые countArgs из них.
+func searchPage(q sqlExecutor, query *models.SearchQuery, matches string, args []interface{}, countQuery string, countArgs int) (*models.SearchPage, error) {
+	page := &models.SearchPage{Results: []*models.SearchResult{}}
+	if err := q.QueryRow(countQuery, args[:countArgs]...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	where := ""
+	if query.Cursor != "" {
+		cursor, err := decodePageCursor(query.Cursor, searchCursorSort)
+		if err != nil {
+			return nil, err
+		}
+		condition, cursorArgs := searchCursorCondition(cursor, len(args))
+		where = " WHERE " + condition
+		args = append(args, cursorArgs...)
+	}
+
+	args = append(args, query.Limit+1)
+	sqlQuery := fmt.Sprintf("SELECT * FROM (%s) m%s ORDER BY rank DESC, id LIMIT $%d", matches, where, len(args))
+
+	results, err := querySearchResults(q, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+		page.NextCursor = newSearchCursor(results[len(results)-1]).encode()
+	}
+	page.Results = results
+
+	return page, nil
+}
+
+// querySearchResults ожидает после taskColumns колонки rank и snippet
+func querySearchResults(q sqlExecutor, query string, args ...interface{}) ([]*models.SearchResult, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tasks: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*models.SearchResult{}
+	var tasks []*models.Task
+
+	for rows.Next() {
+		result := &models.SearchResult{}
+		task, err := scanTask(rows, &result.Rank, &result.Snippet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		result.Task = task
+		results = append(results, result)
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	rows.Close()
+
+	if err := loadTaskTags(q, tasks); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
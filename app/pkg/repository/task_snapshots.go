@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Снимки задач для отмены и повтора операций: задача читается вместе с deleted_at
+// и записывается обратно целиком, включая теги и перенос в корзину или из нее.
+// Время now передает вызывающий: sqlite ожидает UTC.
+
+// getTaskSnapshots возвращает задачи по id, включая задачи из корзины; отсутствующие id пропускаются
+func getTaskSnapshots(q sqlExecutor, ids []int) ([]*models.Task, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+
+	query := "SELECT " + taskColumns + " FROM tasks WHERE id IN (" + strings.Join(placeholders, ", ") + ") ORDER BY id"
+	return queryTasks(q, "failed to get task snapshots", query, args...)
+}
+
+// applyTaskSnapshot переписывает изменяемые поля задачи значениями снимка, created_at не меняется.
+// task.Version — версия, от которой построен снимок: если задачу успели изменить, вернется ErrVersionConflict.
+func applyTaskSnapshot(q sqlExecutor, dialect queryDialect, task *models.Task, now time.Time) error {
+	query := `
+		UPDATE tasks
+		SET parent_id = $1, project_id = $2, title = $3, description = $4, status = $5, priority = $6,
+			due_date = $7, all_day = $8, recurrence = $9, deleted_at = $10, snooze_count = $11, updated_at = $12
+		WHERE id = $13 AND version = $14`
+
+	err := execTask(q, "failed to apply task snapshot", task.ID, query,
+		task.ParentID,
+		task.ProjectID,
+		task.Title,
+		task.Description,
+		task.Status,
+		task.Priority,
+		nullableTime(dialect, task.DueDate),
+		task.AllDay,
+		task.Recurrence,
+		nullableTime(dialect, task.DeletedAt),
+		task.SnoozeCount,
+		now,
+		task.ID,
+		task.Version,
+	)
+	if errors.Is(err, ErrTaskNotFound) {
+		return snapshotConflict(q, task, err)
+	}
+	if err != nil {
+		return err
+	}
+	if err := rescheduleReminders(q, dialect, task.ID, task.DueDate, now); err != nil {
+		return err
+	}
+
+	return replaceTaskTags(q, task.ID, task.Tags, now)
+}
+
+// snapshotConflict отличает устаревшую версию от отсутствующей задачи; задача из корзины тоже считается
+func snapshotConflict(q sqlExecutor, task *models.Task, err error) error {
+	var current int
+	if scanErr := q.QueryRow("SELECT version FROM tasks WHERE id = $1", task.ID).Scan(&current); scanErr != nil {
+		if scanErr == sql.ErrNoRows {
+			return err
+		}
+		return fmt.Errorf("failed to check task version: %w", scanErr)
+	}
+	return fmt.Errorf("task %d has version %d, expected %d: %w", task.ID, current, task.Version, ErrVersionConflict)
+}
+
+func nullableTime(dialect queryDialect, t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return dialect.timeArg(*t)
+}
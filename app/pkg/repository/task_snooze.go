@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+)
+
+// snoozeTask переносит срок открытой задачи на момент until и увеличивает счетчик откладываний.
+// Напоминания со смещением следуют за сроком, напоминания на конкретное время, которые
+// уже сработали или сработали бы раньше until, переносятся на until.
+func snoozeTask(q sqlExecutor, dialect queryDialect, id int, until, now time.Time) error {
+	query := `
+		UPDATE tasks SET due_date = $1, all_day = FALSE, snooze_count = snooze_count + 1, updated_at = $2
+		WHERE id = $3 AND status = 'pending' AND deleted_at IS NULL`
+
+	if err := execTask(q, "failed to snooze task", id, query, dialect.timeArg(until), now, id); err != nil {
+		return err
+	}
+	if err := rescheduleReminders(q, dialect, id, &until, now); err != nil {
+		return err
+	}
+
+	return deferReminders(q, dialect, id, until)
+}
+
+func deferReminders(q sqlExecutor, dialect queryDialect, taskID int, until time.Time) error {
+	query := `
+		UPDATE task_reminders SET at = $1, remind_at = $1, fired_at = NULL
+		WHERE task_id = $2 AND at IS NOT NULL AND remind_at < $1`
+
+	if _, err := q.Exec(query, dialect.timeArg(until), taskID); err != nil {
+		return fmt.Errorf("failed to defer reminders: %w", err)
+	}
+	return nil
+}
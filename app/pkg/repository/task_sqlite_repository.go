@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+// NewSQLiteTaskRepository хранит задачи во встроенной sqlite базе.
+// Все даты пишутся в UTC, чтобы текстовое сравнение в sqlite совпадало с хронологическим.
+func NewSQLiteTaskRepository(db *sql.DB) TaskRepositoryInterface {
+	return &TaskRepository{
+		db:      db,
+		dialect: sqliteQueryDialect,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
@@ -0,0 +1,64 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/app/pkg/database"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/repository/repotest"
+)
+
+func TestSQLiteTaskRepository(t *testing.T) {
+	repotest.RunTaskRepositorySuite(t, func(t *testing.T) repository.TaskRepositoryInterface {
+		db := openSQLite(t)
+		return repository.NewSQLiteTaskRepository(db.DB)
+	})
+}
+
+// отмененный контекст прерывает и чтение, и транзакцию
+func TestSQLiteTaskRepositoryCancelledContext(t *testing.T) {
+	repo := repository.NewSQLiteTaskRepository(openSQLite(t).DB)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.GetAll(ctx, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetAll: expected context.Canceled, got %v", err)
+	}
+	task := &models.Task{Title: "task", Status: models.TaskStatusPending, Priority: models.TaskPriorityLow}
+	if err := repo.Create(ctx, task); !errors.Is(err, context.Canceled) {
+		t.Errorf("Create: expected context.Canceled, got %v", err)
+	}
+
+	tasks, err := repo.GetAll(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("cancelled create must not insert a task, got %d tasks", len(tasks))
+	}
+}
+
+// отдельный файл базы на каждый подтест, демо-данные из миграции удаляем
+func openSQLite(t *testing.T) *database.Database {
+	t.Helper()
+
+	cfg := config.New()
+	cfg.Storage.Driver = config.DriverSQLite
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "todo.db")
+
+	db, err := database.New(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.DB.Exec("DELETE FROM tasks"); err != nil {
+		t.Fatalf("clear sample data: %v", err)
+	}
+	return db
+}
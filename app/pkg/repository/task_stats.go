@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// countTaskGroups считает задачи одним запросом с группировкой, строк в ответе
+// не больше проектов × статусов × приоритетов независимо от размера таблицы
+func countTaskGroups(q sqlExecutor, dialect queryDialect, periods *models.StatsPeriods) ([]*models.TaskCountGroup, error) {
+	query := `
+		SELECT project_id, status, priority, COUNT(*),
+			SUM(CASE WHEN status = 'pending' AND due_date < $1 THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'pending' AND due_date BETWEEN $2 AND $3 THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'pending' AND due_date BETWEEN $4 AND $5 THEN 1 ELSE 0 END)
+		FROM tasks
+		WHERE deleted_at IS NULL
+		GROUP BY project_id, status, priority
+		ORDER BY project_id, status, priority`
+
+	rows, err := q.Query(query,
+		dialect.timeArg(periods.Now),
+		dialect.timeArg(periods.TodayFrom), dialect.timeArg(periods.TodayTo),
+		dialect.timeArg(periods.WeekFrom), dialect.timeArg(periods.WeekTo))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*models.TaskCountGroup
+	for rows.Next() {
+		group := &models.TaskCountGroup{}
+		if err := rows.Scan(&group.ProjectID, &group.Status, &group.Priority, &group.Count,
+			&group.Overdue, &group.DueToday, &group.DueThisWeek); err != nil {
+			return nil, fmt.Errorf("failed to scan task counts: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	return groups, nil
+}
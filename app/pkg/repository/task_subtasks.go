@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// setParent перемещает задачу к parentID и, если у родителя другой проект, переносит туда поддерево
+func setParent(q sqlExecutor, id int, parentID *int, now time.Time) error {
+	query := "UPDATE tasks SET parent_id = $1, updated_at = $2 WHERE id = $3 AND deleted_at IS NULL"
+	if err := execTask(q, "failed to move task", id, query, parentID, now, id); err != nil {
+		return err
+	}
+	if parentID == nil {
+		return nil
+	}
+
+	var parentProject, taskProject int
+	row := q.QueryRow("SELECT p.project_id, t.project_id FROM tasks p, tasks t WHERE p.id = $1 AND t.id = $2", *parentID, id)
+	if err := row.Scan(&parentProject, &taskProject); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("task with id %d %w", *parentID, ErrTaskNotFound)
+		}
+		return fmt.Errorf("failed to get parent project: %w", err)
+	}
+	if parentProject == taskProject {
+		return nil
+	}
+
+	return moveToProject(q, id, parentProject, now)
+}
+
+// deleteKeepingSubtasks поднимает прямых потомков id на его уровень и переносит id в корзину
+func deleteKeepingSubtasks(q sqlExecutor, id int, now time.Time) error {
+	query := `
+		UPDATE tasks SET parent_id = (SELECT parent_id FROM tasks WHERE id = $1), updated_at = $2
+		WHERE parent_id = $1 AND deleted_at IS NULL`
+	if _, err := q.Exec(query, id, now); err != nil {
+		return fmt.Errorf("failed to move subtasks: %w", err)
+	}
+
+	return softDeleteTask(q, id, now)
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Схема tags и task_tags одинакова в postgres и sqlite, поэтому запросы общие.
+// Время передает вызывающий: sqlite ожидает UTC.
+
+// replaceTaskTags заменяет теги задачи, недостающие теги создаются
+func replaceTaskTags(q sqlExecutor, taskID int, names []string, now time.Time) error {
+	if _, err := q.Exec("DELETE FROM task_tags WHERE task_id = $1", taskID); err != nil {
+		return fmt.Errorf("failed to clear task tags: %w", err)
+	}
+
+	for _, name := range names {
+		_, err := q.Exec("INSERT INTO tags (name, created_at) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING", name, now)
+		if err != nil {
+			return fmt.Errorf("failed to create tag %q: %w", name, err)
+		}
+
+		_, err = q.Exec(`
+			INSERT INTO task_tags (task_id, tag_id)
+			SELECT CAST($1 AS INTEGER), id FROM tags WHERE name = $2
+			ON CONFLICT DO NOTHING`, taskID, name)
+		if err != nil {
+			return fmt.Errorf("failed to tag task: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadTaskTags заполняет Tags у переданных задач, теги отсортированы по имени
+func loadTaskTags(q sqlExecutor, tasks []*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Task, len(tasks))
+	placeholders := make([]string, len(tasks))
+	args := make([]interface{}, len(tasks))
+	for i, task := range tasks {
+		task.Tags = []string{}
+		byID[task.ID] = task
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = task.ID
+	}
+
+	rows, err := q.Query(`
+		SELECT tt.task_id, g.name
+		FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+		WHERE tt.task_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY g.name`, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get task tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int
+		var name string
+		if err := rows.Scan(&taskID, &name); err != nil {
+			return fmt.Errorf("failed to scan task tag: %w", err)
+		}
+		byID[taskID].Tags = append(byID[taskID].Tags, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// tagFilterCondition строит условие для TaskFilter.Tags, параметры нумеруются после argCount
+func tagFilterCondition(tags []string, match models.TagMatchMode, argCount int) (string, []interface{}) {
+	placeholders := make([]string, len(tags))
+	args := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		placeholders[i] = fmt.Sprintf("$%d", argCount+i+1)
+		args[i] = tag
+	}
+
+	condition := `id IN (
+		SELECT tt.task_id FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+		WHERE g.name IN (` + strings.Join(placeholders, ", ") + `)`
+	if match == models.TagMatchAll {
+		condition += fmt.Sprintf(" GROUP BY tt.task_id HAVING COUNT(*) = %d", len(tags))
+	}
+
+	return condition + ")", args
+}
+
+func createTag(q sqlExecutor, tag *models.Tag, now time.Time) error {
+	tag.CreatedAt = now
+
+	err := q.QueryRow("INSERT INTO tags (name, created_at) VALUES ($1, $2) RETURNING id", tag.Name, now).Scan(&tag.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return nil
+}
+
+func getTags(q sqlExecutor) ([]*models.Tag, error) {
+	rows, err := q.Query(`
+		SELECT g.id, g.name, g.created_at, COUNT(t.id)
+		FROM tags g
+		LEFT JOIN task_tags tt ON tt.tag_id = g.id
+		LEFT JOIN tasks t ON t.id = tt.task_id AND t.deleted_at IS NULL
+		GROUP BY g.id, g.name, g.created_at
+		ORDER BY g.name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []*models.Tag
+
+	for rows.Next() {
+		tag := &models.Tag{}
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.TaskCount); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tags, nil
+}
+
+func execTag(q sqlExecutor, errMsg string, id int, query string, args ...interface{}) error {
+	result, err := q.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMsg, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag with id %d %w", id, ErrTagNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Корзина: удаленная задача получает deleted_at и пропадает из всех чтений.
+// Поддерево удаляется одной меткой времени, по ней восстановление отличает
+// подзадачи, удаленные вместе с задачей, от удаленных раньше отдельно.
+// Время передает вызывающий: sqlite ожидает UTC.
+
+// liveTaskCondition отсекает задачи в корзине
+const liveTaskCondition = "deleted_at IS NULL"
+
+// softDeleteTask переносит в корзину задачу и ее подзадачи, которые еще не там
+func softDeleteTask(q sqlExecutor, id int, now time.Time) error {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM tasks WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+		)
+		UPDATE tasks SET deleted_at = $2
+		WHERE id IN (SELECT id FROM subtree)`
+
+	return execTask(q, "failed to delete task", id, query, id, now)
+}
+
+// restoreTask возвращает задачу из корзины вместе с подзадачами, удаленными в тот же момент.
+// Если родитель остался в корзине, задача становится корневой.
+func restoreTask(q sqlExecutor, id int) error {
+	_, err := q.Exec(`
+		UPDATE tasks SET parent_id = NULL
+		WHERE id = $1 AND parent_id IN (SELECT id FROM tasks WHERE deleted_at IS NOT NULL)`, id)
+	if err != nil {
+		return fmt.Errorf("failed to detach restored task: %w", err)
+	}
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id, deleted_at FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL
+			UNION ALL
+			SELECT t.id, t.deleted_at FROM tasks t JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at = s.deleted_at
+		)
+		UPDATE tasks SET deleted_at = NULL
+		WHERE id IN (SELECT id FROM subtree)`
+
+	return execTask(q, "failed to restore task", id, query, id)
+}
+
+// getTrash возвращает задачи, удаленные самостоятельно, а не вместе с родителем,
+// последние удаленные первыми
+func getTrash(q sqlExecutor) ([]*models.Task, error) {
+	query := "SELECT " + prefixColumns("t", taskColumns) + `
+		FROM tasks t
+		WHERE t.deleted_at IS NOT NULL AND NOT EXISTS (
+			SELECT 1 FROM tasks p WHERE p.id = t.parent_id AND p.deleted_at = t.deleted_at
+		)
+		ORDER BY t.deleted_at DESC, t.id DESC`
+
+	return queryTasks(q, "failed to get trash", query)
+}
+
+// purgeTask удаляет задачу из корзины навсегда, подзадачи удаляются через ON DELETE CASCADE
+func purgeTask(q sqlExecutor, id int) error {
+	return execTask(q, "failed to purge task", id, "DELETE FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL", id)
+}
+
+// purgeDeleted удаляет навсегда задачи, попавшие в корзину раньше before
+func purgeDeleted(q sqlExecutor, before time.Time) (int, error) {
+	result, err := q.Exec("DELETE FROM tasks WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,515 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/dates"
+	"todo-lits-DMARK/app/pkg/models"
+
+	"github.com/teambition/rrule-go"
+)
+
+var quickAddPriorities = map[string]models.TaskPriority{
+	"high": models.TaskPriorityHigh, "h": models.TaskPriorityHigh, "1": models.TaskPriorityHigh,
+	"высокий": models.TaskPriorityHigh, "важно": models.TaskPriorityHigh,
+	"medium": models.TaskPriorityMedium, "m": models.TaskPriorityMedium, "2": models.TaskPriorityMedium,
+	"средний": models.TaskPriorityMedium,
+	"low":     models.TaskPriorityLow, "l": models.TaskPriorityLow, "3": models.TaskPriorityLow,
+	"низкий": models.TaskPriorityLow,
+}
+
+// дни недели в именительном и винительном падеже: "в среду", "каждую пятницу"
+var quickAddWeekdays = map[string]time.Weekday{
+	"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday, "thursday": time.Thursday,
+	"friday": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+	"понедельник": time.Monday, "вторник": time.Tuesday, "среда": time.Wednesday, "среду": time.Wednesday,
+	"четверг": time.Thursday, "пятница": time.Friday, "пятницу": time.Friday,
+	"суббота": time.Saturday, "субботу": time.Saturday, "воскресенье": time.Sunday,
+}
+
+var rruleWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// единицы для "in 2 hours", "через 3 дня" и "every 2 weeks", "каждые 2 недели"
+type quickAddUnit int
+
+const (
+	unitMinute quickAddUnit = iota
+	unitHour
+	unitDay
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+var quickAddUnits = map[string]quickAddUnit{
+	"minute": unitMinute, "minutes": unitMinute, "min": unitMinute, "mins": unitMinute,
+	"минуту": unitMinute, "минуты": unitMinute, "минут": unitMinute,
+	"hour": unitHour, "hours": unitHour, "час": unitHour, "часа": unitHour, "часов": unitHour,
+	"day": unitDay, "days": unitDay, "день": unitDay, "дня": unitDay, "дней": unitDay,
+	"week": unitWeek, "weeks": unitWeek, "неделю": unitWeek, "недели": unitWeek, "недель": unitWeek,
+	"month": unitMonth, "months": unitMonth, "месяц": unitMonth, "месяца": unitMonth, "месяцев": unitMonth,
+	"year": unitYear, "years": unitYear, "год": unitYear, "года": unitYear, "лет": unitYear,
+}
+
+var unitFrequencies = map[quickAddUnit]string{
+	unitDay:   "DAILY",
+	unitWeek:  "WEEKLY",
+	unitMonth: "MONTHLY",
+	unitYear:  "YEARLY",
+}
+
+var quickAddFrequencies = map[string]string{
+	"daily": "FREQ=DAILY", "weekly": "FREQ=WEEKLY", "monthly": "FREQ=MONTHLY", "yearly": "FREQ=YEARLY",
+	"ежедневно": "FREQ=DAILY", "еженедельно": "FREQ=WEEKLY", "ежемесячно": "FREQ=MONTHLY", "ежегодно": "FREQ=YEARLY",
+}
+
+const weekdaysRule = "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"
+
+var (
+	clock24Pattern    = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	clock12Pattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)$`)
+	isoDatePattern    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	dottedDatePattern = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{4}))?$`)
+)
+
+// ParseQuickAdd разбирает строку быстрого добавления в запрос на создание задачи, ничего не сохраняя
+func (s *taskService) ParseQuickAdd(ctx context.Context, input string) (*models.CreateTaskRequest, error) {
+	return parseQuickAdd(input, s.now())
+}
+
+// parseQuickAdd разбирает строку на английском или русском, например
+// "Call Bob tomorrow 3pm !high #work every monday" или "Позвонить в пятницу в 15:00 !высокий #работа".
+// Распознанные слова убираются, остальные становятся названием задачи. Каждый вид
+// значения берется из первого совпадения, повторы остаются в названии.
+// День без времени — срок на весь день, время без дня — ближайшее такое время,
+// день недели — ближайший такой день, "next" — не раньше завтрашнего.
+func parseQuickAdd(input string, now time.Time) (*models.CreateTaskRequest, error) {
+	p := newQuickAddParser(input, now)
+	for i := 0; i < len(p.words); {
+		n := p.match(i)
+		if n == 0 {
+			p.title = append(p.title, p.words[i])
+			n = 1
+		}
+		i += n
+	}
+
+	title := strings.TrimRight(strings.Join(p.title, " "), ",;")
+	if title == "" {
+		return nil, fmt.Errorf("%w: task title is empty", ErrInvalidQuickAdd)
+	}
+
+	recurrence, err := normalizeRecurrence(p.recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	priority := p.priority
+	if priority == "" {
+		priority = models.TaskPriorityMedium
+	}
+
+	dueDate, err := p.dueDate(recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreateTaskRequest{
+		Title:      title,
+		Priority:   priority,
+		DueDate:    dueDate,
+		AllDay:     dueDate != nil && p.exact == nil && p.clock == nil,
+		Tags:       normalizeTags(p.tags),
+		Recurrence: recurrence,
+	}, nil
+}
+
+type quickAddParser struct {
+	now   time.Time
+	words []string
+	lower []string // в нижнем регистре без запятых и точек в конце
+
+	title      []string
+	tags       []string
+	priority   models.TaskPriority
+	recurrence string
+
+	// срок собирается из дня и времени после разбора всей строки
+	day         *time.Time // полночь выбранного дня
+	weekday     *time.Weekday
+	weekdayNext bool
+	exact       *time.Time // "через 2 часа" задает момент целиком
+	clock       *time.Duration
+}
+
+func newQuickAddParser(input string, now time.Time) *quickAddParser {
+	words := strings.Fields(input)
+	lower := make([]string, len(words))
+	for i, word := range words {
+		lower[i] = strings.ToLower(strings.TrimRight(word, ",;."))
+	}
+	return &quickAddParser{now: now, words: words, lower: lower}
+}
+
+// word возвращает слово i в нижнем регистре или пустую строку за концом строки
+func (p *quickAddParser) word(i int) string {
+	if i < len(p.lower) {
+		return p.lower[i]
+	}
+	return ""
+}
+
+// match пробует распознать значение, начинающееся со слова i, и возвращает число занятых слов
+func (p *quickAddParser) match(i int) int {
+	matchers := []func(int) int{p.matchTag, p.matchPriority, p.matchRecurrence, p.matchRelative, p.matchDay, p.matchClock}
+	for _, matcher := range matchers {
+		if n := matcher(i); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func (p *quickAddParser) matchTag(i int) int {
+	word := strings.TrimRight(p.words[i], ",;.")
+	if len(word) < 2 || word[0] != '#' {
+		return 0
+	}
+	p.tags = append(p.tags, word[1:])
+	return 1
+}
+
+func (p *quickAddParser) matchPriority(i int) int {
+	word := p.word(i)
+	priority, ok := quickAddPriorities[strings.TrimPrefix(word, "!")]
+	if !ok || !strings.HasPrefix(word, "!") || p.priority != "" {
+		return 0
+	}
+	p.priority = priority
+	return 1
+}
+
+func (p *quickAddParser) matchRecurrence(i int) int {
+	if p.recurrence != "" {
+		return 0
+	}
+
+	word := p.word(i)
+	if rule, ok := quickAddFrequencies[word]; ok {
+		p.recurrence = rule
+		return 1
+	}
+
+	switch word {
+	case "every", "каждый", "каждую", "каждое", "каждые":
+	case "по":
+		if p.word(i+1) == "будням" {
+			p.recurrence = weekdaysRule
+			return 2
+		}
+		return 0
+	default:
+		return 0
+	}
+
+	next := p.word(i + 1)
+	if next == "weekday" {
+		p.recurrence = weekdaysRule
+		return 2
+	}
+	if weekday, ok := quickAddWeekdays[next]; ok {
+		p.recurrence = "FREQ=WEEKLY;BYDAY=" + rruleWeekdays[weekday]
+		return 2
+	}
+	if unit, ok := quickAddUnits[next]; ok && unit >= unitDay {
+		p.recurrence = "FREQ=" + unitFrequencies[unit]
+		return 2
+	}
+
+	// "every 2 weeks", "every other day", "каждые 3 дня"
+	interval, err := strconv.Atoi(next)
+	if next == "other" {
+		interval, err = 2, nil
+	}
+	unit, ok := quickAddUnits[p.word(i+2)]
+	if err != nil || interval < 1 || !ok || unit < unitDay {
+		return 0
+	}
+	p.recurrence = fmt.Sprintf("FREQ=%s;INTERVAL=%d", unitFrequencies[unit], interval)
+	return 3
+}
+
+// matchRelative распознает "in 2 hours", "in a week", "через 3 дня", "через час"
+func (p *quickAddParser) matchRelative(i int) int {
+	word := p.word(i)
+	if (word != "in" && word != "через") || p.day != nil || p.weekday != nil || p.exact != nil {
+		return 0
+	}
+
+	count, n := 1, 2
+	next := p.word(i + 1)
+	if value, err := strconv.Atoi(next); err == nil && value > 0 {
+		count, n = value, 3
+	} else if word == "in" && (next == "a" || next == "an") {
+		n = 3
+	} else if word == "in" {
+		return 0
+	}
+
+	unit, ok := quickAddUnits[p.word(i+n-1)]
+	if !ok {
+		return 0
+	}
+
+	switch unit {
+	case unitMinute:
+		exact := p.now.Add(time.Duration(count) * time.Minute)
+		p.exact = &exact
+	case unitHour:
+		exact := p.now.Add(time.Duration(count) * time.Hour)
+		p.exact = &exact
+	case unitDay:
+		p.setDay(0, 0, count)
+	case unitWeek:
+		p.setDay(0, 0, 7*count)
+	case unitMonth:
+		p.setDay(0, count, 0)
+	case unitYear:
+		p.setDay(count, 0, 0)
+	}
+	return n
+}
+
+// matchDay распознает сегодня, завтра, послезавтра, дни недели и даты 2025-03-15 и 15.03(.2025)
+func (p *quickAddParser) matchDay(i int) int {
+	if p.day != nil || p.weekday != nil || p.exact != nil {
+		return 0
+	}
+
+	word := p.word(i)
+	switch word {
+	case "today", "сегодня":
+		p.setDay(0, 0, 0)
+		return 1
+	case "tomorrow", "завтра":
+		p.setDay(0, 0, 1)
+		return 1
+	case "послезавтра":
+		p.setDay(0, 0, 2)
+		return 1
+	case "day":
+		if p.word(i+1) == "after" && p.word(i+2) == "tomorrow" {
+			p.setDay(0, 0, 2)
+			return 3
+		}
+		return 0
+	}
+
+	if weekday, ok := quickAddWeekdays[word]; ok {
+		p.weekday = &weekday
+		return 1
+	}
+	switch word {
+	case "on", "this", "next", "в", "во":
+		if weekday, ok := quickAddWeekdays[p.word(i+1)]; ok {
+			p.weekday = &weekday
+			p.weekdayNext = word == "next"
+			return 2
+		}
+		return 0
+	}
+
+	if isoDatePattern.MatchString(word) {
+		day, err := time.ParseInLocation("2006-01-02", word, p.now.Location())
+		if err != nil {
+			return 0
+		}
+		p.day = &day
+		return 1
+	}
+
+	if m := dottedDatePattern.FindStringSubmatch(word); m != nil {
+		dayOfMonth, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		year := p.now.Year()
+		if m[3] != "" {
+			year, _ = strconv.Atoi(m[3])
+		}
+		day := time.Date(year, time.Month(month), dayOfMonth, 0, 0, 0, 0, p.now.Location())
+		if day.Day() != dayOfMonth || day.Month() != time.Month(month) {
+			return 0 // 31.02 — не дата
+		}
+		// без года — ближайшая такая дата
+		if m[3] == "" && day.Before(p.today()) {
+			day = day.AddDate(1, 0, 0)
+		}
+		p.day = &day
+		return 1
+	}
+
+	return 0
+}
+
+// matchClock распознает 15:00, 3pm, 3:30 pm, "at 9", "в 9 утра", "в 7 вечера"
+func (p *quickAddParser) matchClock(i int) int {
+	if p.clock != nil || p.exact != nil {
+		return 0
+	}
+
+	word := p.word(i)
+	if clock, n := p.parseClock(i); n > 0 {
+		p.clock = &clock
+		return n
+	}
+	if word != "at" && word != "в" {
+		return 0
+	}
+
+	if clock, n := p.parseClock(i + 1); n > 0 {
+		p.clock = &clock
+		return n + 1
+	}
+
+	hour, err := strconv.Atoi(p.word(i + 1))
+	if err != nil || hour < 0 || hour > 23 {
+		return 0
+	}
+	if word == "at" {
+		clock := time.Duration(hour) * time.Hour
+		p.clock = &clock
+		return 2
+	}
+
+	// по-русски час без минут понятен только с частью суток: "в 3 дня"
+	switch p.word(i + 2) {
+	case "утра":
+	case "дня", "вечера":
+		if hour < 12 {
+			hour += 12
+		}
+	case "ночи":
+		if hour == 12 {
+			hour = 0
+		}
+	default:
+		return 0
+	}
+	clock := time.Duration(hour) * time.Hour
+	p.clock = &clock
+	return 3
+}
+
+// parseClock разбирает время из слова i или из пары "3 pm", возвращает смещение от полуночи и число слов
+func (p *quickAddParser) parseClock(i int) (time.Duration, int) {
+	word := p.word(i)
+	if m := clock24Pattern.FindStringSubmatch(word); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return 0, 0
+		}
+		return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, 1
+	}
+
+	n := 1
+	if next := p.word(i + 1); next == "am" || next == "pm" {
+		word += next
+		n = 2
+	}
+	m := clock12Pattern.FindStringSubmatch(word)
+	if m == nil {
+		return 0, 0
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if hour < 1 || hour > 12 || minute > 59 {
+		return 0, 0
+	}
+	hour %= 12
+	if m[3] == "pm" {
+		hour += 12
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, n
+}
+
+func (p *quickAddParser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+func (p *quickAddParser) setDay(years, months, days int) {
+	day := p.today().AddDate(years, months, days)
+	p.day = &day
+}
+
+// atClock возвращает время clock от начала дня day по часам, а не по длительности,
+// чтобы переход на летнее время не сдвигал срок
+func atClock(day time.Time, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+// dueDate собирает срок из распознанных дня и времени. Повторяющаяся задача
+// без дня начинается с ближайшего дня серии: "every monday" — с понедельника.
+func (p *quickAddParser) dueDate(recurrence string) (*time.Time, error) {
+	if p.exact != nil {
+		return p.exact, nil
+	}
+	if p.day == nil && p.weekday == nil && p.clock == nil && recurrence == "" {
+		return nil, nil
+	}
+
+	// без времени срок — весь день
+	at := func(day time.Time) time.Time {
+		if p.clock == nil {
+			return dates.EndOfDay(day)
+		}
+		return atClock(day, *p.clock)
+	}
+
+	var due time.Time
+	switch {
+	case p.day != nil:
+		due = at(*p.day)
+		return &due, nil
+	case p.weekday != nil:
+		days := (int(*p.weekday) - int(p.now.Weekday()) + 7) % 7
+		if p.weekdayNext && days == 0 {
+			days = 7
+		}
+		due = at(p.today().AddDate(0, 0, days))
+		if !due.After(p.now) {
+			due = due.AddDate(0, 0, 7)
+		}
+		return &due, nil
+	}
+
+	// только время или только повторение: сегодня, если время еще не прошло
+	due = at(p.today())
+	if !due.After(p.now) {
+		due = due.AddDate(0, 0, 1)
+	}
+	if recurrence == "" {
+		return &due, nil
+	}
+
+	option, err := parseRecurrence(recurrence)
+	if err != nil {
+		return nil, err
+	}
+	option.Dtstart = due
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	if first := r.After(due, true); !first.IsZero() {
+		due = first
+	}
+	return &due, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// среда, 15 октября 2025, 10:00
+	now := time.Date(2025, 10, 15, 10, 0, 0, 0, time.Local)
+	at := func(month time.Month, day, hour, minute int) *time.Time {
+		due := time.Date(2025, month, day, hour, minute, 0, 0, time.Local)
+		return &due
+	}
+	endOfDay := func(month time.Month, day int) *time.Time {
+		due := time.Date(2025, month, day, 23, 59, 59, 0, time.Local)
+		return &due
+	}
+	// прошедшая в этом году дата без года переносится на следующий
+	nextYear := time.Date(2026, 10, 1, 23, 59, 59, 0, time.Local)
+
+	tests := []struct {
+		input string
+		want  models.CreateTaskRequest
+	}{
+		{"Call Bob tomorrow 3pm !high #work every monday", models.CreateTaskRequest{
+			Title: "Call Bob", Priority: models.TaskPriorityHigh, DueDate: at(10, 16, 15, 0),
+			Tags: []string{"work"}, Recurrence: "FREQ=WEEKLY;BYDAY=MO",
+		}},
+		{"Buy milk", models.CreateTaskRequest{Title: "Buy milk", Priority: models.TaskPriorityMedium}},
+		{"Report today", models.CreateTaskRequest{Title: "Report", Priority: models.TaskPriorityMedium, DueDate: endOfDay(10, 15), AllDay: true}},
+		{"Dentist next friday at 9:30am", models.CreateTaskRequest{Title: "Dentist", Priority: models.TaskPriorityMedium, DueDate: at(10, 17, 9, 30)}},
+		{"Standup on wednesday 9 am", models.CreateTaskRequest{Title: "Standup", Priority: models.TaskPriorityMedium, DueDate: at(10, 22, 9, 0)}},
+		{"Plan next wednesday", models.CreateTaskRequest{Title: "Plan", Priority: models.TaskPriorityMedium, DueDate: endOfDay(10, 22), AllDay: true}},
+		{"Water plants at 8", models.CreateTaskRequest{Title: "Water plants", Priority: models.TaskPriorityMedium, DueDate: at(10, 16, 8, 0)}},
+		{"Ping in 2 hours !l", models.CreateTaskRequest{Title: "Ping", Priority: models.TaskPriorityLow, DueDate: at(10, 15, 12, 0)}},
+		{"Renew in a month", models.CreateTaskRequest{Title: "Renew", Priority: models.TaskPriorityMedium, DueDate: endOfDay(11, 15), AllDay: true}},
+		{"Pay rent 2025-11-01 every month", models.CreateTaskRequest{
+			Title: "Pay rent", Priority: models.TaskPriorityMedium, DueDate: endOfDay(11, 1), AllDay: true, Recurrence: "FREQ=MONTHLY",
+		}},
+		{"Gym every other day 18:00", models.CreateTaskRequest{
+			Title: "Gym", Priority: models.TaskPriorityMedium, DueDate: at(10, 15, 18, 0), Recurrence: "FREQ=DAILY;INTERVAL=2",
+		}},
+		{"Review every weekday", models.CreateTaskRequest{
+			Title: "Review", Priority: models.TaskPriorityMedium, DueDate: endOfDay(10, 15), AllDay: true, Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		}},
+		{"Backup every saturday at 7", models.CreateTaskRequest{
+			Title: "Backup", Priority: models.TaskPriorityMedium, DueDate: at(10, 18, 7, 0), Recurrence: "FREQ=WEEKLY;BYDAY=SA",
+		}},
+		{"Meet in the office day after tomorrow", models.CreateTaskRequest{
+			Title: "Meet in the office", Priority: models.TaskPriorityMedium, DueDate: endOfDay(10, 17), AllDay: true,
+		}},
+		{"Позвонить маме завтра в 15:00 !высокий #Семья", models.CreateTaskRequest{
+			Title: "Позвонить маме", Priority: models.TaskPriorityHigh, DueDate: at(10, 16, 15, 0), Tags: []string{"семья"},
+		}},
+		{"Сдать отчет в пятницу в 7 вечера", models.CreateTaskRequest{Title: "Сдать отчет", Priority: models.TaskPriorityMedium, DueDate: at(10, 17, 19, 0)}},
+		{"Оплатить счета 01.11 ежемесячно", models.CreateTaskRequest{
+			Title: "Оплатить счета", Priority: models.TaskPriorityMedium, DueDate: endOfDay(11, 1), AllDay: true, Recurrence: "FREQ=MONTHLY",
+		}},
+		{"Полить цветы каждые 3 дня", models.CreateTaskRequest{
+			Title: "Полить цветы", Priority: models.TaskPriorityMedium, DueDate: endOfDay(10, 15), AllDay: true, Recurrence: "FREQ=DAILY;INTERVAL=3",
+		}},
+		{"Планерка каждый понедельник в 10:00", models.CreateTaskRequest{
+			Title: "Планерка", Priority: models.TaskPriorityMedium, DueDate: at(10, 20, 10, 0), Recurrence: "FREQ=WEEKLY;BYDAY=MO",
+		}},
+		{"Зарядка по будням в 7 утра", models.CreateTaskRequest{
+			Title: "Зарядка", Priority: models.TaskPriorityMedium, DueDate: at(10, 16, 7, 0), Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		}},
+		{"Перейти через дорогу через 30 минут", models.CreateTaskRequest{
+			Title: "Перейти через дорогу", Priority: models.TaskPriorityMedium, DueDate: at(10, 15, 10, 30),
+		}},
+		{"Купить подарок 01.10", models.CreateTaskRequest{
+			Title: "Купить подарок", Priority: models.TaskPriorityMedium, DueDate: &nextYear, AllDay: true,
+		}},
+		// повторы и незнакомые слова остаются в названии
+		{"Read 31.02 today tomorrow !urgent", models.CreateTaskRequest{
+			Title: "Read 31.02 tomorrow !urgent", Priority: models.TaskPriorityMedium, DueDate: endOfDay(10, 15), AllDay: true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseQuickAdd(tt.input, now)
+			if err != nil {
+				t.Fatalf("parseQuickAdd: %v", err)
+			}
+			if got.Title != tt.want.Title || got.Priority != tt.want.Priority || got.Recurrence != tt.want.Recurrence || got.AllDay != tt.want.AllDay {
+				t.Errorf("expected %q %s %q all day %v, got %q %s %q all day %v",
+					tt.want.Title, tt.want.Priority, tt.want.Recurrence, tt.want.AllDay, got.Title, got.Priority, got.Recurrence, got.AllDay)
+			}
+			if len(got.Tags)+len(tt.want.Tags) > 0 && !reflect.DeepEqual(got.Tags, tt.want.Tags) {
+				t.Errorf("expected tags %v, got %v", tt.want.Tags, got.Tags)
+			}
+			if (got.DueDate == nil) != (tt.want.DueDate == nil) || got.DueDate != nil && !got.DueDate.Equal(*tt.want.DueDate) {
+				t.Errorf("expected due date %v, got %v", tt.want.DueDate, got.DueDate)
+			}
+		})
+	}
+}
+
+func TestParseQuickAddErrors(t *testing.T) {
+	now := time.Date(2025, 10, 15, 10, 0, 0, 0, time.Local)
+	for _, input := range []string{"", "   ", "tomorrow 3pm #work !high"} {
+		if _, err := parseQuickAdd(input, now); !errors.Is(err, ErrInvalidQuickAdd) {
+			t.Errorf("%q: expected ErrInvalidQuickAdd, got %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/dates"
+	"todo-lits-DMARK/app/pkg/models"
+
+	"github.com/teambition/rrule-go"
+)
+
+// задачи повторяются не чаще раза в день
+var recurrenceFrequencies = map[rrule.Frequency]bool{
+	rrule.DAILY:   true,
+	rrule.WEEKLY:  true,
+	rrule.MONTHLY: true,
+	rrule.YEARLY:  true,
+}
+
+// сколько повторений одной задачи показывать в прогнозе
+const maxProjectedOccurrences = 50
+
+// normalizeRecurrence проверяет правило RFC 5545 и приводит его к каноническому виду.
+// Префикс RRULE: допускается, DTSTART нет: началом серии служит срок задачи.
+func normalizeRecurrence(rule string) (string, error) {
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if rule == "" {
+		return "", nil
+	}
+
+	option, err := parseRecurrence(rule)
+	if err != nil {
+		return "", err
+	}
+
+	return option.RRuleString(), nil
+}
+
+func parseRecurrence(rule string) (*rrule.ROption, error) {
+	option, err := rrule.StrToROption(rule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+
+	switch {
+	case !option.Dtstart.IsZero():
+		return nil, fmt.Errorf("%w: DTSTART is taken from the due date", ErrInvalidRecurrence)
+	case !recurrenceFrequencies[option.Freq]:
+		return nil, fmt.Errorf("%w: unsupported frequency %s", ErrInvalidRecurrence, option.Freq)
+	case option.Count < 0 || option.Interval < 0:
+		return nil, fmt.Errorf("%w: COUNT and INTERVAL must be positive", ErrInvalidRecurrence)
+	case option.Count > 0 && !option.Until.IsZero():
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRecurrence)
+	}
+
+	if _, err := rrule.NewRRule(*option); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+
+	return option, nil
+}
+
+// nextOccurrence возвращает первую дату серии позже due и позже after вместе с правилом для
+// следующей задачи. COUNT считает саму задачу, поэтому уменьшается на каждое пройденное
+// повторение, в том числе пропущенное. ok == false, если серия закончилась.
+func nextOccurrence(rule string, due, after time.Time) (next time.Time, nextRule string, ok bool, err error) {
+	option, err := parseRecurrence(rule)
+	if err != nil {
+		return time.Time{}, "", false, err
+	}
+
+	// дни недели и месяца считаются в зоне пользователя, в которой передан after,
+	// иначе вечерние сроки уезжают на соседний день
+	remaining := option.Count
+	option.Count = 0
+	option.Dtstart = due.In(after.Location())
+
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return time.Time{}, "", false, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+
+	next = option.Dtstart
+	if remaining == 0 {
+		if after.Before(next) {
+			after = next
+		}
+		next = r.After(after, false)
+	} else {
+		for {
+			if remaining == 1 {
+				return time.Time{}, "", false, nil
+			}
+			next = r.After(next, false)
+			if next.IsZero() {
+				break
+			}
+			remaining--
+			if next.After(after) {
+				break
+			}
+		}
+	}
+
+	if next.IsZero() {
+		return time.Time{}, "", false, nil
+	}
+
+	option.Count = remaining
+	option.Dtstart = time.Time{}
+	return next, option.RRuleString(), true, nil
+}
+
+// projectOccurrences возвращает повторения после due, попадающие в [from, to].
+// Серия считается в зоне from.
+func projectOccurrences(rule string, due, from, to time.Time) ([]time.Time, error) {
+	option, err := parseRecurrence(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := option.Count
+	option.Count = 0
+	option.Dtstart = due.In(from.Location())
+
+	r, err := rrule.NewRRule(*option)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+
+	var dates []time.Time
+	if remaining == 0 {
+		for _, date := range r.Between(from, to, true) {
+			if date.After(due) && len(dates) < maxProjectedOccurrences {
+				dates = append(dates, date)
+			}
+		}
+		return dates, nil
+	}
+
+	// повторения до from тоже расходуют COUNT
+	next := option.Dtstart
+	for passed := 1; passed < remaining && len(dates) < maxProjectedOccurrences; passed++ {
+		next = r.After(next, false)
+		if next.IsZero() || next.After(to) {
+			break
+		}
+		if !next.Before(from) {
+			dates = append(dates, next)
+		}
+	}
+
+	return dates, nil
+}
+
+// GetUpcomingOccurrences прогнозирует повторения открытых задач до конца недели, начиная с завтра
+func (s *taskService) GetUpcomingOccurrences(ctx context.Context) ([]*models.Occurrence, error) {
+	pending := models.TaskStatusPending
+	tasks, err := s.repo.GetAll(ctx, &models.TaskFilter{Status: &pending, HideArchived: true}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring tasks: %w", err)
+	}
+
+	now := s.now()
+	from := dates.StartOfDay(now).AddDate(0, 0, 1)
+	_, to := dates.WeekRange(now)
+
+	var occurrences []*models.Occurrence
+	for _, task := range tasks {
+		if task.Recurrence == "" || task.DueDate == nil {
+			continue
+		}
+
+		projected, err := projectOccurrences(task.Recurrence, *task.DueDate, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to project task %d: %w", task.ID, err)
+		}
+
+		for _, date := range projected {
+			occurrences = append(occurrences, &models.Occurrence{
+				TaskID:    task.ID,
+				Title:     task.Title,
+				Priority:  task.Priority,
+				ProjectID: task.ProjectID,
+				DueDate:   date,
+				AllDay:    task.AllDay,
+			})
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].DueDate.Before(occurrences[j].DueDate)
+	})
+
+	return occurrences, nil
+}
+
+// nextOccurrenceTask строит, но не сохраняет следующую задачу серии; nil — серия закончилась
+func nextOccurrenceTask(task *models.Task, now time.Time) (*models.Task, error) {
+	due, rule, ok, err := nextOccurrence(task.Recurrence, *task.DueDate, now)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return &models.Task{
+		ParentID:    task.ParentID,
+		ProjectID:   task.ProjectID,
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		DueDate:     &due,
+		AllDay:      task.AllDay,
+		Recurrence:  rule,
+		Tags:        task.Tags,
+	}, nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func localDate(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+}
+
+func TestNextOccurrence(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		due      time.Time
+		after    time.Time
+		want     time.Time
+		wantRule string
+	}{
+		{
+			name: "daily",
+			rule: "FREQ=DAILY",
+			due:  localDate(2024, 3, 4, 9), after: localDate(2024, 3, 4, 10),
+			want: localDate(2024, 3, 5, 9), wantRule: "FREQ=DAILY",
+		},
+		{
+			name: "daily skips missed days",
+			rule: "FREQ=DAILY",
+			due:  localDate(2024, 3, 4, 9), after: localDate(2024, 3, 7, 12),
+			want: localDate(2024, 3, 8, 9), wantRule: "FREQ=DAILY",
+		},
+		{
+			name: "completed early keeps schedule",
+			rule: "FREQ=DAILY",
+			due:  localDate(2024, 3, 4, 9), after: localDate(2024, 3, 1, 9),
+			want: localDate(2024, 3, 5, 9), wantRule: "FREQ=DAILY",
+		},
+		{
+			name: "weekdays skip weekend",
+			rule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			due:  localDate(2024, 3, 8, 9), after: localDate(2024, 3, 8, 10),
+			want: localDate(2024, 3, 11, 9), wantRule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		},
+		{
+			name: "every other week keeps phase",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			due:  localDate(2024, 3, 6, 9), after: localDate(2024, 3, 6, 10),
+			want: localDate(2024, 3, 18, 9), wantRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+		},
+		{
+			name: "monthly by day",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=15",
+			due:  localDate(2024, 1, 15, 9), after: localDate(2024, 1, 15, 10),
+			want: localDate(2024, 2, 15, 9), wantRule: "FREQ=MONTHLY;BYMONTHDAY=15",
+		},
+		{
+			name: "first monday of month",
+			rule: "FREQ=MONTHLY;BYDAY=+1MO",
+			due:  localDate(2024, 3, 4, 9), after: localDate(2024, 3, 4, 10),
+			want: localDate(2024, 4, 1, 9), wantRule: "FREQ=MONTHLY;BYDAY=+1MO",
+		},
+		{
+			name: "yearly",
+			rule: "FREQ=YEARLY",
+			due:  localDate(2024, 2, 10, 9), after: localDate(2024, 2, 10, 10),
+			want: localDate(2025, 2, 10, 9), wantRule: "FREQ=YEARLY",
+		},
+		{
+			name: "count decrements",
+			rule: "FREQ=DAILY;COUNT=3",
+			due:  localDate(2024, 3, 4, 9), after: localDate(2024, 3, 4, 10),
+			want: localDate(2024, 3, 5, 9), wantRule: "FREQ=DAILY;COUNT=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, rule, ok, err := nextOccurrence(tt.rule, tt.due, tt.after)
+			if err != nil || !ok {
+				t.Fatalf("nextOccurrence: ok=%v err=%v", ok, err)
+			}
+			if !next.Equal(tt.want) {
+				t.Errorf("next = %v, want %v", next, tt.want)
+			}
+			if rule != tt.wantRule {
+				t.Errorf("rule = %q, want %q", rule, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestNextOccurrenceSeriesEnd(t *testing.T) {
+	due := localDate(2024, 3, 4, 9)
+
+	tests := []struct {
+		name  string
+		rule  string
+		after time.Time
+	}{
+		{"last of count", "FREQ=DAILY;COUNT=1", due},
+		{"count used by missed days", "FREQ=DAILY;COUNT=3", localDate(2024, 3, 10, 9)},
+		{"until passed", "FREQ=DAILY;UNTIL=20240305T235959Z", localDate(2024, 3, 6, 9)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, ok, err := nextOccurrence(tt.rule, due, tt.after)
+			if err != nil {
+				t.Fatalf("nextOccurrence: %v", err)
+			}
+			if ok {
+				t.Error("expected series to end")
+			}
+		})
+	}
+}
+
+func TestNormalizeRecurrence(t *testing.T) {
+	rule, err := normalizeRecurrence(" rrule:freq=weekly;byday=mo ")
+	if err != nil {
+		t.Fatalf("normalizeRecurrence: %v", err)
+	}
+	if rule != "FREQ=WEEKLY;BYDAY=MO" {
+		t.Errorf("rule = %q", rule)
+	}
+
+	invalid := []string{
+		"FREQ=SOMETIMES",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101T000000Z",
+		"DTSTART:20240101T000000Z;FREQ=DAILY",
+		"BYDAY=MO",
+	}
+	for _, rule := range invalid {
+		if _, err := normalizeRecurrence(rule); !errors.Is(err, ErrInvalidRecurrence) {
+			t.Errorf("%q: expected ErrInvalidRecurrence, got %v", rule, err)
+		}
+	}
+}
+
+func TestProjectOccurrences(t *testing.T) {
+	due := localDate(2024, 3, 4, 9)
+	from, to := localDate(2024, 3, 5, 0), localDate(2024, 3, 10, 23)
+
+	dates, err := projectOccurrences("FREQ=DAILY", due, from, to)
+	if err != nil {
+		t.Fatalf("projectOccurrences: %v", err)
+	}
+	if len(dates) != 6 || !dates[0].Equal(localDate(2024, 3, 5, 9)) {
+		t.Errorf("unexpected daily projection: %v", dates)
+	}
+
+	dates, err = projectOccurrences("FREQ=DAILY;COUNT=3", due, from, to)
+	if err != nil {
+		t.Fatalf("projectOccurrences: %v", err)
+	}
+	if len(dates) != 2 {
+		t.Errorf("count must limit projection, got %v", dates)
+	}
+}
+
+func TestToggleRecurringTaskSpawnsNext(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	due := time.Now().Add(time.Hour).Truncate(time.Second)
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{
+		Title:      "standup",
+		Priority:   models.TaskPriorityHigh,
+		DueDate:    &due,
+		Recurrence: "FREQ=DAILY;COUNT=2",
+		Tags:       []string{"work"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	done, next, err := svc.ToggleTaskStatus(ctx, task.ID, models.SubtaskModeNone)
+	if err != nil {
+		t.Fatalf("ToggleTaskStatus: %v", err)
+	}
+	if done.Status != models.TaskStatusCompleted || done.Recurrence != "" {
+		t.Errorf("completed task must drop the rule: %+v", done)
+	}
+	if next == nil {
+		t.Fatal("expected next occurrence")
+	}
+	if next.Title != "standup" || next.Status != models.TaskStatusPending || len(next.Tags) != 1 {
+		t.Errorf("unexpected next occurrence: %+v", next)
+	}
+	if !next.DueDate.Equal(due.AddDate(0, 0, 1)) || next.Recurrence != "FREQ=DAILY;COUNT=1" {
+		t.Errorf("next due %v rule %q", next.DueDate, next.Recurrence)
+	}
+
+	// повторное переключение старой задачи не создает дубликат
+	if _, _, err := svc.ToggleTaskStatus(ctx, task.ID, models.SubtaskModeNone); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if _, again, err := svc.ToggleTaskStatus(ctx, task.ID, models.SubtaskModeNone); err != nil || again != nil {
+		t.Errorf("expected no duplicate, got %+v (%v)", again, err)
+	}
+
+	// последнее повторение серии
+	if _, last, err := svc.ToggleTaskStatus(ctx, next.ID, models.SubtaskModeNone); err != nil || last != nil {
+		t.Errorf("series must end after COUNT, got %+v (%v)", last, err)
+	}
+}
+
+func TestRecurrenceRequiresDueDate(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	_, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityLow, Recurrence: "FREQ=DAILY"})
+	if err == nil {
+		t.Fatal("expected error without due date")
+	}
+
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	rule := "FREQ=DAILY"
+	if _, err := svc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Recurrence: &rule}); err == nil {
+		t.Fatal("expected error without due date")
+	}
+
+	bad := "FREQ=NEVER"
+	if _, err := svc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Recurrence: &bad}); !errors.Is(err, ErrInvalidRecurrence) {
+		t.Fatalf("expected ErrInvalidRecurrence, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"unicode"
+)
+
+// parseSearchQuery разбирает строку поиска: слова через пробел, "фраза в кавычках",
+// слово* для поиска по началу слова и -слово или -"фраза" для исключения.
+func parseSearchQuery(input string) (*models.SearchQuery, error) {
+	query := &models.SearchQuery{}
+	runes := []rune(strings.TrimSpace(input))
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		term := models.SearchTerm{}
+		if runes[i] == '-' {
+			term.Negated = true
+			i++
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("%w: unterminated quote at position %d", ErrInvalidSearchQuery, i+1)
+			}
+			term.Text = string(runes[i+1 : end])
+			term.Phrase = true
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			term.Text = string(runes[i:end])
+			if strings.HasSuffix(term.Text, "*") {
+				term.Text = strings.TrimRight(term.Text, "*")
+				term.Prefix = true
+			}
+			i = end
+		}
+
+		if term, ok := term.Normalize(); ok {
+			query.Terms = append(query.Terms, term)
+		}
+	}
+
+	for _, term := range query.Terms {
+		if !term.Negated {
+			return query, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: at least one term without '-' is required", ErrInvalidSearchQuery)
+}
+
+// SearchTasks выполняет полнотекстовый поиск и возвращает страницу результатов,
+// page.Limit <= 0 означает размер страницы по умолчанию
+func (s *taskService) SearchTasks(ctx context.Context, input string, page models.PageRequest) (*models.SearchPage, error) {
+	query, err := parseSearchQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	query.Limit = pageLimit(page.Limit)
+	query.Cursor = page.Cursor
+
+	result, err := s.repo.Search(ctx, query)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		return nil, fmt.Errorf("failed to search tasks: %w", err)
+	}
+
+	return result, nil
+}
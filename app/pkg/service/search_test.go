@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []models.SearchTerm
+	}{
+		{"milk", []models.SearchTerm{{Text: "milk"}}},
+		{`buy "fresh milk" -bread`, []models.SearchTerm{
+			{Text: "buy"},
+			{Text: "fresh milk", Phrase: true},
+			{Text: "bread", Negated: true},
+		}},
+		{`отчет* -"черновик отчета"`, []models.SearchTerm{
+			{Text: "отчет", Prefix: true},
+			{Text: "черновик отчета", Phrase: true, Negated: true},
+		}},
+		{"e-mail", []models.SearchTerm{{Text: "e mail", Phrase: true}}},
+		{`"milk"  ... go!`, []models.SearchTerm{{Text: "milk"}, {Text: "go"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			query, err := parseSearchQuery(tt.input)
+			if err != nil {
+				t.Fatalf("parseSearchQuery: %v", err)
+			}
+			if !reflect.DeepEqual(query.Terms, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, query.Terms)
+			}
+		})
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	for _, input := range []string{`"unterminated`, "-milk", "-milk -bread", "... !!"} {
+		if _, err := parseSearchQuery(input); !errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("%q: expected ErrInvalidSearchQuery, got %v", input, err)
+		}
+	}
+}
+
+func TestSearchTasksLimit(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	for i := 0; i < 3; i++ {
+		if _, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "report", Priority: models.TaskPriorityLow}); err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+	}
+
+	page, err := svc.SearchTasks(ctx, "report", models.PageRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("SearchTasks: %v", err)
+	}
+	if len(page.Results) != 2 || page.Total != 3 || page.NextCursor == "" {
+		t.Fatalf("expected 2 of 3 results with a cursor, got %d of %d (cursor %q)", len(page.Results), page.Total, page.NextCursor)
+	}
+
+	page, err = svc.SearchTasks(ctx, "report", models.PageRequest{Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("SearchTasks: %v", err)
+	}
+	if len(page.Results) != 1 || page.NextCursor != "" {
+		t.Errorf("expected last page with 1 result, got %d (cursor %q)", len(page.Results), page.NextCursor)
+	}
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+)
+
+// BulkUpdateTasks применяет одни и те же изменения ко всем задачам ids
+func (s *taskService) BulkUpdateTasks(ctx context.Context, ids []int, updates *models.UpdateTaskRequest) ([]*models.Task, error) {
+	ids, err := bulkIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// запрос вызывающего не меняется, версию каждая задача получает свою
+	shared := *updates
+	updates = &shared
+	updates.Version = nil
+	if updates.Tags != nil {
+		tags := normalizeTags(*updates.Tags)
+		updates.Tags = &tags
+	}
+
+	if err := s.validator.Struct(updates); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := s.normalizeDueUpdate(updates); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.liveTasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.Recurrence != nil {
+		recurrence, err := normalizeRecurrence(*updates.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			if recurrence != "" && task.DueDate == nil && updates.DueDate == nil {
+				return nil, fmt.Errorf("recurring task %d requires a due date", task.ID)
+			}
+		}
+		updates.Recurrence = &recurrence
+	}
+
+	// версия защищает от изменений между чтением и транзакцией, конфликт отменяет всю пачку
+	changes := make([]*models.TaskUpdate, len(tasks))
+	for i, task := range tasks {
+		versioned := *updates
+		versioned.Version = &task.Version
+		changes[i] = &models.TaskUpdate{ID: task.ID, Changes: &versioned}
+	}
+	if err := s.repo.BulkUpdate(ctx, changes, nil); err != nil {
+		return nil, fmt.Errorf("failed to update tasks: %w", err)
+	}
+
+	return s.repo.GetSnapshots(ctx, ids)
+}
+
+// BulkSetStatus завершает или возобновляет задачи. Как и при переключении одной задачи,
+// завершение повторяющейся создает следующую задачу серии, все в одной транзакции.
+// Задачи, уже находящиеся в нужном статусе, не меняются.
+func (s *taskService) BulkSetStatus(ctx context.Context, ids []int, status models.TaskStatus) ([]*models.Task, []*models.Task, error) {
+	ids, err := bulkIDs(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status != models.TaskStatusPending && status != models.TaskStatusCompleted {
+		return nil, nil, fmt.Errorf("invalid task status: %s", status)
+	}
+
+	tasks, err := s.liveTasks(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := s.now()
+	var changes []*models.TaskUpdate
+	var created []*models.Task
+	for _, task := range tasks {
+		if task.Status == status {
+			continue
+		}
+
+		// версия защищает от изменений между чтением и транзакцией
+		updates := &models.UpdateTaskRequest{Status: &status, Version: &task.Version}
+		if status == models.TaskStatusCompleted && task.Recurrence != "" && task.DueDate != nil {
+			next, err := nextOccurrenceTask(task, now)
+			if err != nil {
+				return nil, nil, err
+			}
+			if next != nil {
+				noRecurrence := ""
+				updates.Recurrence = &noRecurrence
+				created = append(created, next)
+			}
+		}
+		changes = append(changes, &models.TaskUpdate{ID: task.ID, Changes: updates})
+	}
+
+	if len(changes) > 0 {
+		if err := s.repo.BulkUpdate(ctx, changes, created); err != nil {
+			return nil, nil, fmt.Errorf("failed to change task status: %w", err)
+		}
+	}
+
+	tasks, err = s.repo.GetSnapshots(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(created) > 0 {
+		created, err = s.repo.GetSnapshots(ctx, taskIDs(created))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return tasks, created, nil
+}
+
+// BulkTagTasks снимает с задач теги remove и добавляет add, остальные теги сохраняются.
+// Перенос задач между тегами — это remove старого и add нового в одном вызове.
+func (s *taskService) BulkTagTasks(ctx context.Context, ids []int, add, remove []string) ([]*models.Task, error) {
+	ids, err := bulkIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	add, remove = normalizeTags(add), normalizeTags(remove)
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, fmt.Errorf("no tags to add or remove")
+	}
+	if err := s.validator.Struct(&models.UpdateTaskRequest{Tags: &add}); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	tasks, err := s.liveTasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*models.TaskUpdate
+	for _, task := range tasks {
+		var names []string
+		for _, name := range task.Tags {
+			if !slices.Contains(remove, name) {
+				names = append(names, name)
+			}
+		}
+		tags := normalizeTags(append(names, add...))
+		if slices.Equal(tags, task.Tags) {
+			continue
+		}
+		changes = append(changes, &models.TaskUpdate{
+			ID:      task.ID,
+			Changes: &models.UpdateTaskRequest{Tags: &tags, Version: &task.Version},
+		})
+	}
+
+	if len(changes) > 0 {
+		if err := s.repo.BulkUpdate(ctx, changes, nil); err != nil {
+			return nil, fmt.Errorf("failed to update task tags: %w", err)
+		}
+	}
+
+	return s.repo.GetSnapshots(ctx, ids)
+}
+
+// BulkMoveToProject переносит задачи вместе с подзадачами в проект
+func (s *taskService) BulkMoveToProject(ctx context.Context, ids []int, projectID int) error {
+	ids, err := bulkIDs(ids)
+	if err != nil {
+		return err
+	}
+	if projectID <= 0 {
+		return fmt.Errorf("invalid project ID: %d", projectID)
+	}
+
+	if err := s.repo.BulkMoveToProject(ctx, ids, projectID); err != nil {
+		return fmt.Errorf("failed to move tasks to project: %w", err)
+	}
+	return nil
+}
+
+// BulkDeleteTasks переносит задачи вместе с подзадачами в корзину
+func (s *taskService) BulkDeleteTasks(ctx context.Context, ids []int) error {
+	ids, err := bulkIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.BulkDelete(ctx, ids); err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", err)
+	}
+	return nil
+}
+
+// liveTasks читает задачи ids; задача в корзине или отсутствующая — ошибка для всей пачки
+func (s *taskService) liveTasks(ctx context.Context, ids []int) ([]*models.Task, error) {
+	tasks, err := s.repo.GetSnapshots(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	result := make([]*models.Task, len(ids))
+	for i, id := range ids {
+		task, ok := byID[id]
+		if !ok || task.DeletedAt != nil {
+			return nil, fmt.Errorf("task with id %d %w", id, repository.ErrTaskNotFound)
+		}
+		result[i] = task
+	}
+	return result, nil
+}
+
+// bulkIDs проверяет id пакетной операции и убирает повторы, сохраняя порядок
+func bulkIDs(ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no task IDs provided")
+	}
+
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid task ID: %d", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+func taskIDs(tasks []*models.Task) []int {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+)
+
+func TestBulkSetStatusSpawnsNextOccurrences(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	due := time.Now().Add(time.Hour)
+	weekly, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "weekly", Priority: models.TaskPriorityLow, DueDate: &due, Recurrence: "FREQ=WEEKLY"})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	plain, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "plain", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	tasks, created, err := svc.BulkSetStatus(ctx, []int{weekly.ID, plain.ID, weekly.ID}, models.TaskStatusCompleted)
+	if err != nil {
+		t.Fatalf("BulkSetStatus: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected duplicate ids to be merged, got %d tasks", len(tasks))
+	}
+	for _, task := range tasks {
+		if task.Status != models.TaskStatusCompleted {
+			t.Errorf("task %d not completed", task.ID)
+		}
+	}
+	if len(created) != 1 || created[0].Recurrence != "FREQ=WEEKLY" || !created[0].DueDate.After(due) {
+		t.Fatalf("expected next weekly occurrence, got %+v", created)
+	}
+
+	// повторное завершение ничего не меняет и не плодит повторения
+	if _, created, err := svc.BulkSetStatus(ctx, []int{weekly.ID}, models.TaskStatusCompleted); err != nil || len(created) != 0 {
+		t.Errorf("expected no-op, got %+v, %v", created, err)
+	}
+}
+
+func TestBulkSetStatusIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	_, _, err = svc.BulkSetStatus(ctx, []int{task.ID, 999}, models.TaskStatusCompleted)
+	if !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+	got, _ := svc.GetTask(ctx, task.ID)
+	if got.Status != models.TaskStatusPending {
+		t.Error("failed batch must not change any task")
+	}
+}
+
+// racingBulkRepository перед пакетной записью меняет задачу, как другой клиент
+type racingBulkRepository struct {
+	repository.TaskRepositoryInterface
+	race func()
+}
+
+func (r *racingBulkRepository) BulkUpdate(ctx context.Context, updates []*models.TaskUpdate, create []*models.Task) error {
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return r.TaskRepositoryInterface.BulkUpdate(ctx, updates, create)
+}
+
+func TestBulkUpdateTasksChecksVersions(t *testing.T) {
+	ctx := context.Background()
+	repo := &racingBulkRepository{TaskRepositoryInterface: repository.NewMemoryTaskRepository()}
+	svc := NewTaskService(repo)
+	first, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "first", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	second, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "second", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	title := "renamed elsewhere"
+	repo.race = func() {
+		if err := repo.Update(ctx, second.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	priority := models.TaskPriorityHigh
+	version := 42
+	updates := &models.UpdateTaskRequest{Priority: &priority, Version: &version}
+	if _, err := svc.BulkUpdateTasks(ctx, []int{first.ID, second.ID}, updates); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	if updates.Version == nil || *updates.Version != version {
+		t.Error("BulkUpdateTasks must not change the caller's request")
+	}
+	for _, id := range []int{first.ID, second.ID} {
+		if got, _ := svc.GetTask(ctx, id); got.Priority != models.TaskPriorityLow {
+			t.Errorf("task %d: conflicting batch must not change any task", id)
+		}
+	}
+
+	if _, err := svc.BulkUpdateTasks(ctx, []int{first.ID, second.ID}, updates); err != nil {
+		t.Fatalf("BulkUpdateTasks: %v", err)
+	}
+	if got, _ := svc.GetTask(ctx, second.ID); got.Priority != models.TaskPriorityHigh || got.Title != title {
+		t.Errorf("expected priority changed and title kept, got %+v", got)
+	}
+}
+
+func TestBulkTagTasksMovesTags(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	first, _ := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "first", Priority: models.TaskPriorityLow, Tags: []string{"old", "keep"}})
+	second, _ := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "second", Priority: models.TaskPriorityLow})
+
+	tasks, err := svc.BulkTagTasks(ctx, []int{first.ID, second.ID}, []string{"New"}, []string{"old"})
+	if err != nil {
+		t.Fatalf("BulkTagTasks: %v", err)
+	}
+
+	want := map[int][]string{first.ID: {"keep", "new"}, second.ID: {"new"}}
+	for _, task := range tasks {
+		if len(task.Tags) != len(want[task.ID]) {
+			t.Fatalf("task %d: expected tags %v, got %v", task.ID, want[task.ID], task.Tags)
+		}
+		for i, tag := range want[task.ID] {
+			if task.Tags[i] != tag {
+				t.Errorf("task %d: expected tags %v, got %v", task.ID, want[task.ID], task.Tags)
+			}
+		}
+	}
+
+	if _, err := svc.BulkTagTasks(ctx, []int{first.ID}, nil, nil); err == nil {
+		t.Error("expected error without tags")
+	}
+}
+
+func TestBulkOperationsRejectInvalidIDs(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	if err := svc.BulkDeleteTasks(ctx, nil); err == nil {
+		t.Error("expected error for empty ids")
+	}
+	if err := svc.BulkMoveToProject(ctx, []int{0}, 1); err == nil {
+		t.Error("expected error for invalid id")
+	}
+}
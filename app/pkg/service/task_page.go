@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// pageLimit приводит размер страницы к допустимому, limit <= 0 означает размер по умолчанию
+func pageLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return defaultPageLimit
+	case limit > maxPageLimit:
+		return maxPageLimit
+	default:
+		return limit
+	}
+}
+
+// GetTasksPage возвращает страницу задач в порядке sort, продолжая с page.Cursor
+func (s *taskService) GetTasksPage(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error) {
+	if err := s.validateTaskListing(filter, sort); err != nil {
+		return nil, err
+	}
+
+	page.Limit = pageLimit(page.Limit)
+	result, err := s.repo.GetPage(ctx, filter, sort, &page)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestPageLimit(t *testing.T) {
+	for limit, want := range map[int]int{-1: defaultPageLimit, 0: defaultPageLimit, 10: 10, 1000: maxPageLimit} {
+		if got := pageLimit(limit); got != want {
+			t.Errorf("pageLimit(%d) = %d, want %d", limit, got, want)
+		}
+	}
+}
+
+func TestGetTasksPage(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	for _, priority := range []models.TaskPriority{models.TaskPriorityLow, models.TaskPriorityHigh, models.TaskPriorityMedium} {
+		if _, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: priority}); err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+	}
+
+	sort := &models.TaskSort{Field: "priority", Order: "desc"}
+	page, err := svc.GetTasksPage(ctx, nil, sort, models.PageRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("GetTasksPage: %v", err)
+	}
+	if len(page.Tasks) != 2 || page.Total != 3 || page.Tasks[0].Priority != models.TaskPriorityHigh {
+		t.Fatalf("unexpected first page: %d of %d tasks", len(page.Tasks), page.Total)
+	}
+
+	_, err = svc.GetTasksPage(ctx, nil, nil, models.PageRequest{Cursor: page.NextCursor})
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for cursor of another sort, got %v", err)
+	}
+
+	if _, err := svc.GetTasksPage(ctx, nil, &models.TaskSort{Field: "title", Order: "asc"}, models.PageRequest{}); err == nil {
+		t.Error("expected invalid sort to be rejected")
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func (s *taskService) CreateProject(ctx context.Context, req *models.CreateProjectRequest) (*models.Project, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	project := &models.Project{Name: req.Name, Color: req.Color}
+	if project.Color == "" {
+		project.Color = models.DefaultProjectColor
+	}
+
+	if err := s.repo.CreateProject(ctx, project); err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return project, nil
+}
+
+// GetProjects возвращает проекты в пользовательском порядке, архивные тоже
+func (s *taskService) GetProjects(ctx context.Context) ([]*models.Project, error) {
+	projects, err := s.repo.GetProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// Inbox нельзя архивировать: иначе новые задачи сразу пропадали бы из списка
+func (s *taskService) UpdateProject(ctx context.Context, id int, updates *models.UpdateProjectRequest) (*models.Project, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid project ID: %d", id)
+	}
+
+	if updates.Name != nil {
+		name := strings.TrimSpace(*updates.Name)
+		updates.Name = &name
+	}
+
+	if err := s.validator.Struct(updates); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	project, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	if project.Inbox && updates.Archived != nil && *updates.Archived {
+		return nil, fmt.Errorf("cannot archive project %d: %w", id, ErrInboxProject)
+	}
+
+	if err := s.repo.UpdateProject(ctx, id, updates); err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+
+	return s.repo.GetProject(ctx, id)
+}
+
+func (s *taskService) ReorderProjects(ctx context.Context, ids []int) error {
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return fmt.Errorf("invalid project ID: %d", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate project ID: %d", id)
+		}
+		seen[id] = true
+	}
+
+	if err := s.repo.ReorderProjects(ctx, ids); err != nil {
+		return fmt.Errorf("failed to reorder projects: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteProject удаляет проект, его задачи переезжают во Inbox
+func (s *taskService) DeleteProject(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("invalid project ID: %d", id)
+	}
+
+	project, err := s.repo.GetProject(ctx, id)
+	if err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	if project.Inbox {
+		return fmt.Errorf("cannot delete project %d: %w", id, ErrInboxProject)
+	}
+
+	inbox, err := s.inbox(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteProject(ctx, id, inbox.ID); err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	return nil
+}
+
+// MoveTaskToProject переносит задачу с подзадачами. Подзадача, которую переносят
+// в другой проект, чем у родителя, становится корневой.
+func (s *taskService) MoveTaskToProject(ctx context.Context, id int, projectID int) (*models.Task, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", id)
+	}
+	if projectID <= 0 {
+		return nil, fmt.Errorf("invalid project ID: %d", projectID)
+	}
+
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+	if _, err := s.repo.GetProject(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	if task.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *task.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("parent task not found: %w", err)
+		}
+		if parent.ProjectID != projectID {
+			if err := s.repo.SetParent(ctx, id, nil); err != nil {
+				return nil, fmt.Errorf("failed to detach task from parent: %w", err)
+			}
+		}
+	}
+
+	if err := s.repo.MoveToProject(ctx, id, projectID); err != nil {
+		return nil, fmt.Errorf("failed to move task to project: %w", err)
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *taskService) inbox(ctx context.Context) (*models.Project, error) {
+	projects, err := s.GetProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, project := range projects {
+		if project.Inbox {
+			return project, nil
+		}
+	}
+	return nil, fmt.Errorf("inbox project is missing")
+}
+
+// archivedProjectIDs нужен для выборок, которые не принимают TaskFilter
+func (s *taskService) archivedProjectIDs(ctx context.Context) (map[int]bool, error) {
+	projects, err := s.GetProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make(map[int]bool)
+	for _, project := range projects {
+		if project.Archived {
+			archived[project.ID] = true
+		}
+	}
+	return archived, nil
+}
+
+func (s *taskService) withoutArchived(ctx context.Context, tasks []*models.Task) ([]*models.Task, error) {
+	archived, err := s.archivedProjectIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		return tasks, nil
+	}
+
+	var visible []*models.Task
+	for _, task := range tasks {
+		if !archived[task.ProjectID] {
+			visible = append(visible, task)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func mustCreateProject(t *testing.T, svc TaskService, name string) *models.Project {
+	t.Helper()
+	ctx := context.Background()
+
+	project, err := svc.CreateProject(ctx, &models.CreateProjectRequest{Name: name})
+	if err != nil {
+		t.Fatalf("CreateProject(%q): %v", name, err)
+	}
+	return project
+}
+
+func TestInboxCannotBeArchivedOrDeleted(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	projects, err := svc.GetProjects(ctx)
+	if err != nil {
+		t.Fatalf("GetProjects: %v", err)
+	}
+	if len(projects) != 1 || !projects[0].Inbox {
+		t.Fatalf("expected only inbox, got %+v", projects)
+	}
+	inbox := projects[0]
+
+	archived := true
+	if _, err := svc.UpdateProject(ctx, inbox.ID, &models.UpdateProjectRequest{Archived: &archived}); !errors.Is(err, ErrInboxProject) {
+		t.Errorf("expected ErrInboxProject on archive, got %v", err)
+	}
+	if err := svc.DeleteProject(ctx, inbox.ID); !errors.Is(err, ErrInboxProject) {
+		t.Errorf("expected ErrInboxProject on delete, got %v", err)
+	}
+
+	name := "  Входящие задачи "
+	renamed, err := svc.UpdateProject(ctx, inbox.ID, &models.UpdateProjectRequest{Name: &name})
+	if err != nil {
+		t.Fatalf("UpdateProject: %v", err)
+	}
+	if renamed.Name != "Входящие задачи" {
+		t.Errorf("expected trimmed name, got %q", renamed.Name)
+	}
+}
+
+func TestCreateProjectValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	project := mustCreateProject(t, svc, " work ")
+	if project.Name != "work" || project.Color != models.DefaultProjectColor {
+		t.Errorf("unexpected project %+v", project)
+	}
+
+	for _, req := range []*models.CreateProjectRequest{
+		{Name: "   "},
+		{Name: "home", Color: "red"},
+	} {
+		if _, err := svc.CreateProject(ctx, req); err == nil {
+			t.Errorf("expected validation error for %+v", req)
+		}
+	}
+
+	if err := svc.ReorderProjects(ctx, []int{project.ID, project.ID}); err == nil {
+		t.Error("expected error for duplicate ids in reorder")
+	}
+}
+
+func TestSubtasksFollowParentProject(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	work := mustCreateProject(t, svc, "work")
+	home := mustCreateProject(t, svc, "home")
+
+	parent, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "parent", Priority: models.TaskPriorityLow, ProjectID: &work.ID})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	child := mustCreateSubtask(t, svc, parent.ID, "child")
+	if child.ProjectID != work.ID {
+		t.Errorf("expected subtask in parent project %d, got %d", work.ID, child.ProjectID)
+	}
+
+	_, err = svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "odd", Priority: models.TaskPriorityLow, ParentID: &parent.ID, ProjectID: &home.ID})
+	if err == nil {
+		t.Error("expected error for subtask in another project")
+	}
+
+	// перенос подзадачи в другой проект отцепляет ее от родителя
+	moved, err := svc.MoveTaskToProject(ctx, child.ID, home.ID)
+	if err != nil {
+		t.Fatalf("MoveTaskToProject: %v", err)
+	}
+	if moved.ProjectID != home.ID || moved.ParentID != nil {
+		t.Errorf("expected root task in home project, got %+v", moved)
+	}
+
+	// а перенос под родителя возвращает ее в проект родителя
+	moved, err = svc.MoveTask(ctx, child.ID, &parent.ID)
+	if err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+	if moved.ProjectID != work.ID {
+		t.Errorf("expected subtask back in work project, got %d", moved.ProjectID)
+	}
+}
+
+func TestArchivedProjectIsHiddenFromStatsAndDateFilters(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	work := mustCreateProject(t, svc, "work")
+
+	past := time.Now().Add(-time.Hour)
+	if _, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "loose", Priority: models.TaskPriorityLow}); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	report, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "report", Priority: models.TaskPriorityLow, ProjectID: &work.ID, DueDate: &past})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	archived := true
+	if _, err := svc.UpdateProject(ctx, work.ID, &models.UpdateProjectRequest{Archived: &archived}); err != nil {
+		t.Fatalf("UpdateProject: %v", err)
+	}
+
+	stats, err := svc.GetTaskStats(ctx)
+	if err != nil {
+		t.Fatalf("GetTaskStats: %v", err)
+	}
+	if stats.Total != 1 || stats.Overdue != 0 {
+		t.Errorf("expected archived tasks excluded from totals, got %+v", stats)
+	}
+	if len(stats.Projects) != 2 || !stats.Projects[1].Archived || stats.Projects[1].Total != 1 || stats.Projects[1].Overdue != 1 {
+		t.Errorf("expected archived project stats to be kept, got %+v", stats.Projects)
+	}
+
+	overdue, err := svc.GetOverdueTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetOverdueTasks: %v", err)
+	}
+	if len(overdue) != 0 {
+		t.Errorf("expected no overdue tasks from archived project, got %+v", overdue)
+	}
+
+	if _, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "late", Priority: models.TaskPriorityLow, ProjectID: &work.ID}); err == nil {
+		t.Error("expected error creating task in archived project")
+	}
+
+	// удаление проекта переносит задачи во Inbox
+	if err := svc.DeleteProject(ctx, work.ID); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+	got, err := svc.GetTask(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.ProjectID != 1 {
+		t.Errorf("expected task moved to inbox, got project %d", got.ProjectID)
+	}
+}
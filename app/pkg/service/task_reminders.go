@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// MissedReminderGrace — насколько напоминание может опоздать, чтобы не считаться пропущенным.
+// Планировщик просыпается с небольшой задержкой, пропущенными считаются напоминания,
+// время которых прошло, пока приложение было закрыто.
+const MissedReminderGrace = time.Minute
+
+// AddReminder добавляет напоминание к задаче: в момент At или за OffsetMinutes до срока
+func (s *taskService) AddReminder(ctx context.Context, taskID int, req *models.CreateReminderRequest) (*models.Reminder, error) {
+	if taskID <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", taskID)
+	}
+
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if (req.At == nil) == (req.OffsetMinutes == nil) {
+		return nil, fmt.Errorf("reminder requires either a time or an offset before the due date")
+	}
+
+	task, err := s.repo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+
+	reminder := &models.Reminder{TaskID: taskID, At: req.At, OffsetMinutes: req.OffsetMinutes}
+	if req.At != nil {
+		reminder.RemindAt = *req.At
+	} else {
+		if task.DueDate == nil {
+			return nil, fmt.Errorf("reminder before the due date requires a due date")
+		}
+		reminder.RemindAt = models.ReminderTime(*task.DueDate, *req.OffsetMinutes)
+	}
+
+	if err := s.repo.AddReminder(ctx, reminder); err != nil {
+		return nil, fmt.Errorf("failed to add reminder: %w", err)
+	}
+
+	return reminder, nil
+}
+
+func (s *taskService) GetReminders(ctx context.Context, taskID int) ([]*models.Reminder, error) {
+	if taskID <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", taskID)
+	}
+
+	reminders, err := s.repo.GetReminders(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+func (s *taskService) DeleteReminder(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("invalid reminder ID: %d", id)
+	}
+
+	if err := s.repo.DeleteReminder(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete reminder: %w", err)
+	}
+
+	return nil
+}
+
+// FireDueReminders забирает напоминания, время которых наступило к now, вместе с задачами.
+// Каждое напоминание возвращается один раз, в том числе пропущенные за время простоя.
+func (s *taskService) FireDueReminders(ctx context.Context, now time.Time) ([]*models.DueReminder, error) {
+	reminders, err := s.repo.ClaimDueReminders(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due reminders: %w", err)
+	}
+	if len(reminders) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int, 0, len(reminders))
+	for _, reminder := range reminders {
+		ids = append(ids, reminder.TaskID)
+	}
+	tasks, err := s.repo.GetSnapshots(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reminded tasks: %w", err)
+	}
+	byID := make(map[int]*models.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	due := make([]*models.DueReminder, 0, len(reminders))
+	for _, reminder := range reminders {
+		task, ok := byID[reminder.TaskID]
+		if !ok {
+			continue
+		}
+		due = append(due, &models.DueReminder{
+			Reminder: reminder,
+			Task:     task,
+			Missed:   reminder.RemindAt.Before(now.Add(-MissedReminderGrace)),
+		})
+	}
+
+	return due, nil
+}
+
+// ReleaseReminder возвращает напоминание, которое не удалось доставить, следующей проверке
+func (s *taskService) ReleaseReminder(ctx context.Context, id int) error {
+	if err := s.repo.ReleaseReminder(ctx, id); err != nil {
+		return fmt.Errorf("failed to release reminder: %w", err)
+	}
+	return nil
+}
+
+// SnoozeTask откладывает открытую задачу до until. Срок в прошлом здесь не проверяется:
+// его отсекает вызывающий, если until задан пользователем.
+func (s *taskService) SnoozeTask(ctx context.Context, id int, until time.Time) (*models.Task, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", id)
+	}
+
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+	if task.Status != models.TaskStatusPending {
+		return nil, fmt.Errorf("completed task %d cannot be snoozed", id)
+	}
+
+	if err := s.repo.Snooze(ctx, id, until); err != nil {
+		return nil, fmt.Errorf("failed to snooze task: %w", err)
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// NextReminderAt возвращает время ближайшего напоминания или nil, если ждать нечего
+func (s *taskService) NextReminderAt(ctx context.Context) (*time.Time, error) {
+	next, err := s.repo.NextReminderAt(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next reminder: %w", err)
+	}
+
+	return next, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestAddReminderValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "no due", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	at := time.Now().Add(time.Hour)
+	offset, negative := 15, -5
+	tests := []struct {
+		name string
+		req  *models.CreateReminderRequest
+	}{
+		{"neither time nor offset", &models.CreateReminderRequest{}},
+		{"both time and offset", &models.CreateReminderRequest{At: &at, OffsetMinutes: &offset}},
+		{"negative offset", &models.CreateReminderRequest{OffsetMinutes: &negative}},
+		{"offset without due date", &models.CreateReminderRequest{OffsetMinutes: &offset}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.AddReminder(ctx, task.ID, tt.req); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func TestFireDueReminders(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	now := time.Now()
+	due := now.Add(time.Hour)
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "call", Priority: models.TaskPriorityLow, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	// пропущено, пока приложение было закрыто; наступает сейчас; сработает за 30 минут до срока
+	missedAt, nowAt, offset := now.Add(-2*time.Hour), now.Add(-time.Second), 30
+	for _, req := range []*models.CreateReminderRequest{{At: &missedAt}, {At: &nowAt}, {OffsetMinutes: &offset}} {
+		if _, err := svc.AddReminder(ctx, task.ID, req); err != nil {
+			t.Fatalf("AddReminder: %v", err)
+		}
+	}
+
+	fired, err := svc.FireDueReminders(ctx, now)
+	if err != nil {
+		t.Fatalf("FireDueReminders: %v", err)
+	}
+	if len(fired) != 2 {
+		t.Fatalf("expected 2 due reminders, got %d", len(fired))
+	}
+	if !fired[0].Missed || fired[1].Missed {
+		t.Errorf("expected only the first reminder to be missed, got %v and %v", fired[0].Missed, fired[1].Missed)
+	}
+	if fired[0].Task == nil || fired[0].Task.Title != "call" {
+		t.Errorf("expected reminder with its task, got %+v", fired[0].Task)
+	}
+
+	next, err := svc.NextReminderAt(ctx)
+	if err != nil {
+		t.Fatalf("NextReminderAt: %v", err)
+	}
+	if want := due.Add(-30 * time.Minute); next == nil || !next.Equal(want) {
+		t.Errorf("expected next reminder at %v, got %v", want, next)
+	}
+
+	if fired, _ := svc.FireDueReminders(ctx, now); len(fired) != 0 {
+		t.Errorf("expected reminders to fire once, got %d again", len(fired))
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// AddTaskRevisions записывает изменения задач в журнал одной транзакцией
+func (s *taskService) AddTaskRevisions(ctx context.Context, revisions []*models.TaskRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	if err := s.repo.AddRevisions(ctx, revisions); err != nil {
+		return fmt.Errorf("failed to record task history: %w", err)
+	}
+
+	return nil
+}
+
+// GetTaskHistory возвращает журнал изменений задачи, последние изменения первыми.
+// Журнал задачи в корзине тоже доступен.
+func (s *taskService) GetTaskHistory(ctx context.Context, id int) ([]*models.TaskRevision, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", id)
+	}
+
+	revisions, err := s.repo.GetRevisions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task history: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func (s *taskService) GetTaskRevision(ctx context.Context, id int) (*models.TaskRevision, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid revision ID: %d", id)
+	}
+
+	revision, err := s.repo.GetRevision(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task revision: %w", err)
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Синтаксис запроса проверяет usecase, где живет разборщик языка фильтров,
+// сервис отвечает за имена и хранение.
+
+func (s *taskService) CreateSavedFilter(ctx context.Context, req *models.CreateSavedFilterRequest) (*models.SavedFilter, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Query = strings.TrimSpace(req.Query)
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if existing, err := s.findSavedFilter(ctx, req.Name); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return nil, fmt.Errorf("saved filter %q already exists", req.Name)
+	}
+
+	filter := &models.SavedFilter{Name: req.Name, Query: req.Query}
+	if err := s.repo.CreateSavedFilter(ctx, filter); err != nil {
+		return nil, fmt.Errorf("failed to create saved filter: %w", err)
+	}
+
+	return filter, nil
+}
+
+func (s *taskService) GetSavedFilter(ctx context.Context, id int) (*models.SavedFilter, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid saved filter ID: %d", id)
+	}
+
+	filter, err := s.repo.GetSavedFilter(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("saved filter not found: %w", err)
+	}
+
+	return filter, nil
+}
+
+// GetSavedFilters возвращает фильтры в порядке создания
+func (s *taskService) GetSavedFilters(ctx context.Context) ([]*models.SavedFilter, error) {
+	filters, err := s.repo.GetSavedFilters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+func (s *taskService) UpdateSavedFilter(ctx context.Context, id int, updates *models.UpdateSavedFilterRequest) (*models.SavedFilter, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid saved filter ID: %d", id)
+	}
+
+	if updates.Name != nil {
+		name := strings.TrimSpace(*updates.Name)
+		updates.Name = &name
+	}
+	if updates.Query != nil {
+		query := strings.TrimSpace(*updates.Query)
+		updates.Query = &query
+	}
+
+	if err := s.validator.Struct(updates); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if updates.Name != nil {
+		if existing, err := s.findSavedFilter(ctx, *updates.Name); err != nil {
+			return nil, err
+		} else if existing != nil && existing.ID != id {
+			return nil, fmt.Errorf("saved filter %q already exists", *updates.Name)
+		}
+	}
+
+	if err := s.repo.UpdateSavedFilter(ctx, id, updates); err != nil {
+		return nil, fmt.Errorf("failed to update saved filter: %w", err)
+	}
+
+	return s.repo.GetSavedFilter(ctx, id)
+}
+
+func (s *taskService) DeleteSavedFilter(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("invalid saved filter ID: %d", id)
+	}
+
+	if err := s.repo.DeleteSavedFilter(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete saved filter: %w", err)
+	}
+
+	return nil
+}
+
+// имена сравниваются без учета регистра, как и в filter:"имя"
+func (s *taskService) findSavedFilter(ctx context.Context, name string) (*models.SavedFilter, error) {
+	filters, err := s.GetSavedFilters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filter := range filters {
+		if strings.EqualFold(filter.Name, name) {
+			return filter, nil
+		}
+	}
+	return nil, nil
+}
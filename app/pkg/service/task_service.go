@@ -1,8 +1,12 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
+	"todo-lits-DMARK/app/pkg/dates"
 	"todo-lits-DMARK/app/pkg/models"
 	"todo-lits-DMARK/app/pkg/repository"
 
@@ -12,6 +16,8 @@ import (
 type taskService struct {
 	repo      repository.TaskRepositoryInterface
 	validator *validator.Validate
+	// зона пользователя для «сегодня», недели и сроков на весь день, по умолчанию системная
+	location atomic.Pointer[time.Location]
 }
 
 func NewTaskService(repo repository.TaskRepositoryInterface) TaskService {
@@ -20,10 +26,12 @@ func NewTaskService(repo repository.TaskRepositoryInterface) TaskService {
 	validator.RegisterValidation("task_status", validateTaskStatus)
 	validator.RegisterValidation("task_priority", validateTaskPriority)
 
-	return &taskService{
+	s := &taskService{
 		repo:      repo,
 		validator: validator,
 	}
+	s.location.Store(time.Local)
+	return s
 }
 func validateTaskStatus(fl validator.FieldLevel) bool {
 	status := fl.Field().String()
@@ -37,92 +45,193 @@ func validateTaskPriority(fl validator.FieldLevel) bool {
 		priority == string(models.TaskPriorityHigh)
 }
 
-func (s *taskService) CreateTask(req *models.CreateTaskRequest) (*models.Task, error) {
+func (s *taskService) CreateTask(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, error) {
+	req.Tags = normalizeTags(req.Tags)
+
 	if err := s.validator.Struct(req); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	recurrence, err := normalizeRecurrence(req.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	if recurrence != "" && req.DueDate == nil {
+		return nil, fmt.Errorf("recurring task requires a due date")
+	}
+	if req.AllDay {
+		if req.DueDate == nil {
+			return nil, fmt.Errorf("all-day task requires a due date")
+		}
+		req.DueDate = s.allDayDue(*req.DueDate)
+	}
+
 	if req.Priority == "" {
 		req.Priority = models.TaskPriorityMedium
 	}
 
+	// подзадача всегда в проекте родителя, без проекта задача попадает во Inbox
+	projectID := 0
+	if req.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *req.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("parent task not found: %w", err)
+		}
+		if req.ProjectID != nil && *req.ProjectID != parent.ProjectID {
+			return nil, fmt.Errorf("subtask must belong to parent project %d", parent.ProjectID)
+		}
+		projectID = parent.ProjectID
+	} else if req.ProjectID != nil {
+		project, err := s.repo.GetProject(ctx, *req.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("project not found: %w", err)
+		}
+		if project.Archived {
+			return nil, fmt.Errorf("cannot add task to archived project %d", project.ID)
+		}
+		projectID = project.ID
+	}
+
 	task := &models.Task{
+		ParentID:    req.ParentID,
+		ProjectID:   projectID,
 		Title:       req.Title,
 		Description: req.Description,
 		Priority:    req.Priority,
 		DueDate:     req.DueDate,
+		AllDay:      req.AllDay,
+		Recurrence:  recurrence,
+		Tags:        req.Tags,
 	}
 
-	if err := s.repo.Create(task); err != nil {
+	if err := s.repo.Create(ctx, task); err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
-	return task, nil
+	return s.repo.GetByID(ctx, task.ID)
 }
-func (s *taskService) GetTask(id int) (*models.Task, error) {
+func (s *taskService) GetTask(ctx context.Context, id int) (*models.Task, error) {
 	if id <= 0 {
 		return nil, fmt.Errorf("invalid task ID: %d", id)
 	}
 
-	task, err := s.repo.GetByID(id)
+	task, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
 	return task, nil
 }
-func (s *taskService) GetAllTasks(filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error) {
-	if sort != nil {
-		if err := s.validator.Struct(sort); err != nil {
-			return nil, fmt.Errorf("invalid sort parameters: %w", err)
-		}
+func (s *taskService) GetAllTasks(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error) {
+	if err := s.validateTaskListing(filter, sort); err != nil {
+		return nil, err
 	}
 
-	tasks, err := s.repo.GetAll(filter, sort)
+	tasks, err := s.repo.GetAll(ctx, filter, sort)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks: %w", err)
 	}
 
 	return tasks, nil
 }
-func (s *taskService) UpdateTask(id int, updates *models.UpdateTaskRequest) (*models.Task, error) {
+
+// validateTaskListing проверяет сортировку и нормализует теги фильтра
+func (s *taskService) validateTaskListing(filter *models.TaskFilter, sort *models.TaskSort) error {
+	if sort != nil {
+		if err := s.validator.Struct(sort); err != nil {
+			return fmt.Errorf("invalid sort parameters: %w", err)
+		}
+	}
+
+	if filter != nil && len(filter.Tags) > 0 {
+		switch filter.TagMatch {
+		case "", models.TagMatchAny, models.TagMatchAll:
+		default:
+			return fmt.Errorf("invalid tag match mode: %s", filter.TagMatch)
+		}
+		filter.Tags = normalizeTags(filter.Tags)
+	}
+
+	return nil
+}
+
+func (s *taskService) UpdateTask(ctx context.Context, id int, updates *models.UpdateTaskRequest) (*models.Task, error) {
 	if id <= 0 {
 		return nil, fmt.Errorf("invalid task ID: %d", id)
 	}
 
+	if updates.Tags != nil {
+		tags := normalizeTags(*updates.Tags)
+		updates.Tags = &tags
+	}
+
 	if err := s.validator.Struct(updates); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
+	if err := s.normalizeDueUpdate(updates); err != nil {
+		return nil, err
+	}
 
-	if _, err := s.repo.GetByID(id); err != nil {
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
 		return nil, fmt.Errorf("task not found: %w", err)
 	}
+	if updates.Version != nil && *updates.Version != task.Version {
+		return nil, &ConflictError{Expected: *updates.Version, Current: task}
+	}
+
+	if updates.Recurrence != nil {
+		recurrence, err := normalizeRecurrence(*updates.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+		if recurrence != "" && task.DueDate == nil && updates.DueDate == nil {
+			return nil, fmt.Errorf("recurring task requires a due date")
+		}
+		updates.Recurrence = &recurrence
+	}
 
-	if err := s.repo.Update(id, updates); err != nil {
+	if err := s.repo.Update(ctx, id, updates); err != nil {
+		// задачу изменили между чтением и записью
+		if errors.Is(err, repository.ErrVersionConflict) {
+			if current, getErr := s.repo.GetByID(ctx, id); getErr == nil {
+				return nil, &ConflictError{Expected: *updates.Version, Current: current}
+			}
+		}
 		return nil, fmt.Errorf("failed to update task: %w", err)
 	}
 
-	return s.repo.GetByID(id)
+	return s.repo.GetByID(ctx, id)
 }
-func (s *taskService) DeleteTask(id int) error {
+func (s *taskService) DeleteTask(ctx context.Context, id int) error {
 	if id <= 0 {
 		return fmt.Errorf("invalid task ID: %d", id)
 	}
 
-	if err := s.repo.Delete(id); err != nil {
+	if err := s.repo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
 
 	return nil
 }
-func (s *taskService) ToggleTaskStatus(id int) (*models.Task, error) {
+
+// mode влияет только на завершение: открытые подзадачи игнорируются, завершаются или блокируют операцию.
+// Завершение повторяющейся задачи создает следующую задачу серии, она возвращается в next.
+// Подзадачи, сама задача и следующая задача серии сохраняются одной транзакцией.
+func (s *taskService) ToggleTaskStatus(ctx context.Context, id int, mode models.SubtaskMode) (task *models.Task, next *models.Task, err error) {
 	if id <= 0 {
-		return nil, fmt.Errorf("invalid task ID: %d", id)
+		return nil, nil, fmt.Errorf("invalid task ID: %d", id)
+	}
+
+	switch mode {
+	case "", models.SubtaskModeNone, models.SubtaskModeComplete, models.SubtaskModeBlock:
+	default:
+		return nil, nil, fmt.Errorf("invalid subtask mode: %s", mode)
 	}
 
-	task, err := s.repo.GetByID(id)
+	task, err = s.repo.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get task: %w", err)
+		return nil, nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
 	var newStatus models.TaskStatus
@@ -132,74 +241,249 @@ func (s *taskService) ToggleTaskStatus(id int) (*models.Task, error) {
 		newStatus = models.TaskStatusPending
 	}
 
+	var changes []*models.TaskUpdate
+	if newStatus == models.TaskStatusCompleted && (mode == models.SubtaskModeComplete || mode == models.SubtaskModeBlock) {
+		if changes, err = s.resolveOpenSubtasks(ctx, id, mode); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// версия защищает от изменений между чтением и транзакцией, в том числе от повторного завершения
 	updates := &models.UpdateTaskRequest{
-		Status: &newStatus,
+		Status:  &newStatus,
+		Version: &task.Version,
 	}
 
-	if err := s.repo.Update(id, updates); err != nil {
-		return nil, fmt.Errorf("failed to toggle task status: %w", err)
+	var created []*models.Task
+	if newStatus == models.TaskStatusCompleted && task.Recurrence != "" && task.DueDate != nil {
+		// правило переходит к следующей задаче, поэтому повторное завершение старой не создает дубликат
+		next, err := nextOccurrenceTask(task, s.now())
+		if err != nil {
+			return nil, nil, err
+		}
+		if next != nil {
+			noRecurrence := ""
+			updates.Recurrence = &noRecurrence
+			created = append(created, next)
+		}
+	}
+	changes = append(changes, &models.TaskUpdate{ID: id, Changes: updates})
+
+	if err := s.repo.BulkUpdate(ctx, changes, created); err != nil {
+		return nil, nil, fmt.Errorf("failed to toggle task status: %w", err)
+	}
+
+	task, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(created) > 0 {
+		if next, err = s.repo.GetByID(ctx, created[0].ID); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return s.repo.GetByID(id)
+	return task, next, nil
 }
-func (s *taskService) GetOverdueTasks() ([]*models.Task, error) {
-	tasks, err := s.repo.GetOverdue()
+func (s *taskService) GetOverdueTasks(ctx context.Context) ([]*models.Task, error) {
+	tasks, err := s.repo.GetOverdue(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get overdue tasks: %w", err)
 	}
 
-	return tasks, nil
+	return s.withoutArchived(ctx, tasks)
 }
-func (s *taskService) GetTasksByDateFilter(dateFilter string) ([]*models.Task, error) {
-	now := time.Now()
+func (s *taskService) GetTasksByDateFilter(ctx context.Context, dateFilter string) ([]*models.Task, error) {
+	now := s.now()
 	var from, to time.Time
 
 	switch dateFilter {
 	case "today":
-		from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		to = from.Add(24 * time.Hour).Add(-time.Second)
+		from, to = dates.DayRange(now)
 	case "week":
-
-		weekday := int(now.Weekday())
-		if weekday == 0 {
-			weekday = 7
-		}
-		from = now.AddDate(0, 0, -(weekday - 1))
-		from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
-		to = from.AddDate(0, 0, 7).Add(-time.Second)
+		from, to = dates.WeekRange(now)
 	case "overdue":
-		return s.GetOverdueTasks()
+		return s.GetOverdueTasks(ctx)
 	default:
 		return nil, fmt.Errorf("invalid date filter: %s", dateFilter)
 	}
 
-	tasks, err := s.repo.GetByDateRange(from, to)
+	tasks, err := s.repo.GetByDateRange(ctx, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks by date filter: %w", err)
 	}
 
-	return tasks, nil
+	return s.withoutArchived(ctx, tasks)
 }
-func (s *taskService) GetTaskStats() (*TaskStats, error) {
-	allTasks, err := s.repo.GetAll(nil, nil)
+
+// GetTaskStats считает задачи одним агрегирующим запросом, строки задач не загружаются
+func (s *taskService) GetTaskStats(ctx context.Context) (*TaskStats, error) {
+	now := s.now()
+	periods := &models.StatsPeriods{Now: now}
+	periods.TodayFrom, periods.TodayTo = dates.DayRange(now)
+	periods.WeekFrom, periods.WeekTo = dates.WeekRange(now)
+
+	groups, err := s.repo.CountTasks(ctx, periods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks for stats: %w", err)
+	}
+
+	projects, err := s.GetProjects(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks for stats: %w", err)
+		return nil, fmt.Errorf("failed to get projects for stats: %w", err)
 	}
 
-	stats := &TaskStats{}
-	stats.Total = len(allTasks)
+	stats := &TaskStats{ByPriority: map[models.TaskPriority]int{}}
+	byProject := make(map[int]*ProjectStats, len(projects))
+	for _, project := range projects {
+		projectStats := &ProjectStats{
+			ProjectID: project.ID,
+			Name:      project.Name,
+			Color:     project.Color,
+			Archived:  project.Archived,
+		}
+		byProject[project.ID] = projectStats
+		stats.Projects = append(stats.Projects, projectStats)
+	}
 
-	for _, task := range allTasks {
-		switch task.Status {
+	for _, group := range groups {
+		projectStats := byProject[group.ProjectID]
+		if projectStats == nil {
+			continue
+		}
+		projectStats.Total += group.Count
+		projectStats.Overdue += group.Overdue
+
+		switch group.Status {
 		case models.TaskStatusPending:
-			stats.Pending++
-			if task.IsOverdue() {
-				stats.Overdue++
-			}
+			projectStats.Pending += group.Count
 		case models.TaskStatusCompleted:
-			stats.Completed++
+			projectStats.Completed += group.Count
+		}
+
+		if projectStats.Archived {
+			continue
 		}
+		stats.DueToday += group.DueToday
+		stats.DueThisWeek += group.DueThisWeek
+		if group.Status == models.TaskStatusPending {
+			stats.ByPriority[group.Priority] += group.Count
+		}
+	}
+
+	for _, projectStats := range stats.Projects {
+		if projectStats.Archived {
+			continue
+		}
+		stats.Total += projectStats.Total
+		stats.Pending += projectStats.Pending
+		stats.Completed += projectStats.Completed
+		stats.Overdue += projectStats.Overdue
 	}
 
 	return stats, nil
 }
+
+// resolveOpenSubtasks проверяет открытые подзадачи id и возвращает их завершение для той же транзакции
+func (s *taskService) resolveOpenSubtasks(ctx context.Context, id int, mode models.SubtaskMode) ([]*models.TaskUpdate, error) {
+	subtree, err := s.repo.GetSubtree(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+
+	var open []*models.Task
+	for _, task := range subtree[1:] {
+		if task.Status == models.TaskStatusPending {
+			open = append(open, task)
+		}
+	}
+	if len(open) == 0 {
+		return nil, nil
+	}
+
+	if mode == models.SubtaskModeBlock {
+		return nil, fmt.Errorf("cannot complete task %d: %d open: %w", id, len(open), ErrOpenSubtasks)
+	}
+
+	completed := models.TaskStatusCompleted
+	changes := make([]*models.TaskUpdate, len(open))
+	for i, task := range open {
+		changes[i] = &models.TaskUpdate{ID: task.ID, Changes: &models.UpdateTaskRequest{Status: &completed, Version: &task.Version}}
+	}
+
+	return changes, nil
+}
+
+func (s *taskService) GetTaskTree(ctx context.Context, id int) (*models.TaskNode, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", id)
+	}
+
+	subtree, err := s.repo.GetSubtree(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task tree: %w", err)
+	}
+
+	return models.BuildTaskForest(subtree)[0], nil
+}
+
+// MoveTask переносит задачу к новому родителю, nil делает ее корневой
+func (s *taskService) MoveTask(ctx context.Context, id int, parentID *int) (*models.Task, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", id)
+	}
+
+	if parentID != nil {
+		if _, err := s.repo.GetByID(ctx, *parentID); err != nil {
+			return nil, fmt.Errorf("parent task not found: %w", err)
+		}
+
+		subtree, err := s.repo.GetSubtree(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("task not found: %w", err)
+		}
+		for _, task := range subtree {
+			if task.ID == *parentID {
+				return nil, fmt.Errorf("cannot move task %d under its own subtask %d", id, *parentID)
+			}
+		}
+	}
+
+	// поддерево переезжает в проект нового родителя в той же транзакции
+	if err := s.repo.SetParent(ctx, id, parentID); err != nil {
+		return nil, fmt.Errorf("failed to move task: %w", err)
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// DeleteTaskKeepingSubtasks поднимает прямых потомков на уровень удаляемой задачи
+// и возвращает их в новом состоянии
+func (s *taskService) DeleteTaskKeepingSubtasks(ctx context.Context, id int) ([]*models.Task, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", id)
+	}
+
+	subtree, err := s.repo.GetSubtree(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	if err := s.repo.DeleteKeepingSubtasks(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	var moved []*models.Task
+	for _, task := range subtree[1:] {
+		if task.ParentID == nil || *task.ParentID != id {
+			continue
+		}
+		updated, err := s.repo.GetByID(ctx, task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reload subtask %d: %w", task.ID, err)
+		}
+		moved = append(moved, updated)
+	}
+
+	return moved, nil
+}
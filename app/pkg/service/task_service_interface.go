@@ -1,21 +1,152 @@
 package service
 
-import "todo-lits-DMARK/app/pkg/models"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// ErrOpenSubtasks возвращается при завершении задачи с открытыми подзадачами в режиме block
+var ErrOpenSubtasks = errors.New("task has open subtasks")
+
+// ErrInvalidRecurrence возвращается для некорректного или неподдерживаемого правила повторения
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// ErrInvalidSearchQuery возвращается, если строку поиска не удалось разобрать
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// ErrInvalidQuickAdd возвращается, если в строке быстрого добавления не осталось названия задачи
+var ErrInvalidQuickAdd = errors.New("invalid quick add text")
+
+// ErrInvalidCursor возвращается для курсора, выданного другой сортировке или поврежденного
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// ErrInboxProject возвращается при попытке удалить или архивировать Inbox
+var ErrInboxProject = errors.New("inbox project cannot be removed")
+
+// ErrVersionConflict оборачивается ConflictError
+var ErrVersionConflict = errors.New("task was modified by someone else")
+
+// ConflictError возвращается UpdateTask и ApplyTaskSnapshots, если задачу изменили после того, как клиент
+// прочитал версию Expected. Current — актуальная копия задачи для слияния.
+type ConflictError struct {
+	Expected int
+	Current  *models.Task
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("task %d was modified: version %d, expected %d", e.Current.ID, e.Current.Version, e.Expected)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
 
 type TaskService interface {
-	CreateTask(req *models.CreateTaskRequest) (*models.Task, error)
-	GetTask(id int) (*models.Task, error)
-	GetAllTasks(filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error)
-	UpdateTask(id int, updates *models.UpdateTaskRequest) (*models.Task, error)
-	DeleteTask(id int) error
-	ToggleTaskStatus(id int) (*models.Task, error)
-	GetOverdueTasks() ([]*models.Task, error)
-	GetTasksByDateFilter(dateFilter string) ([]*models.Task, error)
-	GetTaskStats() (*TaskStats, error)
+	CreateTask(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, error)
+	GetTask(ctx context.Context, id int) (*models.Task, error)
+	GetAllTasks(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort) ([]*models.Task, error)
+	GetTasksPage(ctx context.Context, filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error)
+	UpdateTask(ctx context.Context, id int, updates *models.UpdateTaskRequest) (*models.Task, error)
+	DeleteTask(ctx context.Context, id int) error
+	ToggleTaskStatus(ctx context.Context, id int, mode models.SubtaskMode) (task *models.Task, next *models.Task, err error)
+	GetOverdueTasks(ctx context.Context) ([]*models.Task, error)
+	GetTasksByDateFilter(ctx context.Context, dateFilter string) ([]*models.Task, error)
+	GetTaskStats(ctx context.Context) (*TaskStats, error)
+	GetUpcomingOccurrences(ctx context.Context) ([]*models.Occurrence, error)
+	SearchTasks(ctx context.Context, query string, page models.PageRequest) (*models.SearchPage, error)
+
+	// корзина
+	GetTrash(ctx context.Context) ([]*models.Task, error)
+	RestoreTask(ctx context.Context, id int) ([]*models.Task, error)
+	PurgeTask(ctx context.Context, id int) error
+	EmptyTrash(ctx context.Context) (int, error)
+	PurgeExpiredTrash(ctx context.Context, retention time.Duration) (int, error)
+
+	// пакетные операции: все задачи меняются одной транзакцией или не меняется ни одна.
+	// Возвращаются задачи после изменения; BulkSetStatus отдельно возвращает созданные повторения.
+	BulkUpdateTasks(ctx context.Context, ids []int, updates *models.UpdateTaskRequest) ([]*models.Task, error)
+	BulkSetStatus(ctx context.Context, ids []int, status models.TaskStatus) (tasks []*models.Task, created []*models.Task, err error)
+	BulkTagTasks(ctx context.Context, ids []int, add, remove []string) ([]*models.Task, error)
+	BulkMoveToProject(ctx context.Context, ids []int, projectID int) error
+	BulkDeleteTasks(ctx context.Context, ids []int) error
+
+	// снимки задач для отмены операций, включая задачи в корзине
+	GetTaskSnapshots(ctx context.Context, ids []int) ([]*models.Task, error)
+	ApplyTaskSnapshots(ctx context.Context, tasks []*models.Task) error
+
+	// журнал изменений задач
+	AddTaskRevisions(ctx context.Context, revisions []*models.TaskRevision) error
+	GetTaskHistory(ctx context.Context, id int) ([]*models.TaskRevision, error)
+	GetTaskRevision(ctx context.Context, id int) (*models.TaskRevision, error)
+
+	// напоминания: FireDueReminders отдает каждое наступившее напоминание один раз,
+	// ReleaseReminder возвращает недоставленное
+	AddReminder(ctx context.Context, taskID int, req *models.CreateReminderRequest) (*models.Reminder, error)
+	GetReminders(ctx context.Context, taskID int) ([]*models.Reminder, error)
+	DeleteReminder(ctx context.Context, id int) error
+	FireDueReminders(ctx context.Context, now time.Time) ([]*models.DueReminder, error)
+	ReleaseReminder(ctx context.Context, id int) error
+	NextReminderAt(ctx context.Context) (*time.Time, error)
+	// SnoozeTask переносит срок открытой задачи на until вместе с напоминаниями
+	SnoozeTask(ctx context.Context, id int, until time.Time) (*models.Task, error)
+
+	// ParseQuickAdd разбирает строку вида "Call Bob tomorrow 3pm !high #work" без сохранения задачи
+	ParseQuickAdd(ctx context.Context, text string) (*models.CreateTaskRequest, error)
+
+	// часовой пояс пользователя: в нем считаются «сегодня», неделя, повторения и сроки на весь день
+	Location() *time.Location
+	SetLocation(loc *time.Location)
+
+	// иерархия задач
+	GetTaskTree(ctx context.Context, id int) (*models.TaskNode, error)
+	MoveTask(ctx context.Context, id int, parentID *int) (*models.Task, error)
+	DeleteTaskKeepingSubtasks(ctx context.Context, id int) ([]*models.Task, error)
+
+	// теги
+	CreateTag(ctx context.Context, name string) (*models.Tag, error)
+	GetTags(ctx context.Context) ([]*models.Tag, error)
+	RenameTag(ctx context.Context, id int, name string) (*models.Tag, error)
+	DeleteTag(ctx context.Context, id int) error
+
+	// проекты
+	CreateProject(ctx context.Context, req *models.CreateProjectRequest) (*models.Project, error)
+	GetProjects(ctx context.Context) ([]*models.Project, error)
+	UpdateProject(ctx context.Context, id int, updates *models.UpdateProjectRequest) (*models.Project, error)
+	ReorderProjects(ctx context.Context, ids []int) error
+	DeleteProject(ctx context.Context, id int) error
+	MoveTaskToProject(ctx context.Context, id int, projectID int) (*models.Task, error)
+
+	// сохраненные фильтры
+	CreateSavedFilter(ctx context.Context, req *models.CreateSavedFilterRequest) (*models.SavedFilter, error)
+	GetSavedFilter(ctx context.Context, id int) (*models.SavedFilter, error)
+	GetSavedFilters(ctx context.Context) ([]*models.SavedFilter, error)
+	UpdateSavedFilter(ctx context.Context, id int, updates *models.UpdateSavedFilterRequest) (*models.SavedFilter, error)
+	DeleteSavedFilter(ctx context.Context, id int) error
 }
+
+// TaskStats не учитывает задачи архивных проектов, по проектам считаются все.
+// DueToday, DueThisWeek и ByPriority считают только открытые задачи.
 type TaskStats struct {
-	Total     int `json:"total"`
-	Pending   int `json:"pending"`
-	Completed int `json:"completed"`
-	Overdue   int `json:"overdue"`
+	Total       int                         `json:"total"`
+	Pending     int                         `json:"pending"`
+	Completed   int                         `json:"completed"`
+	Overdue     int                         `json:"overdue"`
+	DueToday    int                         `json:"due_today"`
+	DueThisWeek int                         `json:"due_this_week"`
+	ByPriority  map[models.TaskPriority]int `json:"by_priority"`
+	Projects    []*ProjectStats             `json:"projects"`
+}
+
+type ProjectStats struct {
+	ProjectID int    `json:"project_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Archived  bool   `json:"archived"`
+	Total     int    `json:"total"`
+	Pending   int    `json:"pending"`
+	Completed int    `json:"completed"`
+	Overdue   int    `json:"overdue"`
 }
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+)
+
+func newTestService() TaskService {
+	return NewTaskService(repository.NewMemoryTaskRepository())
+}
+
+func TestCreateTask(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.ID == 0 || task.Status != models.TaskStatusPending {
+		t.Errorf("unexpected task: %+v", task)
+	}
+}
+
+func TestCreateTaskValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	tests := []struct {
+		name string
+		req  *models.CreateTaskRequest
+	}{
+		{"empty title", &models.CreateTaskRequest{Priority: models.TaskPriorityLow}},
+		{"unknown priority", &models.CreateTaskRequest{Title: "task", Priority: "urgent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateTask(ctx, tt.req); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestUpdateTaskNotFound(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	title := "renamed"
+	_, err := svc.UpdateTask(ctx, 42, &models.UpdateTaskRequest{Title: &title})
+	if !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestUpdateTaskVersionConflict(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	stale := task.Version
+	mine, theirs := "mine", "theirs"
+	if _, err := svc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &theirs, Version: &stale}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	_, err = svc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &mine, Version: &stale})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if conflict.Expected != stale || conflict.Current.Title != theirs || conflict.Current.Version != stale+1 {
+		t.Errorf("conflict must carry the current task: %+v", conflict.Current)
+	}
+
+	// клиент сливает изменения и повторяет запрос с актуальной версией
+	updated, err := svc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &mine, Version: &conflict.Current.Version})
+	if err != nil {
+		t.Fatalf("UpdateTask with current version: %v", err)
+	}
+	if updated.Title != mine {
+		t.Errorf("expected title %q, got %q", mine, updated.Title)
+	}
+}
+
+func TestToggleTaskStatus(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityMedium})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	toggled, _, err := svc.ToggleTaskStatus(ctx, task.ID, models.SubtaskModeNone)
+	if err != nil {
+		t.Fatalf("ToggleTaskStatus: %v", err)
+	}
+	if toggled.Status != models.TaskStatusCompleted {
+		t.Fatalf("expected completed, got %q", toggled.Status)
+	}
+
+	toggled, _, err = svc.ToggleTaskStatus(ctx, task.ID, models.SubtaskModeNone)
+	if err != nil {
+		t.Fatalf("ToggleTaskStatus: %v", err)
+	}
+	if toggled.Status != models.TaskStatusPending {
+		t.Fatalf("expected pending, got %q", toggled.Status)
+	}
+}
+
+func TestGetTaskStats(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryTaskRepository()
+	svc := NewTaskService(repo)
+
+	past := time.Now().Add(-time.Hour)
+	for _, task := range []*models.Task{
+		{Title: "overdue", Priority: models.TaskPriorityHigh, DueDate: &past},
+		{Title: "pending", Priority: models.TaskPriorityLow},
+		{Title: "done", Priority: models.TaskPriorityLow, DueDate: &past},
+	} {
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if _, _, err := svc.ToggleTaskStatus(ctx, 3, models.SubtaskModeNone); err != nil {
+		t.Fatalf("ToggleTaskStatus: %v", err)
+	}
+
+	stats, err := svc.GetTaskStats(ctx)
+	if err != nil {
+		t.Fatalf("GetTaskStats: %v", err)
+	}
+
+	want := [4]int{3, 2, 1, 1}
+	got := [4]int{stats.Total, stats.Pending, stats.Completed, stats.Overdue}
+	if got != want {
+		t.Errorf("expected total/pending/completed/overdue %v, got %v", want, got)
+	}
+	if len(stats.Projects) != 1 || stats.Projects[0].Total != 3 || stats.Projects[0].Overdue != 1 {
+		t.Errorf("expected all tasks in inbox stats, got %+v", stats.Projects)
+	}
+	if stats.ByPriority[models.TaskPriorityHigh] != 1 || stats.ByPriority[models.TaskPriorityLow] != 1 {
+		t.Errorf("expected one open task of each used priority, got %v", stats.ByPriority)
+	}
+}
+
+func TestGetTasksByDateFilterRejectsUnknown(t *testing.T) {
+	if _, err := newTestService().GetTasksByDateFilter(context.Background(), "month"); err == nil {
+		t.Fatal("expected error for unknown filter")
+	}
+}
+
+func mustCreateSubtask(t *testing.T, svc TaskService, parentID int, title string) *models.Task {
+	t.Helper()
+	ctx := context.Background()
+
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{ParentID: &parentID, Title: title, Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask(%q): %v", title, err)
+	}
+	return task
+}
+
+func TestToggleTaskStatusSubtaskModes(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	parent, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "parent", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	child := mustCreateSubtask(t, svc, parent.ID, "child")
+	grandchild := mustCreateSubtask(t, svc, child.ID, "grandchild")
+
+	if _, _, err := svc.ToggleTaskStatus(ctx, parent.ID, "cascade"); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+
+	if _, _, err := svc.ToggleTaskStatus(ctx, parent.ID, models.SubtaskModeBlock); !errors.Is(err, ErrOpenSubtasks) {
+		t.Fatalf("expected ErrOpenSubtasks, got %v", err)
+	}
+
+	if _, _, err := svc.ToggleTaskStatus(ctx, parent.ID, models.SubtaskModeComplete); err != nil {
+		t.Fatalf("ToggleTaskStatus: %v", err)
+	}
+	tree, err := svc.GetTaskTree(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("GetTaskTree: %v", err)
+	}
+	if tree.CompletedChildren != 1 || tree.TotalChildren != 1 {
+		t.Errorf("expected 1/1 completed children, got %d/%d", tree.CompletedChildren, tree.TotalChildren)
+	}
+	if got := tree.Children[0].Children[0].Task; got.ID != grandchild.ID || got.Status != models.TaskStatusCompleted {
+		t.Errorf("expected grandchild to be completed, got %+v", got)
+	}
+}
+
+// failingBulkRepository отклоняет транзакцию целиком, как база при ошибке
+type failingBulkRepository struct {
+	repository.TaskRepositoryInterface
+}
+
+func (r failingBulkRepository) BulkUpdate(context.Context, []*models.TaskUpdate, []*models.Task) error {
+	return errors.New("transaction failed")
+}
+
+func TestToggleTaskStatusIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryTaskRepository()
+	svc := NewTaskService(failingBulkRepository{repo})
+
+	due := time.Now().Add(time.Hour)
+	parent, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "parent", Priority: models.TaskPriorityLow, DueDate: &due, Recurrence: "FREQ=DAILY"})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	child := mustCreateSubtask(t, svc, parent.ID, "child")
+
+	if _, _, err := svc.ToggleTaskStatus(ctx, parent.ID, models.SubtaskModeComplete); err == nil {
+		t.Fatal("expected toggle to fail")
+	}
+
+	// ни подзадача, ни задача не завершены, следующая задача серии не создана
+	tasks, err := repo.GetAll(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected no new occurrence, got %d tasks", len(tasks))
+	}
+	for _, task := range tasks {
+		if task.Status != models.TaskStatusPending {
+			t.Errorf("expected task %d (child %d) to stay pending", task.ID, child.ID)
+		}
+		if task.ID == parent.ID && task.Recurrence == "" {
+			t.Error("expected parent to keep its recurrence rule")
+		}
+	}
+}
+
+func TestMoveTaskRejectsCycles(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	root, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "root", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	child := mustCreateSubtask(t, svc, root.ID, "child")
+
+	if _, err := svc.MoveTask(ctx, root.ID, &child.ID); err == nil {
+		t.Fatal("expected error moving task under its subtask")
+	}
+	if _, err := svc.MoveTask(ctx, root.ID, &root.ID); err == nil {
+		t.Fatal("expected error moving task under itself")
+	}
+
+	moved, err := svc.MoveTask(ctx, child.ID, nil)
+	if err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+	if moved.ParentID != nil {
+		t.Errorf("expected root task, got parent %d", *moved.ParentID)
+	}
+}
+
+func TestDeleteTaskKeepingSubtasks(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	root, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "root", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	middle := mustCreateSubtask(t, svc, root.ID, "middle")
+	leaf := mustCreateSubtask(t, svc, middle.ID, "leaf")
+
+	moved, err := svc.DeleteTaskKeepingSubtasks(ctx, middle.ID)
+	if err != nil {
+		t.Fatalf("DeleteTaskKeepingSubtasks: %v", err)
+	}
+	if len(moved) != 1 || moved[0].ID != leaf.ID || moved[0].ParentID == nil || *moved[0].ParentID != root.ID {
+		t.Fatalf("expected leaf to move under root, got %+v", moved)
+	}
+
+	if _, err := svc.GetTask(ctx, middle.ID); !errors.Is(err, repository.ErrTaskNotFound) {
+		t.Errorf("expected middle task to be deleted, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+)
+
+// GetTaskSnapshots возвращает полное состояние задач по id, задачи из корзины тоже.
+// Удаленных навсегда задач в результате нет.
+func (s *taskService) GetTaskSnapshots(ctx context.Context, ids []int) ([]*models.Task, error) {
+	tasks, err := s.repo.GetSnapshots(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task snapshots: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ApplyTaskSnapshots записывает состояние задач одной транзакцией: либо все, либо ни одной.
+// Если задачу изменили после чтения снимка с версией Version, возвращается ConflictError.
+func (s *taskService) ApplyTaskSnapshots(ctx context.Context, tasks []*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	if err := s.repo.ApplySnapshots(ctx, tasks); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			if conflict := s.snapshotConflict(ctx, tasks); conflict != nil {
+				return conflict
+			}
+		}
+		return fmt.Errorf("failed to apply task snapshots: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotConflict находит первую задачу, версия которой разошлась со снимком
+func (s *taskService) snapshotConflict(ctx context.Context, tasks []*models.Task) *ConflictError {
+	ids := make([]int, len(tasks))
+	expected := make(map[int]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+		expected[task.ID] = task.Version
+	}
+
+	current, err := s.repo.GetSnapshots(ctx, ids)
+	if err != nil {
+		return nil
+	}
+	for _, task := range current {
+		if task.Version != expected[task.ID] {
+			return &ConflictError{Expected: expected[task.ID], Current: task}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+)
+
+func (s *taskService) CreateTag(ctx context.Context, name string) (*models.Tag, error) {
+	tag := &models.Tag{Name: normalizeTagName(name)}
+	if err := s.validator.Struct(tag); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if existing, err := s.findTag(ctx, tag.Name); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return nil, fmt.Errorf("tag %q already exists", tag.Name)
+	}
+
+	if err := s.repo.CreateTag(ctx, tag); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// GetTags возвращает теги по алфавиту с количеством задач у каждого
+func (s *taskService) GetTags(ctx context.Context) ([]*models.Tag, error) {
+	tags, err := s.repo.GetTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (s *taskService) RenameTag(ctx context.Context, id int, name string) (*models.Tag, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid tag ID: %d", id)
+	}
+
+	renamed := &models.Tag{ID: id, Name: normalizeTagName(name)}
+	if err := s.validator.Struct(renamed); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if existing, err := s.findTag(ctx, renamed.Name); err != nil {
+		return nil, err
+	} else if existing != nil && existing.ID != id {
+		return nil, fmt.Errorf("tag %q already exists", renamed.Name)
+	}
+
+	if err := s.repo.RenameTag(ctx, id, renamed.Name); err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	tag, err := s.findTag(ctx, renamed.Name)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, fmt.Errorf("tag with id %d %w", id, repository.ErrTagNotFound)
+	}
+
+	return tag, nil
+}
+
+// связи с задачами удаляются вместе с тегом, сами задачи остаются
+func (s *taskService) DeleteTag(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("invalid tag ID: %d", id)
+	}
+
+	if err := s.repo.DeleteTag(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return nil
+}
+
+func (s *taskService) findTag(ctx context.Context, name string) (*models.Tag, error) {
+	tags, err := s.GetTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range tags {
+		if tag.Name == name {
+			return tag, nil
+		}
+	}
+	return nil, nil
+}
+
+// теги сравниваются без учета регистра и пробелов по краям, поэтому хранятся в нижнем регистре
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags приводит имена к нижнему регистру, убирает пустые и повторы и сортирует
+func normalizeTags(names []string) []string {
+	if names == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(names))
+	tags := []string{}
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, name)
+	}
+	sort.Strings(tags)
+
+	return tags
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestCreateTaskNormalizesTags(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{
+		Title:    "task",
+		Priority: models.TaskPriorityLow,
+		Tags:     []string{" Work", "home", "work", ""},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if len(task.Tags) != 2 || task.Tags[0] != "home" || task.Tags[1] != "work" {
+		t.Errorf("expected [home work], got %v", task.Tags)
+	}
+
+	tasks, err := svc.GetAllTasks(ctx, &models.TaskFilter{Tags: []string{"WORK"}}, nil)
+	if err != nil {
+		t.Fatalf("GetAllTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Errorf("expected tag filter to ignore case, got %+v", tasks)
+	}
+
+	if _, err := svc.GetAllTasks(ctx, &models.TaskFilter{Tags: []string{"work"}, TagMatch: "some"}, nil); err == nil {
+		t.Error("expected error for unknown tag match mode")
+	}
+}
+
+func TestTagCRUD(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	tag, err := svc.CreateTag(ctx, "  Work ")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if tag.Name != "work" {
+		t.Errorf("expected normalized name, got %q", tag.Name)
+	}
+	if _, err := svc.CreateTag(ctx, "WORK"); err == nil {
+		t.Error("expected error for duplicate tag")
+	}
+	if _, err := svc.CreateTag(ctx, "   "); err == nil {
+		t.Error("expected validation error for empty name")
+	}
+
+	home, err := svc.CreateTag(ctx, "home")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if _, err := svc.RenameTag(ctx, home.ID, "Work"); err == nil {
+		t.Error("expected error renaming to an existing tag")
+	}
+
+	renamed, err := svc.RenameTag(ctx, home.ID, "Family")
+	if err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if renamed.ID != home.ID || renamed.Name != "family" {
+		t.Errorf("unexpected renamed tag: %+v", renamed)
+	}
+
+	if err := svc.DeleteTag(ctx, tag.ID); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	tags, err := svc.GetTags(ctx)
+	if err != nil {
+		t.Fatalf("GetTags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Name != "family" {
+		t.Errorf("expected only family tag, got %+v", tags)
+	}
+}
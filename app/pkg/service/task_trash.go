@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// emptyTrashMargin — запас для EmptyTrash: база округляет время удаления,
+// и задача, удаленная только что, не должна остаться в корзине
+const emptyTrashMargin = time.Second
+
+// GetTrash возвращает задачи, удаленные самостоятельно; их подзадачи восстанавливаются вместе с ними
+func (s *taskService) GetTrash(ctx context.Context) ([]*models.Task, error) {
+	tasks, err := s.repo.GetTrash(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask возвращает задачу из корзины и отдает восстановленное поддерево
+func (s *taskService) RestoreTask(ctx context.Context, id int) ([]*models.Task, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid task ID: %d", id)
+	}
+
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	subtree, err := s.repo.GetSubtree(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload restored task: %w", err)
+	}
+
+	return subtree, nil
+}
+
+// PurgeTask удаляет задачу из корзины навсегда
+func (s *taskService) PurgeTask(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("invalid task ID: %d", id)
+	}
+
+	if err := s.repo.Purge(ctx, id); err != nil {
+		return fmt.Errorf("failed to purge task: %w", err)
+	}
+
+	return nil
+}
+
+func (s *taskService) EmptyTrash(ctx context.Context) (int, error) {
+	count, err := s.repo.PurgeDeleted(ctx, time.Now().Add(emptyTrashMargin))
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+
+	return count, nil
+}
+
+// PurgeExpiredTrash удаляет навсегда задачи, пролежавшие в корзине дольше retention.
+// retention <= 0 отключает очистку.
+func (s *taskService) PurgeExpiredTrash(ctx context.Context, retention time.Duration) (int, error) {
+	if retention <= 0 {
+		return 0, nil
+	}
+
+	count, err := s.repo.PurgeDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired trash: %w", err)
+	}
+
+	return count, nil
+}
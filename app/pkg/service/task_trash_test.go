@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestTrashRestoreAndPurge(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+
+	parent, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "parent", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "child", Priority: models.TaskPriorityLow, ParentID: &parent.ID}); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if err := svc.DeleteTask(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "orphan", Priority: models.TaskPriorityLow, ParentID: &parent.ID}); err == nil {
+		t.Error("expected subtask of trashed task to be rejected")
+	}
+
+	restored, err := svc.RestoreTask(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	if len(restored) != 2 || restored[0].ID != parent.ID {
+		t.Fatalf("expected task with subtask restored, got %+v", restored)
+	}
+
+	if err := svc.DeleteTask(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	// свежая корзина не попадает под автоочистку
+	for _, retention := range []time.Duration{0, time.Hour} {
+		count, err := svc.PurgeExpiredTrash(ctx, retention)
+		if err != nil || count != 0 {
+			t.Errorf("PurgeExpiredTrash(%v): expected nothing purged, got %d, %v", retention, count, err)
+		}
+	}
+
+	count, err := svc.EmptyTrash(ctx)
+	if err != nil {
+		t.Fatalf("EmptyTrash: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected task and subtask purged, got %d", count)
+	}
+
+	trash, err := svc.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	if len(trash) != 0 {
+		t.Errorf("expected empty trash, got %d tasks", len(trash))
+	}
+}
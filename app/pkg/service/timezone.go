@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/dates"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// Location возвращает часовой пояс пользователя
+func (s *taskService) Location() *time.Location {
+	return s.location.Load()
+}
+
+// SetLocation меняет часовой пояс, в котором считаются «сегодня», неделя и сроки на весь день
+func (s *taskService) SetLocation(loc *time.Location) {
+	s.location.Store(loc)
+}
+
+// now возвращает текущее время в зоне пользователя
+func (s *taskService) now() time.Time {
+	return time.Now().In(s.Location())
+}
+
+// allDayDue сводит срок на весь день к последней секунде его дня в зоне пользователя
+func (s *taskService) allDayDue(due time.Time) *time.Time {
+	end := dates.EndOfDay(due.In(s.Location()))
+	return &end
+}
+
+// normalizeDueUpdate: весь день задается вместе со сроком, новый срок без AllDay — срок со временем
+func (s *taskService) normalizeDueUpdate(updates *models.UpdateTaskRequest) error {
+	if updates.DueDate == nil {
+		if updates.AllDay != nil && *updates.AllDay {
+			return fmt.Errorf("all-day task requires a due date")
+		}
+		return nil
+	}
+
+	if updates.AllDay == nil {
+		timed := false
+		updates.AllDay = &timed
+	}
+	if *updates.AllDay {
+		updates.DueDate = s.allDayDue(*updates.DueDate)
+	}
+	return nil
+}
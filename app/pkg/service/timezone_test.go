@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/dates"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func TestTodayFollowsLocation(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	// UTC+14 и UTC-11: «сегодня» в этих зонах никогда не совпадает,
+	// поэтому один из краев дня в Киритимати лежит вне дня в Паго-Паго
+	kiritimati := mustLocation(t, "Pacific/Kiritimati")
+	pagoPago := mustLocation(t, "Pacific/Pago_Pago")
+
+	now := time.Now()
+	due, end := dates.DayRange(now.In(kiritimati))
+	if from, _ := dates.DayRange(now.In(pagoPago)); !due.Before(from) {
+		due = end
+	}
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "edge", Priority: models.TaskPriorityLow, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	svc.SetLocation(kiritimati)
+	if tasks, _ := svc.GetTasksByDateFilter(ctx, "today"); len(tasks) != 1 || tasks[0].ID != task.ID {
+		t.Errorf("expected task due today in Kiritimati, got %+v", tasks)
+	}
+
+	svc.SetLocation(pagoPago)
+	if tasks, _ := svc.GetTasksByDateFilter(ctx, "today"); len(tasks) != 0 {
+		t.Errorf("expected no tasks due today in Pago Pago, got %+v", tasks)
+	}
+}
+
+func TestAllDayDueDate(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService()
+	tokyo := mustLocation(t, "Asia/Tokyo")
+	svc.SetLocation(tokyo)
+
+	// 20:00 UTC — уже следующий день в Токио
+	due := time.Date(2030, 5, 1, 20, 0, 0, 0, time.UTC)
+	task, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "all day", Priority: models.TaskPriorityLow, DueDate: &due, AllDay: true})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if want := time.Date(2030, 5, 2, 23, 59, 59, 0, tokyo); !task.AllDay || !task.DueDate.Equal(want) {
+		t.Errorf("expected all-day task due %v, got %v (all day %v)", want, task.DueDate, task.AllDay)
+	}
+
+	// новый срок без AllDay задает время
+	task, err = svc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{DueDate: &due})
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if task.AllDay || !task.DueDate.Equal(due) {
+		t.Errorf("expected timed task due %v, got %v (all day %v)", due, task.DueDate, task.AllDay)
+	}
+
+	allDay := true
+	if _, err := svc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{AllDay: &allDay}); err == nil {
+		t.Error("expected error for all day without due date")
+	}
+	if _, err := svc.CreateTask(ctx, &models.CreateTaskRequest{Title: "no date", Priority: models.TaskPriorityLow, AllDay: true}); err == nil {
+		t.Error("expected error for all-day task without due date")
+	}
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// BulkUpdateTasks применяет одни и те же изменения ко всем задачам ids
+func (uc *taskUsecase) BulkUpdateTasks(ctx context.Context, ids []int, updates *models.UpdateTaskRequest) error {
+	if updates.Title != nil {
+		title := strings.TrimSpace(*updates.Title)
+		if title == "" {
+			return fmt.Errorf("task title cannot be empty")
+		}
+		updates.Title = &title
+	}
+
+	if updates.Description != nil {
+		description := strings.TrimSpace(*updates.Description)
+		updates.Description = &description
+	}
+
+	if updates.DueDate != nil && uc.dueInPast(*updates.DueDate, updates.AllDay != nil && *updates.AllDay) {
+		return fmt.Errorf("due date cannot be in the past")
+	}
+
+	before, err := uc.snapshot(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := uc.taskService.BulkUpdateTasks(ctx, ids, updates)
+	if err != nil {
+		return err
+	}
+
+	uc.record(ctx, HistoryBulkUpdate, before, taskIDs(tasks))
+	event := newTaskEvent(TasksBulkUpdated, tasks...)
+	event.Changes = updates
+	uc.publisher.Publish(event)
+	return nil
+}
+
+// BulkSetStatus завершает или возобновляет задачи, подзадачи не затрагиваются.
+// Созданные при завершении повторения отменяются вместе с операцией.
+func (uc *taskUsecase) BulkSetStatus(ctx context.Context, ids []int, status models.TaskStatus) error {
+	before, err := uc.snapshot(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	tasks, created, err := uc.taskService.BulkSetStatus(ctx, ids, status)
+	if err != nil {
+		return err
+	}
+
+	uc.record(ctx, HistoryToggle, before, append(taskIDs(tasks), taskIDs(created)...))
+	uc.publisher.Publish(newTaskEvent(TaskToggled, tasks...))
+	if len(created) > 0 {
+		uc.publisher.Publish(newTaskEvent(TaskCreated, created...))
+	}
+	return nil
+}
+
+// BulkTagTasks снимает теги remove и добавляет add; новые теги создаются
+func (uc *taskUsecase) BulkTagTasks(ctx context.Context, ids []int, add, remove []string) error {
+	before, err := uc.snapshot(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := uc.taskService.BulkTagTasks(ctx, ids, add, remove)
+	if err != nil {
+		return err
+	}
+
+	uc.record(ctx, HistoryBulkUpdate, before, taskIDs(tasks))
+	uc.publisher.Publish(newTaskEvent(TasksBulkUpdated, tasks...))
+	uc.publisher.Publish(newTaskEvent(TagsChanged))
+	return nil
+}
+
+// BulkMoveToProject переносит задачи с подзадачами в проект. Как и перенос одной
+// задачи, он пишется в журнал изменений, но не в историю отмены.
+func (uc *taskUsecase) BulkMoveToProject(ctx context.Context, ids []int, projectID int) error {
+	subtree, err := uc.subtreeIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	before, err := uc.snapshot(ctx, subtree...)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.taskService.BulkMoveToProject(ctx, ids, projectID); err != nil {
+		return err
+	}
+
+	uc.audit(ctx, models.RevisionUpdate, before, subtree)
+
+	tasks, err := uc.snapshot(ctx, subtree...)
+	if err != nil {
+		return err
+	}
+	uc.publisher.Publish(newTaskEvent(TasksBulkUpdated, tasks...))
+	return nil
+}
+
+// BulkDeleteTasks переносит задачи с подзадачами в корзину, id подзадач тоже попадают в событие
+func (uc *taskUsecase) BulkDeleteTasks(ctx context.Context, ids []int) error {
+	subtree, err := uc.subtreeIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	before, err := uc.snapshot(ctx, subtree...)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.taskService.BulkDeleteTasks(ctx, ids); err != nil {
+		return err
+	}
+
+	uc.record(ctx, HistoryDelete, before, subtree)
+	event := newTaskEvent(TaskDeleted)
+	event.TaskIDs = subtree
+	uc.publisher.Publish(event)
+	return nil
+}
+
+// subtreeIDs собирает id задач вместе с подзадачами без повторов
+func (uc *taskUsecase) subtreeIDs(ctx context.Context, ids []int) ([]int, error) {
+	seen := make(map[int]bool)
+	var result []int
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		tree, err := uc.taskService.GetTaskTree(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range flattenTree(tree) {
+			if !seen[task.ID] {
+				seen[task.ID] = true
+				result = append(result, task.ID)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/service"
+)
+
+func TestBulkSetStatusUndoRemovesNextOccurrences(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	due := time.Now().Add(time.Hour)
+	weekly, err := uc.CreateTask(ctx, &models.CreateTaskRequest{Title: "weekly", Priority: models.TaskPriorityMedium, DueDate: &due, Recurrence: "FREQ=WEEKLY"})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	plain := mustCreate(t, uc, "plain")
+
+	if err := uc.BulkSetStatus(ctx, []int{weekly.ID, plain.ID}, models.TaskStatusCompleted); err != nil {
+		t.Fatalf("BulkSetStatus: %v", err)
+	}
+
+	events := publisher.events[2:]
+	if len(events) != 2 || events[0].Type != TaskToggled || len(events[0].Tasks) != 2 || events[1].Type != TaskCreated {
+		t.Fatalf("expected toggled and created events, got %+v", events)
+	}
+
+	result, err := uc.Undo(ctx)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if result.Action != HistoryToggle || len(result.Tasks) != 3 {
+		t.Fatalf("expected two tasks and next occurrence, got %+v", result)
+	}
+
+	tasks, err := uc.GetTasks(ctx, "", "", "", "", nil, "", 0, "")
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected next occurrence to be removed, got %d tasks", len(tasks))
+	}
+	for _, task := range tasks {
+		if task.Status != models.TaskStatusPending {
+			t.Errorf("task %d should be pending after undo", task.ID)
+		}
+	}
+}
+
+func TestBulkDeleteIncludesSubtasks(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	parent := mustCreate(t, uc, "parent")
+	child, err := uc.CreateTask(ctx, &models.CreateTaskRequest{ParentID: &parent.ID, Title: "child", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	other := mustCreate(t, uc, "other")
+
+	// подзадача, выбранная вместе с родителем, удаляется один раз
+	if err := uc.BulkDeleteTasks(ctx, []int{parent.ID, child.ID, other.ID}); err != nil {
+		t.Fatalf("BulkDeleteTasks: %v", err)
+	}
+
+	event := publisher.events[len(publisher.events)-1]
+	if event.Type != TaskDeleted || len(event.TaskIDs) != 3 {
+		t.Fatalf("expected delete event for 3 tasks, got %+v", event)
+	}
+
+	if _, err := uc.Undo(ctx); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	for _, id := range []int{parent.ID, child.ID, other.ID} {
+		if _, err := uc.GetTask(ctx, id); err != nil {
+			t.Errorf("task %d should be restored: %v", id, err)
+		}
+	}
+}
+
+func TestBulkTagAndMove(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	first := mustCreate(t, uc, "first")
+	second := mustCreate(t, uc, "second")
+
+	if err := uc.BulkTagTasks(ctx, []int{first.ID, second.ID}, []string{"work"}, nil); err != nil {
+		t.Fatalf("BulkTagTasks: %v", err)
+	}
+	project, err := uc.CreateProject(ctx, &models.CreateProjectRequest{Name: "Work"})
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if err := uc.BulkMoveToProject(ctx, []int{first.ID, second.ID}, project.ID); err != nil {
+		t.Fatalf("BulkMoveToProject: %v", err)
+	}
+
+	for _, id := range []int{first.ID, second.ID} {
+		task, err := uc.GetTask(ctx, id)
+		if err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+		if task.ProjectID != project.ID || len(task.Tags) != 1 || task.Tags[0] != "work" {
+			t.Errorf("task %d: expected project %d and tag work, got %d %+v", id, project.ID, task.ProjectID, task.Tags)
+		}
+	}
+
+	// перенос не попадает в историю отмены, поэтому отменяются теги
+	result, err := uc.Undo(ctx)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if result.Action != HistoryBulkUpdate {
+		t.Errorf("expected tagging to be undone, got %s", result.Action)
+	}
+	task, _ := uc.GetTask(ctx, first.ID)
+	if len(task.Tags) != 0 || task.ProjectID != project.ID {
+		t.Errorf("expected tags removed and project kept, got %+v", task)
+	}
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+type TaskEventType string
+
+// имена событий совпадают с именами wails событий на фронтенде
+const (
+	TaskCreated      TaskEventType = "task:created"
+	TaskUpdated      TaskEventType = "task:updated"
+	TaskDeleted      TaskEventType = "task:deleted"
+	TaskRestored     TaskEventType = "task:restored"
+	TaskToggled      TaskEventType = "task:toggled"
+	TasksBulkUpdated TaskEventType = "tasks:bulk_updated"
+	TagsChanged      TaskEventType = "tags:changed"
+	ProjectsChanged  TaskEventType = "projects:changed"
+	FiltersChanged   TaskEventType = "filters:changed"
+	TrashChanged     TaskEventType = "trash:changed"
+	RemindersChanged TaskEventType = "reminders:changed"
+)
+
+// TaskEvent описывает изменение задач после успешной операции
+type TaskEvent struct {
+	Type       TaskEventType             `json:"type"`
+	TaskIDs    []int                     `json:"task_ids"`
+	Tasks      []*models.Task            `json:"tasks,omitempty"`
+	Changes    *models.UpdateTaskRequest `json:"changes,omitempty"`
+	OccurredAt time.Time                 `json:"occurred_at"`
+}
+
+type EventPublisher interface {
+	Publish(event TaskEvent)
+}
+
+// EventPublisherFunc позволяет передать обычную функцию как EventPublisher
+type EventPublisherFunc func(event TaskEvent)
+
+func (f EventPublisherFunc) Publish(event TaskEvent) {
+	f(event)
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(TaskEvent) {}
+
+func newTaskEvent(eventType TaskEventType, tasks ...*models.Task) TaskEvent {
+	return TaskEvent{
+		Type:       eventType,
+		TaskIDs:    taskIDs(tasks),
+		Tasks:      tasks,
+		OccurredAt: time.Now(),
+	}
+}
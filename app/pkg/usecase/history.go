@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/service"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+)
+
+// historyLimit — сколько последних операций можно отменить
+const historyLimit = 50
+
+// HistoryAction — операция над задачами, которую отменяет Undo и повторяет Redo
+type HistoryAction string
+
+const (
+	HistoryCreate     HistoryAction = "create"
+	HistoryUpdate     HistoryAction = "update"
+	HistoryDelete     HistoryAction = "delete"
+	HistoryToggle     HistoryAction = "toggle"
+	HistoryBulkUpdate HistoryAction = "bulk_update"
+	HistoryRevert     HistoryAction = "revert"
+	HistorySnooze     HistoryAction = "snooze"
+)
+
+// revisionAction — действие, под которым операция попадает в журнал изменений
+func (a HistoryAction) revisionAction() models.RevisionAction {
+	switch a {
+	case HistoryCreate:
+		return models.RevisionCreate
+	case HistoryDelete:
+		return models.RevisionDelete
+	case HistoryToggle:
+		return models.RevisionToggle
+	case HistoryRevert:
+		return models.RevisionRevert
+	case HistorySnooze:
+		return models.RevisionSnooze
+	default:
+		return models.RevisionUpdate
+	}
+}
+
+// HistoryResult — отмененная или повторенная операция и состояние затронутых задач после нее.
+// Задачи, оказавшиеся в корзине, приходят с DeletedAt.
+type HistoryResult struct {
+	Action HistoryAction  `json:"action"`
+	Tasks  []*models.Task `json:"tasks"`
+}
+
+// historyEntry хранит задачи до и после операции, before[i] и after[i] — одна задача
+type historyEntry struct {
+	action HistoryAction
+	before []*models.Task
+	after  []*models.Task
+}
+
+// history — стеки отмены и повтора одной сессии приложения
+type history struct {
+	mu   sync.Mutex
+	undo []historyEntry
+	redo []historyEntry
+}
+
+// push добавляет операцию и сбрасывает повтор: после новой операции повторять нечего
+func (h *history) push(entry historyEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.undo = append(h.undo, entry)
+	if len(h.undo) > historyLimit {
+		h.undo = slices.Delete(h.undo, 0, len(h.undo)-historyLimit)
+	}
+	h.redo = nil
+}
+
+// Undo отменяет последнюю операцию над задачами
+func (uc *taskUsecase) Undo(ctx context.Context) (*HistoryResult, error) {
+	h := uc.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.undo) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	entry := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+
+	// неприменимая запись, например с задачей, удаленной навсегда, из истории выпадает;
+	// при конфликте версий запись остается, и отмену можно повторить
+	tasks, err := uc.applyHistory(ctx, models.RevisionUndo, entry.after, entry.before)
+	if err != nil {
+		if errors.Is(err, service.ErrVersionConflict) {
+			h.undo = append(h.undo, entry)
+		}
+		return nil, fmt.Errorf("failed to undo %s: %w", entry.action, err)
+	}
+
+	h.redo = append(h.redo, entry)
+	return &HistoryResult{Action: entry.action, Tasks: tasks}, nil
+}
+
+// Redo повторяет последнюю отмененную операцию
+func (uc *taskUsecase) Redo(ctx context.Context) (*HistoryResult, error) {
+	h := uc.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.redo) == 0 {
+		return nil, ErrNothingToRedo
+	}
+	entry := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+
+	tasks, err := uc.applyHistory(ctx, models.RevisionRedo, entry.before, entry.after)
+	if err != nil {
+		if errors.Is(err, service.ErrVersionConflict) {
+			h.redo = append(h.redo, entry)
+		}
+		return nil, fmt.Errorf("failed to redo %s: %w", entry.action, err)
+	}
+
+	h.undo = append(h.undo, entry)
+	return &HistoryResult{Action: entry.action, Tasks: tasks}, nil
+}
+
+// applyHistory переводит задачи из состояния from в to одной транзакцией.
+// Меняются только поля, которые меняла сама операция, поэтому более поздние
+// изменения других полей, например перенос в проект, сохраняются. Если с тех пор
+// изменили само поле операции, ничего не пишется и возвращается ConflictError.
+func (uc *taskUsecase) applyHistory(ctx context.Context, action models.RevisionAction, from, to []*models.Task) ([]*models.Task, error) {
+	ids := taskIDs(to)
+	current, err := uc.taskService.GetTaskSnapshots(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.Task, len(current))
+	for _, task := range current {
+		byID[task.ID] = task
+	}
+
+	targets := make([]*models.Task, len(to))
+	for i, target := range to {
+		task, ok := byID[target.ID]
+		if !ok {
+			return nil, fmt.Errorf("task %d no longer exists", target.ID)
+		}
+		// поле, которое операция меняла, с тех пор изменили: отмена затерла бы более новое значение
+		if changedSince(task, from[i], target) {
+			return nil, &service.ConflictError{Expected: from[i].Version, Current: task}
+		}
+		targets[i] = revertTaskState(task, from[i], target)
+	}
+
+	if err := uc.taskService.ApplyTaskSnapshots(ctx, targets); err != nil {
+		return nil, err
+	}
+
+	tasks, err := uc.taskService.GetTaskSnapshots(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]taskChange, 0, len(tasks))
+	for _, task := range tasks {
+		if previous := byID[task.ID]; !sameTaskState(previous, task) {
+			changes = append(changes, taskChange{before: previous, after: task})
+		}
+	}
+	uc.logRevisions(ctx, action, changes)
+
+	uc.publishHistory(from, to, tasks)
+	return tasks, nil
+}
+
+// publishHistory сообщает об изменениях так же, как исходные операции:
+// ушедшие в корзину задачи удалены, вернувшиеся из нее восстановлены
+func (uc *taskUsecase) publishHistory(from, to, tasks []*models.Task) {
+	var deleted []int
+	var restored, updated []*models.Task
+	for i, task := range tasks {
+		switch {
+		case task.DeletedAt != nil:
+			deleted = append(deleted, task.ID)
+		case from[i].DeletedAt != nil && to[i].DeletedAt == nil:
+			restored = append(restored, task)
+		default:
+			updated = append(updated, task)
+		}
+	}
+
+	if len(deleted) > 0 {
+		event := newTaskEvent(TaskDeleted)
+		event.TaskIDs = deleted
+		uc.publisher.Publish(event)
+	}
+	if len(restored) > 0 {
+		uc.publisher.Publish(newTaskEvent(TaskRestored, restored...))
+	}
+	if len(updated) > 0 {
+		uc.publisher.Publish(newTaskEvent(TaskUpdated, updated...))
+	}
+}
+
+// snapshot запоминает задачи перед операцией, чтобы ее можно было отменить
+func (uc *taskUsecase) snapshot(ctx context.Context, ids ...int) ([]*models.Task, error) {
+	return uc.taskService.GetTaskSnapshots(ctx, ids)
+}
+
+// record кладет в историю и в журнал изменений операцию над задачами ids. Задачи, которых нет
+// в before, созданы операцией: их отмена переносит задачу в корзину.
+func (uc *taskUsecase) record(ctx context.Context, action HistoryAction, before []*models.Task, ids []int) {
+	changes := uc.collectChanges(ctx, before, ids)
+	uc.logRevisions(ctx, action.revisionAction(), changes)
+
+	entry := historyEntry{action: action}
+	for _, change := range changes {
+		prev := change.before
+		if prev == nil {
+			created := *change.after
+			// postgres хранит микросекунды, иначе повтор не узнал бы записанное время
+			deletedAt := time.Now().Truncate(time.Microsecond)
+			created.DeletedAt = &deletedAt
+			prev = &created
+		}
+		entry.before = append(entry.before, prev)
+		entry.after = append(entry.after, change.after)
+	}
+
+	if len(entry.after) > 0 {
+		uc.history.push(entry)
+	}
+}
+
+// taskChange — задача до и после операции, before nil — задача создана операцией
+type taskChange struct {
+	before, after *models.Task
+}
+
+// collectChanges читает задачи ids после операции и сопоставляет их с before, неизмененные пропускает
+func (uc *taskUsecase) collectChanges(ctx context.Context, before []*models.Task, ids []int) []taskChange {
+	after, err := uc.taskService.GetTaskSnapshots(ctx, ids)
+	if err != nil {
+		// операция уже выполнена, без снимка ее просто нельзя будет отменить
+		log.Printf("Failed to snapshot changed tasks: %v", err)
+		return nil
+	}
+
+	previous := make(map[int]*models.Task, len(before))
+	for _, task := range before {
+		previous[task.ID] = task
+	}
+
+	var changes []taskChange
+	for _, task := range after {
+		prev := previous[task.ID]
+		if prev != nil && sameTaskState(prev, task) {
+			continue
+		}
+		changes = append(changes, taskChange{before: prev, after: task})
+	}
+	return changes
+}
+
+// revertTaskState возвращает копию current, в которой поля, различающиеся в from и to, взяты из to
+func revertTaskState(current, from, to *models.Task) *models.Task {
+	task := *current
+	if !equalPtr(from.ParentID, to.ParentID) {
+		task.ParentID = to.ParentID
+	}
+	if from.ProjectID != to.ProjectID {
+		task.ProjectID = to.ProjectID
+	}
+	if from.Title != to.Title {
+		task.Title = to.Title
+	}
+	if from.Description != to.Description {
+		task.Description = to.Description
+	}
+	if from.Status != to.Status {
+		task.Status = to.Status
+	}
+	if from.Priority != to.Priority {
+		task.Priority = to.Priority
+	}
+	if !equalTime(from.DueDate, to.DueDate) {
+		task.DueDate = to.DueDate
+	}
+	if from.AllDay != to.AllDay {
+		task.AllDay = to.AllDay
+	}
+	if from.Recurrence != to.Recurrence {
+		task.Recurrence = to.Recurrence
+	}
+	if !equalTime(from.DeletedAt, to.DeletedAt) {
+		task.DeletedAt = to.DeletedAt
+	}
+	if !slices.Equal(from.Tags, to.Tags) {
+		task.Tags = to.Tags
+	}
+	if from.SnoozeCount != to.SnoozeCount {
+		task.SnoozeCount = to.SnoozeCount
+	}
+	return &task
+}
+
+// changedSince сообщает, отличается ли current от from в полях, которые меняет переход from → to.
+// revertTaskState(current, to, from) записывает в эти поля значения from и совпадает с current,
+// только если их никто не менял.
+func changedSince(current, from, to *models.Task) bool {
+	return !sameTaskState(revertTaskState(current, to, from), current)
+}
+
+// sameTaskState сравнивает поля, которые восстанавливает история
+func sameTaskState(a, b *models.Task) bool {
+	return equalPtr(a.ParentID, b.ParentID) &&
+		a.ProjectID == b.ProjectID &&
+		a.Title == b.Title &&
+		a.Description == b.Description &&
+		a.Status == b.Status &&
+		a.Priority == b.Priority &&
+		equalTime(a.DueDate, b.DueDate) &&
+		a.AllDay == b.AllDay &&
+		a.Recurrence == b.Recurrence &&
+		equalTime(a.DeletedAt, b.DeletedAt) &&
+		slices.Equal(a.Tags, b.Tags) &&
+		a.SnoozeCount == b.SnoozeCount
+}
+
+func equalPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
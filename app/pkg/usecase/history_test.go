@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/service"
+)
+
+func TestUndoRedoUpdate(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	task := mustCreate(t, uc, "draft")
+
+	title := "final"
+	tags := []string{"work"}
+	if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &title, Tags: &tags}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	result, err := uc.Undo(ctx)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if result.Action != HistoryUpdate || len(result.Tasks) != 1 {
+		t.Fatalf("unexpected undo result: %+v", result)
+	}
+	got, err := uc.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.Title != "draft" || len(got.Tags) != 0 {
+		t.Errorf("update not undone: %+v", got)
+	}
+
+	if _, err := uc.Redo(ctx); err != nil {
+		t.Fatalf("Redo: %v", err)
+	}
+	got, _ = uc.GetTask(ctx, task.ID)
+	if got.Title != "final" || len(got.Tags) != 1 {
+		t.Errorf("update not redone: %+v", got)
+	}
+	if _, err := uc.Redo(ctx); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestUndoCreateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	parent := mustCreate(t, uc, "parent")
+	child, err := uc.CreateTask(ctx, &models.CreateTaskRequest{ParentID: &parent.ID, Title: "child", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if err := uc.DeleteTask(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	publisher.events = nil
+	if _, err := uc.Undo(ctx); err != nil {
+		t.Fatalf("Undo delete: %v", err)
+	}
+	for _, id := range []int{parent.ID, child.ID} {
+		if _, err := uc.GetTask(ctx, id); err != nil {
+			t.Errorf("task %d should be restored: %v", id, err)
+		}
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != TaskRestored || len(publisher.events[0].TaskIDs) != 2 {
+		t.Errorf("expected restore event for subtree, got %+v", publisher.events)
+	}
+
+	// отмена создания переносит задачу в корзину
+	if _, err := uc.Undo(ctx); err != nil {
+		t.Fatalf("Undo create: %v", err)
+	}
+	if _, err := uc.GetTask(ctx, child.ID); err == nil {
+		t.Error("expected undone subtask to be in trash")
+	}
+	if _, err := uc.Redo(ctx); err != nil {
+		t.Fatalf("Redo create: %v", err)
+	}
+	if _, err := uc.GetTask(ctx, child.ID); err != nil {
+		t.Errorf("redone subtask should be back: %v", err)
+	}
+}
+
+func TestUndoToggleRemovesNextOccurrence(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+
+	due := time.Now().Add(time.Hour)
+	task, err := uc.CreateTask(ctx, &models.CreateTaskRequest{Title: "weekly", Priority: models.TaskPriorityMedium, DueDate: &due, Recurrence: "FREQ=WEEKLY"})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := uc.ToggleTaskComplete(ctx, task.ID, models.SubtaskModeNone); err != nil {
+		t.Fatalf("ToggleTaskComplete: %v", err)
+	}
+
+	result, err := uc.Undo(ctx)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if result.Action != HistoryToggle || len(result.Tasks) != 2 {
+		t.Fatalf("expected toggled task and next occurrence, got %+v", result)
+	}
+
+	tasks, err := uc.GetTasks(ctx, "", "", "", "", nil, "", 0, "")
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != task.ID || tasks[0].Status != models.TaskStatusPending {
+		t.Errorf("expected only the reopened task, got %+v", tasks)
+	}
+}
+
+func TestUndoBulkUpdateKeepsLaterChanges(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	first := mustCreate(t, uc, "first")
+	second := mustCreate(t, uc, "second")
+
+	priority := models.TaskPriorityHigh
+	if err := uc.BulkUpdateTasks(ctx, []int{first.ID, second.ID}, &models.UpdateTaskRequest{Priority: &priority}); err != nil {
+		t.Fatalf("BulkUpdateTasks: %v", err)
+	}
+
+	// перенос в проект не записывается в историю и не откатывается отменой
+	project, err := uc.CreateProject(ctx, &models.CreateProjectRequest{Name: "Work"})
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if _, err := uc.MoveTaskToProject(ctx, first.ID, project.ID); err != nil {
+		t.Fatalf("MoveTaskToProject: %v", err)
+	}
+
+	if _, err := uc.Undo(ctx); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	got, _ := uc.GetTask(ctx, first.ID)
+	if got.Priority != models.TaskPriorityMedium || got.ProjectID != project.ID {
+		t.Errorf("expected priority undone and project kept, got %+v", got)
+	}
+	got, _ = uc.GetTask(ctx, second.ID)
+	if got.Priority != models.TaskPriorityMedium {
+		t.Errorf("expected priority undone, got %q", got.Priority)
+	}
+}
+
+func TestHistoryIsBoundedAndResetByNewMutation(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	task := mustCreate(t, uc, "task")
+
+	for i := 0; i < historyLimit+5; i++ {
+		description := time.Duration(i).String()
+		if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Description: &description}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+	}
+
+	undone := 0
+	for {
+		if _, err := uc.Undo(ctx); errors.Is(err, ErrNothingToUndo) {
+			break
+		} else if err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+		undone++
+	}
+	if undone != historyLimit {
+		t.Errorf("expected %d undoable operations, got %d", historyLimit, undone)
+	}
+
+	title := "new"
+	if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if _, err := uc.Redo(ctx); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("new mutation must clear redo, got %v", err)
+	}
+}
+
+func TestUndoFailsForPurgedTask(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	task := mustCreate(t, uc, "task")
+
+	if err := uc.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if err := uc.PurgeTask(ctx, task.ID); err != nil {
+		t.Fatalf("PurgeTask: %v", err)
+	}
+
+	if _, err := uc.Undo(ctx); err == nil {
+		t.Fatal("expected undo of purged task to fail")
+	}
+	// неприменимая запись выпадает, следующая отмена берет создание задачи и тоже не применяется
+	if _, err := uc.Undo(ctx); err == nil {
+		t.Fatal("expected undo of purged task creation to fail")
+	}
+	if _, err := uc.Undo(ctx); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("expected empty history, got %v", err)
+	}
+}
+
+func TestUndoConflictsWithLaterEditFromAnotherSession(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryTaskRepository()
+	uc := NewTaskUsecase(service.NewTaskService(repo), &recordingPublisher{}, "tester")
+	other := NewTaskUsecase(service.NewTaskService(repo), &recordingPublisher{}, "other")
+	task := mustCreate(t, uc, "draft")
+
+	title := "final"
+	if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	newer := "edited elsewhere"
+	if _, err := other.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &newer}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	// запись остается в истории, но и повторная попытка не затирает чужое изменение
+	for i := 0; i < 2; i++ {
+		_, err := uc.Undo(ctx)
+		var conflict *service.ConflictError
+		if !errors.As(err, &conflict) || conflict.Current.Title != newer {
+			t.Fatalf("expected conflict with current task, got %v", err)
+		}
+	}
+	got, _ := uc.GetTask(ctx, task.ID)
+	if got.Title != newer {
+		t.Errorf("undo must keep newer title, got %q", got.Title)
+	}
+}
+
+// racingRepository перед записью снимков меняет задачу, как другой клиент между чтением и записью
+type racingRepository struct {
+	repository.TaskRepositoryInterface
+	race func()
+}
+
+func (r *racingRepository) ApplySnapshots(ctx context.Context, tasks []*models.Task) error {
+	if race := r.race; race != nil {
+		r.race = nil
+		race()
+	}
+	return r.TaskRepositoryInterface.ApplySnapshots(ctx, tasks)
+}
+
+func TestUndoConflictsWithConcurrentUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := &racingRepository{TaskRepositoryInterface: repository.NewMemoryTaskRepository()}
+	uc := NewTaskUsecase(service.NewTaskService(repo), &recordingPublisher{}, "tester")
+	task := mustCreate(t, uc, "draft")
+
+	title := "final"
+	if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+
+	description := "from another client"
+	repo.race = func() {
+		if err := repo.Update(ctx, task.ID, &models.UpdateTaskRequest{Description: &description}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	_, err := uc.Undo(ctx)
+	var conflict *service.ConflictError
+	if !errors.As(err, &conflict) || conflict.Current.Description != description {
+		t.Fatalf("expected conflict with current task, got %v", err)
+	}
+	got, _ := uc.GetTask(ctx, task.ID)
+	if got.Title != "final" || got.Description != description {
+		t.Errorf("conflicting undo must not write: %+v", got)
+	}
+
+	// запись осталась в истории, повторная отмена сохраняет чужое изменение
+	if _, err := uc.Undo(ctx); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	got, _ = uc.GetTask(ctx, task.ID)
+	if got.Title != "draft" || got.Description != description {
+		t.Errorf("undo should keep concurrent change: %+v", got)
+	}
+}
@@ -0,0 +1,431 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todo-lits-DMARK/app/pkg/dates"
+	"todo-lits-DMARK/app/pkg/models"
+	"unicode"
+)
+
+// ErrInvalidQuery оборачивается всеми ошибками разбора языка фильтров
+var ErrInvalidQuery = errors.New("invalid query")
+
+// QueryError указывает, какой токен запроса не удалось разобрать.
+// Pos — позиция начала токена в символах, считая с 1.
+type QueryError struct {
+	Pos   int
+	Token string
+	Msg   string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid query at position %d (%s): %s", e.Pos, e.Token, e.Msg)
+}
+
+func (e *QueryError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+const queryFieldsHelp = "status, priority, due, created, tag, project, filter, sort"
+
+var relativeDatePattern = regexp.MustCompile(`^([+-]?\d+)([dwm])$`)
+
+// queryToken — слово запроса: field:value или свободный текст с пустым field
+type queryToken struct {
+	pos     int
+	raw     string
+	negated bool
+	field   string
+	value   string
+	quoted  bool
+}
+
+func (t queryToken) errorf(format string, args ...interface{}) error {
+	return &QueryError{Pos: t.pos, Token: t.raw, Msg: fmt.Sprintf(format, args...)}
+}
+
+// tokenizeQuery делит строку по пробелам. Кавычки объединяют слова в одно значение:
+// "текст с пробелами" или tag:"два слова". Ведущий минус означает отрицание.
+func tokenizeQuery(input string) ([]queryToken, error) {
+	runes := []rune(input)
+	var tokens []queryToken
+
+	readQuoted := func(start int) (string, int, error) {
+		end := start + 1
+		for end < len(runes) && runes[end] != '"' {
+			end++
+		}
+		if end == len(runes) {
+			return "", 0, &QueryError{Pos: start + 1, Token: string(runes[start:]), Msg: "unterminated quote"}
+		}
+		return string(runes[start+1 : end]), end + 1, nil
+	}
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		token := queryToken{pos: i + 1}
+		start := i
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			token.negated = true
+			i++
+		}
+
+		if runes[i] == '"' {
+			value, next, err := readQuoted(i)
+			if err != nil {
+				return nil, err
+			}
+			token.value, token.quoted, i = value, true, next
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+
+			if colon := strings.IndexRune(word, ':'); colon > 0 {
+				token.field = strings.ToLower(word[:colon])
+				token.value = word[colon+1:]
+				if token.value == "" && i < len(runes) && runes[i] == '"' {
+					value, next, err := readQuoted(i)
+					if err != nil {
+						return nil, err
+					}
+					token.value, token.quoted, i = value, true, next
+				}
+			} else {
+				token.value = word
+			}
+		}
+
+		token.raw = string(runes[start:i])
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// queryParser разбирает запрос в models.TaskQuery. Относительные даты считаются от now
+// в его часовом поясе, имена проектов ищутся через projects только при необходимости.
+// savedFilters раскрывает filter:"имя"; nil запрещает ссылки, так разбираются сами
+// сохраненные фильтры, чтобы они не ссылались друг на друга.
+type queryParser struct {
+	now          time.Time
+	projects     func() ([]*models.Project, error)
+	savedFilters func() ([]*models.SavedFilter, error)
+}
+
+func (p *queryParser) parse(input string) (*models.TaskQuery, error) {
+	tokens, err := tokenizeQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &models.TaskQuery{}
+	explicitSort := false
+	for _, token := range tokens {
+		if token.field == "" {
+			term := models.SearchTerm{Text: token.value, Phrase: token.quoted, Negated: token.negated}
+			if !token.quoted && strings.HasSuffix(term.Text, "*") {
+				term.Text = strings.TrimRight(term.Text, "*")
+				term.Prefix = true
+			}
+			if term, ok := term.Normalize(); ok {
+				query.Text = append(query.Text, term)
+			}
+			continue
+		}
+
+		// явный sort: заменяет сортировку сохраненного фильтра
+		if token.field == "sort" {
+			if explicitSort {
+				return nil, token.errorf("sort is specified more than once")
+			}
+			if query.Sort, err = parseQuerySort(token); err != nil {
+				return nil, err
+			}
+			explicitSort = true
+			continue
+		}
+
+		if token.field == "filter" {
+			saved, err := p.expandSavedFilter(token)
+			if err != nil {
+				return nil, err
+			}
+			query.Conditions = append(query.Conditions, saved.Conditions...)
+			query.Text = append(query.Text, saved.Text...)
+			if query.Sort == nil {
+				query.Sort = saved.Sort
+			}
+			continue
+		}
+
+		condition, err := p.parseCondition(token)
+		if err != nil {
+			return nil, err
+		}
+		query.Conditions = append(query.Conditions, *condition)
+	}
+
+	return query, nil
+}
+
+func (p *queryParser) parseCondition(token queryToken) (*models.QueryCondition, error) {
+	op, value := splitQueryOperator(token.value)
+	if token.quoted || op == "=" {
+		op = ""
+	}
+	if token.quoted {
+		value = token.value
+	}
+	if strings.TrimSpace(value) == "" {
+		return nil, token.errorf("missing value for %s", token.field)
+	}
+
+	condition := &models.QueryCondition{
+		Field:   models.QueryField(token.field),
+		Op:      models.QueryOpIn,
+		Negated: token.negated,
+	}
+
+	switch condition.Field {
+	case models.QueryFieldStatus:
+		if op != "" {
+			return nil, token.errorf("status does not support comparison %s", op)
+		}
+		status := models.TaskStatus(strings.ToLower(value))
+		if status != models.TaskStatusPending && status != models.TaskStatusCompleted {
+			return nil, token.errorf("unknown status %q, expected pending or completed", value)
+		}
+		condition.Values = []string{string(status)}
+
+	case models.QueryFieldPriority:
+		rank := models.TaskPriority(strings.ToLower(value)).Rank()
+		if rank == 0 {
+			return nil, token.errorf("unknown priority %q, expected low, medium or high", value)
+		}
+		for _, priority := range []models.TaskPriority{models.TaskPriorityLow, models.TaskPriorityMedium, models.TaskPriorityHigh} {
+			if compareQueryRank(priority.Rank(), op, rank) {
+				condition.Values = append(condition.Values, string(priority))
+			}
+		}
+		if len(condition.Values) == 0 {
+			return nil, token.errorf("no priority matches %s%s", op, value)
+		}
+
+	case models.QueryFieldTag:
+		if op != "" {
+			return nil, token.errorf("tag does not support comparison %s", op)
+		}
+		condition.Values = []string{strings.ToLower(strings.TrimSpace(value))}
+
+	case models.QueryFieldProject:
+		if op != "" {
+			return nil, token.errorf("project does not support comparison %s", op)
+		}
+		ids, err := p.findProjects(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, token.errorf("unknown project %q", value)
+		}
+		condition.ProjectIDs = ids
+
+	case models.QueryFieldDue, models.QueryFieldCreated:
+		if err := p.parseDateCondition(token, condition, op, strings.ToLower(value)); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, token.errorf("unknown field %q, expected one of: %s", token.field, queryFieldsHelp)
+	}
+
+	return condition, nil
+}
+
+// parseDateCondition переводит дату в границы дня: due:<7d — раньше начала седьмого дня
+// от сегодня, due:<=today — до конца сегодняшнего дня, due:today — в течение дня
+func (p *queryParser) parseDateCondition(token queryToken, condition *models.QueryCondition, op, value string) error {
+	if value == "none" {
+		if condition.Field != models.QueryFieldDue || op != "" {
+			return token.errorf("only due:none is supported")
+		}
+		condition.Op = models.QueryOpEmpty
+		return nil
+	}
+
+	day, err := p.parseDay(value)
+	if err != nil {
+		return token.errorf("%v", err)
+	}
+	next := day.AddDate(0, 0, 1)
+
+	switch op {
+	case "":
+		condition.Op, condition.From, condition.To = models.QueryOpRange, day, next
+	case "<":
+		condition.Op, condition.From = models.QueryOpBefore, day
+	case "<=":
+		condition.Op, condition.From = models.QueryOpBefore, next
+	case ">":
+		condition.Op, condition.From = models.QueryOpAfter, next
+	case ">=":
+		condition.Op, condition.From = models.QueryOpAfter, day
+	}
+
+	return nil
+}
+
+// parseDay понимает today, tomorrow, yesterday, смещения 3d, -1w, 2m и даты 2006-01-02
+func (p *queryParser) parseDay(value string) (time.Time, error) {
+	today := dates.StartOfDay(p.now)
+
+	switch value {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if match := relativeDatePattern.FindStringSubmatch(value); match != nil {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset %q", value)
+		}
+		switch match[2] {
+		case "d":
+			return today.AddDate(0, 0, n), nil
+		case "w":
+			return today.AddDate(0, 0, 7*n), nil
+		default:
+			return today.AddDate(0, n, 0), nil
+		}
+	}
+
+	day, err := time.ParseInLocation(dates.DateLayout, value, p.now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected today, tomorrow, yesterday, 7d, 2w, 1m or YYYY-MM-DD", value)
+	}
+	return day, nil
+}
+
+func (p *queryParser) findProjects(value string) ([]int, error) {
+	projects, err := p.projects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project: %w", err)
+	}
+
+	id, idErr := strconv.Atoi(value)
+	var ids []int
+	for _, project := range projects {
+		if strings.EqualFold(project.Name, strings.TrimSpace(value)) || (idErr == nil && project.ID == id) {
+			ids = append(ids, project.ID)
+		}
+	}
+	return ids, nil
+}
+
+// expandSavedFilter разбирает запрос сохраненного фильтра по имени или id.
+// Отрицание не поддерживается: фильтр — это набор условий через AND.
+func (p *queryParser) expandSavedFilter(token queryToken) (*models.TaskQuery, error) {
+	if p.savedFilters == nil {
+		return nil, token.errorf("saved filters cannot reference other saved filters")
+	}
+	if token.negated {
+		return nil, token.errorf("filter cannot be negated")
+	}
+
+	value := strings.TrimSpace(token.value)
+	if value == "" {
+		return nil, token.errorf("missing value for filter")
+	}
+
+	filters, err := p.savedFilters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve saved filter: %w", err)
+	}
+
+	id, idErr := strconv.Atoi(value)
+	for _, filter := range filters {
+		if strings.EqualFold(filter.Name, value) || (idErr == nil && filter.ID == id) {
+			nested := &queryParser{now: p.now, projects: p.projects}
+			query, err := nested.parse(filter.Query)
+			if err != nil {
+				return nil, token.errorf("saved filter %q: %v", filter.Name, err)
+			}
+			return query, nil
+		}
+	}
+
+	return nil, token.errorf("unknown saved filter %q", value)
+}
+
+// parseQuerySort понимает sort:due, sort:priority:asc и т.д.
+// По умолчанию срок сортируется по возрастанию, дата создания и приоритет по убыванию.
+func parseQuerySort(token queryToken) (*models.TaskSort, error) {
+	if token.negated {
+		return nil, token.errorf("sort cannot be negated")
+	}
+
+	field, order, _ := strings.Cut(strings.ToLower(token.value), ":")
+	sort := &models.TaskSort{}
+
+	switch field {
+	case "due", "due_date":
+		sort.Field, sort.Order = "due_date", "asc"
+	case "created", "created_at":
+		sort.Field, sort.Order = "created_at", "desc"
+	case "priority":
+		sort.Field, sort.Order = "priority", "desc"
+	default:
+		return nil, token.errorf("unknown sort field %q, expected due, created or priority", field)
+	}
+
+	switch order {
+	case "":
+	case "asc", "desc":
+		sort.Order = order
+	default:
+		return nil, token.errorf("unknown sort order %q, expected asc or desc", order)
+	}
+
+	return sort, nil
+}
+
+func splitQueryOperator(value string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "", value
+}
+
+func compareQueryRank(rank int, op string, value int) bool {
+	switch op {
+	case "<":
+		return rank < value
+	case "<=":
+		return rank <= value
+	case ">":
+		return rank > value
+	case ">=":
+		return rank >= value
+	default:
+		return rank == value
+	}
+}
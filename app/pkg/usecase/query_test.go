@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func newTestQueryParser() *queryParser {
+	return &queryParser{
+		// среда, 13 марта 2024
+		now: time.Date(2024, 3, 13, 15, 30, 0, 0, time.Local),
+		projects: func() ([]*models.Project, error) {
+			return []*models.Project{
+				{ID: 1, Name: "Inbox", Inbox: true},
+				{ID: 2, Name: "Work"},
+			}, nil
+		},
+	}
+}
+
+func TestParseTaskQuery(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		input string
+		want  *models.TaskQuery
+	}{
+		{
+			input: `status:pending priority:>=medium due:<7d tag:work -tag:later "quoted text" sort:due`,
+			want: &models.TaskQuery{
+				Conditions: []models.QueryCondition{
+					{Field: models.QueryFieldStatus, Op: models.QueryOpIn, Values: []string{"pending"}},
+					{Field: models.QueryFieldPriority, Op: models.QueryOpIn, Values: []string{"medium", "high"}},
+					{Field: models.QueryFieldDue, Op: models.QueryOpBefore, From: day(20)},
+					{Field: models.QueryFieldTag, Op: models.QueryOpIn, Values: []string{"work"}},
+					{Field: models.QueryFieldTag, Op: models.QueryOpIn, Values: []string{"later"}, Negated: true},
+				},
+				Text: []models.SearchTerm{{Text: "quoted text", Phrase: true}},
+				Sort: &models.TaskSort{Field: "due_date", Order: "asc"},
+			},
+		},
+		{
+			input: `priority:<high due:today created:>=2024-03-01 project:work`,
+			want: &models.TaskQuery{
+				Conditions: []models.QueryCondition{
+					{Field: models.QueryFieldPriority, Op: models.QueryOpIn, Values: []string{"low", "medium"}},
+					{Field: models.QueryFieldDue, Op: models.QueryOpRange, From: day(13), To: day(14)},
+					{Field: models.QueryFieldCreated, Op: models.QueryOpAfter, From: day(1)},
+					{Field: models.QueryFieldProject, Op: models.QueryOpIn, ProjectIDs: []int{2}},
+				},
+			},
+		},
+		{
+			input: `-due:none due:<=tomorrow отчет* -draft sort:priority:asc`,
+			want: &models.TaskQuery{
+				Conditions: []models.QueryCondition{
+					{Field: models.QueryFieldDue, Op: models.QueryOpEmpty, Negated: true},
+					{Field: models.QueryFieldDue, Op: models.QueryOpBefore, From: day(15)},
+				},
+				Text: []models.SearchTerm{
+					{Text: "отчет", Prefix: true},
+					{Text: "draft", Negated: true},
+				},
+				Sort: &models.TaskSort{Field: "priority", Order: "asc"},
+			},
+		},
+		{
+			input: `tag:"Road Trip" due:>-1w`,
+			want: &models.TaskQuery{
+				Conditions: []models.QueryCondition{
+					{Field: models.QueryFieldTag, Op: models.QueryOpIn, Values: []string{"road trip"}},
+					{Field: models.QueryFieldDue, Op: models.QueryOpAfter, From: day(7)},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := newTestQueryParser().parse(tt.input)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v\ngot      %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseTaskQueryErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		token string
+	}{
+		{`status:open`, 1, "status:open"},
+		{`tag:work priority:urgent`, 10, "priority:urgent"},
+		{`due:<soon`, 1, "due:<soon"},
+		{`owner:me`, 1, "owner:me"},
+		{`project:Home`, 1, "project:Home"},
+		{`status:>pending`, 1, "status:>pending"},
+		{`created:none`, 1, "created:none"},
+		{`priority:>high`, 1, "priority:>high"},
+		{`sort:due sort:priority`, 10, "sort:priority"},
+		{`-sort:due`, 1, "-sort:due"},
+		{`tag:`, 1, "tag:"},
+		{`work "unterminated`, 6, `"unterminated`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := newTestQueryParser().parse(tt.input)
+			if !errors.Is(err, ErrInvalidQuery) {
+				t.Fatalf("expected ErrInvalidQuery, got %v", err)
+			}
+			var queryErr *QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("expected *QueryError, got %T", err)
+			}
+			if queryErr.Pos != tt.pos || queryErr.Token != tt.token {
+				t.Errorf("expected error at %d %q, got %d %q (%v)", tt.pos, tt.token, queryErr.Pos, queryErr.Token, err)
+			}
+		})
+	}
+}
+
+func TestParseSavedFilterReference(t *testing.T) {
+	parser := newTestQueryParser()
+	parser.savedFilters = func() ([]*models.SavedFilter, error) {
+		return []*models.SavedFilter{
+			{ID: 1, Name: "Urgent work", Query: "priority:high project:work sort:due"},
+			{ID: 2, Name: "Nested", Query: `filter:"Urgent work"`},
+		}, nil
+	}
+
+	got, err := parser.parse(`filter:"urgent WORK" status:pending`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := &models.TaskQuery{
+		Conditions: []models.QueryCondition{
+			{Field: models.QueryFieldPriority, Op: models.QueryOpIn, Values: []string{"high"}},
+			{Field: models.QueryFieldProject, Op: models.QueryOpIn, ProjectIDs: []int{2}},
+			{Field: models.QueryFieldStatus, Op: models.QueryOpIn, Values: []string{"pending"}},
+		},
+		Sort: &models.TaskSort{Field: "due_date", Order: "asc"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v\ngot      %+v", want, got)
+	}
+
+	// явная сортировка важнее сортировки сохраненного фильтра
+	got, err = parser.parse(`filter:1 sort:created`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got.Sort == nil || got.Sort.Field != "created_at" {
+		t.Errorf("expected explicit sort to win, got %+v", got.Sort)
+	}
+
+	for _, input := range []string{`filter:Nested`, `-filter:1`, `filter:missing`} {
+		if _, err := parser.parse(input); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: expected ErrInvalidQuery, got %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// ParseQuickAdd разбирает строку быстрого добавления для предпросмотра, ничего не создавая
+func (uc *taskUsecase) ParseQuickAdd(ctx context.Context, text string) (*models.CreateTaskRequest, error) {
+	return uc.taskService.ParseQuickAdd(ctx, text)
+}
+
+// QuickAdd разбирает строку и создает задачу в проекте projectID (nil — Inbox).
+// Задача проходит те же проверки, что и в CreateTask, и отменяется через Undo.
+func (uc *taskUsecase) QuickAdd(ctx context.Context, text string, projectID *int) (*models.Task, error) {
+	req, err := uc.taskService.ParseQuickAdd(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	req.ProjectID = projectID
+	return uc.CreateTask(ctx, req)
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestQuickAdd(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+
+	preview, err := uc.ParseQuickAdd(ctx, "Call Bob tomorrow 3pm !high #work every monday")
+	if err != nil {
+		t.Fatalf("ParseQuickAdd: %v", err)
+	}
+	if tasks, _ := uc.GetTasks(ctx, "", "", "", "", nil, "", 0, ""); len(tasks) != 0 {
+		t.Fatalf("expected preview not to create tasks, got %d", len(tasks))
+	}
+
+	task, err := uc.QuickAdd(ctx, "Call Bob tomorrow 3pm !high #work every monday", nil)
+	if err != nil {
+		t.Fatalf("QuickAdd: %v", err)
+	}
+	if task.Title != preview.Title || task.Priority != models.TaskPriorityHigh || task.Recurrence != preview.Recurrence {
+		t.Errorf("expected task to match preview %+v, got %+v", preview, task)
+	}
+	if task.DueDate == nil || !task.DueDate.Equal(*preview.DueDate) {
+		t.Errorf("expected due date %v, got %v", preview.DueDate, task.DueDate)
+	}
+	if len(task.Tags) != 1 || task.Tags[0] != "work" {
+		t.Errorf("expected tag work, got %+v", task.Tags)
+	}
+
+	result, err := uc.Undo(ctx)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if result.Action != HistoryCreate {
+		t.Errorf("expected quick add to be undone as create, got %s", result.Action)
+	}
+
+	if _, err := uc.QuickAdd(ctx, "Report 2020-01-01", nil); err == nil {
+		t.Error("expected error for due date in the past")
+	}
+	if _, err := uc.QuickAdd(ctx, "#work !low", nil); err == nil {
+		t.Error("expected error for text without title")
+	}
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// AddReminder добавляет напоминание к задаче, у задачи может быть несколько напоминаний
+func (uc *taskUsecase) AddReminder(ctx context.Context, taskID int, req *models.CreateReminderRequest) (*models.Reminder, error) {
+	reminder, err := uc.taskService.AddReminder(ctx, taskID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.publisher.Publish(newRemindersEvent(taskID))
+	return reminder, nil
+}
+
+func (uc *taskUsecase) GetReminders(ctx context.Context, taskID int) ([]*models.Reminder, error) {
+	return uc.taskService.GetReminders(ctx, taskID)
+}
+
+func (uc *taskUsecase) DeleteReminder(ctx context.Context, id int) error {
+	if err := uc.taskService.DeleteReminder(ctx, id); err != nil {
+		return err
+	}
+
+	uc.publisher.Publish(newRemindersEvent())
+	return nil
+}
+
+func (uc *taskUsecase) FireDueReminders(ctx context.Context, now time.Time) ([]*models.DueReminder, error) {
+	return uc.taskService.FireDueReminders(ctx, now)
+}
+
+func (uc *taskUsecase) ReleaseReminder(ctx context.Context, id int) error {
+	return uc.taskService.ReleaseReminder(ctx, id)
+}
+
+func (uc *taskUsecase) NextReminderAt(ctx context.Context) (*time.Time, error) {
+	return uc.taskService.NextReminderAt(ctx)
+}
+
+func newRemindersEvent(taskIDs ...int) TaskEvent {
+	event := newTaskEvent(RemindersChanged)
+	event.TaskIDs = taskIDs
+	return event
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/service"
+)
+
+func TestRemindersPublishEvents(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+	task := mustCreate(t, uc, "task")
+	publisher.events = nil
+
+	at := time.Now().Add(time.Hour)
+	reminder, err := uc.AddReminder(ctx, task.ID, &models.CreateReminderRequest{At: &at})
+	if err != nil {
+		t.Fatalf("AddReminder: %v", err)
+	}
+	if err := uc.DeleteReminder(ctx, reminder.ID); err != nil {
+		t.Fatalf("DeleteReminder: %v", err)
+	}
+
+	if len(publisher.events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(publisher.events))
+	}
+	for _, event := range publisher.events {
+		if event.Type != RemindersChanged {
+			t.Errorf("expected %s, got %s", RemindersChanged, event.Type)
+		}
+	}
+	if ids := publisher.events[0].TaskIDs; len(ids) != 1 || ids[0] != task.ID {
+		t.Errorf("expected event for task %d, got %v", task.ID, ids)
+	}
+
+	// история отмены не затрагивается
+	if _, err := uc.Undo(ctx); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if _, err := uc.GetTask(ctx, task.ID); err == nil {
+		t.Error("expected undo to revert task creation, not reminders")
+	}
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// GetTaskHistory возвращает журнал изменений задачи, последние изменения первыми
+func (uc *taskUsecase) GetTaskHistory(ctx context.Context, id int) ([]*models.TaskRevision, error) {
+	return uc.taskService.GetTaskHistory(ctx, id)
+}
+
+// RevertTask возвращает задачу к состоянию после ревизии revisionID. Если родитель
+// из ревизии в корзине или удален, задача становится корневой; удаленный проект
+// заменяется текущим. Возврат записывается в журнал и отменяется через Undo.
+func (uc *taskUsecase) RevertTask(ctx context.Context, taskID, revisionID int) (*models.Task, error) {
+	revision, err := uc.taskService.GetTaskRevision(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.TaskID != taskID {
+		return nil, fmt.Errorf("revision %d does not belong to task %d", revisionID, taskID)
+	}
+
+	before, err := uc.snapshot(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(before) == 0 {
+		return nil, fmt.Errorf("task %d no longer exists", taskID)
+	}
+
+	target := *revision.Task
+	target.ID = taskID
+	target.Version = before[0].Version
+	if target.ParentID != nil {
+		parents, err := uc.snapshot(ctx, *target.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if len(parents) == 0 || parents[0].DeletedAt != nil {
+			target.ParentID = nil
+		}
+	}
+	if !uc.projectExists(ctx, target.ProjectID) {
+		target.ProjectID = before[0].ProjectID
+	}
+
+	if err := uc.taskService.ApplyTaskSnapshots(ctx, []*models.Task{&target}); err != nil {
+		return nil, err
+	}
+
+	after, err := uc.snapshot(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, HistoryRevert, before, []int{taskID})
+	uc.publishHistory(before, after, after)
+	return after[0], nil
+}
+
+func (uc *taskUsecase) projectExists(ctx context.Context, id int) bool {
+	projects, err := uc.taskService.GetProjects(ctx)
+	if err != nil {
+		return false
+	}
+	for _, project := range projects {
+		if project.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// audit пишет в журнал операцию, которая не попадает в историю отмены
+func (uc *taskUsecase) audit(ctx context.Context, action models.RevisionAction, before []*models.Task, ids []int) {
+	uc.logRevisions(ctx, action, uc.collectChanges(ctx, before, ids))
+}
+
+// logRevisions записывает изменения задач в журнал от имени actor
+func (uc *taskUsecase) logRevisions(ctx context.Context, action models.RevisionAction, changes []taskChange) {
+	revisions := make([]*models.TaskRevision, 0, len(changes))
+	for _, change := range changes {
+		revisions = append(revisions, &models.TaskRevision{
+			TaskID:  change.after.ID,
+			Action:  revisionAction(action, change),
+			Actor:   uc.actor,
+			Changes: models.DiffTasks(change.before, change.after),
+			Task:    change.after,
+		})
+	}
+
+	if err := uc.taskService.AddTaskRevisions(ctx, revisions); err != nil {
+		// изменение уже сохранено, сбой журнала не должен превращать его в ошибку
+		log.Printf("Failed to record task history: %v", err)
+	}
+}
+
+// revisionAction уточняет действие операции для отдельной задачи: задача, созданная
+// операцией, попавшая в корзину или вернувшаяся из нее, записывается соответственно.
+// Отмена, повтор и возврат к ревизии записываются как есть.
+func revisionAction(action models.RevisionAction, change taskChange) models.RevisionAction {
+	if change.before == nil {
+		return models.RevisionCreate
+	}
+
+	switch action {
+	case models.RevisionUndo, models.RevisionRedo, models.RevisionRevert:
+		return action
+	}
+
+	wasDeleted, isDeleted := change.before.DeletedAt != nil, change.after.DeletedAt != nil
+	switch {
+	case !wasDeleted && isDeleted:
+		return models.RevisionDelete
+	case wasDeleted && !isDeleted:
+		return models.RevisionRestore
+	case action == models.RevisionDelete:
+		// подзадачи, поднятые на уровень выше при удалении родителя
+		return models.RevisionUpdate
+	}
+	return action
+}
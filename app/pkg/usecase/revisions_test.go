@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestTaskHistoryRecordsFieldChanges(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	task := mustCreate(t, uc, "draft")
+
+	title := "final"
+	priority := models.TaskPriorityHigh
+	if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &title, Priority: &priority}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if _, err := uc.ToggleTaskComplete(ctx, task.ID, models.SubtaskModeNone); err != nil {
+		t.Fatalf("ToggleTaskComplete: %v", err)
+	}
+
+	revisions, err := uc.GetTaskHistory(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTaskHistory: %v", err)
+	}
+	actions := []models.RevisionAction{models.RevisionToggle, models.RevisionUpdate, models.RevisionCreate}
+	if len(revisions) != len(actions) {
+		t.Fatalf("expected %d revisions, got %d", len(actions), len(revisions))
+	}
+	for i, action := range actions {
+		if revisions[i].Action != action || revisions[i].Actor != "tester" {
+			t.Errorf("revision %d: expected %s by tester, got %s by %q", i, action, revisions[i].Action, revisions[i].Actor)
+		}
+	}
+
+	update := revisions[1]
+	if len(update.Changes) != 2 {
+		t.Fatalf("expected title and priority changes, got %+v", update.Changes)
+	}
+	if update.Changes[0] != (models.FieldChange{Field: "title", Before: "draft", After: "final"}) {
+		t.Errorf("unexpected title change: %+v", update.Changes[0])
+	}
+	if update.Task.Title != "final" || update.Task.Status != models.TaskStatusPending {
+		t.Errorf("unexpected snapshot: %+v", update.Task)
+	}
+}
+
+func TestTaskHistoryDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	parent := mustCreate(t, uc, "parent")
+	child, err := uc.CreateTask(ctx, &models.CreateTaskRequest{ParentID: &parent.ID, Title: "child", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if err := uc.DeleteTask(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := uc.RestoreTask(ctx, parent.ID); err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+
+	revisions, err := uc.GetTaskHistory(ctx, child.ID)
+	if err != nil {
+		t.Fatalf("GetTaskHistory: %v", err)
+	}
+	if len(revisions) != 3 || revisions[0].Action != models.RevisionRestore || revisions[1].Action != models.RevisionDelete {
+		t.Fatalf("expected restore, delete, create for subtask, got %+v", revisions)
+	}
+}
+
+func TestRevertTask(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	task := mustCreate(t, uc, "v1")
+
+	for _, title := range []string{"v2", "v3"} {
+		if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+	}
+
+	revisions, err := uc.GetTaskHistory(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTaskHistory: %v", err)
+	}
+	first := revisions[len(revisions)-1]
+
+	reverted, err := uc.RevertTask(ctx, task.ID, first.ID)
+	if err != nil {
+		t.Fatalf("RevertTask: %v", err)
+	}
+	if reverted.Title != "v1" {
+		t.Errorf("expected title v1, got %q", reverted.Title)
+	}
+
+	revisions, _ = uc.GetTaskHistory(ctx, task.ID)
+	if revisions[0].Action != models.RevisionRevert {
+		t.Errorf("expected revert to be logged, got %s", revisions[0].Action)
+	}
+
+	// возврат к ревизии отменяется как обычная операция
+	result, err := uc.Undo(ctx)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if result.Action != HistoryRevert {
+		t.Errorf("expected revert to be undone, got %s", result.Action)
+	}
+	got, _ := uc.GetTask(ctx, task.ID)
+	if got.Title != "v3" {
+		t.Errorf("expected title v3 after undo, got %q", got.Title)
+	}
+
+	other := mustCreate(t, uc, "other")
+	if _, err := uc.RevertTask(ctx, other.ID, first.ID); err == nil {
+		t.Error("expected error for revision of another task")
+	}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+// snoozeMorningHour — час, на который откладывают «завтра» и «на следующей неделе»
+const snoozeMorningHour = 9
+
+// SnoozeTask откладывает задачу: срок переносится на выбранное время, напоминания
+// следуют за ним, счетчик откладываний растет. Срок, отсчитанный от текущего момента,
+// не проходит проверку UpdateTask на дату в прошлом; until учитывается только
+// для SnoozeCustom и должен быть в будущем.
+func (uc *taskUsecase) SnoozeTask(ctx context.Context, id int, option models.SnoozeOption, until *time.Time) (*models.Task, error) {
+	target, err := snoozeTarget(uc.now(), option, until)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := uc.snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := uc.taskService.SnoozeTask(ctx, id, target)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, HistorySnooze, before, []int{id})
+	event := newTaskEvent(TaskUpdated, task)
+	event.Changes = &models.UpdateTaskRequest{DueDate: task.DueDate, AllDay: &task.AllDay}
+	uc.publisher.Publish(event)
+	return task, nil
+}
+
+// snoozeTarget вычисляет новый срок задачи для варианта option относительно now
+func snoozeTarget(now time.Time, option models.SnoozeOption, until *time.Time) (time.Time, error) {
+	morning := func(days int) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day()+days, snoozeMorningHour, 0, 0, 0, now.Location())
+	}
+
+	switch option {
+	case models.Snooze15Minutes:
+		return now.Add(15 * time.Minute), nil
+	case models.Snooze1Hour:
+		return now.Add(time.Hour), nil
+	case models.SnoozeTomorrow:
+		return morning(1), nil
+	case models.SnoozeNextWeek:
+		// до понедельника от 1 до 7 дней: в понедельник — через неделю
+		days := (8 - int(now.Weekday())) % 7
+		if days == 0 {
+			days = 7
+		}
+		return morning(days), nil
+	case models.SnoozeCustom:
+		if until == nil {
+			return time.Time{}, fmt.Errorf("snooze time is required")
+		}
+		if !until.After(now) {
+			return time.Time{}, fmt.Errorf("snooze time must be in the future")
+		}
+		return *until, nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown snooze option %q", option)
+	}
+}
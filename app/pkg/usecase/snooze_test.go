@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/service"
+)
+
+func TestSnoozeTarget(t *testing.T) {
+	// среда, 14:30
+	now := time.Date(2025, time.March, 12, 14, 30, 0, 0, time.UTC)
+	custom := now.Add(3 * time.Hour)
+	past := now.Add(-time.Minute)
+
+	tests := []struct {
+		option models.SnoozeOption
+		until  *time.Time
+		want   time.Time
+	}{
+		{models.Snooze15Minutes, nil, now.Add(15 * time.Minute)},
+		{models.Snooze1Hour, nil, now.Add(time.Hour)},
+		{models.SnoozeTomorrow, nil, time.Date(2025, time.March, 13, 9, 0, 0, 0, time.UTC)},
+		{models.SnoozeNextWeek, nil, time.Date(2025, time.March, 17, 9, 0, 0, 0, time.UTC)},
+		{models.SnoozeCustom, &custom, custom},
+	}
+	for _, tt := range tests {
+		got, err := snoozeTarget(now, tt.option, tt.until)
+		if err != nil {
+			t.Errorf("%s: %v", tt.option, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.option, tt.want, got)
+		}
+	}
+
+	// в понедельник «следующая неделя» — понедельник через неделю
+	monday := time.Date(2025, time.March, 17, 8, 0, 0, 0, time.UTC)
+	if got, _ := snoozeTarget(monday, models.SnoozeNextWeek, nil); !got.Equal(time.Date(2025, time.March, 24, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected next Monday, got %v", got)
+	}
+
+	for _, until := range []*time.Time{nil, &past} {
+		if _, err := snoozeTarget(now, models.SnoozeCustom, until); err == nil {
+			t.Errorf("expected custom snooze to %v to be rejected", until)
+		}
+	}
+	if _, err := snoozeTarget(now, "forever", nil); err == nil {
+		t.Error("expected unknown option to be rejected")
+	}
+}
+
+func TestSnoozeTaskIsUndoable(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+
+	due := time.Now().Add(time.Minute)
+	task, err := uc.CreateTask(ctx, &models.CreateTaskRequest{Title: "call", Priority: models.TaskPriorityLow, DueDate: &due})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	snoozed, err := uc.SnoozeTask(ctx, task.ID, models.Snooze1Hour, nil)
+	if err != nil {
+		t.Fatalf("SnoozeTask: %v", err)
+	}
+	if snoozed.SnoozeCount != 1 || snoozed.DueDate == nil || snoozed.DueDate.Before(due.Add(50*time.Minute)) {
+		t.Fatalf("expected task snoozed for an hour, got %+v", snoozed)
+	}
+
+	revisions, _ := uc.GetTaskHistory(ctx, task.ID)
+	if revisions[0].Action != models.RevisionSnooze {
+		t.Errorf("expected snooze in the journal, got %s", revisions[0].Action)
+	}
+
+	result, err := uc.Undo(ctx)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if result.Action != HistorySnooze {
+		t.Errorf("expected snooze to be undone, got %s", result.Action)
+	}
+	got, _ := uc.GetTask(ctx, task.ID)
+	if got.SnoozeCount != 0 || !got.DueDate.Equal(due) {
+		t.Errorf("expected original due date and count after undo, got %v and %d", got.DueDate, got.SnoozeCount)
+	}
+
+	if _, err := uc.ToggleTaskComplete(ctx, task.ID, models.SubtaskModeNone); err != nil {
+		t.Fatalf("ToggleTaskComplete: %v", err)
+	}
+	if _, err := uc.SnoozeTask(ctx, task.ID, models.Snooze15Minutes, nil); err == nil {
+		t.Error("expected completed task to be rejected")
+	}
+}
+
+func TestSnoozeOverdueTask(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryTaskRepository()
+	uc := NewTaskUsecase(service.NewTaskService(repo), nil, "tester")
+
+	overdue := time.Now().Add(-24 * time.Hour)
+	task := &models.Task{Title: "overdue", Priority: models.TaskPriorityMedium, DueDate: &overdue}
+	if err := repo.Create(ctx, task); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{DueDate: &overdue}); err == nil {
+		t.Fatal("expected UpdateTask to reject a due date in the past")
+	}
+
+	snoozed, err := uc.SnoozeTask(ctx, task.ID, models.SnoozeTomorrow, nil)
+	if err != nil {
+		t.Fatalf("SnoozeTask: %v", err)
+	}
+	if snoozed.IsOverdue() || snoozed.DueDate.Hour() != snoozeMorningHour {
+		t.Errorf("expected task moved to tomorrow morning, got %v", snoozed.DueDate)
+	}
+}
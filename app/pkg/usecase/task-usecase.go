@@ -1,27 +1,104 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
+	"todo-lits-DMARK/app/pkg/dates"
 	"todo-lits-DMARK/app/pkg/models"
 	"todo-lits-DMARK/app/pkg/service"
 )
 
 type TaskUsecase interface {
 	// Основные операции CRUD
-	CreateTask(req *models.CreateTaskRequest) (*models.Task, error)
-	GetTask(id int) (*models.Task, error)
-	GetTasks(status string, priority string, sortBy string, sortOrder string) ([]*models.Task, error)
-	UpdateTask(id int, updates *models.UpdateTaskRequest) (*models.Task, error)
-	DeleteTask(id int) error
+	CreateTask(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, error)
+	GetTask(ctx context.Context, id int) (*models.Task, error)
+	GetTasks(ctx context.Context, status string, priority string, sortBy string, sortOrder string, tags []string, tagMatch string, projectID int, query string) ([]*models.Task, error)
+	GetTasksPage(ctx context.Context, status string, priority string, sortBy string, sortOrder string, tags []string, tagMatch string, projectID int, query string, limit int, cursor string) (*models.TaskPage, error)
+	UpdateTask(ctx context.Context, id int, updates *models.UpdateTaskRequest) (*models.Task, error)
+	DeleteTask(ctx context.Context, id int) error
 
 	// Специальные операции
-	ToggleTaskComplete(id int) (*models.Task, error)
-	GetTasksByDateRange(dateFilter string) ([]*models.Task, error)
-	GetDashboardData() (*DashboardData, error)
-	SearchTasks(query string) ([]*models.Task, error)
-	BulkUpdateTasks(ids []int, updates *models.UpdateTaskRequest) error
+	ToggleTaskComplete(ctx context.Context, id int, mode models.SubtaskMode) (*models.Task, error)
+	GetTasksByDateRange(ctx context.Context, dateFilter string) ([]*models.Task, error)
+	GetDashboardData(ctx context.Context) (*DashboardData, error)
+	SearchTasks(ctx context.Context, query string, limit int, cursor string) (*models.SearchPage, error)
+
+	// Пакетные операции выполняются целиком или не выполняются вовсе.
+	// Выбранные вместе с родителем подзадачи обрабатываются один раз.
+	BulkUpdateTasks(ctx context.Context, ids []int, updates *models.UpdateTaskRequest) error
+	BulkSetStatus(ctx context.Context, ids []int, status models.TaskStatus) error
+	BulkTagTasks(ctx context.Context, ids []int, add, remove []string) error
+	BulkMoveToProject(ctx context.Context, ids []int, projectID int) error
+	BulkDeleteTasks(ctx context.Context, ids []int) error
+
+	// Корзина: DeleteTask переносит задачу с подзадачами в корзину
+	GetTrash(ctx context.Context) ([]*models.Task, error)
+	RestoreTask(ctx context.Context, id int) (*models.Task, error)
+	PurgeTask(ctx context.Context, id int) error
+	EmptyTrash(ctx context.Context) (int, error)
+	PurgeExpiredTrash(ctx context.Context, retentionDays int) (int, error)
+
+	// История: отмена и повтор создания, изменения, удаления и завершения задач в рамках сессии.
+	// Пустая история возвращает ErrNothingToUndo и ErrNothingToRedo.
+	Undo(ctx context.Context) (*HistoryResult, error)
+	Redo(ctx context.Context) (*HistoryResult, error)
+
+	// Журнал изменений: каждое изменение задачи с автором, полями до и после и состоянием задачи.
+	// RevertTask возвращает задачу к состоянию после выбранной ревизии.
+	GetTaskHistory(ctx context.Context, id int) ([]*models.TaskRevision, error)
+	RevertTask(ctx context.Context, taskID, revisionID int) (*models.Task, error)
+
+	// Напоминания: в момент времени или за несколько минут до срока задачи.
+	// FireDueReminders отдает каждое наступившее напоминание один раз, включая пропущенные,
+	// пока приложение было закрыто; ReleaseReminder отдает недоставленное напоминание следующей
+	// проверке; NextReminderAt — когда проверять снова.
+	AddReminder(ctx context.Context, taskID int, req *models.CreateReminderRequest) (*models.Reminder, error)
+	GetReminders(ctx context.Context, taskID int) ([]*models.Reminder, error)
+	DeleteReminder(ctx context.Context, id int) error
+	FireDueReminders(ctx context.Context, now time.Time) ([]*models.DueReminder, error)
+	ReleaseReminder(ctx context.Context, id int) error
+	NextReminderAt(ctx context.Context) (*time.Time, error)
+
+	// Отложить задачу: на 15 минут, час, до завтрашнего утра, до следующей недели или до времени until.
+	// Срок и напоминания переносятся одной транзакцией, операция отменяется через Undo.
+	SnoozeTask(ctx context.Context, id int, option models.SnoozeOption, until *time.Time) (*models.Task, error)
+
+	// Быстрое добавление из одной строки: "Call Bob tomorrow 3pm !high #work every monday".
+	// ParseQuickAdd показывает результат разбора, QuickAdd создает задачу.
+	ParseQuickAdd(ctx context.Context, text string) (*models.CreateTaskRequest, error)
+	QuickAdd(ctx context.Context, text string, projectID *int) (*models.Task, error)
+
+	// Часовой пояс пользователя: в нем считаются «сегодня», неделя, фильтры по датам и сроки на весь день
+	Location() *time.Location
+	SetLocation(loc *time.Location)
+
+	// Подзадачи
+	GetTaskTree(ctx context.Context, id int) (*models.TaskNode, error)
+	GetTasksTree(ctx context.Context, status string, priority string, sortBy string, sortOrder string, tags []string, tagMatch string, projectID int, query string) ([]*models.TaskNode, error)
+	MoveTask(ctx context.Context, id int, parentID *int) (*models.Task, error)
+	DeleteTaskKeepSubtasks(ctx context.Context, id int) error
+
+	// Теги
+	CreateTag(ctx context.Context, name string) (*models.Tag, error)
+	GetTags(ctx context.Context) ([]*models.Tag, error)
+	RenameTag(ctx context.Context, id int, name string) (*models.Tag, error)
+	DeleteTag(ctx context.Context, id int) error
+
+	// Проекты
+	CreateProject(ctx context.Context, req *models.CreateProjectRequest) (*models.Project, error)
+	GetProjects(ctx context.Context) ([]*models.Project, error)
+	UpdateProject(ctx context.Context, id int, updates *models.UpdateProjectRequest) (*models.Project, error)
+	ReorderProjects(ctx context.Context, ids []int) error
+	DeleteProject(ctx context.Context, id int) error
+	MoveTaskToProject(ctx context.Context, id int, projectID int) (*models.Task, error)
+
+	// Сохраненные фильтры (умные списки), вычисляются через GetTasks с query filter:"имя"
+	CreateSavedFilter(ctx context.Context, req *models.CreateSavedFilterRequest) (*models.SavedFilter, error)
+	GetSavedFilters(ctx context.Context) ([]*models.SavedFilter, error)
+	UpdateSavedFilter(ctx context.Context, id int, updates *models.UpdateSavedFilterRequest) (*models.SavedFilter, error)
+	DeleteSavedFilter(ctx context.Context, id int) error
 }
 type DashboardData struct {
 	Stats         *service.TaskStats `json:"stats"`
@@ -29,17 +106,38 @@ type DashboardData struct {
 	OverdueTasks  []*models.Task     `json:"overdue_tasks"`
 	TodayTasks    []*models.Task     `json:"today_tasks"`
 	UpcomingTasks []*models.Task     `json:"upcoming_tasks"`
+	Tags          []*models.Tag      `json:"tags"`
+
+	// будущие повторения на этой неделе, отдельными задачами еще не созданы
+	UpcomingOccurrences []*models.Occurrence `json:"upcoming_occurrences"`
+
+	// сохраненные фильтры с TaskCount, посчитанным на момент запроса
+	SavedFilters []*models.SavedFilter `json:"saved_filters"`
 }
 type taskUsecase struct {
 	taskService service.TaskService
+	publisher   EventPublisher
+	history     *history
+	actor       string
 }
 
-func NewTaskUsecase(taskService service.TaskService) TaskUsecase {
+// publisher получает событие после каждой успешной мутации, nil отключает события.
+// actor записывается в журнал изменений как автор, например "user@host".
+func NewTaskUsecase(taskService service.TaskService, publisher EventPublisher, actor string) TaskUsecase {
+	if publisher == nil {
+		publisher = nopPublisher{}
+	}
+	if actor == "" {
+		actor = "unknown"
+	}
 	return &taskUsecase{
 		taskService: taskService,
+		publisher:   publisher,
+		history:     &history{},
+		actor:       actor,
 	}
 }
-func (uc *taskUsecase) CreateTask(req *models.CreateTaskRequest) (*models.Task, error) {
+func (uc *taskUsecase) CreateTask(ctx context.Context, req *models.CreateTaskRequest) (*models.Task, error) {
 
 	if strings.TrimSpace(req.Title) == "" {
 		return nil, fmt.Errorf("task title cannot be empty")
@@ -48,24 +146,54 @@ func (uc *taskUsecase) CreateTask(req *models.CreateTaskRequest) (*models.Task,
 	req.Title = strings.TrimSpace(req.Title)
 	req.Description = strings.TrimSpace(req.Description)
 
-	if req.DueDate != nil && req.DueDate.Before(time.Now()) {
+	if req.DueDate != nil && uc.dueInPast(*req.DueDate, req.AllDay) {
 		return nil, fmt.Errorf("due date cannot be in the past")
 	}
 
-	task, err := uc.taskService.CreateTask(req)
+	task, err := uc.taskService.CreateTask(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
+	uc.record(ctx, HistoryCreate, nil, []int{task.ID})
+	uc.publisher.Publish(newTaskEvent(TaskCreated, task))
 	return task, nil
 }
-func (uc *taskUsecase) GetTask(id int) (*models.Task, error) {
-	return uc.taskService.GetTask(id)
+func (uc *taskUsecase) GetTask(ctx context.Context, id int) (*models.Task, error) {
+	return uc.taskService.GetTask(ctx, id)
 }
 
-func (uc *taskUsecase) GetTasks(status string, priority string, sortBy string, sortOrder string) ([]*models.Task, error) {
+// tagMatch: "any" (по умолчанию) — есть любой из тегов, "all" — есть все теги.
+// projectID 0 показывает все проекты, кроме архивных.
+// query — строка языка фильтров, например `status:pending priority:>=medium due:<7d tag:work sort:due`.
+// Условия запроса дополняют остальные параметры, его sort заменяет sortBy и sortOrder.
+// filter:"имя" подставляет условия сохраненного фильтра.
+func (uc *taskUsecase) GetTasks(ctx context.Context, status string, priority string, sortBy string, sortOrder string, tags []string, tagMatch string, projectID int, query string) ([]*models.Task, error) {
+	filter, sort, err := uc.taskListing(ctx, status, priority, sortBy, sortOrder, tags, tagMatch, projectID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.taskService.GetAllTasks(ctx, filter, sort)
+}
+
+// GetTasksPage — постраничный GetTasks. limit <= 0 означает размер страницы по умолчанию,
+// cursor берется из NextCursor предыдущей страницы и действует только для тех же параметров.
+func (uc *taskUsecase) GetTasksPage(ctx context.Context, status string, priority string, sortBy string, sortOrder string, tags []string, tagMatch string, projectID int, query string, limit int, cursor string) (*models.TaskPage, error) {
+	filter, sort, err := uc.taskListing(ctx, status, priority, sortBy, sortOrder, tags, tagMatch, projectID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.taskService.GetTasksPage(ctx, filter, sort, models.PageRequest{Limit: limit, Cursor: cursor})
+}
 
-	filter := &models.TaskFilter{}
+// taskListing собирает фильтр и сортировку из параметров GetTasks
+func (uc *taskUsecase) taskListing(ctx context.Context, status string, priority string, sortBy string, sortOrder string, tags []string, tagMatch string, projectID int, query string) (*models.TaskFilter, *models.TaskSort, error) {
+	filter := &models.TaskFilter{
+		Tags:     tags,
+		TagMatch: models.TagMatchMode(tagMatch),
+	}
 
 	if status != "" && status != "all" {
 		taskStatus := models.TaskStatus(status)
@@ -89,10 +217,49 @@ func (uc *taskUsecase) GetTasks(status string, priority string, sortBy string, s
 		}
 	}
 
-	return uc.taskService.GetAllTasks(filter, sort)
+	if strings.TrimSpace(query) != "" {
+		parser := &queryParser{
+			now: uc.now(),
+			projects: func() ([]*models.Project, error) {
+				return uc.taskService.GetProjects(ctx)
+			},
+			savedFilters: func() ([]*models.SavedFilter, error) {
+				return uc.taskService.GetSavedFilters(ctx)
+			},
+		}
+		parsed, err := parser.parse(query)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter.Query = parsed
+		if parsed.Sort != nil {
+			sort = parsed.Sort
+		}
+	}
+
+	// project: в запросе выбирает проект явно, как и projectID, поэтому архив не скрывается
+	if projectID > 0 {
+		filter.ProjectID = &projectID
+	} else if !selectsProject(filter.Query) {
+		filter.HideArchived = true
+	}
+
+	return filter, sort, nil
+}
+
+func selectsProject(query *models.TaskQuery) bool {
+	if query == nil {
+		return false
+	}
+	for _, condition := range query.Conditions {
+		if condition.Field == models.QueryFieldProject && !condition.Negated {
+			return true
+		}
+	}
+	return false
 }
 
-func (uc *taskUsecase) UpdateTask(id int, updates *models.UpdateTaskRequest) (*models.Task, error) {
+func (uc *taskUsecase) UpdateTask(ctx context.Context, id int, updates *models.UpdateTaskRequest) (*models.Task, error) {
 
 	if updates.Title != nil {
 		title := strings.TrimSpace(*updates.Title)
@@ -107,22 +274,158 @@ func (uc *taskUsecase) UpdateTask(id int, updates *models.UpdateTaskRequest) (*m
 		updates.Description = &description
 	}
 
-	if updates.DueDate != nil && updates.DueDate.Before(time.Now()) {
+	if updates.DueDate != nil && uc.dueInPast(*updates.DueDate, updates.AllDay != nil && *updates.AllDay) {
 		return nil, fmt.Errorf("due date cannot be in the past")
 	}
 
-	return uc.taskService.UpdateTask(id, updates)
+	before, err := uc.snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := uc.taskService.UpdateTask(ctx, id, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.record(ctx, HistoryUpdate, before, []int{id})
+	event := newTaskEvent(TaskUpdated, task)
+	event.Changes = updates
+	uc.publisher.Publish(event)
+	return task, nil
+}
+
+// подзадачи удаляются вместе с задачей, их id тоже попадают в событие
+func (uc *taskUsecase) DeleteTask(ctx context.Context, id int) error {
+	tree, err := uc.taskService.GetTaskTree(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	ids := taskIDs(flattenTree(tree))
+	before, err := uc.snapshot(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.taskService.DeleteTask(ctx, id); err != nil {
+		return err
+	}
+
+	uc.record(ctx, HistoryDelete, before, ids)
+	event := newTaskEvent(TaskDeleted)
+	event.TaskIDs = ids
+	uc.publisher.Publish(event)
+	return nil
+}
+
+func (uc *taskUsecase) GetTrash(ctx context.Context) ([]*models.Task, error) {
+	return uc.taskService.GetTrash(ctx)
+}
+
+// RestoreTask возвращает задачу вместе с подзадачами, удаленными одновременно с ней
+func (uc *taskUsecase) RestoreTask(ctx context.Context, id int) (*models.Task, error) {
+	before, err := uc.snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := uc.taskService.RestoreTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// подзадачи восстанавливаются только из той же пачки удаления, что и задача
+	if len(before) == 1 {
+		for _, task := range restored[1:] {
+			trashed := *task
+			trashed.DeletedAt = before[0].DeletedAt
+			before = append(before, &trashed)
+		}
+	}
+	uc.audit(ctx, models.RevisionRestore, before, taskIDs(restored))
+
+	uc.publisher.Publish(newTaskEvent(TaskRestored, restored...))
+	return restored[0], nil
+}
+
+func (uc *taskUsecase) PurgeTask(ctx context.Context, id int) error {
+	if err := uc.taskService.PurgeTask(ctx, id); err != nil {
+		return err
+	}
+
+	uc.publisher.Publish(newTaskEvent(TrashChanged))
+	return nil
+}
+
+func (uc *taskUsecase) EmptyTrash(ctx context.Context) (int, error) {
+	count, err := uc.taskService.EmptyTrash(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		uc.publisher.Publish(newTaskEvent(TrashChanged))
+	}
+	return count, nil
 }
 
-func (uc *taskUsecase) DeleteTask(id int) error {
-	return uc.taskService.DeleteTask(id)
+// PurgeExpiredTrash удаляет навсегда задачи старше retentionDays дней в корзине, 0 отключает очистку
+func (uc *taskUsecase) PurgeExpiredTrash(ctx context.Context, retentionDays int) (int, error) {
+	count, err := uc.taskService.PurgeExpiredTrash(ctx, time.Duration(retentionDays)*24*time.Hour)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		uc.publisher.Publish(newTaskEvent(TrashChanged))
+	}
+	return count, nil
 }
 
-func (uc *taskUsecase) ToggleTaskComplete(id int) (*models.Task, error) {
-	return uc.taskService.ToggleTaskStatus(id)
+func (uc *taskUsecase) ToggleTaskComplete(ctx context.Context, id int, mode models.SubtaskMode) (*models.Task, error) {
+	// при завершении вместе с подзадачами меняется все поддерево
+	ids := []int{id}
+	if mode == models.SubtaskModeComplete {
+		tree, err := uc.taskService.GetTaskTree(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		ids = taskIDs(flattenTree(tree))
+	}
+	before, err := uc.snapshot(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	task, next, err := uc.taskService.ToggleTaskStatus(ctx, id, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	// следующее повторение создано переключением и отменяется вместе с ним
+	if next != nil {
+		ids = append(ids, next.ID)
+	}
+	uc.record(ctx, HistoryToggle, before, ids)
+
+	affected := []*models.Task{task}
+	if mode == models.SubtaskModeComplete && task.Status == models.TaskStatusCompleted {
+		tree, err := uc.taskService.GetTaskTree(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		affected = flattenTree(tree)
+	}
+
+	uc.publisher.Publish(newTaskEvent(TaskToggled, affected...))
+	if next != nil {
+		uc.publisher.Publish(newTaskEvent(TaskCreated, next))
+	}
+	return task, nil
 }
 
-func (uc *taskUsecase) GetTasksByDateRange(dateFilter string) ([]*models.Task, error) {
+func (uc *taskUsecase) GetTasksByDateRange(ctx context.Context, dateFilter string) ([]*models.Task, error) {
 	validFilters := map[string]bool{
 		"today":   true,
 		"week":    true,
@@ -133,118 +436,338 @@ func (uc *taskUsecase) GetTasksByDateRange(dateFilter string) ([]*models.Task, e
 		return nil, fmt.Errorf("invalid date filter: %s. Valid filters: today, week, overdue", dateFilter)
 	}
 
-	return uc.taskService.GetTasksByDateFilter(dateFilter)
+	return uc.taskService.GetTasksByDateFilter(ctx, dateFilter)
 }
 
-func (uc *taskUsecase) GetDashboardData() (*DashboardData, error) {
+func (uc *taskUsecase) GetDashboardData(ctx context.Context) (*DashboardData, error) {
 
-	stats, err := uc.taskService.GetTaskStats()
+	stats, err := uc.taskService.GetTaskStats(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task stats: %w", err)
 	}
 
 	recentSort := &models.TaskSort{Field: "created_at", Order: "desc"}
-	allTasks, err := uc.taskService.GetAllTasks(nil, recentSort)
+	recent, err := uc.taskService.GetTasksPage(ctx, &models.TaskFilter{HideArchived: true}, recentSort, models.PageRequest{Limit: 5})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recent tasks: %w", err)
 	}
+	recentTasks := recent.Tasks
 
-	var recentTasks []*models.Task
-	if len(allTasks) > 5 {
-		recentTasks = allTasks[:5]
-	} else {
-		recentTasks = allTasks
-	}
-
-	overdueTasks, err := uc.taskService.GetOverdueTasks()
+	overdueTasks, err := uc.taskService.GetOverdueTasks(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get overdue tasks: %w", err)
 	}
 
-	todayTasks, err := uc.taskService.GetTasksByDateFilter("today")
+	todayTasks, err := uc.taskService.GetTasksByDateFilter(ctx, "today")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get today tasks: %w", err)
 	}
 
-	upcomingTasks, err := uc.taskService.GetTasksByDateFilter("week")
+	upcomingTasks, err := uc.taskService.GetTasksByDateFilter(ctx, "week")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get upcoming tasks: %w", err)
 	}
 
+	// дни сравниваются в зоне пользователя
 	var filteredUpcoming []*models.Task
-	today := time.Now().Format("2006-01-02")
+	now := uc.now()
+	today := now.Format(dates.DateLayout)
 
 	for _, task := range upcomingTasks {
-		if task.DueDate != nil && task.DueDate.Format("2006-01-02") != today {
+		if task.DueDate != nil && task.DueDate.In(now.Location()).Format(dates.DateLayout) != today {
 			filteredUpcoming = append(filteredUpcoming, task)
 		}
 	}
 
+	tags, err := uc.taskService.GetTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	occurrences, err := uc.taskService.GetUpcomingOccurrences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upcoming occurrences: %w", err)
+	}
+
+	savedFilters, err := uc.taskService.GetSavedFilters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved filters: %w", err)
+	}
+
+	// фильтр с ошибкой, например с удаленным проектом, не ломает дашборд.
+	// Счетчик берется из COUNT страницы, сами задачи не загружаются.
+	for _, filter := range savedFilters {
+		page, err := uc.GetTasksPage(ctx, "", "", "", "", nil, "", 0, filter.Query, 1, "")
+		if err != nil {
+			filter.Error = err.Error()
+			continue
+		}
+		filter.TaskCount = page.Total
+	}
+
 	return &DashboardData{
 		Stats:         stats,
 		RecentTasks:   recentTasks,
 		OverdueTasks:  overdueTasks,
 		TodayTasks:    todayTasks,
 		UpcomingTasks: filteredUpcoming,
+		Tags:          tags,
+
+		UpcomingOccurrences: occurrences,
+		SavedFilters:        savedFilters,
 	}, nil
 }
 
-func (uc *taskUsecase) SearchTasks(query string) ([]*models.Task, error) {
+// SearchTasks поддерживает "фразы", префиксы слово* и исключения -слово.
+// Результаты приходят страницами, cursor берется из NextCursor предыдущей страницы.
+func (uc *taskUsecase) SearchTasks(ctx context.Context, query string, limit int, cursor string) (*models.SearchPage, error) {
 	if strings.TrimSpace(query) == "" {
 		return nil, fmt.Errorf("search query cannot be empty")
 	}
 
-	allTasks, err := uc.taskService.GetAllTasks(nil, nil)
+	return uc.taskService.SearchTasks(ctx, query, models.PageRequest{Limit: limit, Cursor: cursor})
+}
+
+func (uc *taskUsecase) GetTaskTree(ctx context.Context, id int) (*models.TaskNode, error) {
+	return uc.taskService.GetTaskTree(ctx, id)
+}
+
+func (uc *taskUsecase) GetTasksTree(ctx context.Context, status string, priority string, sortBy string, sortOrder string, tags []string, tagMatch string, projectID int, query string) ([]*models.TaskNode, error) {
+	tasks, err := uc.GetTasks(ctx, status, priority, sortBy, sortOrder, tags, tagMatch, projectID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.BuildTaskForest(tasks), nil
+}
+
+func (uc *taskUsecase) MoveTask(ctx context.Context, id int, parentID *int) (*models.Task, error) {
+	before, err := uc.snapshot(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search tasks: %w", err)
+		return nil, err
 	}
 
-	var matchedTasks []*models.Task
-	query = strings.ToLower(strings.TrimSpace(query))
+	task, err := uc.taskService.MoveTask(ctx, id, parentID)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, task := range allTasks {
+	uc.audit(ctx, models.RevisionUpdate, before, []int{id})
 
-		if strings.Contains(strings.ToLower(task.Title), query) ||
-			strings.Contains(strings.ToLower(task.Description), query) {
-			matchedTasks = append(matchedTasks, task)
-		}
+	uc.publisher.Publish(newTaskEvent(TaskUpdated, task))
+	return task, nil
+}
+
+// DeleteTaskKeepSubtasks удаляет задачу, а ее подзадачи переносит к ее родителю
+func (uc *taskUsecase) DeleteTaskKeepSubtasks(ctx context.Context, id int) error {
+	tree, err := uc.taskService.GetTaskTree(ctx, id)
+	if err != nil {
+		return err
+	}
+	ids := []int{id}
+	for _, child := range tree.Children {
+		ids = append(ids, child.Task.ID)
+	}
+	before, err := uc.snapshot(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	moved, err := uc.taskService.DeleteTaskKeepingSubtasks(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	return matchedTasks, nil
+	uc.record(ctx, HistoryDelete, before, ids)
+
+	if len(moved) > 0 {
+		uc.publisher.Publish(newTaskEvent(TasksBulkUpdated, moved...))
+	}
+
+	event := newTaskEvent(TaskDeleted)
+	event.TaskIDs = []int{id}
+	uc.publisher.Publish(event)
+	return nil
 }
 
-func (uc *taskUsecase) BulkUpdateTasks(ids []int, updates *models.UpdateTaskRequest) error {
-	if len(ids) == 0 {
-		return fmt.Errorf("no task IDs provided")
+func (uc *taskUsecase) CreateTag(ctx context.Context, name string) (*models.Tag, error) {
+	tag, err := uc.taskService.CreateTag(ctx, name)
+	if err != nil {
+		return nil, err
 	}
 
-	if updates.Title != nil {
-		title := strings.TrimSpace(*updates.Title)
-		if title == "" {
-			return fmt.Errorf("task title cannot be empty")
-		}
-		updates.Title = &title
+	uc.publisher.Publish(newTaskEvent(TagsChanged))
+	return tag, nil
+}
+
+func (uc *taskUsecase) GetTags(ctx context.Context) ([]*models.Tag, error) {
+	return uc.taskService.GetTags(ctx)
+}
+
+// переименование и удаление меняют теги у задач, поэтому фронтенд перечитывает списки
+func (uc *taskUsecase) RenameTag(ctx context.Context, id int, name string) (*models.Tag, error) {
+	tag, err := uc.taskService.RenameTag(ctx, id, name)
+	if err != nil {
+		return nil, err
 	}
 
-	if updates.Description != nil {
-		description := strings.TrimSpace(*updates.Description)
-		updates.Description = &description
+	uc.publisher.Publish(newTaskEvent(TagsChanged))
+	return tag, nil
+}
+
+func (uc *taskUsecase) DeleteTag(ctx context.Context, id int) error {
+	if err := uc.taskService.DeleteTag(ctx, id); err != nil {
+		return err
+	}
+
+	uc.publisher.Publish(newTaskEvent(TagsChanged))
+	return nil
+}
+
+func (uc *taskUsecase) CreateProject(ctx context.Context, req *models.CreateProjectRequest) (*models.Project, error) {
+	project, err := uc.taskService.CreateProject(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.publisher.Publish(newTaskEvent(ProjectsChanged))
+	return project, nil
+}
+
+func (uc *taskUsecase) GetProjects(ctx context.Context) ([]*models.Project, error) {
+	return uc.taskService.GetProjects(ctx)
+}
+
+// архивирование прячет задачи проекта из списков, поэтому фронтенд перечитывает все
+func (uc *taskUsecase) UpdateProject(ctx context.Context, id int, updates *models.UpdateProjectRequest) (*models.Project, error) {
+	project, err := uc.taskService.UpdateProject(ctx, id, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.publisher.Publish(newTaskEvent(ProjectsChanged))
+	return project, nil
+}
+
+func (uc *taskUsecase) ReorderProjects(ctx context.Context, ids []int) error {
+	if err := uc.taskService.ReorderProjects(ctx, ids); err != nil {
+		return err
+	}
+
+	uc.publisher.Publish(newTaskEvent(ProjectsChanged))
+	return nil
+}
+
+func (uc *taskUsecase) DeleteProject(ctx context.Context, id int) error {
+	if err := uc.taskService.DeleteProject(ctx, id); err != nil {
+		return err
+	}
+
+	uc.publisher.Publish(newTaskEvent(ProjectsChanged))
+	return nil
+}
+
+// подзадачи переезжают вместе с задачей, поэтому событие содержит все поддерево
+func (uc *taskUsecase) MoveTaskToProject(ctx context.Context, id int, projectID int) (*models.Task, error) {
+	tree, err := uc.taskService.GetTaskTree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := taskIDs(flattenTree(tree))
+	before, err := uc.snapshot(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := uc.taskService.MoveTaskToProject(ctx, id, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.audit(ctx, models.RevisionUpdate, before, ids)
+
+	tree, err = uc.taskService.GetTaskTree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.publisher.Publish(newTaskEvent(TasksBulkUpdated, flattenTree(tree)...))
+	return task, nil
+}
+
+// запрос проверяется при сохранении, чтобы ошибка была видна сразу, а не на дашборде
+func (uc *taskUsecase) CreateSavedFilter(ctx context.Context, req *models.CreateSavedFilterRequest) (*models.SavedFilter, error) {
+	if err := uc.validateSavedFilterQuery(ctx, req.Query); err != nil {
+		return nil, err
 	}
 
-	if updates.DueDate != nil && updates.DueDate.Before(time.Now()) {
-		return fmt.Errorf("due date cannot be in the past")
+	filter, err := uc.taskService.CreateSavedFilter(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	var errors []string
-	for _, id := range ids {
-		if _, err := uc.taskService.UpdateTask(id, updates); err != nil {
-			errors = append(errors, fmt.Sprintf("failed to update task %d: %v", id, err))
+	uc.publisher.Publish(newTaskEvent(FiltersChanged))
+	return filter, nil
+}
+
+func (uc *taskUsecase) GetSavedFilters(ctx context.Context) ([]*models.SavedFilter, error) {
+	return uc.taskService.GetSavedFilters(ctx)
+}
+
+func (uc *taskUsecase) UpdateSavedFilter(ctx context.Context, id int, updates *models.UpdateSavedFilterRequest) (*models.SavedFilter, error) {
+	if updates.Query != nil {
+		if err := uc.validateSavedFilterQuery(ctx, *updates.Query); err != nil {
+			return nil, err
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("bulk update failed: %s", strings.Join(errors, "; "))
+	filter, err := uc.taskService.UpdateSavedFilter(ctx, id, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.publisher.Publish(newTaskEvent(FiltersChanged))
+	return filter, nil
+}
+
+func (uc *taskUsecase) DeleteSavedFilter(ctx context.Context, id int) error {
+	if err := uc.taskService.DeleteSavedFilter(ctx, id); err != nil {
+		return err
 	}
 
+	uc.publisher.Publish(newTaskEvent(FiltersChanged))
 	return nil
 }
+
+// сохраненный фильтр не может ссылаться на другие через filter:
+func (uc *taskUsecase) validateSavedFilterQuery(ctx context.Context, query string) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("saved filter query cannot be empty")
+	}
+
+	parser := &queryParser{
+		now: uc.now(),
+		projects: func() ([]*models.Project, error) {
+			return uc.taskService.GetProjects(ctx)
+		},
+	}
+	_, err := parser.parse(query)
+	return err
+}
+
+// flattenTree возвращает задачи дерева, родители идут раньше детей
+func flattenTree(root *models.TaskNode) []*models.Task {
+	tasks := []*models.Task{root.Task}
+	for _, child := range root.Children {
+		tasks = append(tasks, flattenTree(child)...)
+	}
+	return tasks
+}
+
+func taskIDs(tasks []*models.Task) []int {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
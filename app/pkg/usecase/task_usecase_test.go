@@ -0,0 +1,526 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/service"
+)
+
+func newTestUsecase() TaskUsecase {
+	return NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), nil, "tester")
+}
+
+func mustCreate(t *testing.T, uc TaskUsecase, title string) *models.Task {
+	t.Helper()
+	ctx := context.Background()
+
+	task, err := uc.CreateTask(ctx, &models.CreateTaskRequest{Title: title, Priority: models.TaskPriorityMedium})
+	if err != nil {
+		t.Fatalf("CreateTask(%q): %v", title, err)
+	}
+	return task
+}
+
+func TestCreateTaskTrimsInput(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+
+	task, err := uc.CreateTask(ctx, &models.CreateTaskRequest{
+		Title:       "  Buy milk  ",
+		Description: " 2 litres ",
+		Priority:    models.TaskPriorityLow,
+	})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.Title != "Buy milk" || task.Description != "2 litres" {
+		t.Errorf("input not trimmed: %+v", task)
+	}
+}
+
+func TestCreateTaskRejectsInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		req  *models.CreateTaskRequest
+	}{
+		{"blank title", &models.CreateTaskRequest{Title: "   ", Priority: models.TaskPriorityLow}},
+		{"past due date", &models.CreateTaskRequest{Title: "late", Priority: models.TaskPriorityLow, DueDate: &past}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.CreateTask(ctx, tt.req); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestGetTasksIgnoresAllFilter(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	mustCreate(t, uc, "first")
+	done := mustCreate(t, uc, "second")
+	if _, err := uc.ToggleTaskComplete(ctx, done.ID, models.SubtaskModeNone); err != nil {
+		t.Fatalf("ToggleTaskComplete: %v", err)
+	}
+
+	tasks, err := uc.GetTasks(ctx, "all", "all", "", "", nil, "", 0, "")
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+
+	tasks, err = uc.GetTasks(ctx, "completed", "", "", "", nil, "", 0, "")
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != done.ID {
+		t.Fatalf("expected only task %d, got %+v", done.ID, tasks)
+	}
+}
+
+func TestGetTasksPage(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	var titles []string
+	for _, title := range []string{"one", "two", "three", "four", "five"} {
+		mustCreate(t, uc, title)
+	}
+
+	// sort из запроса действует так же, как в GetTasks
+	cursor := ""
+	for {
+		page, err := uc.GetTasksPage(ctx, "", "", "", "", nil, "", 0, "sort:created:asc", 2, cursor)
+		if err != nil {
+			t.Fatalf("GetTasksPage: %v", err)
+		}
+		if page.Total != 5 {
+			t.Fatalf("expected total 5, got %d", page.Total)
+		}
+		for _, task := range page.Tasks {
+			titles = append(titles, task.Title)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if want := []string{"one", "two", "three", "four", "five"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("expected %v, got %v", want, titles)
+	}
+
+	if _, err := uc.GetTasksPage(ctx, "", "", "", "", nil, "", 0, "", 2, "garbage"); !errors.Is(err, service.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	mustCreate(t, uc, "Buy Milk")
+	mustCreate(t, uc, "Call mom")
+
+	page, err := uc.SearchTasks(ctx, "  milk ", 0, "")
+	if err != nil {
+		t.Fatalf("SearchTasks: %v", err)
+	}
+	if len(page.Results) != 1 || page.Results[0].Task.Title != "Buy Milk" || page.Total != 1 {
+		t.Fatalf("unexpected search result: %+v", page.Results)
+	}
+
+	if _, err := uc.SearchTasks(ctx, "  ", 0, ""); err == nil {
+		t.Fatal("expected error for empty query")
+	}
+}
+
+func TestGetDashboardDataLimitsRecentTasks(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	for i := 0; i < 7; i++ {
+		mustCreate(t, uc, "task")
+		time.Sleep(time.Millisecond)
+	}
+
+	data, err := uc.GetDashboardData(ctx)
+	if err != nil {
+		t.Fatalf("GetDashboardData: %v", err)
+	}
+	if data.Stats.Total != 7 {
+		t.Errorf("expected 7 tasks in stats, got %d", data.Stats.Total)
+	}
+	if len(data.RecentTasks) != 5 {
+		t.Fatalf("expected 5 recent tasks, got %d", len(data.RecentTasks))
+	}
+	if data.RecentTasks[0].ID != 7 {
+		t.Errorf("expected newest task first, got %d", data.RecentTasks[0].ID)
+	}
+}
+
+func TestBulkUpdateTasksIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	task := mustCreate(t, uc, "task")
+
+	priority := models.TaskPriorityHigh
+	err := uc.BulkUpdateTasks(ctx, []int{task.ID, 404}, &models.UpdateTaskRequest{Priority: &priority})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected failure for task 404, got %v", err)
+	}
+
+	updated, err := uc.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if updated.Priority != models.TaskPriorityMedium {
+		t.Errorf("failed batch must not update any task, got %q", updated.Priority)
+	}
+}
+
+type recordingPublisher struct {
+	events []TaskEvent
+}
+
+func (p *recordingPublisher) Publish(event TaskEvent) {
+	p.events = append(p.events, event)
+}
+
+func TestMutationsPublishEvents(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	task := mustCreate(t, uc, "task")
+	other := mustCreate(t, uc, "other")
+
+	title := "renamed"
+	if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{Title: &title}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if _, err := uc.ToggleTaskComplete(ctx, task.ID, models.SubtaskModeNone); err != nil {
+		t.Fatalf("ToggleTaskComplete: %v", err)
+	}
+	priority := models.TaskPriorityHigh
+	if err := uc.BulkUpdateTasks(ctx, []int{task.ID, other.ID}, &models.UpdateTaskRequest{Priority: &priority}); err != nil {
+		t.Fatalf("BulkUpdateTasks: %v", err)
+	}
+	if err := uc.DeleteTask(ctx, other.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	// неудачные операции событий не публикуют
+	if err := uc.DeleteTask(ctx, other.ID); err == nil {
+		t.Fatal("expected error deleting missing task")
+	}
+
+	want := []struct {
+		eventType TaskEventType
+		ids       []int
+	}{
+		{TaskCreated, []int{task.ID}},
+		{TaskCreated, []int{other.ID}},
+		{TaskUpdated, []int{task.ID}},
+		{TaskToggled, []int{task.ID}},
+		{TasksBulkUpdated, []int{task.ID, other.ID}},
+		{TaskDeleted, []int{other.ID}},
+	}
+	if len(publisher.events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), publisher.events)
+	}
+	for i, w := range want {
+		event := publisher.events[i]
+		if event.Type != w.eventType || len(event.TaskIDs) != len(w.ids) {
+			t.Fatalf("event %d: expected %s %v, got %s %v", i, w.eventType, w.ids, event.Type, event.TaskIDs)
+		}
+		for j := range w.ids {
+			if event.TaskIDs[j] != w.ids[j] {
+				t.Fatalf("event %d: expected ids %v, got %v", i, w.ids, event.TaskIDs)
+			}
+		}
+	}
+
+	updated := publisher.events[2]
+	if updated.Tasks[0].Title != title || updated.Changes == nil || *updated.Changes.Title != title {
+		t.Errorf("update event lacks payload: %+v", updated)
+	}
+	if publisher.events[3].Tasks[0].Status != models.TaskStatusCompleted {
+		t.Errorf("toggle event should carry new status, got %q", publisher.events[3].Tasks[0].Status)
+	}
+}
+
+func TestSubtaskEventsCoverDescendants(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	parent := mustCreate(t, uc, "parent")
+	child, err := uc.CreateTask(ctx, &models.CreateTaskRequest{ParentID: &parent.ID, Title: "child", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if _, err := uc.ToggleTaskComplete(ctx, parent.ID, models.SubtaskModeComplete); err != nil {
+		t.Fatalf("ToggleTaskComplete: %v", err)
+	}
+	if err := uc.DeleteTask(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	toggled := publisher.events[2]
+	if toggled.Type != TaskToggled || len(toggled.Tasks) != 2 || toggled.Tasks[1].Status != models.TaskStatusCompleted {
+		t.Errorf("toggle event should include completed subtask, got %+v", toggled)
+	}
+	deleted := publisher.events[3]
+	if deleted.Type != TaskDeleted || len(deleted.TaskIDs) != 2 || deleted.TaskIDs[1] != child.ID {
+		t.Errorf("delete event should include subtask ids, got %+v", deleted)
+	}
+}
+
+func TestDashboardIncludesTagCounts(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	for _, tags := range [][]string{{"work"}, {"work", "home"}} {
+		if _, err := uc.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityLow, Tags: tags}); err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+	}
+
+	data, err := uc.GetDashboardData(ctx)
+	if err != nil {
+		t.Fatalf("GetDashboardData: %v", err)
+	}
+	if len(data.Tags) != 2 || data.Tags[0].Name != "home" || data.Tags[0].TaskCount != 1 ||
+		data.Tags[1].Name != "work" || data.Tags[1].TaskCount != 2 {
+		t.Errorf("unexpected tag counts: %+v", data.Tags)
+	}
+
+	tasks, err := uc.GetTasks(ctx, "", "", "", "", []string{"work", "home"}, "all", 0, "")
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Errorf("expected one task with both tags, got %d", len(tasks))
+	}
+
+	if err := uc.DeleteTag(ctx, data.Tags[0].ID); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if last := publisher.events[len(publisher.events)-1]; last.Type != TagsChanged {
+		t.Errorf("expected %s event, got %s", TagsChanged, last.Type)
+	}
+}
+
+func TestArchivedProjectHiddenFromDefaultView(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	project, err := uc.CreateProject(ctx, &models.CreateProjectRequest{Name: "work"})
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	loose := mustCreate(t, uc, "loose")
+	report, err := uc.CreateTask(ctx, &models.CreateTaskRequest{Title: "report", Priority: models.TaskPriorityLow, ProjectID: &project.ID})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := uc.CreateTask(ctx, &models.CreateTaskRequest{ParentID: &report.ID, Title: "draft", Priority: models.TaskPriorityLow}); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	archived := true
+	if _, err := uc.UpdateProject(ctx, project.ID, &models.UpdateProjectRequest{Archived: &archived}); err != nil {
+		t.Fatalf("UpdateProject: %v", err)
+	}
+	if last := publisher.events[len(publisher.events)-1]; last.Type != ProjectsChanged {
+		t.Errorf("expected %s event, got %s", ProjectsChanged, last.Type)
+	}
+
+	tasks, err := uc.GetTasks(ctx, "", "", "", "", nil, "", 0, "")
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != loose.ID {
+		t.Errorf("expected only inbox task in default view, got %+v", tasks)
+	}
+
+	tasks, err = uc.GetTasks(ctx, "", "", "", "", nil, "", project.ID, "")
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("expected archived project tasks by explicit filter, got %d", len(tasks))
+	}
+
+	data, err := uc.GetDashboardData(ctx)
+	if err != nil {
+		t.Fatalf("GetDashboardData: %v", err)
+	}
+	if len(data.RecentTasks) != 1 || data.Stats.Total != 1 {
+		t.Errorf("expected archived tasks hidden from dashboard, got %d recent, %d total", len(data.RecentTasks), data.Stats.Total)
+	}
+
+	// перенос задачи публикует все поддерево
+	if _, err := uc.MoveTaskToProject(ctx, report.ID, loose.ProjectID); err != nil {
+		t.Fatalf("MoveTaskToProject: %v", err)
+	}
+	last := publisher.events[len(publisher.events)-1]
+	if last.Type != TasksBulkUpdated || len(last.TaskIDs) != 2 {
+		t.Errorf("expected bulk update with subtree, got %+v", last)
+	}
+}
+
+func TestToggleRecurringTaskPublishesNextOccurrence(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	due := time.Now().Add(time.Hour)
+	task, err := uc.CreateTask(ctx, &models.CreateTaskRequest{
+		Title:      "weekly review",
+		Priority:   models.TaskPriorityMedium,
+		DueDate:    &due,
+		Recurrence: "FREQ=WEEKLY",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	publisher.events = nil
+
+	if _, err := uc.ToggleTaskComplete(ctx, task.ID, models.SubtaskModeNone); err != nil {
+		t.Fatalf("ToggleTaskComplete: %v", err)
+	}
+
+	if len(publisher.events) != 2 || publisher.events[0].Type != TaskToggled || publisher.events[1].Type != TaskCreated {
+		t.Fatalf("expected toggled and created events, got %+v", publisher.events)
+	}
+	next := publisher.events[1].Tasks[0]
+	if next.ID == task.ID || next.Recurrence != "FREQ=WEEKLY" {
+		t.Errorf("unexpected next occurrence: %+v", next)
+	}
+}
+
+func TestSavedFiltersEvaluateThroughGetTasks(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+
+	soon := time.Now().Add(48 * time.Hour)
+	urgent, err := uc.CreateTask(ctx, &models.CreateTaskRequest{Title: "urgent", Priority: models.TaskPriorityHigh, DueDate: &soon})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	mustCreate(t, uc, "someday")
+
+	if _, err := uc.CreateSavedFilter(ctx, &models.CreateSavedFilterRequest{Name: "Broken", Query: "priority:urgent"}); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected ErrInvalidQuery, got %v", err)
+	}
+
+	filter, err := uc.CreateSavedFilter(ctx, &models.CreateSavedFilterRequest{Name: "This week", Query: "due:<7d priority:high"})
+	if err != nil {
+		t.Fatalf("CreateSavedFilter: %v", err)
+	}
+	if _, err := uc.CreateSavedFilter(ctx, &models.CreateSavedFilterRequest{Name: "this week", Query: "status:pending"}); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+
+	tasks, err := uc.GetTasks(ctx, "", "", "", "", nil, "", 0, `filter:"This week"`)
+	if err != nil {
+		t.Fatalf("GetTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != urgent.ID {
+		t.Errorf("expected only the urgent task, got %v", taskIDs(tasks))
+	}
+
+	data, err := uc.GetDashboardData(ctx)
+	if err != nil {
+		t.Fatalf("GetDashboardData: %v", err)
+	}
+	if len(data.SavedFilters) != 1 || data.SavedFilters[0].ID != filter.ID || data.SavedFilters[0].TaskCount != 1 {
+		t.Errorf("expected saved filter with one task, got %+v", data.SavedFilters)
+	}
+
+	// проект удален после сохранения фильтра: дашборд показывает ошибку вместо счетчика
+	project, err := uc.CreateProject(ctx, &models.CreateProjectRequest{Name: "Garden"})
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	if _, err := uc.CreateSavedFilter(ctx, &models.CreateSavedFilterRequest{Name: "Garden", Query: "project:garden"}); err != nil {
+		t.Fatalf("CreateSavedFilter: %v", err)
+	}
+	if err := uc.DeleteProject(ctx, project.ID); err != nil {
+		t.Fatalf("DeleteProject: %v", err)
+	}
+
+	data, err = uc.GetDashboardData(ctx)
+	if err != nil {
+		t.Fatalf("GetDashboardData: %v", err)
+	}
+	if len(data.SavedFilters) != 2 || data.SavedFilters[1].Error == "" {
+		t.Errorf("expected error for the garden filter, got %+v", data.SavedFilters)
+	}
+}
+
+func TestTrashPublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	uc := NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), publisher, "tester")
+
+	parent := mustCreate(t, uc, "parent")
+	child, err := uc.CreateTask(ctx, &models.CreateTaskRequest{ParentID: &parent.ID, Title: "child", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if err := uc.DeleteTask(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	trash, err := uc.GetTrash(ctx)
+	if err != nil || len(trash) != 1 || trash[0].ID != parent.ID {
+		t.Fatalf("expected parent alone in trash, got %+v (%v)", trash, err)
+	}
+
+	publisher.events = nil
+	restored, err := uc.RestoreTask(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	if restored.ID != parent.ID || restored.DeletedAt != nil {
+		t.Errorf("unexpected restored task: %+v", restored)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != TaskRestored ||
+		!reflect.DeepEqual(publisher.events[0].TaskIDs, []int{parent.ID, child.ID}) {
+		t.Fatalf("expected restore event for subtree, got %+v", publisher.events)
+	}
+
+	if err := uc.DeleteTask(ctx, parent.ID); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	publisher.events = nil
+	if count, err := uc.PurgeExpiredTrash(ctx, 30); err != nil || count != 0 {
+		t.Fatalf("fresh trash must survive retention, got %d (%v)", count, err)
+	}
+	if count, err := uc.EmptyTrash(ctx); err != nil || count == 0 {
+		t.Fatalf("EmptyTrash: %d (%v)", count, err)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != TrashChanged {
+		t.Fatalf("expected single trash event, got %+v", publisher.events)
+	}
+	if _, err := uc.GetTask(ctx, child.ID); err == nil {
+		t.Error("expected purged child to be gone")
+	}
+}
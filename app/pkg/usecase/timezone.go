@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"time"
+	"todo-lits-DMARK/app/pkg/dates"
+)
+
+func (uc *taskUsecase) Location() *time.Location {
+	return uc.taskService.Location()
+}
+
+// SetLocation меняет часовой пояс пользователя; уже сохраненные сроки не пересчитываются
+func (uc *taskUsecase) SetLocation(loc *time.Location) {
+	uc.taskService.SetLocation(loc)
+}
+
+// now возвращает текущее время в часовом поясе пользователя
+func (uc *taskUsecase) now() time.Time {
+	return time.Now().In(uc.taskService.Location())
+}
+
+// dueInPast сообщает, прошел ли срок; срок на весь день сегодня еще не прошел
+func (uc *taskUsecase) dueInPast(due time.Time, allDay bool) bool {
+	now := uc.now()
+	if allDay {
+		due = dates.EndOfDay(due.In(now.Location()))
+	}
+	return due.Before(now)
+}
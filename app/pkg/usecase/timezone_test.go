@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/dates"
+	"todo-lits-DMARK/app/pkg/models"
+)
+
+func TestAllDayTaskDueToday(t *testing.T) {
+	ctx := context.Background()
+	uc := newTestUsecase()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	uc.SetLocation(tokyo)
+
+	// полночь уже прошла, но весь сегодняшний день срок еще не истек
+	today := dates.StartOfDay(time.Now().In(tokyo))
+	task, err := uc.CreateTask(ctx, &models.CreateTaskRequest{Title: "today", Priority: models.TaskPriorityLow, DueDate: &today, AllDay: true})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if !task.AllDay || !task.DueDate.Equal(dates.EndOfDay(today)) {
+		t.Errorf("expected all-day task due %v, got %v", dates.EndOfDay(today), task.DueDate)
+	}
+
+	yesterday := today.AddDate(0, 0, -1)
+	allDay := true
+	if _, err := uc.UpdateTask(ctx, task.ID, &models.UpdateTaskRequest{DueDate: &yesterday, AllDay: &allDay}); err == nil {
+		t.Error("expected error for all-day due date in the past")
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+// PreviewQuickAdd разбирает строку быстрого добавления и возвращает задачу, которую создаст QuickAdd.
+// Ошибка означает, что после разбора не осталось названия.
+func (a *App) PreviewQuickAdd(text string) (*QuickAddPreviewResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	req, err := uc.ParseQuickAdd(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return newQuickAddPreviewResponse(req), nil
+}
+
+// QuickAdd создает задачу из строки вида "Call Bob tomorrow 3pm !high #work every monday"
+// или "Позвонить завтра в 15:00 !высокий #работа" в проекте projectID (0 — Inbox)
+func (a *App) QuickAdd(text string, projectID int) (*TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	var project *int
+	if projectID != 0 {
+		project = &projectID
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	task, err := uc.QuickAdd(ctx, text, project)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskResponse(task), nil
+}
@@ -0,0 +1,31 @@
+package app
+
+import "testing"
+
+func TestQuickAdd(t *testing.T) {
+	a, _ := newTestApp(t)
+
+	preview, err := a.PreviewQuickAdd("Позвонить маме завтра в 15:00 !высокий #семья")
+	if err != nil {
+		t.Fatalf("PreviewQuickAdd: %v", err)
+	}
+	if preview.Title != "Позвонить маме" || preview.Priority != "high" || preview.DueDate == nil || len(preview.Tags) != 1 {
+		t.Errorf("unexpected preview %+v", preview)
+	}
+
+	project, err := a.CreateProject("Дом", "")
+	if err != nil {
+		t.Fatalf("CreateProject: %v", err)
+	}
+	task, err := a.QuickAdd("Позвонить маме завтра в 15:00 !высокий #семья", project.ID)
+	if err != nil {
+		t.Fatalf("QuickAdd: %v", err)
+	}
+	if task.Title != preview.Title || task.ProjectID != project.ID || !task.DueDate.Equal(*preview.DueDate) {
+		t.Errorf("expected task matching preview in project %d, got %+v", project.ID, task)
+	}
+
+	if _, err := a.PreviewQuickAdd("завтра #семья"); err == nil {
+		t.Error("expected error for text without title")
+	}
+}
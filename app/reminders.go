@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/app/pkg/dates"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/notify"
+)
+
+// событие для фронтенда о сработавшем напоминании
+const reminderFiredEvent = "reminder:fired"
+
+// reminderMaxWait — максимальная пауза между проверками: так подхватываются напоминания,
+// добавленные другими клиентами общей базы. Переменные, чтобы тесты могли их уменьшить.
+var (
+	reminderMaxWait = time.Minute
+	notifyTimeout   = 5 * time.Second
+	// пауза перед повторной доставкой, если системное уведомление не показалось
+	notifyRetryDelay = 30 * time.Second
+)
+
+// startReminders запускает планировщик напоминаний. Первая проверка сразу после
+// подключения отправляет напоминания, пропущенные, пока приложение было закрыто.
+func (a *App) startReminders(ctx context.Context) {
+	go func() {
+		for {
+			wait := notifyRetryDelay
+			if a.fireDueReminders() {
+				wait = a.untilNextReminder()
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-a.remindersWake:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// без сессионной шины напоминания показываются только в окне приложения.
+// Создается в OnStartup до запуска фоновых циклов, которые его только читают.
+func newDesktopNotifier(cfg *config.Config) notify.Notifier {
+	if !cfg.App.DesktopNotifications {
+		return notify.NewNopNotifier()
+	}
+
+	notifier, err := notify.NewDesktopNotifier(cfg.App.Name, "")
+	if err != nil {
+		log.Printf("Desktop notifications disabled: %v", err)
+		return notify.NewNopNotifier()
+	}
+	return notifier
+}
+
+// wakeReminders пересчитывает время следующей проверки после изменения задач или напоминаний
+func (a *App) wakeReminders() {
+	select {
+	case a.remindersWake <- struct{}{}:
+	default:
+	}
+}
+
+// fireDueReminders показывает наступившие напоминания в окне и системным уведомлением.
+// Напоминание, уведомление о котором не показалось, возвращается в ожидание и сработает
+// при следующей проверке, а в окне второй раз не появится. false — была такая неудача.
+func (a *App) fireDueReminders() bool {
+	uc, err := a.usecase()
+	if err != nil {
+		return true
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	due, err := uc.FireDueReminders(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to fire due reminders: %v", err)
+		return true
+	}
+
+	delivered := true
+	for _, reminder := range due {
+		id := reminder.Reminder.ID
+		if _, shown := a.undelivered[id]; !shown {
+			a.emit(reminderFiredEvent, newReminderFiredResponse(reminder))
+		}
+
+		if err := a.notifyReminder(reminder); err != nil {
+			log.Printf("Failed to show reminder for task %d: %v", reminder.Task.ID, err)
+			if err := uc.ReleaseReminder(ctx, id); err != nil {
+				log.Printf("Failed to release reminder %d: %v", id, err)
+				continue
+			}
+			a.undelivered[id] = struct{}{}
+			delivered = false
+			continue
+		}
+		delete(a.undelivered, id)
+	}
+	return delivered
+}
+
+func (a *App) notifyReminder(due *models.DueReminder) error {
+	ctx, cancel := a.operationContext(notifyTimeout)
+	defer cancel()
+
+	return a.notifier.Notify(ctx, reminderNotification(due, a.currentLocation()))
+}
+
+// reminderNotification показывает время в зоне пользователя, а не в системной
+func reminderNotification(due *models.DueReminder, loc *time.Location) notify.Notification {
+	body := ""
+	if due.Task.DueDate != nil {
+		body = fmt.Sprintf("Срок: %s", due.Task.DueDate.In(loc).Format("02.01.2006 15:04"))
+	}
+	if due.Missed {
+		missed := fmt.Sprintf("Пропущено в %s", due.Reminder.RemindAt.In(loc).Format("02.01.2006 15:04"))
+		if body == "" {
+			body = missed
+		} else {
+			body = missed + "\n" + body
+		}
+	}
+	return notify.Notification{Title: due.Task.Title, Body: body}
+}
+
+// untilNextReminder — сколько ждать до ближайшего напоминания, не больше reminderMaxWait
+func (a *App) untilNextReminder() time.Duration {
+	uc, err := a.usecase()
+	if err != nil {
+		return reminderMaxWait
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	next, err := uc.NextReminderAt(ctx)
+	if err != nil {
+		log.Printf("Failed to get next reminder: %v", err)
+		return reminderMaxWait
+	}
+	if next == nil {
+		return reminderMaxWait
+	}
+
+	wait := time.Until(*next)
+	if wait < 0 {
+		return 0
+	}
+	if wait > reminderMaxWait {
+		return reminderMaxWait
+	}
+	return wait
+}
+
+// AddReminder добавляет напоминание к задаче: at — момент в формате RFC 3339 или время без смещения в зоне пользователя,
+// либо пустая строка и offsetMinutes — за сколько минут до срока задачи напомнить
+func (a *App) AddReminder(taskID int, at string, offsetMinutes int) (*ReminderResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	req := &models.CreateReminderRequest{}
+	if at != "" {
+		parsed, err := dates.Parse(at, a.currentLocation())
+		if err != nil {
+			return nil, fmt.Errorf("invalid reminder time: %w", err)
+		}
+		req.At = &parsed.Time
+	} else {
+		req.OffsetMinutes = &offsetMinutes
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	reminder, err := uc.AddReminder(ctx, taskID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReminderResponse(reminder), nil
+}
+
+// SnoozeTask откладывает задачу: option — 15m, 1h, tomorrow, next_week или custom,
+// until — время для custom в тех же форматах, что и срок задачи, для остальных вариантов не используется
+func (a *App) SnoozeTask(id int, option string, until string) (*TaskResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return nil, err
+	}
+
+	var untilTime *time.Time
+	if until != "" {
+		parsed, err := dates.Parse(until, a.currentLocation())
+		if err != nil {
+			return nil, fmt.Errorf("invalid snooze time: %w", err)
+		}
+		untilTime = &parsed.Time
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+
+	task, err := uc.SnoozeTask(ctx, id, models.SnoozeOption(option), untilTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskResponse(task), nil
+}
+
+// GetReminders возвращает напоминания задачи в порядке срабатывания, включая сработавшие
+func (a *App) GetReminders(taskID int) ([]ReminderResponse, error) {
+	uc, err := a.usecase()
+	if err != nil {
+		return []ReminderResponse{}, nil
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	reminders, err := uc.GetReminders(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReminderResponses(reminders), nil
+}
+
+func (a *App) DeleteReminder(id int) error {
+	uc, err := a.usecase()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.writeContext()
+	defer cancel()
+	return uc.DeleteReminder(ctx, id)
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/notify"
+)
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []notify.Notification
+	// пока задана, уведомления не показываются
+	err error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func (n *recordingNotifier) Close() error { return nil }
+
+func TestFireDueRemindersEmitsAndNotifies(t *testing.T) {
+	ctx := context.Background()
+	a, events := newTestApp(t)
+	notifier := &recordingNotifier{}
+	a.notifier = notifier
+
+	task, err := a.taskUsecase.CreateTask(ctx, &models.CreateTaskRequest{Title: "Позвонить", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	// напоминание на вчера, пока приложение было закрыто
+	yesterday := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+	if _, err := a.AddReminder(task.ID, yesterday, 0); err != nil {
+		t.Fatalf("AddReminder: %v", err)
+	}
+	if _, err := a.AddReminder(task.ID, "tomorrow", 0); err == nil {
+		t.Error("expected invalid reminder time to be rejected")
+	}
+
+	a.fireDueReminders()
+	a.fireDueReminders()
+
+	if len(*events) != 1 || (*events)[0].name != reminderFiredEvent {
+		t.Fatalf("expected one %s event, got %+v", reminderFiredEvent, *events)
+	}
+	fired := (*events)[0].data[0].(ReminderFiredResponse)
+	if fired.Task.ID != task.ID || !fired.Missed || fired.Reminder.FiredAt == nil {
+		t.Errorf("unexpected fired reminder: %+v", fired)
+	}
+
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("expected one desktop notification, got %+v", notifier.notifications)
+	}
+	if n := notifier.notifications[0]; n.Title != "Позвонить" || !strings.HasPrefix(n.Body, "Пропущено") {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+}
+
+func TestFailedNotificationIsRetried(t *testing.T) {
+	ctx := context.Background()
+	a, events := newTestApp(t)
+	notifier := &recordingNotifier{err: errors.New("notification daemon is not responding")}
+	a.notifier = notifier
+
+	task, err := a.taskUsecase.CreateTask(ctx, &models.CreateTaskRequest{Title: "Позвонить", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := a.AddReminder(task.ID, time.Now().Add(-time.Minute).Format(time.RFC3339), 0); err != nil {
+		t.Fatalf("AddReminder: %v", err)
+	}
+
+	if a.fireDueReminders() {
+		t.Fatal("expected failed delivery to be reported")
+	}
+	reminders, _ := a.taskUsecase.GetReminders(ctx, task.ID)
+	if len(reminders) != 1 || reminders[0].FiredAt != nil {
+		t.Fatalf("undelivered reminder must stay pending, got %+v", reminders)
+	}
+
+	// уведомления снова работают: напоминание доставляется, а в окне не повторяется
+	notifier.err = nil
+	if !a.fireDueReminders() {
+		t.Fatal("expected reminder to be delivered")
+	}
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("expected one desktop notification, got %+v", notifier.notifications)
+	}
+	if len(*events) != 1 {
+		t.Errorf("expected reminder shown in the window once, got %+v", *events)
+	}
+	reminders, _ = a.taskUsecase.GetReminders(ctx, task.ID)
+	if reminders[0].FiredAt == nil {
+		t.Errorf("delivered reminder must be marked fired: %+v", reminders[0])
+	}
+}
+
+func TestReminderSchedulerWakesForNewReminders(t *testing.T) {
+	a, _ := newTestApp(t)
+	a.notifier = &recordingNotifier{}
+	fired := make(chan ReminderFiredResponse, 1)
+	a.emit = func(name string, data ...interface{}) {
+		if name == reminderFiredEvent {
+			fired <- data[0].(ReminderFiredResponse)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a.startReminders(ctx)
+
+	task, err := a.taskUsecase.CreateTask(ctx, &models.CreateTaskRequest{Title: "soon", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	soon := time.Now().Add(50 * time.Millisecond).Format(time.RFC3339Nano)
+	if _, err := a.AddReminder(task.ID, soon, 0); err != nil {
+		t.Fatalf("AddReminder: %v", err)
+	}
+	// в приложении планировщик будят события usecase
+	a.wakeReminders()
+
+	select {
+	case reminder := <-fired:
+		if reminder.Task.ID != task.ID || reminder.Missed {
+			t.Errorf("unexpected fired reminder: %+v", reminder)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("reminder was not fired")
+	}
+}
+
+func TestSnoozeTask(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestApp(t)
+
+	task, err := a.taskUsecase.CreateTask(ctx, &models.CreateTaskRequest{Title: "task", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	until := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	snoozed, err := a.SnoozeTask(task.ID, "custom", until.Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("SnoozeTask: %v", err)
+	}
+	if snoozed.SnoozeCount != 1 || snoozed.DueDate == nil || !snoozed.DueDate.Equal(until) {
+		t.Errorf("expected task snoozed until %v, got %+v", until, snoozed)
+	}
+
+	if _, err := a.SnoozeTask(task.ID, "custom", "later"); err == nil {
+		t.Error("expected invalid snooze time to be rejected")
+	}
+}
+
+func TestReminderNotificationUsesUserTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	due := time.Date(2030, 5, 1, 9, 30, 0, 0, time.UTC)
+	remindAt := due.Add(-time.Hour)
+
+	n := reminderNotification(&models.DueReminder{
+		Task:     &models.Task{Title: "task", DueDate: &due},
+		Reminder: &models.Reminder{RemindAt: remindAt},
+		Missed:   true,
+	}, tokyo)
+	if want := "Пропущено в 01.05.2030 17:30\nСрок: 01.05.2030 18:30"; n.Body != want {
+		t.Errorf("expected %q, got %q", want, n.Body)
+	}
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/app/pkg/database"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/service"
+	"todo-lits-DMARK/app/pkg/usecase"
+)
+
+// ErrStorageUnavailable возвращается из биндингов, пока нет подключения к базе
+var ErrStorageUnavailable = errors.New("storage unavailable")
+
+// StorageUnavailableError несет последнюю ошибку подключения,
+// errors.Is(err, ErrStorageUnavailable) для нее возвращает true
+type StorageUnavailableError struct {
+	Cause error
+}
+
+func (e *StorageUnavailableError) Error() string {
+	if e.Cause == nil {
+		return ErrStorageUnavailable.Error()
+	}
+	return fmt.Sprintf("%s: %v", ErrStorageUnavailable, e.Cause)
+}
+
+func (e *StorageUnavailableError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{ErrStorageUnavailable}
+	}
+	return []error{ErrStorageUnavailable, e.Cause}
+}
+
+// StorageStatus отдается фронтенду, чтобы показать режим без сохранения
+type StorageStatus struct {
+	Available   bool       `json:"available"`
+	Driver      string     `json:"driver"`
+	LastError   string     `json:"last_error"`
+	LastAttempt *time.Time `json:"last_attempt" ts_type:"string"`
+	Attempts    int        `json:"attempts"`
+}
+
+// событие для фронтенда при смене состояния хранилища
+const storageStatusEvent = "storage:status"
+
+// интервалы переподключения, переменные чтобы тесты могли их уменьшить
+var (
+	reconnectMinDelay = 2 * time.Second
+	reconnectMaxDelay = time.Minute
+)
+
+// connect пробует открыть базу и подключить usecase, обновляя статус хранилища.
+// Попытка ограничена таймаутом подключения, поэтому зависший сервер не блокирует запуск.
+func (a *App) connect(ctx context.Context, cfg *config.Config) error {
+	if cfg.Timeouts.Connect > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeouts.Connect)
+		defer cancel()
+	}
+	db, err := a.openDatabase(ctx, cfg)
+
+	a.mu.Lock()
+	now := time.Now()
+	a.storage.Attempts++
+	a.storage.LastAttempt = &now
+	if err != nil {
+		a.storage.Available = false
+		a.storage.LastError = err.Error()
+		a.storageErr = err
+	} else {
+		a.db = db
+		a.taskUsecase = a.newTaskUsecase(db, cfg.App.Actor)
+		a.storage.Available = true
+		a.storage.LastError = ""
+		a.storageErr = nil
+	}
+	status := a.storage
+	a.mu.Unlock()
+
+	a.emit(storageStatusEvent, status)
+	return err
+}
+
+// reconnectLoop повторяет подключение с экспоненциальной задержкой до успеха или отмены ctx
+func (a *App) reconnectLoop(ctx context.Context, cfg *config.Config) {
+	delay := reconnectMinDelay
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if err := a.connect(ctx, cfg); err != nil {
+			log.Printf("Reconnect to database failed: %v", err)
+			delay *= 2
+			if delay > reconnectMaxDelay {
+				delay = reconnectMaxDelay
+			}
+			continue
+		}
+
+		log.Println("Database connection established after reconnect")
+		a.startChangeFeed(ctx, cfg)
+		a.startTrashPurge(ctx, cfg)
+		a.startReminders(ctx)
+		return
+	}
+}
+
+// usecase возвращает подключенный usecase или типизированную ошибку недоступности
+func (a *App) usecase() (usecase.TaskUsecase, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.taskUsecase == nil {
+		return nil, &StorageUnavailableError{Cause: a.storageErr}
+	}
+	return a.taskUsecase, nil
+}
+
+// readContext, writeContext и bulkContext возвращают контекст одной операции с таймаутом из конфига
+func (a *App) readContext() (context.Context, context.CancelFunc) {
+	return a.operationContext(a.timeouts.Read)
+}
+
+func (a *App) writeContext() (context.Context, context.CancelFunc) {
+	return a.operationContext(a.timeouts.Write)
+}
+
+func (a *App) bulkContext() (context.Context, context.CancelFunc) {
+	return a.operationContext(a.timeouts.Bulk)
+}
+
+// operationContext наследует контекст приложения, поэтому завершение прерывает
+// выполняющиеся запросы; timeout 0 не ограничивает операцию по времени
+func (a *App) operationContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// GetStorageStatus сообщает, сохраняются ли данные и почему нет
+func (a *App) GetStorageStatus() StorageStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.storage
+}
+
+// вызывается под a.mu
+func (a *App) newTaskUsecase(db *database.Database, actor string) usecase.TaskUsecase {
+	taskRepo := newTaskRepository(db)
+	taskService := service.NewTaskService(taskRepo)
+	taskService.SetLocation(a.location)
+	return usecase.NewTaskUsecase(taskService, usecase.EventPublisherFunc(a.publishTaskEvent), actor)
+}
+
+// события usecase пробрасываются во фронтенд под тем же именем.
+// Изменение задач может сдвинуть напоминания, поэтому планировщик пересчитывает ожидание.
+func (a *App) publishTaskEvent(event usecase.TaskEvent) {
+	a.emit(string(event.Type), newTaskEventResponse(event))
+	a.wakeReminders()
+}
+
+// выбираем реализацию репозитория под драйвер подключения
+func newTaskRepository(db *database.Database) repository.TaskRepositoryInterface {
+	if db.Driver == config.DriverSQLite {
+		return repository.NewSQLiteTaskRepository(db.DB)
+	}
+	return repository.NewTaskRepository(db.DB)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/app/pkg/database"
+)
+
+func TestBindingsReturnStorageUnavailable(t *testing.T) {
+	a := NewApp()
+
+	if _, err := a.CreateTask("task", "", "low", "", nil, 0, ""); !errors.Is(err, ErrStorageUnavailable) {
+		t.Errorf("CreateTask: expected ErrStorageUnavailable, got %v", err)
+	}
+	if _, err := a.UpdateTask(1, "task", "", "", "", "", nil, nil, 0); !errors.Is(err, ErrStorageUnavailable) {
+		t.Errorf("UpdateTask: expected ErrStorageUnavailable, got %v", err)
+	}
+	if _, err := a.ToggleTaskComplete(1, ""); !errors.Is(err, ErrStorageUnavailable) {
+		t.Errorf("ToggleTaskComplete: expected ErrStorageUnavailable, got %v", err)
+	}
+	if err := a.DeleteTask(1); !errors.Is(err, ErrStorageUnavailable) {
+		t.Errorf("DeleteTask: expected ErrStorageUnavailable, got %v", err)
+	}
+
+	tasks, err := a.GetTasks("", "", "", "", nil, "", 0, "")
+	if err != nil || len(tasks) != 0 {
+		t.Errorf("GetTasks: expected empty list, got %v, %v", tasks, err)
+	}
+}
+
+func TestReconnectLoopWiresUsecase(t *testing.T) {
+	reconnectMinDelay, reconnectMaxDelay = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { reconnectMinDelay, reconnectMaxDelay = 2*time.Second, time.Minute })
+
+	cfg := config.New()
+	cfg.Storage.Driver = config.DriverSQLite
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "todo.db")
+
+	var mu sync.Mutex
+	var statuses []StorageStatus
+	failures := 3
+
+	a := NewApp()
+	a.emit = func(event string, data ...interface{}) {
+		if event != storageStatusEvent {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, data[0].(StorageStatus))
+	}
+	a.openDatabase = func(ctx context.Context, cfg *config.Config) (*database.Database, error) {
+		if failures > 0 {
+			failures--
+			return nil, errors.New("connection refused")
+		}
+		return database.New(ctx, cfg)
+	}
+
+	if err := a.connect(context.Background(), cfg); err == nil {
+		t.Fatal("expected first connect to fail")
+	}
+
+	_, err := a.CreateTask("task", "", "low", "", nil, 0, "")
+	var unavailable *StorageUnavailableError
+	if !errors.As(err, &unavailable) || unavailable.Cause.Error() != "connection refused" {
+		t.Fatalf("expected StorageUnavailableError with cause, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	a.reconnectLoop(ctx, cfg)
+	t.Cleanup(func() { a.OnShutdown(context.Background()) })
+
+	status := a.GetStorageStatus()
+	if !status.Available || status.Attempts != 4 || status.LastError != "" {
+		t.Fatalf("unexpected status after reconnect: %+v", status)
+	}
+
+	task, err := a.CreateTask("task", "", "low", "", nil, 0, "")
+	if err != nil {
+		t.Fatalf("CreateTask after reconnect: %v", err)
+	}
+	if task.ID == 0 {
+		t.Errorf("expected persisted task, got %+v", task)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(statuses) != 4 || !statuses[3].Available {
+		t.Errorf("expected 4 status events ending with available, got %+v", statuses)
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/app/pkg/database"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/usecase"
+)
+
+// фронтенд перезагружает все данные, если часть уведомлений могла потеряться
+const tasksResyncEvent = "tasks:resync"
+
+// startChangeFeed подписывается на изменения других клиентов общей postgres базы
+func (a *App) startChangeFeed(ctx context.Context, cfg *config.Config) {
+	if cfg.Storage.Driver == config.DriverSQLite {
+		return
+	}
+
+	listener, err := database.NewChangeListener(cfg)
+	if err != nil {
+		log.Printf("Change feed disabled: %v", err)
+		return
+	}
+
+	go func() {
+		defer listener.Close()
+		listener.Listen(ctx, a.handleRemoteChange)
+	}()
+
+	log.Println("Listening for task changes from other clients")
+}
+
+// handleRemoteChange превращает уведомление postgres в такое же событие, как локальные мутации
+func (a *App) handleRemoteChange(change database.TaskChange) {
+	uc, err := a.usecase()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := a.readContext()
+	defer cancel()
+
+	var event usecase.TaskEvent
+	switch change.Op {
+	case database.TaskChangeResync:
+		a.emit(tasksResyncEvent)
+		return
+
+	case database.TaskChangeDelete:
+		event = usecase.TaskEvent{Type: usecase.TaskDeleted, TaskIDs: []int{change.TaskID}}
+
+	default:
+		task, err := uc.GetTask(ctx, change.TaskID)
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			// перенос в корзину приходит как UPDATE, для остальных клиентов задача удалена
+			event = usecase.TaskEvent{Type: usecase.TaskDeleted, TaskIDs: []int{change.TaskID}}
+			break
+		}
+		if err != nil {
+			log.Printf("Failed to load remotely changed task %d: %v", change.TaskID, err)
+			return
+		}
+
+		event = usecase.TaskEvent{Type: usecase.TaskUpdated, TaskIDs: []int{task.ID}, Tasks: []*models.Task{task}}
+		if change.Op == database.TaskChangeInsert {
+			event.Type = usecase.TaskCreated
+		}
+	}
+
+	event.OccurredAt = time.Now()
+	response := newTaskEventResponse(event)
+	response.Remote = true
+	a.emit(string(event.Type), response)
+	a.wakeReminders()
+}
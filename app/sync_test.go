@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"todo-lits-DMARK/app/pkg/database"
+	"todo-lits-DMARK/app/pkg/models"
+	"todo-lits-DMARK/app/pkg/repository"
+	"todo-lits-DMARK/app/pkg/service"
+	"todo-lits-DMARK/app/pkg/usecase"
+)
+
+type emittedEvent struct {
+	name string
+	data []interface{}
+}
+
+func newTestApp(t *testing.T) (*App, *[]emittedEvent) {
+	t.Helper()
+
+	var events []emittedEvent
+	a := NewApp()
+	a.emit = func(name string, data ...interface{}) {
+		events = append(events, emittedEvent{name: name, data: data})
+	}
+	a.taskUsecase = usecase.NewTaskUsecase(service.NewTaskService(repository.NewMemoryTaskRepository()), nil, "tester")
+	return a, &events
+}
+
+func TestHandleRemoteChange(t *testing.T) {
+	ctx := context.Background()
+	a, events := newTestApp(t)
+
+	task, err := a.taskUsecase.CreateTask(ctx, &models.CreateTaskRequest{Title: "remote", Priority: models.TaskPriorityLow})
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	a.handleRemoteChange(database.TaskChange{Op: database.TaskChangeInsert, TaskID: task.ID})
+	a.handleRemoteChange(database.TaskChange{Op: database.TaskChangeUpdate, TaskID: task.ID})
+	a.handleRemoteChange(database.TaskChange{Op: database.TaskChangeDelete, TaskID: 99})
+	a.handleRemoteChange(database.TaskChange{Op: database.TaskChangeUpdate, TaskID: 404})
+	a.handleRemoteChange(database.TaskChange{Op: database.TaskChangeResync})
+
+	// задача, которой не видно после UPDATE, перенесена в корзину
+	wantNames := []string{"task:created", "task:updated", "task:deleted", "task:deleted", tasksResyncEvent}
+	if len(*events) != len(wantNames) {
+		t.Fatalf("expected events %v, got %+v", wantNames, *events)
+	}
+	for i, name := range wantNames {
+		if (*events)[i].name != name {
+			t.Fatalf("event %d: expected %s, got %s", i, name, (*events)[i].name)
+		}
+	}
+
+	created := (*events)[0].data[0].(TaskEventResponse)
+	if !created.Remote || len(created.Tasks) != 1 || created.Tasks[0].Title != "remote" {
+		t.Errorf("unexpected created payload: %+v", created)
+	}
+	deleted := (*events)[2].data[0].(TaskEventResponse)
+	if len(deleted.TaskIDs) != 1 || deleted.TaskIDs[0] != 99 || len(deleted.Tasks) != 0 {
+		t.Errorf("unexpected deleted payload: %+v", deleted)
+	}
+	trashed := (*events)[3].data[0].(TaskEventResponse)
+	if !trashed.Remote || len(trashed.TaskIDs) != 1 || trashed.TaskIDs[0] != 404 {
+		t.Errorf("unexpected trashed payload: %+v", trashed)
+	}
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/app/pkg/dates"
+)
+
+// GetTimezone возвращает часовой пояс пользователя, пустая строка — системный
+func (a *App) GetTimezone() string {
+	loc := a.currentLocation()
+	if loc == time.Local {
+		return ""
+	}
+	return loc.String()
+}
+
+// SetTimezone задает часовой пояс пользователя по имени IANA, например Europe/Moscow;
+// пустая строка возвращает системный. Пояс запоминается до следующего запуска,
+// уже сохраненные сроки не пересчитываются.
+func (a *App) SetTimezone(name string) error {
+	loc, err := dates.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	if err := a.saveTimezone(name); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.location = loc
+	uc := a.taskUsecase
+	a.mu.Unlock()
+
+	if uc != nil {
+		uc.SetLocation(loc)
+	}
+	return nil
+}
+
+// loadTimezone выбирает пояс при запуске: сохраненный в приложении, иначе APP_TIMEZONE, иначе системный
+func (a *App) loadTimezone(cfg *config.Config) {
+	a.settingsPath = cfg.App.SettingsPath
+
+	name := cfg.App.Timezone
+	if settings, err := config.LoadSettings(a.settingsPath); err != nil {
+		log.Printf("Failed to load settings: %v", err)
+	} else if settings.Timezone != nil {
+		name = *settings.Timezone
+	}
+
+	loc, err := dates.LoadLocation(name)
+	if err != nil {
+		log.Printf("Using system timezone: %v", err)
+		return
+	}
+	a.location = loc
+}
+
+// saveTimezone запоминает пояс в файле настроек, вызывается под a.mu
+func (a *App) saveTimezone(name string) error {
+	if a.settingsPath == "" {
+		return nil
+	}
+
+	settings, err := config.LoadSettings(a.settingsPath)
+	if err != nil {
+		// испорченный файл перезаписывается, иначе пояс нельзя было бы сменить
+		log.Printf("Failed to load settings: %v", err)
+		settings = &config.Settings{}
+	}
+	settings.Timezone = &name
+	if err := config.SaveSettings(a.settingsPath, settings); err != nil {
+		return fmt.Errorf("failed to save timezone: %w", err)
+	}
+	return nil
+}
+
+func (a *App) currentLocation() *time.Location {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.location
+}
+
+// parseDueDate разбирает срок из фронтенда в зоне пользователя
+func (a *App) parseDueDate(value string) (*dates.Due, error) {
+	due, err := dates.Parse(value, a.currentLocation())
+	if err != nil {
+		return nil, fmt.Errorf("invalid due date: %w", err)
+	}
+	return due, nil
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+	"todo-lits-DMARK/app/pkg/dates"
+)
+
+func TestCreateTaskDueDateFormats(t *testing.T) {
+	a, _ := newTestApp(t)
+	if err := a.SetTimezone("Asia/Tokyo"); err != nil {
+		t.Fatalf("SetTimezone: %v", err)
+	}
+	if got := a.GetTimezone(); got != "Asia/Tokyo" {
+		t.Errorf("expected Asia/Tokyo, got %q", got)
+	}
+	tokyo := a.currentLocation()
+
+	tests := []struct {
+		dueDate string
+		want    time.Time
+		allDay  bool
+	}{
+		{"2030-05-01T09:30:00Z", time.Date(2030, 5, 1, 9, 30, 0, 0, time.UTC), false},
+		{"2030-05-01T09:30:00+03:00", time.Date(2030, 5, 1, 6, 30, 0, 0, time.UTC), false},
+		{"2030-05-01T09:30", time.Date(2030, 5, 1, 9, 30, 0, 0, tokyo), false},
+		{"2030-05-01", time.Date(2030, 5, 1, 23, 59, 59, 0, tokyo), true},
+	}
+	for _, tt := range tests {
+		task, err := a.CreateTask("task", "", "low", tt.dueDate, nil, 0, "")
+		if err != nil {
+			t.Fatalf("CreateTask(%q): %v", tt.dueDate, err)
+		}
+		if task.DueDate == nil || !task.DueDate.Equal(tt.want) || task.AllDay != tt.allDay {
+			t.Errorf("%q: expected %v (all day %v), got %v (all day %v)", tt.dueDate, tt.want, tt.allDay, task.DueDate, task.AllDay)
+		}
+	}
+
+	if _, err := a.CreateTask("task", "", "low", "01.05.2030", nil, 0, ""); !errors.Is(err, dates.ErrInvalidDate) {
+		t.Errorf("expected ErrInvalidDate, got %v", err)
+	}
+	if err := a.SetTimezone("Mars/Olympus"); !errors.Is(err, dates.ErrInvalidTimezone) {
+		t.Errorf("expected ErrInvalidTimezone, got %v", err)
+	}
+	if err := a.SetTimezone(""); err != nil || a.GetTimezone() != "" {
+		t.Errorf("expected system timezone, got %q (%v)", a.GetTimezone(), err)
+	}
+}
+
+func TestUpdateTaskDueDate(t *testing.T) {
+	a, _ := newTestApp(t)
+	task, err := a.CreateTask("task", "", "low", "2030-05-01", nil, 0, "")
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	updated, err := a.UpdateTask(task.ID, "", "", "", "", "2030-05-02T10:00:00Z", nil, nil, task.Version)
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if updated.Task.AllDay || !updated.Task.DueDate.Equal(time.Date(2030, 5, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected timed due date, got %v (all day %v)", updated.Task.DueDate, updated.Task.AllDay)
+	}
+
+	if _, err := a.UpdateTask(task.ID, "", "", "", "", "soon", nil, nil, 0); !errors.Is(err, dates.ErrInvalidDate) {
+		t.Errorf("expected ErrInvalidDate from UpdateTask, got %v", err)
+	}
+	if err := a.BulkUpdateTasks([]int{task.ID}, "", "2030-13-01"); !errors.Is(err, dates.ErrInvalidDate) {
+		t.Errorf("expected ErrInvalidDate from BulkUpdateTasks, got %v", err)
+	}
+}
+
+func TestTimezoneIsPersisted(t *testing.T) {
+	cfg := &config.Config{App: config.AppConfig{
+		Timezone:     "Europe/Moscow",
+		SettingsPath: filepath.Join(t.TempDir(), "TodoApp", "settings.json"),
+	}}
+
+	// без сохраненного выбора действует APP_TIMEZONE
+	a := NewApp()
+	a.loadTimezone(cfg)
+	if got := a.GetTimezone(); got != "Europe/Moscow" {
+		t.Fatalf("expected Europe/Moscow, got %q", got)
+	}
+	if err := a.SetTimezone("Asia/Tokyo"); err != nil {
+		t.Fatalf("SetTimezone: %v", err)
+	}
+
+	restarted := NewApp()
+	restarted.loadTimezone(cfg)
+	if got := restarted.GetTimezone(); got != "Asia/Tokyo" {
+		t.Errorf("expected saved Asia/Tokyo after restart, got %q", got)
+	}
+
+	// выбранный системный пояс тоже запоминается и перекрывает APP_TIMEZONE
+	if err := restarted.SetTimezone(""); err != nil {
+		t.Fatalf("SetTimezone: %v", err)
+	}
+	restarted = NewApp()
+	restarted.loadTimezone(cfg)
+	if got := restarted.GetTimezone(); got != "" {
+		t.Errorf("expected saved system timezone, got %q", got)
+	}
+}
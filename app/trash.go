@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo-lits-DMARK/app/pkg/config"
+)
+
+// как часто проверяется корзина на задачи старше срока хранения
+var trashPurgeInterval = 24 * time.Hour
+
+// startTrashPurge удаляет навсегда задачи, пролежавшие в корзине дольше TRASH_RETENTION_DAYS,
+// сразу при подключении и затем раз в trashPurgeInterval
+func (a *App) startTrashPurge(ctx context.Context, cfg *config.Config) {
+	if cfg.App.TrashRetentionDays == 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			a.purgeExpiredTrash(cfg.App.TrashRetentionDays)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *App) purgeExpiredTrash(retentionDays int) {
+	uc, err := a.usecase()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := a.bulkContext()
+	defer cancel()
+
+	count, err := uc.PurgeExpiredTrash(ctx, retentionDays)
+	if err != nil {
+		log.Printf("Failed to purge expired trash: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Purged %d tasks from trash older than %d days", count, retentionDays)
+	}
+}
@@ -1,13 +1,33 @@
 import './style.css';
 import './app.css';
 import * as App from '../wailsjs/go/app/App';
+import { EventsOn } from '../wailsjs/runtime/runtime';
+
+// Tasks and search results are fetched in pages of this size
+const PAGE_SIZE = 50;
 
 // Application state
 class TodoApp {
     constructor() {
         this.tasks = [];
+        this.projects = [];
         this.currentEditId = null;
+        this.currentEditVersion = 0;
+        this.conflictTask = null;
+        // Ids of tasks selected for bulk actions, kept across re-renders
+        this.selectedIds = new Set();
         this.isLoading = false;
+        this.isLoadingTasks = false;
+        this.isLoadingMore = false;
+        // Infinite scroll: fetchPage(cursor) loads the next page of the current list
+        this.fetchPage = null;
+        this.nextCursor = '';
+        this.totalTasks = 0;
+        this.listGeneration = 0;
+        // Quick add preview requests, only the latest one is rendered
+        this.quickAddRequest = 0;
+        // IANA zone that defines "today" for the backend, empty means the system zone
+        this.timezone = '';
         this.currentTab = 'dashboard'; // Отслеживание текущей вкладки
         
         // DOM elements
@@ -22,10 +42,148 @@ class TodoApp {
         this.bindElements();
         this.setupEventListeners();
         this.initTheme();
+        await this.initTimezone();
         
         // Hide loading screen and show app
         await this.loadInitialData();
         this.hideLoading();
+        
+        this.listenTaskEvents();
+        await this.watchStorageStatus();
+    }
+
+    // Patch local state from backend task events instead of refetching
+    listenTaskEvents() {
+        const events = ['task:created', 'task:updated', 'task:toggled', 'task:deleted', 'task:restored', 'tasks:bulk_updated'];
+        events.forEach(name => EventsOn(name, (event) => this.applyTaskEvent(event)));
+        
+        // Changes from other clients may have been missed while the feed was reconnecting
+        EventsOn('tasks:resync', () => this.refreshAllData());
+        
+        // Renaming or deleting a tag changes many tasks at once
+        EventsOn('tags:changed', () => this.refreshAllData());
+        
+        // Archiving or deleting a project hides or moves its tasks
+        EventsOn('projects:changed', async () => {
+            await this.loadProjects();
+            this.refreshAllData();
+        });
+        
+        // Counts of smart lists come with the dashboard
+        EventsOn('filters:changed', () => this.loadDashboardData());
+        
+        // Tasks purged from the trash are not shown anywhere else
+        EventsOn('trash:changed', () => this.loadTrash());
+        
+        // Missed reminders arrive in a batch right after startup
+        EventsOn('reminder:fired', (reminder) => this.showReminder(reminder));
+        EventsOn('reminders:changed', () => this.loadReminders());
+    }
+
+    applyTaskEvent(event) {
+        // Date filters, search and queries are evaluated on the backend, so reload the list in that case
+        if (this.elements.dateFilter?.value || this.getSearchQuery() || this.elements.taskQuery?.value.trim()) {
+            this.loadTasks();
+        } else if (event.type === 'task:deleted') {
+            const count = this.tasks.length;
+            this.tasks = this.tasks.filter(task => !event.task_ids.includes(task.id));
+            this.totalTasks -= count - this.tasks.length;
+            this.renderTasks();
+        } else {
+            event.tasks.forEach(task => this.upsertTask(task));
+            this.renderTasks();
+        }
+        
+        this.loadDashboardData();
+        if (event.type === 'task:deleted' || event.type === 'task:restored') {
+            this.loadTrash();
+        }
+    }
+
+    upsertTask(task) {
+        const index = this.tasks.findIndex(t => t.id === task.id);
+        
+        if (!this.matchesFilters(task)) {
+            if (index !== -1) {
+                this.tasks.splice(index, 1);
+                this.totalTasks--;
+            }
+            return;
+        }
+        
+        if (index === -1) {
+            this.tasks.unshift(task);
+            this.totalTasks++;
+        } else {
+            this.tasks[index] = task;
+        }
+    }
+
+    matchesFilters(task) {
+        const status = this.elements.statusFilter?.value || '';
+        const priority = this.elements.priorityFilter?.value || '';
+        
+        return (!status || task.status === status) &&
+            (!priority || task.priority === priority) &&
+            this.matchesProjectFilter(task) &&
+            this.matchesTagFilter(task);
+    }
+
+    // Without an explicit project the backend hides archived projects
+    matchesProjectFilter(task) {
+        const projectId = this.getProjectFilter();
+        if (projectId) return task.project_id === projectId;
+        
+        return !this.findProject(task.project_id)?.archived;
+    }
+
+    getProjectFilter() {
+        return Number(this.elements.projectFilter?.value || 0);
+    }
+
+    findProject(id) {
+        return this.projects.find(project => project.id === id);
+    }
+
+    matchesTagFilter(task) {
+        const tags = this.getTagFilter();
+        if (tags.length === 0) return true;
+        
+        const matches = tags.filter(tag => task.tags.includes(tag));
+        return this.elements.tagMatch?.value === 'all' ? matches.length === tags.length : matches.length > 0;
+    }
+
+    getTagFilter() {
+        return this.parseTags(this.elements.tagFilter?.value || '');
+    }
+
+    // Tags are stored lowercase on the backend
+    parseTags(value) {
+        return [...new Set(value.split(',').map(tag => tag.trim().toLowerCase()).filter(tag => tag))];
+    }
+
+    // Warn when tasks are not being saved and reload once storage reconnects
+    async watchStorageStatus() {
+        EventsOn('storage:status', (status) => {
+            if (status.available) {
+                this.showToast('Подключение к базе данных восстановлено', 'success');
+                this.refreshAllData();
+            }
+        });
+        
+        try {
+            const status = await App.GetStorageStatus();
+            if (!status.available) {
+                this.showToast(
+                    'Изменения не будут сохранены, пока база данных недоступна. Переподключение выполняется автоматически.',
+                    'warning',
+                    'Хранилище недоступно',
+                    10000
+                );
+            }
+        } catch (error) {
+            console.error('Error loading storage status:', error);
+        }
     }
 
     // Bind DOM elements
@@ -36,28 +194,60 @@ class TodoApp {
         
         // Theme toggle
         this.elements.themeToggle = document.getElementById('themeToggle');
+        this.elements.timezoneSelect = document.getElementById('timezoneSelect');
+        
+        // Undo / redo
+        this.elements.undoBtn = document.getElementById('undoBtn');
+        this.elements.redoBtn = document.getElementById('redoBtn');
         
         // Dashboard elements
         this.elements.totalTasks = document.getElementById('totalTasks');
         this.elements.pendingTasks = document.getElementById('pendingTasks');
         this.elements.completedTasks = document.getElementById('completedTasks');
         this.elements.overdueTasks = document.getElementById('overdueTasks');
+        this.elements.statsBreakdown = document.getElementById('statsBreakdown');
         this.elements.recentTasksList = document.getElementById('recentTasksList');
         this.elements.overdueTasksList = document.getElementById('overdueTasksList');
+        this.elements.upcomingTasksList = document.getElementById('upcomingTasksList');
+        this.elements.tagsList = document.getElementById('tagsList');
+        this.elements.projectsList = document.getElementById('projectsList');
+        this.elements.newProjectName = document.getElementById('newProjectName');
+        this.elements.newProjectColor = document.getElementById('newProjectColor');
+        this.elements.addProjectBtn = document.getElementById('addProjectBtn');
+        this.elements.savedFiltersList = document.getElementById('savedFiltersList');
+        this.elements.newFilterName = document.getElementById('newFilterName');
+        this.elements.newFilterQuery = document.getElementById('newFilterQuery');
+        this.elements.addFilterBtn = document.getElementById('addFilterBtn');
+        this.elements.trashList = document.getElementById('trashList');
+        this.elements.emptyTrashBtn = document.getElementById('emptyTrashBtn');
         
         // Task form
         this.elements.taskForm = document.getElementById('taskForm');
+        this.elements.quickAddInput = document.getElementById('quickAddInput');
+        this.elements.quickAddPreview = document.getElementById('quickAddPreview');
         this.elements.taskTitle = document.getElementById('taskTitle');
         this.elements.taskDescription = document.getElementById('taskDescription');
         this.elements.taskPriority = document.getElementById('taskPriority');
         this.elements.taskDueDate = document.getElementById('taskDueDate');
+        this.elements.taskAllDay = document.getElementById('taskAllDay');
+        this.elements.taskTags = document.getElementById('taskTags');
+        this.elements.taskRecurrence = document.getElementById('taskRecurrence');
+        this.elements.taskRecurrenceCustom = document.getElementById('taskRecurrenceCustom');
+        this.elements.taskRecurrenceCustomRow = document.getElementById('taskRecurrenceCustomRow');
+        this.elements.taskProject = document.getElementById('taskProject');
         this.elements.saveTaskBtn = document.getElementById('saveTaskBtn');
         this.elements.cancelEditBtn = document.getElementById('cancelEditBtn');
         
         // Filters
+        this.elements.projectFilter = document.getElementById('projectFilter');
         this.elements.statusFilter = document.getElementById('statusFilter');
         this.elements.priorityFilter = document.getElementById('priorityFilter');
         this.elements.dateFilter = document.getElementById('dateFilter');
+        this.elements.tagFilter = document.getElementById('tagFilter');
+        this.elements.taskSearch = document.getElementById('taskSearch');
+        this.elements.taskQuery = document.getElementById('taskQuery');
+        this.elements.taskQueryError = document.getElementById('taskQueryError');
+        this.elements.tagMatch = document.getElementById('tagMatch');
         this.elements.sortBy = document.getElementById('sortBy');
         this.elements.sortOrder = document.getElementById('sortOrder');
         
@@ -66,12 +256,40 @@ class TodoApp {
         this.elements.completedTasksList = document.getElementById('completedTasksList');
         this.elements.activeTaskCount = document.getElementById('activeTaskCount');
         this.elements.completedTaskCount = document.getElementById('completedTaskCount');
+        this.elements.tasksPager = document.getElementById('tasksPager');
+        this.elements.tasksSentinel = document.getElementById('tasksSentinel');
+        
+        // Bulk actions
+        this.elements.bulkBar = document.getElementById('bulkBar');
+        this.elements.bulkCount = document.getElementById('bulkCount');
+        this.elements.bulkPriority = document.getElementById('bulkPriority');
+        this.elements.bulkProject = document.getElementById('bulkProject');
+        this.elements.bulkTags = document.getElementById('bulkTags');
         
         // Modal
         this.elements.deleteModal = document.getElementById('deleteModal');
         this.elements.deleteTaskTitle = document.getElementById('deleteTaskTitle');
         this.elements.confirmDeleteBtn = document.getElementById('confirmDeleteBtn');
         this.elements.cancelDeleteBtn = document.getElementById('cancelDeleteBtn');
+        this.elements.conflictModal = document.getElementById('conflictModal');
+        this.elements.conflictFields = document.getElementById('conflictFields');
+        this.elements.keepMineBtn = document.getElementById('keepMineBtn');
+        this.elements.takeServerBtn = document.getElementById('takeServerBtn');
+        this.elements.historyModal = document.getElementById('historyModal');
+        this.elements.historyTaskTitle = document.getElementById('historyTaskTitle');
+        this.elements.historyList = document.getElementById('historyList');
+        this.elements.closeHistoryBtn = document.getElementById('closeHistoryBtn');
+        this.elements.remindersModal = document.getElementById('remindersModal');
+        this.elements.remindersTaskTitle = document.getElementById('remindersTaskTitle');
+        this.elements.remindersList = document.getElementById('remindersList');
+        this.elements.reminderKind = document.getElementById('reminderKind');
+        this.elements.reminderAt = document.getElementById('reminderAt');
+        this.elements.addReminderBtn = document.getElementById('addReminderBtn');
+        this.elements.closeRemindersBtn = document.getElementById('closeRemindersBtn');
+        this.elements.snoozeModal = document.getElementById('snoozeModal');
+        this.elements.snoozeTaskTitle = document.getElementById('snoozeTaskTitle');
+        this.elements.snoozeUntil = document.getElementById('snoozeUntil');
+        this.elements.closeSnoozeBtn = document.getElementById('closeSnoozeBtn');
         
         // Quick actions
         this.elements.addTaskBtn = document.getElementById('addTaskBtn');
@@ -83,6 +301,16 @@ class TodoApp {
 
     // Setup event listeners
     setupEventListeners() {
+        // Infinite scroll of the task list
+        if (this.elements.tasksSentinel && 'IntersectionObserver' in window) {
+            const observer = new IntersectionObserver(entries => {
+                if (entries.some(entry => entry.isIntersecting)) {
+                    this.loadMoreTasks();
+                }
+            }, { rootMargin: '200px' });
+            observer.observe(this.elements.tasksSentinel);
+        }
+        
         // Tab navigation
         this.elements.tabBtns.forEach(btn => {
             btn.addEventListener('click', (e) => this.switchTab(e.target.dataset.tab));
@@ -90,21 +318,64 @@ class TodoApp {
         
         // Theme toggle
         this.elements.themeToggle.addEventListener('click', () => this.toggleTheme());
+        this.elements.timezoneSelect.addEventListener('change', (e) => this.setTimezone(e.target.value));
+        
+        // All-day tasks have a date without time
+        this.elements.taskAllDay.addEventListener('change', (e) => this.setAllDay(e.target.checked));
+        
+        // Undo / redo, shortcuts are left to text fields while typing
+        this.elements.undoBtn.addEventListener('click', () => this.undo());
+        this.elements.redoBtn.addEventListener('click', () => this.redo());
+        document.addEventListener('keydown', (e) => {
+            if (!(e.ctrlKey || e.metaKey) || e.target.closest('input, textarea, select')) return;
+            
+            const key = e.key.toLowerCase();
+            if (key === 'z' && !e.shiftKey) {
+                e.preventDefault();
+                this.undo();
+            } else if ((key === 'z' && e.shiftKey) || key === 'y') {
+                e.preventDefault();
+                this.redo();
+            }
+        });
         
         // Task form
         this.elements.saveTaskBtn.addEventListener('click', () => this.saveTask());
         this.elements.cancelEditBtn.addEventListener('click', () => this.cancelEdit());
         
         // Filters
+        this.elements.projectFilter.addEventListener('change', () => this.filterTasks());
         this.elements.statusFilter.addEventListener('change', () => this.filterTasks());
         this.elements.priorityFilter.addEventListener('change', () => this.filterTasks());
         this.elements.dateFilter.addEventListener('change', () => this.filterTasks());
+        this.elements.tagFilter.addEventListener('input', this.debounce(() => this.filterTasks(), 300));
+        this.elements.taskSearch.addEventListener('input', this.debounce(() => this.filterTasks(), 300));
+        this.elements.taskQuery.addEventListener('input', this.debounce(() => this.filterTasks(), 400));
+        this.elements.tagMatch.addEventListener('change', () => this.filterTasks());
         this.elements.sortBy.addEventListener('change', () => this.filterTasks());
         this.elements.sortOrder.addEventListener('change', () => this.filterTasks());
         
+        // Bulk actions, selects apply immediately
+        this.elements.bulkPriority.addEventListener('change', () => this.bulkAction('priority'));
+        this.elements.bulkProject.addEventListener('change', () => this.bulkAction('move'));
+        document.addEventListener('change', (e) => {
+            if (e.target.classList.contains('task-select')) {
+                this.toggleSelection(Number(e.target.dataset.taskId), e.target.checked);
+            }
+        });
+        
         // Modal
         this.elements.confirmDeleteBtn.addEventListener('click', () => this.confirmDelete());
         this.elements.cancelDeleteBtn.addEventListener('click', () => this.hideDeleteModal());
+        this.elements.closeHistoryBtn.addEventListener('click', () => this.hideHistoryModal());
+        this.elements.closeRemindersBtn.addEventListener('click', () => this.hideRemindersModal());
+        this.elements.addReminderBtn.addEventListener('click', () => this.addReminder());
+        this.elements.closeSnoozeBtn.addEventListener('click', () => this.hideSnoozeModal());
+        this.elements.reminderKind.addEventListener('change', () => {
+            this.elements.reminderAt.disabled = this.elements.reminderKind.value !== 'at';
+        });
+        this.elements.keepMineBtn.addEventListener('click', () => this.resolveConflict(true));
+        this.elements.takeServerBtn.addEventListener('click', () => this.resolveConflict(false));
         
         // Quick actions
         this.elements.addTaskBtn.addEventListener('click', () => this.switchTab('tasks'));
@@ -114,11 +385,83 @@ class TodoApp {
             this.filterTasks();
         });
         
-        // Collapse buttons
+        // Quick add: live preview while typing, Enter creates the task
+        this.elements.quickAddInput.addEventListener('input', this.debounce(() => this.previewQuickAdd(), 300));
+        this.elements.quickAddInput.addEventListener('keydown', (e) => {
+            if (e.key === 'Enter') {
+                e.preventDefault();
+                this.quickAdd();
+            }
+        });
+        
+        // Recurrence: the custom preset reveals the RRULE input
+        this.elements.taskRecurrence.addEventListener('change', () => {
+            const custom = this.elements.taskRecurrence.value === 'custom';
+            this.elements.taskRecurrenceCustomRow.style.display = custom ? '' : 'none';
+            if (custom) this.elements.taskRecurrenceCustom.focus();
+        });
+        
+        // Projects
+        this.elements.addProjectBtn.addEventListener('click', () => this.createProject());
+        this.elements.newProjectName.addEventListener('keypress', (e) => {
+            if (e.key === 'Enter') {
+                this.createProject();
+            }
+        });
+        
+        // Saved filters
+        this.elements.addFilterBtn.addEventListener('click', () => this.createSavedFilter());
+        this.elements.newFilterQuery.addEventListener('keypress', (e) => {
+            if (e.key === 'Enter') {
+                this.createSavedFilter();
+            }
+        });
+        
+        // Trash
+        this.elements.emptyTrashBtn.addEventListener('click', () => this.emptyTrash());
+        
+        // Collapse buttons, tag chips, project, saved filter and trash actions
         document.addEventListener('click', (e) => {
             if (e.target.classList.contains('collapse-btn')) {
                 this.toggleCollapse(e.target);
             }
+            const tag = e.target.closest('.task-tag');
+            if (tag) {
+                this.filterByTag(tag.dataset.tag);
+            }
+            const projectAction = e.target.closest('[data-project-action]');
+            if (projectAction) {
+                this.handleProjectAction(projectAction.dataset.projectAction, Number(projectAction.dataset.projectId));
+            }
+            const filterAction = e.target.closest('[data-filter-action]');
+            if (filterAction) {
+                this.handleSavedFilterAction(filterAction.dataset.filterAction, Number(filterAction.dataset.filterId));
+            }
+            const trashAction = e.target.closest('[data-trash-action]');
+            if (trashAction) {
+                this.handleTrashAction(trashAction.dataset.trashAction, Number(trashAction.dataset.taskId));
+            }
+            const bulkAction = e.target.closest('[data-bulk-action]');
+            if (bulkAction) {
+                this.bulkAction(bulkAction.dataset.bulkAction);
+            }
+            const revertAction = e.target.closest('[data-revision-id]');
+            if (revertAction) {
+                this.revertTask(Number(revertAction.dataset.taskId), Number(revertAction.dataset.revisionId));
+            }
+            const snoozeTask = e.target.closest('[data-snooze-task]');
+            if (snoozeTask) {
+                snoozeTask.closest('.toast')?.remove();
+                this.showSnoozeModal(Number(snoozeTask.dataset.snoozeTask));
+            }
+            const snoozeAction = e.target.closest('[data-snooze]');
+            if (snoozeAction) {
+                this.snoozeTask(snoozeAction.dataset.snooze);
+            }
+            const reminderAction = e.target.closest('[data-reminder-id]');
+            if (reminderAction) {
+                this.deleteReminder(Number(reminderAction.dataset.reminderId));
+            }
         });
         
         // Form validation
@@ -138,6 +481,48 @@ class TodoApp {
         this.setTheme(savedTheme);
     }
 
+    // The backend remembers the zone; a zone left in localStorage by older versions is moved there once
+    async initTimezone() {
+        const select = this.elements.timezoneSelect;
+        const systemZone = Intl.DateTimeFormat().resolvedOptions().timeZone;
+        const zones = typeof Intl.supportedValuesOf === 'function' ? Intl.supportedValuesOf('timeZone') : [];
+        select.innerHTML = `<option value="">Системный (${this.escapeHtml(systemZone)})</option>` +
+            zones.map(zone => `<option value="${this.escapeAttr(zone)}">${this.escapeHtml(zone)}</option>`).join('');
+        
+        const saved = localStorage.getItem('timezone');
+        if (saved !== null) {
+            try {
+                await App.SetTimezone(saved);
+            } catch (error) {
+                console.error('Error applying saved timezone:', error);
+            }
+            localStorage.removeItem('timezone');
+        }
+        try {
+            this.timezone = await App.GetTimezone();
+        } catch (error) {
+            console.error('Error loading timezone:', error);
+        }
+        
+        if (this.timezone && !zones.includes(this.timezone)) {
+            select.add(new Option(this.timezone, this.timezone));
+        }
+        select.value = this.timezone;
+    }
+
+    // "Today" and "week" move with the zone, so every list is reloaded
+    async setTimezone(zone) {
+        try {
+            await App.SetTimezone(zone);
+            this.timezone = zone;
+            this.refreshAllData();
+        } catch (error) {
+            console.error('Error setting timezone:', error);
+            this.elements.timezoneSelect.value = this.timezone;
+            this.showToast('Неизвестный часовой пояс', 'error');
+        }
+    }
+
     // Toggle theme
     toggleTheme() {
         const currentTheme = document.documentElement.getAttribute('data-theme');
@@ -186,7 +571,7 @@ class TodoApp {
     async refreshCurrentTabData() {
         try {
             if (this.currentTab === 'dashboard') {
-                await this.loadDashboardData();
+                await Promise.all([this.loadDashboardData(), this.loadTrash()]);
             } else if (this.currentTab === 'tasks') {
                 await this.loadTasks();
             }
@@ -207,7 +592,9 @@ class TodoApp {
     // Load initial data
     async loadInitialData() {
         try {
+            await this.loadProjects();
             await this.loadDashboardData();
+            await this.loadTrash();
             await this.loadTasks();
         } catch (error) {
             console.error('Error loading initial data:', error);
@@ -222,6 +609,10 @@ class TodoApp {
             this.updateDashboardStats(data.stats);
             this.renderRecentTasks(data.recent_tasks);
             this.renderOverdueTasks(data.overdue_tasks);
+            this.renderUpcomingTasks(data.upcoming_tasks, data.upcoming_occurrences);
+            this.renderTagCounts(data.tags);
+            this.renderProjectStats(data.stats.projects);
+            this.renderSavedFilters(data.saved_filters);
         } catch (error) {
             console.error('Error loading dashboard:', error);
             this.showToast('Ошибка загрузки дашборда', 'error');
@@ -236,6 +627,15 @@ class TodoApp {
             this.elements.completedTasks.textContent = stats.completed;
             this.elements.overdueTasks.textContent = stats.overdue;
         }
+        
+        // Open tasks by due period and priority
+        if (this.elements.statsBreakdown) {
+            const byPriority = ['high', 'medium', 'low']
+                .map(priority => `${this.getPriorityLabel(priority)}: ${stats.by_priority?.[priority] || 0}`)
+                .join(' · ');
+            this.elements.statsBreakdown.textContent =
+                `Сегодня: ${stats.due_today} · На этой неделе: ${stats.due_this_week} · Открытые — ${byPriority}`;
+        }
     }
 
     // Render recent tasks
@@ -280,24 +680,383 @@ class TodoApp {
         `).join('');
     }
 
+    // Render upcoming tasks merged with projected occurrences of recurring tasks
+    renderUpcomingTasks(tasks, occurrences) {
+        const container = this.elements.upcomingTasksList;
+        if (!container) return;
+        
+        const items = [
+            ...(tasks || []).map(task => ({ ...task, projected: false })),
+            ...(occurrences || []).map(occurrence => ({ ...occurrence, projected: true }))
+        ].sort((a, b) => new Date(a.due_date) - new Date(b.due_date));
+        
+        if (items.length === 0) {
+            container.innerHTML = '<div class="empty-state">Нет задач на неделю</div>';
+            return;
+        }
+        
+        container.innerHTML = items.slice(0, 7).map(item => `
+            <div class="task-item-mini ${item.projected ? 'projected' : ''}">
+                <div class="task-title-mini">${item.projected ? '🔁 ' : ''}${this.escapeHtml(item.title)}</div>
+                <div class="task-meta-mini">
+                    <span class="priority-${item.priority}">${this.getPriorityLabel(item.priority)}</span>
+                    • ${this.formatDate(item.due_date)}
+                    ${item.projected ? '• <span class="task-projected">повторение</span>' : ''}
+                </div>
+            </div>
+        `).join('');
+    }
+
+    // Render tag usage counts
+    renderTagCounts(tags) {
+        const container = this.elements.tagsList;
+        if (!container) return;
+        
+        if (!tags || tags.length === 0) {
+            container.innerHTML = '<div class="empty-state">Нет тегов</div>';
+            return;
+        }
+        
+        container.innerHTML = [...tags]
+            .sort((a, b) => b.task_count - a.task_count)
+            .map(tag => `
+                <span class="task-tag" data-tag="${this.escapeAttr(tag.name)}">
+                    #${this.escapeHtml(tag.name)}<span class="tag-count">${tag.task_count}</span>
+                </span>
+            `).join('');
+    }
+
+    // Load projects for the selects and task badges
+    async loadProjects() {
+        try {
+            this.projects = await App.GetProjects() || [];
+            this.renderProjectOptions();
+        } catch (error) {
+            console.error('Error loading projects:', error);
+        }
+    }
+
+    // Archived projects can be filtered on but new tasks cannot be added to them
+    renderProjectOptions() {
+        const filterValue = this.elements.projectFilter.value;
+        const taskValue = this.elements.taskProject.value;
+        
+        this.elements.projectFilter.innerHTML = '<option value="">Все активные</option>' + this.projects.map(project => `
+            <option value="${project.id}">${this.escapeHtml(project.name)}${project.archived ? ' (архив)' : ''}</option>
+        `).join('');
+        this.elements.taskProject.innerHTML = this.projects
+            .filter(project => !project.archived)
+            .map(project => `<option value="${project.id}">${this.escapeHtml(project.name)}</option>`)
+            .join('');
+        this.elements.bulkProject.innerHTML = '<option value="">В проект…</option>' + this.elements.taskProject.innerHTML;
+        
+        if (this.findProject(Number(filterValue))) {
+            this.elements.projectFilter.value = filterValue;
+        }
+        if (this.elements.taskProject.querySelector(`option[value="${taskValue}"]`)) {
+            this.elements.taskProject.value = taskValue;
+        }
+    }
+
+    // Render per-project stats with reorder, archive and delete actions
+    renderProjectStats(stats) {
+        const container = this.elements.projectsList;
+        if (!container) return;
+        
+        if (!stats || stats.length === 0) {
+            container.innerHTML = '<div class="empty-state">Нет проектов</div>';
+            return;
+        }
+        
+        container.innerHTML = stats.map((item, index) => {
+            const project = this.findProject(item.project_id);
+            const inbox = project?.inbox;
+            
+            return `
+                <div class="project-row ${item.archived ? 'archived' : ''}">
+                    <span class="project-dot" style="background: ${this.escapeAttr(item.color)}"></span>
+                    <span class="project-name" data-project-action="open" data-project-id="${item.project_id}">${this.escapeHtml(item.name)}</span>
+                    <span class="project-counts">${item.pending} / ${item.total}${item.overdue ? ` • <span style="color: var(--danger);">${item.overdue}</span>` : ''}</span>
+                    <button class="project-action" data-project-action="up" data-project-id="${item.project_id}" title="Выше" ${index === 0 ? 'disabled' : ''}>↑</button>
+                    <button class="project-action" data-project-action="down" data-project-id="${item.project_id}" title="Ниже" ${index === stats.length - 1 ? 'disabled' : ''}>↓</button>
+                    ${inbox ? '' : `
+                        <button class="project-action" data-project-action="${item.archived ? 'unarchive' : 'archive'}" data-project-id="${item.project_id}" title="${item.archived ? 'Вернуть из архива' : 'В архив'}">${item.archived ? '↺' : '🗄'}</button>
+                        <button class="project-action" data-project-action="delete" data-project-id="${item.project_id}" title="Удалить, задачи перейдут во входящие">✕</button>
+                    `}
+                </div>
+            `;
+        }).join('');
+    }
+
+    async createProject() {
+        const name = this.elements.newProjectName.value.trim();
+        if (!name) return;
+        
+        try {
+            await App.CreateProject(name, this.elements.newProjectColor.value);
+            this.elements.newProjectName.value = '';
+            this.showToast('Проект создан', 'success');
+        } catch (error) {
+            console.error('Error creating project:', error);
+            this.showToast('Ошибка создания проекта', 'error');
+        }
+    }
+
+    // Lists are refreshed by the projects:changed event
+    async handleProjectAction(action, projectId) {
+        try {
+            switch (action) {
+                case 'open':
+                    this.filterByProject(projectId);
+                    break;
+                case 'up':
+                case 'down':
+                    await this.moveProject(projectId, action === 'up' ? -1 : 1);
+                    break;
+                case 'archive':
+                case 'unarchive':
+                    await App.ArchiveProject(projectId, action === 'archive');
+                    break;
+                case 'delete':
+                    await App.DeleteProject(projectId);
+                    this.showToast('Проект удален, задачи перенесены во входящие', 'success');
+                    break;
+            }
+        } catch (error) {
+            console.error('Error updating project:', error);
+            this.showToast('Ошибка изменения проекта', 'error');
+        }
+    }
+
+    async moveProject(projectId, offset) {
+        const ids = this.projects.map(project => project.id);
+        const index = ids.indexOf(projectId);
+        const target = index + offset;
+        if (index === -1 || target < 0 || target >= ids.length) return;
+        
+        [ids[index], ids[target]] = [ids[target], ids[index]];
+        await App.ReorderProjects(ids);
+    }
+
+    // Render smart lists with live counts, a broken query shows its error instead
+    renderSavedFilters(filters) {
+        this.savedFilters = filters || [];
+        const container = this.elements.savedFiltersList;
+        if (!container) return;
+        
+        if (this.savedFilters.length === 0) {
+            container.innerHTML = '<div class="empty-state">Нет сохраненных списков</div>';
+            return;
+        }
+        
+        container.innerHTML = this.savedFilters.map(filter => `
+            <div class="project-row">
+                <span class="project-name" data-filter-action="open" data-filter-id="${filter.id}">${this.escapeHtml(filter.name)}</span>
+                <span class="saved-filter-query" title="${this.escapeAttr(filter.query)}">${this.escapeHtml(filter.query)}</span>
+                ${filter.error
+                    ? `<span class="project-counts" style="color: var(--danger);" title="${this.escapeAttr(filter.error)}">ошибка</span>`
+                    : `<span class="project-counts">${filter.task_count}</span>`}
+                <button class="project-action" data-filter-action="delete" data-filter-id="${filter.id}" title="Удалить список">✕</button>
+            </div>
+        `).join('');
+    }
+
+    async createSavedFilter() {
+        const name = this.elements.newFilterName.value.trim();
+        const query = this.elements.newFilterQuery.value.trim() || this.elements.taskQuery.value.trim();
+        if (!name || !query) return;
+        
+        try {
+            await App.CreateSavedFilter(name, query);
+            this.elements.newFilterName.value = '';
+            this.elements.newFilterQuery.value = '';
+            this.showToast('Список сохранен', 'success');
+        } catch (error) {
+            console.error('Error creating saved filter:', error);
+            this.showToast(`Ошибка сохранения списка: ${error}`, 'error');
+        }
+    }
+
+    // Counts are refreshed by the filters:changed event
+    async handleSavedFilterAction(action, filterId) {
+        const filter = this.savedFilters?.find(item => item.id === filterId);
+        if (!filter) return;
+        
+        try {
+            switch (action) {
+                case 'open':
+                    this.openSavedFilter(filter);
+                    break;
+                case 'delete':
+                    await App.DeleteSavedFilter(filterId);
+                    this.showToast('Список удален', 'success');
+                    break;
+            }
+        } catch (error) {
+            console.error('Error updating saved filter:', error);
+            this.showToast('Ошибка изменения списка', 'error');
+        }
+    }
+
+    // Lists are refreshed by the task events published for the reverted changes
+    async undo() {
+        try {
+            const result = await App.Undo();
+            if (result) {
+                this.showToast(`Отменено: ${this.getHistoryActionLabel(result.action)}`, 'info');
+            } else {
+                this.showToast('Нечего отменять', 'info');
+            }
+        } catch (error) {
+            console.error('Error undoing:', error);
+            this.showToast(`Не удалось отменить: ${error}`, 'error');
+        }
+    }
+
+    async redo() {
+        try {
+            const result = await App.Redo();
+            if (result) {
+                this.showToast(`Повторено: ${this.getHistoryActionLabel(result.action)}`, 'info');
+            } else {
+                this.showToast('Нечего повторять', 'info');
+            }
+        } catch (error) {
+            console.error('Error redoing:', error);
+            this.showToast(`Не удалось повторить: ${error}`, 'error');
+        }
+    }
+
+    getHistoryActionLabel(action) {
+        const labels = {
+            create: 'создание задачи',
+            update: 'изменение задачи',
+            delete: 'удаление задачи',
+            toggle: 'изменение статуса',
+            bulk_update: 'массовое изменение',
+            revert: 'восстановление версии',
+            snooze: 'откладывание задачи'
+        };
+        return labels[action] || action;
+    }
+
+    async loadTrash() {
+        try {
+            this.renderTrash(await App.GetTrash());
+        } catch (error) {
+            console.error('Error loading trash:', error);
+        }
+    }
+
+    // Subtasks deleted together with a task are restored and purged with it
+    renderTrash(tasks) {
+        const container = this.elements.trashList;
+        if (!container) return;
+        
+        this.elements.emptyTrashBtn.disabled = !tasks || tasks.length === 0;
+        if (!tasks || tasks.length === 0) {
+            container.innerHTML = '<div class="empty-state">Корзина пуста</div>';
+            return;
+        }
+        
+        container.innerHTML = tasks.map(task => `
+            <div class="project-row">
+                <span class="project-name">${this.escapeHtml(task.title)}</span>
+                <span class="project-counts">${this.formatDate(task.deleted_at)}</span>
+                <button class="project-action" data-trash-action="restore" data-task-id="${task.id}" title="Восстановить">↺</button>
+                <button class="project-action" data-trash-action="purge" data-task-id="${task.id}" title="Удалить навсегда">✕</button>
+            </div>
+        `).join('');
+    }
+
+    // Lists are refreshed by the task:restored and trash:changed events
+    async handleTrashAction(action, taskId) {
+        try {
+            switch (action) {
+                case 'restore':
+                    await App.RestoreTask(taskId);
+                    this.showToast('Задача восстановлена', 'success');
+                    break;
+                case 'purge':
+                    await App.PurgeTask(taskId);
+                    this.showToast('Задача удалена навсегда', 'success');
+                    break;
+            }
+        } catch (error) {
+            console.error('Error updating trash:', error);
+            this.showToast('Ошибка изменения корзины', 'error');
+        }
+    }
+
+    async emptyTrash() {
+        try {
+            const count = await App.EmptyTrash();
+            this.showToast(`Удалено задач: ${count}`, 'success');
+        } catch (error) {
+            console.error('Error emptying trash:', error);
+            this.showToast('Ошибка очистки корзины', 'error');
+        }
+    }
+
+    // A smart list is opened as filter:"name" so more conditions can be added to the query,
+    // names with quotes cannot be quoted and are referenced by id
+    openSavedFilter(filter) {
+        this.switchTab('tasks');
+        this.elements.taskQuery.value = filter.name.includes('"') ? `filter:${filter.id}` : `filter:"${filter.name}"`;
+        this.filterTasks();
+    }
+
+    // Show tasks of the given project
+    filterByProject(projectId) {
+        this.switchTab('tasks');
+        this.elements.projectFilter.value = String(projectId);
+        this.filterTasks();
+    }
+
+    // Show tasks with the given tag
+    filterByTag(tag) {
+        this.switchTab('tasks');
+        this.elements.tagFilter.value = tag;
+        this.elements.tagMatch.value = 'any';
+        this.filterTasks();
+    }
+
     // Load tasks with better error handling
     async loadTasks() {
-        if (this.isLoading) return;
+        // Separate flag: a pending save must not block the reload triggered by its event
+        if (this.isLoadingTasks) return;
         
-        this.setLoading(true);
+        this.isLoadingTasks = true;
         
         try {
             const status = this.elements.statusFilter?.value || '';
             const priority = this.elements.priorityFilter?.value || '';
             const sortBy = this.elements.sortBy?.value || 'created_at';
             const sortOrder = this.elements.sortOrder?.value || 'desc';
+            const tags = this.getTagFilter();
+            const tagMatch = this.elements.tagMatch?.value || 'any';
+            const projectId = this.getProjectFilter();
             
-            let tasks;
+            this.searchSnippets = new Map();
             
-            // Check if date filter is applied
+            // Search results keep relevance order, other filters are applied on top
+            const searchQuery = this.getSearchQuery();
+            const taskQuery = this.elements.taskQuery?.value.trim() || '';
             const dateFilter = this.elements.dateFilter?.value || '';
-            if (dateFilter) {
-                tasks = await App.GetTasksByDateFilter(dateFilter);
+            this.showQueryError('');
+            let fetchPage = null;
+            if (searchQuery) {
+                fetchPage = async (cursor) => {
+                    const page = await App.SearchTasks(searchQuery, PAGE_SIZE, cursor);
+                    page.results.forEach(result => this.searchSnippets.set(result.task.id, result.snippet));
+                    const tasks = page.results.map(result => result.task).filter(task =>
+                        (!status || task.status === status) &&
+                        (!priority || task.priority === priority) &&
+                        this.matchesTagFilter(task) && this.matchesProjectFilter(task));
+                    return { tasks, next_cursor: page.next_cursor, total: page.total };
+                };
+            } else if (dateFilter && !taskQuery) {
+                let tasks = await App.GetTasksByDateFilter(dateFilter);
                 // Apply additional filters
                 if (status) {
                     tasks = tasks.filter(task => task.status === status);
@@ -305,19 +1064,109 @@ class TodoApp {
                 if (priority) {
                     tasks = tasks.filter(task => task.priority === priority);
                 }
+                tasks = tasks.filter(task => this.matchesTagFilter(task) && this.matchesProjectFilter(task));
+                this.setTaskPage({ tasks, next_cursor: '', total: tasks.length }, null);
             } else {
-                tasks = await App.GetTasks(status, priority, sortBy, sortOrder);
+                fetchPage = (cursor) => App.GetTasksPage(status, priority, sortBy, sortOrder, tags, tagMatch, projectId, taskQuery, PAGE_SIZE, cursor);
             }
             
-            this.tasks = tasks || [];
-            this.renderTasks();
+            if (fetchPage) {
+                this.setTaskPage(await fetchPage(''), fetchPage);
+            }
             
         } catch (error) {
             console.error('Error loading tasks:', error);
+            // Query errors are shown next to the input, the list keeps the previous result
+            if (String(error).includes('invalid query')) {
+                this.showQueryError(String(error));
+                return;
+            }
+            const message = String(error).includes('invalid search query') ? `Ошибка поиска: ${error}` : 'Ошибка загрузки задач';
+            this.showToast(message, 'error');
+        } finally {
+            this.isLoadingTasks = false;
+        }
+        
+        this.loadMoreIfVisible();
+    }
+
+    // Replace the list with the first page; later pages come from loadMoreTasks
+    setTaskPage(page, fetchPage) {
+        this.listGeneration++;
+        this.fetchPage = fetchPage;
+        this.nextCursor = page.next_cursor || '';
+        this.totalTasks = page.total;
+        this.tasks = page.tasks || [];
+        this.renderTasks();
+    }
+
+    async loadMoreTasks() {
+        if (!this.fetchPage || !this.nextCursor || this.isLoadingTasks || this.isLoadingMore) return;
+        
+        this.isLoadingMore = true;
+        const generation = this.listGeneration;
+        
+        try {
+            const page = await this.fetchPage(this.nextCursor);
+            // Filters changed while the page was loading
+            if (generation !== this.listGeneration) return;
+            
+            // Tasks created since the first page are already at the top of the list
+            const known = new Set(this.tasks.map(task => task.id));
+            this.tasks.push(...page.tasks.filter(task => !known.has(task.id)));
+            this.nextCursor = page.next_cursor || '';
+            this.totalTasks = page.total;
+            this.renderTasks();
+        } catch (error) {
+            console.error('Error loading more tasks:', error);
             this.showToast('Ошибка загрузки задач', 'error');
+            return;
         } finally {
-            this.setLoading(false);
+            this.isLoadingMore = false;
         }
+        
+        this.loadMoreIfVisible();
+    }
+
+    // The observer fires only on visibility changes, a short page keeps the sentinel in view
+    loadMoreIfVisible() {
+        const sentinel = this.elements.tasksSentinel;
+        if (!sentinel || !this.nextCursor || this.currentTab !== 'tasks') return;
+        
+        if (sentinel.getBoundingClientRect().top < window.innerHeight + 200) {
+            this.loadMoreTasks();
+        }
+    }
+
+    renderPager() {
+        const pager = this.elements.tasksPager;
+        if (!pager) return;
+        
+        pager.style.display = this.tasks.length ? '' : 'none';
+        pager.textContent = `Показано ${this.tasks.length} из ${Math.max(this.totalTasks, this.tasks.length)}`;
+    }
+
+    showQueryError(message) {
+        if (!this.elements.taskQueryError) return;
+        
+        this.elements.taskQueryError.textContent = message;
+        this.elements.taskQueryError.style.display = message ? '' : 'none';
+        this.elements.taskQuery?.classList.toggle('invalid', Boolean(message));
+    }
+
+    getSearchQuery() {
+        return this.elements.taskSearch?.value.trim() || '';
+    }
+
+    // Snippet text is escaped, only the <mark> highlights from the backend are kept
+    renderSearchSnippet(task) {
+        const snippet = this.searchSnippets?.get(task.id);
+        if (!snippet) return '';
+        
+        const html = this.escapeHtml(snippet)
+            .replaceAll('&lt;mark&gt;', '<mark>')
+            .replaceAll('&lt;/mark&gt;', '</mark>');
+        return `<div class="task-snippet">${html}</div>`;
     }
 
     // Filter tasks
@@ -332,6 +1181,7 @@ class TodoApp {
         
         this.renderTaskList(activeTasks, this.elements.activeTasksList);
         this.renderTaskList(completedTasks, this.elements.completedTasksList);
+        this.renderBulkBar();
         
         // Update counters
         if (this.elements.activeTaskCount) {
@@ -340,6 +1190,8 @@ class TodoApp {
         if (this.elements.completedTaskCount) {
             this.elements.completedTaskCount.textContent = completedTasks.length;
         }
+        
+        this.renderPager();
     }
 
     // Render task list
@@ -370,17 +1222,23 @@ class TodoApp {
         const isOverdue = task.is_overdue;
         
         return `
-            <div class="task-item ${task.status === 'completed' ? 'completed' : ''} ${isOverdue ? 'overdue' : ''}" data-task-id="${task.id}">
+            <div class="task-item ${task.status === 'completed' ? 'completed' : ''} ${isOverdue ? 'overdue' : ''} ${this.selectedIds.has(task.id) ? 'selected' : ''}" data-task-id="${task.id}">
+                <input type="checkbox" class="task-select" data-task-id="${task.id}" title="Выбрать" ${this.selectedIds.has(task.id) ? 'checked' : ''}>
                 <div class="task-checkbox ${task.status === 'completed' ? 'checked' : ''}" 
                      onclick="todoApp.toggleTaskStatus(${task.id})">
                 </div>
                 <div class="task-content">
                     <div class="task-title">${this.escapeHtml(task.title)}</div>
                     ${task.description ? `<div class="task-description">${this.escapeHtml(task.description)}</div>` : ''}
+                    ${this.renderSearchSnippet(task)}
                     <div class="task-meta">
+                        ${this.renderProjectBadge(task)}
                         <span class="task-priority ${task.priority}">${this.getPriorityLabel(task.priority)}</span>
                         ${dueDate ? `<span class="task-due-date ${isOverdue ? 'overdue' : ''}">${this.formatDate(dueDate)}</span>` : ''}
+                        ${task.recurrence ? `<span class="task-recurrence" title="${this.escapeAttr(task.recurrence)}">🔁 ${this.getRecurrenceLabel(task.recurrence)}</span>` : ''}
+                        ${task.snooze_count > 0 ? `<span class="task-snoozed" title="Сколько раз задачу откладывали">⏰ ${task.snooze_count}</span>` : ''}
                         <span class="task-created">Создано: ${this.formatDate(new Date(task.created_at))}</span>
+                        ${task.tags.map(tag => `<span class="task-tag" data-tag="${this.escapeAttr(tag)}">#${this.escapeHtml(tag)}</span>`).join('')}
                     </div>
                 </div>
                 <div class="task-actions">
@@ -390,6 +1248,25 @@ class TodoApp {
                             <path d="M18.5 2.5a2.121 2.121 0 0 1 3 3L12 15l-4 1 1-4 9.5-9.5z"/>
                         </svg>
                     </button>
+                    <button class="task-action-btn" onclick="todoApp.showHistoryModal(${task.id})" title="История изменений">
+                        <svg viewBox="0 0 24 24" fill="none" stroke="currentColor">
+                            <circle cx="12" cy="12" r="10"/>
+                            <polyline points="12,6 12,12 16,14"/>
+                        </svg>
+                    </button>
+                    ${task.status === 'pending' ? `
+                    <button class="task-action-btn" data-snooze-task="${task.id}" title="Отложить">
+                        <svg viewBox="0 0 24 24" fill="none" stroke="currentColor">
+                            <polyline points="13,17 18,12 13,7"/>
+                            <polyline points="6,17 11,12 6,7"/>
+                        </svg>
+                    </button>` : ''}
+                    <button class="task-action-btn" onclick="todoApp.showRemindersModal(${task.id})" title="Напоминания">
+                        <svg viewBox="0 0 24 24" fill="none" stroke="currentColor">
+                            <path d="M18 8A6 6 0 0 0 6 8c0 7-3 9-3 9h18s-3-2-3-9"/>
+                            <path d="M13.73 21a2 2 0 0 1-3.46 0"/>
+                        </svg>
+                    </button>
                     <button class="task-action-btn delete" onclick="todoApp.showDeleteModal(${task.id})" title="Удалить">
                         <svg viewBox="0 0 24 24" fill="none" stroke="currentColor">
                             <polyline points="3,6 5,6 21,6"/>
@@ -401,12 +1278,47 @@ class TodoApp {
         `;
     }
 
+    getRecurrenceLabel(rule) {
+        const option = this.elements.taskRecurrence.querySelector(`option[value="${CSS.escape(rule)}"]`);
+        return option ? option.textContent : 'Повторяется';
+    }
+
+    // Preset value or the custom RRULE text
+    getRecurrenceValue() {
+        const value = this.elements.taskRecurrence.value;
+        return value === 'custom' ? this.elements.taskRecurrenceCustom.value.trim() : value;
+    }
+
+    setRecurrenceValue(rule) {
+        const isPreset = rule === '' || rule !== 'custom' &&
+            this.elements.taskRecurrence.querySelector(`option[value="${CSS.escape(rule)}"]`);
+        this.elements.taskRecurrence.value = isPreset ? rule : 'custom';
+        this.elements.taskRecurrenceCustom.value = isPreset ? '' : rule;
+        this.elements.taskRecurrenceCustomRow.style.display = isPreset ? 'none' : '';
+    }
+
+    renderProjectBadge(task) {
+        const project = this.findProject(task.project_id);
+        if (!project) return '';
+        
+        return `
+            <span class="task-project">
+                <span class="project-dot" style="background: ${this.escapeAttr(project.color)}"></span>
+                ${this.escapeHtml(project.name)}
+            </span>
+        `;
+    }
+
     // Save task with comprehensive updates
     async saveTask() {
         const title = this.elements.taskTitle.value.trim();
         const description = this.elements.taskDescription.value.trim();
         const priority = this.elements.taskPriority.value;
+        // "YYYY-MM-DD" for all-day tasks, otherwise wall time in the user's zone
         const dueDate = this.elements.taskDueDate.value;
+        const tags = this.parseTags(this.elements.taskTags.value);
+        const projectId = Number(this.elements.taskProject.value || 0);
+        const recurrence = this.getRecurrenceValue();
         
         // Validation
         if (!title) {
@@ -424,21 +1336,37 @@ class TodoApp {
             return;
         }
         
+        if (recurrence && !dueDate) {
+            this.showToast('Для повторяющейся задачи нужен срок выполнения', 'error');
+            return;
+        }
+        
         this.setLoading(true);
         
         try {
             let result;
             
             if (this.currentEditId) {
-                // Update existing task
-                result = await App.UpdateTask(
+                // Update existing task, the version guards against overwriting a concurrent edit
+                const response = await App.UpdateTask(
                     this.currentEditId,
                     title,
                     description,
                     '', // status - don't change
                     priority,
-                    dueDate ? new Date(dueDate).toISOString() : ''
+                    dueDate,
+                    tags,
+                    recurrence,
+                    this.currentEditVersion
                 );
+                if (response.conflict) {
+                    this.showConflictModal(response.task);
+                    return;
+                }
+                result = response.task;
+                if (projectId && result.project_id !== projectId) {
+                    await App.MoveTaskToProject(this.currentEditId, projectId);
+                }
                 this.showToast('Задача обновлена', 'success');
             } else {
                 // Create new task
@@ -446,16 +1374,17 @@ class TodoApp {
                     title,
                     description,
                     priority,
-                    dueDate ? new Date(dueDate).toISOString() : ''
+                    dueDate,
+                    tags,
+                    projectId,
+                    recurrence
                 );
                 this.showToast('Задача создана', 'success');
             }
             
+            // Lists are patched by the task events emitted from the backend
             this.clearForm();
             
-            // Comprehensive data reload
-            await this.refreshAllData();
-            
         } catch (error) {
             console.error('Error saving task:', error);
             this.showToast('Ошибка сохранения задачи', 'error');
@@ -464,12 +1393,62 @@ class TodoApp {
         }
     }
 
+    // Show what the quick add text will turn into; stale responses are dropped
+    async previewQuickAdd() {
+        const text = this.elements.quickAddInput.value.trim();
+        const request = ++this.quickAddRequest;
+        const preview = this.elements.quickAddPreview;
+        
+        if (!text) {
+            preview.classList.remove('error');
+            preview.innerHTML = '';
+            return;
+        }
+        
+        try {
+            const parsed = await App.PreviewQuickAdd(text);
+            if (request !== this.quickAddRequest) return;
+            
+            preview.classList.remove('error');
+            preview.innerHTML = `
+                <strong>${this.escapeHtml(parsed.title)}</strong>
+                <span class="task-priority ${parsed.priority}">${this.getPriorityLabel(parsed.priority)}</span>
+                ${parsed.due_date ? `<span>📅 ${this.formatDueLabel(parsed)}</span>` : ''}
+                ${parsed.recurrence ? `<span class="task-recurrence" title="${this.escapeAttr(parsed.recurrence)}">🔁 ${this.getRecurrenceLabel(parsed.recurrence)}</span>` : ''}
+                ${parsed.tags.map(tag => `<span class="task-tag">#${this.escapeHtml(tag)}</span>`).join('')}
+            `;
+        } catch (error) {
+            if (request !== this.quickAddRequest) return;
+            preview.classList.add('error');
+            preview.textContent = 'Не осталось названия задачи';
+        }
+    }
+    
+    // The task lands in the project selected in the form; lists are patched by task:created
+    async quickAdd() {
+        const text = this.elements.quickAddInput.value.trim();
+        if (!text) return;
+        
+        try {
+            await App.QuickAdd(text, Number(this.elements.taskProject.value || 0));
+            this.quickAddRequest++;
+            this.elements.quickAddInput.value = '';
+            this.elements.quickAddPreview.classList.remove('error');
+            this.elements.quickAddPreview.innerHTML = '';
+            this.showToast('Задача создана', 'success');
+        } catch (error) {
+            console.error('Error adding task:', error);
+            this.showToast(`Не удалось создать задачу: ${this.escapeHtml(String(error))}`, 'error');
+        }
+    }
+
     // Refresh all data comprehensively
     async refreshAllData() {
         try {
             // Always reload both dashboard and tasks data
             await Promise.all([
                 this.loadDashboardData(),
+                this.loadTrash(),
                 this.loadTasks()
             ]);
             
@@ -486,19 +1465,7 @@ class TodoApp {
         const task = this.tasks.find(t => t.id === taskId);
         if (!task) return;
         
-        this.currentEditId = taskId;
-        
-        // Fill form with task data
-        this.elements.taskTitle.value = task.title;
-        this.elements.taskDescription.value = task.description || '';
-        this.elements.taskPriority.value = task.priority;
-        
-        if (task.due_date) {
-            const date = new Date(task.due_date);
-            this.elements.taskDueDate.value = this.formatDateTimeLocal(date);
-        } else {
-            this.elements.taskDueDate.value = '';
-        }
+        this.fillEditForm(task);
         
         // Update form UI
         this.elements.saveTaskBtn.innerHTML = `
@@ -514,6 +1481,78 @@ class TodoApp {
         this.elements.taskForm.scrollIntoView({ behavior: 'smooth' });
     }
 
+    // Fill the form with task data and remember the version being edited
+    fillEditForm(task) {
+        this.currentEditId = task.id;
+        this.currentEditVersion = task.version;
+        
+        // Fill form with task data
+        this.elements.taskTitle.value = task.title;
+        this.elements.taskDescription.value = task.description || '';
+        this.elements.taskPriority.value = task.priority;
+        this.elements.taskTags.value = task.tags.join(', ');
+        this.elements.taskProject.value = String(task.project_id);
+        this.setRecurrenceValue(task.recurrence || '');
+        
+        this.elements.taskDueDate.value = '';
+        this.setAllDay(Boolean(task.all_day));
+        if (task.due_date) {
+            this.elements.taskDueDate.value = this.formatDueLocal(task);
+        }
+    }
+
+    // Switch the due date input between a date and a date with time, keeping the day
+    setAllDay(allDay) {
+        const input = this.elements.taskDueDate;
+        const value = input.value;
+        this.elements.taskAllDay.checked = allDay;
+        input.type = allDay ? 'date' : 'datetime-local';
+        if (value) {
+            input.value = allDay ? value.slice(0, 10) : `${value.slice(0, 10)}T09:00`;
+        }
+    }
+
+    // The task was saved elsewhere since editing started: compare the form with the server copy
+    showConflictModal(serverTask) {
+        this.conflictTask = serverTask;
+        
+        const serverDue = serverTask.due_date ? this.formatDueLocal(serverTask) : '';
+        const fields = [
+            ['Название', this.elements.taskTitle.value.trim(), serverTask.title],
+            ['Описание', this.elements.taskDescription.value.trim(), serverTask.description || ''],
+            ['Приоритет', this.getPriorityLabel(this.elements.taskPriority.value), this.getPriorityLabel(serverTask.priority)],
+            ['Срок', this.elements.taskDueDate.value.replace('T', ' '), serverDue.replace('T', ' ')],
+            ['Теги', this.parseTags(this.elements.taskTags.value).join(', '), serverTask.tags.join(', ')],
+            ['Повторение', this.getRecurrenceValue() || '', serverTask.recurrence || '']
+        ];
+        
+        this.elements.conflictFields.innerHTML = fields
+            .filter(([, mine, server]) => mine !== server)
+            .map(([label, mine, server]) => `
+                <tr>
+                    <td>${label}</td>
+                    <td>${this.escapeHtml(mine || '—')}</td>
+                    <td>${this.escapeHtml(server || '—')}</td>
+                </tr>
+            `).join('') || '<tr><td colspan="3">Значения совпадают</td></tr>';
+        this.elements.conflictModal.classList.add('show');
+    }
+
+    // Keeping mine saves the form again on top of the server version
+    async resolveConflict(keepMine) {
+        const serverTask = this.conflictTask;
+        this.elements.conflictModal.classList.remove('show');
+        this.conflictTask = null;
+        if (!serverTask) return;
+        
+        if (keepMine) {
+            this.currentEditVersion = serverTask.version;
+            await this.saveTask();
+        } else {
+            this.fillEditForm(serverTask);
+        }
+    }
+
     // Cancel edit
     cancelEdit() {
         this.currentEditId = null;
@@ -526,6 +1565,9 @@ class TodoApp {
         this.elements.taskDescription.value = '';
         this.elements.taskPriority.value = 'medium';
         this.elements.taskDueDate.value = '';
+        this.setAllDay(false);
+        this.elements.taskTags.value = '';
+        this.setRecurrenceValue('');
         
         this.elements.saveTaskBtn.innerHTML = `
             <svg viewBox="0 0 24 24" fill="none" stroke="currentColor">
@@ -536,6 +1578,7 @@ class TodoApp {
         `;
         this.elements.cancelEditBtn.style.display = 'none';
         this.currentEditId = null;
+        this.currentEditVersion = 0;
     }
 
     // Toggle task status with comprehensive updates
@@ -543,12 +1586,9 @@ class TodoApp {
         this.setLoading(true);
         
         try {
-            await App.ToggleTaskComplete(taskId);
+            await App.ToggleTaskComplete(taskId, "");
             this.showToast('Статус задачи изменен', 'success');
             
-            // Comprehensive data reload
-            await this.refreshAllData();
-            
         } catch (error) {
             console.error('Error toggling task status:', error);
             this.showToast('Ошибка изменения статуса', 'error');
@@ -557,6 +1597,92 @@ class TodoApp {
         }
     }
 
+    toggleSelection(taskId, selected) {
+        if (selected) {
+            this.selectedIds.add(taskId);
+        } else {
+            this.selectedIds.delete(taskId);
+        }
+        document.querySelector(`.task-item[data-task-id="${taskId}"]`)?.classList.toggle('selected', selected);
+        this.renderBulkBar();
+    }
+
+    // Tasks that left the list (deleted, filtered out) are dropped from the selection
+    renderBulkBar() {
+        const visible = new Set(this.tasks.map(task => task.id));
+        this.selectedIds.forEach(id => {
+            if (!visible.has(id)) this.selectedIds.delete(id);
+        });
+        
+        this.elements.bulkCount.textContent = this.selectedIds.size;
+        this.elements.bulkBar.style.display = this.selectedIds.size > 0 ? '' : 'none';
+    }
+
+    clearSelection() {
+        this.selectedIds.clear();
+        document.querySelectorAll('.task-select:checked').forEach(input => {
+            input.checked = false;
+            input.closest('.task-item')?.classList.remove('selected');
+        });
+        this.renderBulkBar();
+    }
+
+    // Bulk operations are all-or-nothing on the backend, lists are updated by the task events
+    async bulkAction(action) {
+        const ids = [...this.selectedIds];
+        if (action === 'clear' || ids.length === 0) {
+            this.clearSelection();
+            return;
+        }
+        
+        const tags = this.parseTags(this.elements.bulkTags.value);
+        if ((action === 'tag' || action === 'untag') && tags.length === 0) {
+            this.showToast('Укажите теги', 'error');
+            return;
+        }
+        
+        this.setLoading(true);
+        
+        try {
+            switch (action) {
+                case 'complete':
+                case 'reopen':
+                    await App.BulkSetStatus(ids, action === 'complete' ? 'completed' : 'pending');
+                    break;
+                case 'priority':
+                    if (!this.elements.bulkPriority.value) return;
+                    await App.BulkUpdateTasks(ids, this.elements.bulkPriority.value, '');
+                    break;
+                case 'move':
+                    if (!this.elements.bulkProject.value) return;
+                    await App.BulkMoveToProject(ids, Number(this.elements.bulkProject.value));
+                    break;
+                case 'tag':
+                    await App.BulkTagTasks(ids, tags, []);
+                    break;
+                case 'untag':
+                    await App.BulkTagTasks(ids, [], tags);
+                    break;
+                case 'delete':
+                    await App.BulkDeleteTasks(ids);
+                    break;
+                default:
+                    return;
+            }
+            
+            this.showToast(`Изменено задач: ${ids.length}`, 'success');
+            this.elements.bulkTags.value = '';
+            this.clearSelection();
+        } catch (error) {
+            console.error('Error in bulk action:', error);
+            this.showToast(`Ни одна задача не изменена: ${error}`, 'error');
+        } finally {
+            this.elements.bulkPriority.value = '';
+            this.elements.bulkProject.value = '';
+            this.setLoading(false);
+        }
+    }
+
     // Show delete modal
     showDeleteModal(taskId) {
         const task = this.tasks.find(t => t.id === taskId);
@@ -573,113 +1699,262 @@ class TodoApp {
         this.currentDeleteId = null;
     }
 
-    // Confirm delete with comprehensive updates
-    async confirmDelete() {
-        if (!this.currentDeleteId) return;
+    // Show the audit log of a task, newest changes first
+    async showHistoryModal(taskId) {
+        const task = this.tasks.find(t => t.id === taskId);
+        if (!task) return;
         
-        this.setLoading(true);
+        this.elements.historyTaskTitle.textContent = task.title;
+        this.elements.historyList.innerHTML = '<div class="empty-state">Загрузка...</div>';
+        this.elements.historyModal.classList.add('show');
         
         try {
-            await App.DeleteTask(this.currentDeleteId);
-            this.showToast('Задача удалена', 'success');
-            
-            this.hideDeleteModal();
-            
-            // Comprehensive data reload
-            await this.refreshAllData();
-            
-            // Show warning about UI update issue
-            this.showUpdateWarning();
-            
+            this.renderTaskHistory(await App.GetTaskHistory(taskId));
         } catch (error) {
-            console.error('Error deleting task:', error);
-            this.showToast('Ошибка удаления задачи', 'error');
-        } finally {
-            this.setLoading(false);
+            console.error('Error loading task history:', error);
+            this.elements.historyList.innerHTML = '<div class="empty-state">Не удалось загрузить историю</div>';
         }
     }
 
-    // Show warning about UI update issue (temporary solution)
-    showUpdateWarning() {
-        // Only show this warning occasionally, not every time
-        if (Math.random() < 0.3) { // 30% chance to show
-            this.showToast(
-                'Если изменения не отображаются, переключитесь между вкладками "Дашборд" и "Задачи" для обновления интерфейса', 
-                'warning', 
-                'Временная проблема с обновлением', 
-                7000 // Show for 7 seconds
-            );
-        }
+    hideHistoryModal() {
+        this.elements.historyModal.classList.remove('show');
     }
 
-    // Show initial warning modal on app startup
-    showInitialWarningModal() {
-        // Check if warning was already shown in this session
-        if (sessionStorage.getItem('warningShown')) {
+    // The newest revision is the current state, so only older ones can be restored
+    renderTaskHistory(revisions) {
+        const container = this.elements.historyList;
+        if (!revisions || revisions.length === 0) {
+            container.innerHTML = '<div class="empty-state">Изменений пока нет</div>';
             return;
         }
         
-        // Create warning modal
-        const warningModal = document.createElement('div');
-        warningModal.className = 'modal show';
-        warningModal.id = 'warningModal';
-        warningModal.innerHTML = `
-            <div class="modal-content" style="max-width: 500px;">
-                <div class="modal-header">
-                    <h3 style="color: var(--warning); display: flex; align-items: center; gap: 0.5rem;">
-                        <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" style="width: 24px; height: 24px;">
-                            <path d="M10.29 3.86L1.82 18a2 2 0 0 0 1.71 3h16.94a2 2 0 0 0 1.71-3L13.71 3.86a2 2 0 0 0-3.42 0z"/>
-                            <line x1="12" y1="9" x2="12" y2="13"/>
-                            <line x1="12" y1="17" x2="12.01" y2="17"/>
-                        </svg>
-                        Важное уведомление
-                    </h3>
-                </div>
-                <div class="modal-body">
-                    <p style="margin-bottom: 1rem; color: var(--text);">
-                        <strong>Внимание!</strong> В приложении есть известная проблема с обновлением интерфейса.
-                    </p>
-                    <p style="margin-bottom: 1rem; color: var(--text-secondary);">
-                        После выполнения операций (создание, редактирование, удаление задач) 
-                        изменения могут не отображаться сразу.
-                    </p>
-                    <div style="background: var(--bg-secondary); padding: 1rem; border-radius: var(--radius); border-left: 4px solid var(--warning); margin-bottom: 1rem;">
-                        <p style="color: var(--text); font-weight: 600; margin-bottom: 0.5rem;">
-                            Как исправить:
-                        </p>
-                        <p style="color: var(--text-secondary);">
-                            Переключайтесь между вкладками <strong>"Дашборд"</strong> и <strong>"Задачи"</strong> 
-                            для обновления отображаемых данных.
-                        </p>
-                    </div>
-                    <p style="color: var(--text-secondary); font-size: 0.875rem;">
-                        Мы работаем над исправлением этой проблемы.
-                    </p>
-                </div>
-                <div class="modal-footer">
-                    <button class="btn btn-primary" id="warningModalOk">Понятно</button>
+        container.innerHTML = revisions.map((revision, index) => `
+            <div class="history-entry">
+                <div class="project-row">
+                    <span class="project-name">${this.getRevisionActionLabel(revision.action)}</span>
+                    <span class="project-counts">${this.escapeHtml(revision.actor)} • ${new Date(revision.created_at).toLocaleString('ru-RU')}</span>
+                    ${index > 0 ? `<button class="project-action" data-task-id="${revision.task_id}" data-revision-id="${revision.id}" title="Вернуть эту версию">↺</button>` : ''}
                 </div>
+                <ul class="history-changes">
+                    ${revision.changes.map(change => `
+                        <li>
+                            <span class="history-field">${this.getFieldLabel(change.field)}:</span>
+                            <span class="history-before">${this.escapeHtml(change.before || '—')}</span>
+                            →
+                            <span class="history-after">${this.escapeHtml(change.after || '—')}</span>
+                        </li>
+                    `).join('')}
+                </ul>
             </div>
-        `;
+        `).join('');
+    }
+
+    // A task can have several reminders: at a fixed time or relative to its due date
+    async showRemindersModal(taskId) {
+        const task = this.tasks.find(t => t.id === taskId);
+        if (!task) return;
         
-        // Add modal to page
-        document.body.appendChild(warningModal);
+        this.reminderTaskId = taskId;
+        this.elements.remindersTaskTitle.textContent = task.title;
+        this.elements.reminderKind.value = task.due_date ? '15' : 'at';
+        this.elements.reminderAt.disabled = this.elements.reminderKind.value !== 'at';
+        this.elements.reminderAt.value = '';
+        this.elements.remindersList.innerHTML = '<div class="empty-state">Загрузка...</div>';
+        this.elements.remindersModal.classList.add('show');
+        await this.loadReminders();
+    }
+
+    hideRemindersModal() {
+        this.elements.remindersModal.classList.remove('show');
+        this.reminderTaskId = null;
+    }
+
+    async loadReminders() {
+        if (!this.reminderTaskId) return;
         
-        // Add click handler for OK button
-      const okButton = warningModal.querySelector('.modal-footer button');
+        try {
+            this.renderReminders(await App.GetReminders(this.reminderTaskId));
+        } catch (error) {
+            console.error('Error loading reminders:', error);
+            this.elements.remindersList.innerHTML = '<div class="empty-state">Не удалось загрузить напоминания</div>';
+        }
+    }
 
+    renderReminders(reminders) {
+        const container = this.elements.remindersList;
+        if (!reminders || reminders.length === 0) {
+            container.innerHTML = '<div class="empty-state">Напоминаний нет</div>';
+            return;
+        }
+        
+        container.innerHTML = reminders.map(reminder => `
+            <div class="history-entry${reminder.fired_at ? ' reminder-fired' : ''}">
+                <div class="project-row">
+                    <span class="project-name">${new Date(reminder.remind_at).toLocaleString('ru-RU')}</span>
+                    <span class="project-counts">${this.getReminderLabel(reminder)}</span>
+                    <button class="project-action" data-reminder-id="${reminder.id}" title="Удалить напоминание">✕</button>
+                </div>
+            </div>
+        `).join('');
+    }
 
-        okButton.addEventListener('click', () => {
-            warningModal.remove();
-            sessionStorage.setItem('warningShown', 'true');
-        });
+    getReminderLabel(reminder) {
+        const status = reminder.fired_at ? ' • сработало' : '';
+        if (reminder.offset_minutes === null || reminder.offset_minutes === undefined) {
+            return `в указанное время${status}`;
+        }
+        if (reminder.offset_minutes === 0) {
+            return `в момент срока${status}`;
+        }
+        const option = [...this.elements.reminderKind.options].find(o => o.value === String(reminder.offset_minutes));
+        const label = option ? option.textContent.toLowerCase() : `за ${reminder.offset_minutes} мин. до срока`;
+        return `${label}${status}`;
+    }
+
+    async addReminder() {
+        const kind = this.elements.reminderKind.value;
+        let at = '';
+        let offset = 0;
+        if (kind === 'at') {
+            if (!this.elements.reminderAt.value) {
+                this.showToast('Укажите время напоминания', 'error');
+                return;
+            }
+            at = this.elements.reminderAt.value;
+        } else {
+            offset = Number(kind);
+        }
         
-        // Prevent closing by clicking outside
-        warningModal.addEventListener('click', (e) => {
-            if (e.target === warningModal) {
-                // Do nothing - force user to click OK
+        try {
+            await App.AddReminder(this.reminderTaskId, at, offset);
+            this.showToast('Напоминание добавлено', 'success');
+            await this.loadReminders();
+        } catch (error) {
+            console.error('Error adding reminder:', error);
+            this.showToast(`Не удалось добавить напоминание: ${error}`, 'error');
+        }
+    }
+
+    async deleteReminder(id) {
+        try {
+            await App.DeleteReminder(id);
+            await this.loadReminders();
+        } catch (error) {
+            console.error('Error deleting reminder:', error);
+            this.showToast('Ошибка удаления напоминания', 'error');
+        }
+    }
+
+    // Fired reminders stay on screen until the user closes or snoozes them
+    showReminder(reminder) {
+        const due = reminder.task.due_date ? `Срок: ${this.formatDate(new Date(reminder.task.due_date))}` : '';
+        const title = reminder.missed ? 'Пропущенное напоминание' : 'Напоминание';
+        const snooze = `<button class="btn btn-secondary toast-action" data-snooze-task="${reminder.task.id}">Отложить</button>`;
+        this.showToast([this.escapeHtml(reminder.task.title), due].filter(Boolean).join(' • ') + snooze, 'warning', title, 0);
+    }
+
+    // The task may be outside the current list when snoozed from a reminder
+    async showSnoozeModal(taskId) {
+        try {
+            const task = this.tasks.find(t => t.id === taskId) || await App.GetTask(taskId);
+            this.snoozeTaskId = taskId;
+            this.elements.snoozeTaskTitle.textContent = task.title;
+            this.elements.snoozeUntil.value = '';
+            this.elements.snoozeModal.classList.add('show');
+        } catch (error) {
+            console.error('Error loading task:', error);
+            this.showToast('Задача не найдена', 'error');
+        }
+    }
+
+    hideSnoozeModal() {
+        this.elements.snoozeModal.classList.remove('show');
+        this.snoozeTaskId = null;
+    }
+
+    // Lists are refreshed by the task:updated event of the snooze
+    async snoozeTask(option) {
+        let until = '';
+        if (option === 'custom') {
+            if (!this.elements.snoozeUntil.value) {
+                this.showToast('Укажите, до какого времени отложить', 'error');
+                return;
             }
-        });
+            until = this.elements.snoozeUntil.value;
+        }
+        
+        try {
+            const task = await App.SnoozeTask(this.snoozeTaskId, option, until);
+            this.hideSnoozeModal();
+            this.showToast(`Отложено до ${this.formatDate(new Date(task.due_date))}`, 'success');
+        } catch (error) {
+            console.error('Error snoozing task:', error);
+            this.showToast(`Не удалось отложить задачу: ${error}`, 'error');
+        }
+    }
+
+    // Lists are refreshed by the task:updated event of the revert
+    async revertTask(taskId, revisionId) {
+        try {
+            await App.RevertTask(taskId, revisionId);
+            this.showToast('Версия задачи восстановлена', 'success');
+            this.renderTaskHistory(await App.GetTaskHistory(taskId));
+        } catch (error) {
+            console.error('Error reverting task:', error);
+            this.showToast(`Не удалось восстановить версию: ${error}`, 'error');
+        }
+    }
+
+    getRevisionActionLabel(action) {
+        const labels = {
+            create: 'Создание',
+            update: 'Изменение',
+            delete: 'Перемещение в корзину',
+            restore: 'Восстановление из корзины',
+            toggle: 'Изменение статуса',
+            revert: 'Возврат к версии',
+            snooze: 'Отложено',
+            undo: 'Отмена',
+            redo: 'Повтор'
+        };
+        return labels[action] || action;
+    }
+
+    getFieldLabel(field) {
+        const labels = {
+            parent_id: 'Родитель',
+            project_id: 'Проект',
+            title: 'Название',
+            description: 'Описание',
+            status: 'Статус',
+            priority: 'Приоритет',
+            due_date: 'Срок',
+            recurrence: 'Повторение',
+            tags: 'Теги',
+            deleted_at: 'В корзине',
+            snooze_count: 'Отложено раз'
+        };
+        return labels[field] || field;
+    }
+
+    // Confirm delete with comprehensive updates
+    async confirmDelete() {
+        if (!this.currentDeleteId) return;
+        
+        this.setLoading(true);
+        
+        try {
+            await App.DeleteTask(this.currentDeleteId);
+            this.showToast('Задача перемещена в корзину', 'success');
+            
+            this.hideDeleteModal();
+            
+        } catch (error) {
+            console.error('Error deleting task:', error);
+            this.showToast('Ошибка удаления задачи', 'error');
+        } finally {
+            this.setLoading(false);
+        }
     }
 
     // Toggle collapse
@@ -766,12 +2041,14 @@ class TodoApp {
         
         this.elements.toastContainer.appendChild(toast);
         
-        // Auto remove after duration
-        setTimeout(() => {
-            if (toast.parentElement) {
-                toast.remove();
-            }
-        }, duration);
+        // Auto remove after duration, 0 keeps the toast until it is closed
+        if (duration > 0) {
+            setTimeout(() => {
+                if (toast.parentElement) {
+                    toast.remove();
+                }
+            }, duration);
+        }
     }
 
     // Utility functions
@@ -781,6 +2058,10 @@ class TodoApp {
         return div.innerHTML;
     }
 
+    escapeAttr(text) {
+        return this.escapeHtml(text).replace(/"/g, '&quot;');
+    }
+
     getPriorityLabel(priority) {
         const labels = {
             high: 'Высокий',
@@ -790,13 +2071,32 @@ class TodoApp {
         return labels[priority] || priority;
     }
 
+    // Calendar parts of the date in the user's zone
+    zonedParts(date) {
+        const parts = {};
+        new Intl.DateTimeFormat('en-CA', {
+            timeZone: this.timezone || undefined,
+            year: 'numeric',
+            month: '2-digit',
+            day: '2-digit',
+            hour: '2-digit',
+            minute: '2-digit',
+            hourCycle: 'h23'
+        }).formatToParts(date).forEach(part => { parts[part.type] = part.value; });
+        return parts;
+    }
+
+    // Days are counted by the calendar of the user's zone, not by 24-hour spans
+    dayNumber(date) {
+        const { year, month, day } = this.zonedParts(date);
+        return Date.UTC(Number(year), Number(month) - 1, Number(day)) / (1000 * 60 * 60 * 24);
+    }
+
     formatDate(date) {
         if (!date) return '';
         
         const d = new Date(date);
-        const now = new Date();
-        const diff = d.getTime() - now.getTime();
-        const days = Math.ceil(diff / (1000 * 60 * 60 * 24));
+        const days = this.dayNumber(d) - this.dayNumber(new Date());
         
         if (days === 0) {
             return 'Сегодня';
@@ -811,20 +2111,29 @@ class TodoApp {
         }
         
         return d.toLocaleDateString('ru-RU', {
+            timeZone: this.timezone || undefined,
             day: '2-digit',
             month: '2-digit',
             year: 'numeric'
         });
     }
 
+    // Due date with time unless the task takes the whole day
+    formatDueLabel(task) {
+        const options = task.all_day ? { dateStyle: 'short' } : { dateStyle: 'short', timeStyle: 'short' };
+        return new Date(task.due_date).toLocaleString('ru-RU', { ...options, timeZone: this.timezone || undefined });
+    }
+
+    // Value for the due date input: the backend reads it back in the same zone
+    formatDueLocal(task) {
+        const local = this.formatDateTimeLocal(new Date(task.due_date));
+        return task.all_day ? local.slice(0, 10) : local;
+    }
+
+    // Wall time in the user's zone for datetime-local inputs
     formatDateTimeLocal(date) {
-        const year = date.getFullYear();
-        const month = String(date.getMonth() + 1).padStart(2, '0');
-        const day = String(date.getDate()).padStart(2, '0');
-        const hours = String(date.getHours()).padStart(2, '0');
-        const minutes = String(date.getMinutes()).padStart(2, '0');
-        
-        return `${year}-${month}-${day}T${hours}:${minutes}`;
+        const { year, month, day, hour, minute } = this.zonedParts(date);
+        return `${year}-${month}-${day}T${hour}:${minute}`;
     }
 
     // Debounce utility
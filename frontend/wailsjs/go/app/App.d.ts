@@ -1,22 +1,109 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {app} from '../models';
 
-export function CreateTask(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Record<string, any>>;
+export function AddReminder(arg1:number,arg2:string,arg3:number):Promise<app.ReminderResponse>;
+
+export function ArchiveProject(arg1:number,arg2:boolean):Promise<app.ProjectResponse>;
+
+export function BulkDeleteTasks(arg1:Array<number>):Promise<void>;
+
+export function BulkMoveToProject(arg1:Array<number>,arg2:number):Promise<void>;
+
+export function BulkSetStatus(arg1:Array<number>,arg2:string):Promise<void>;
+
+export function BulkTagTasks(arg1:Array<number>,arg2:Array<string>,arg3:Array<string>):Promise<void>;
+
+export function BulkUpdateTasks(arg1:Array<number>,arg2:string,arg3:string):Promise<void>;
+
+export function CreateProject(arg1:string,arg2:string):Promise<app.ProjectResponse>;
+
+export function CreateSavedFilter(arg1:string,arg2:string):Promise<app.SavedFilterResponse>;
+
+export function CreateSubtask(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:Array<string>):Promise<app.TaskResponse>;
+
+export function CreateTag(arg1:string):Promise<app.TagResponse>;
+
+export function CreateTask(arg1:string,arg2:string,arg3:string,arg4:string,arg5:Array<string>,arg6:number,arg7:string):Promise<app.TaskResponse>;
+
+export function DeleteProject(arg1:number):Promise<void>;
+
+export function DeleteReminder(arg1:number):Promise<void>;
+
+export function DeleteSavedFilter(arg1:number):Promise<void>;
+
+export function DeleteTag(arg1:number):Promise<void>;
 
 export function DeleteTask(arg1:number):Promise<void>;
 
-export function GetDashboardData():Promise<Record<string, any>>;
+export function DeleteTaskKeepSubtasks(arg1:number):Promise<void>;
+
+export function EmptyTrash():Promise<number>;
+
+export function GetDashboardData():Promise<app.DashboardResponse>;
+
+export function GetProjects():Promise<Array<app.ProjectResponse>>;
+
+export function GetReminders(arg1:number):Promise<Array<app.ReminderResponse>>;
+
+export function GetSavedFilters():Promise<Array<app.SavedFilterResponse>>;
+
+export function GetStorageStatus():Promise<app.StorageStatus>;
+
+export function GetTags():Promise<Array<app.TagResponse>>;
 
-export function GetTask(arg1:number):Promise<Record<string, any>>;
+export function GetTask(arg1:number):Promise<app.TaskResponse>;
 
-export function GetTasks(arg1:string,arg2:string,arg3:string,arg4:string):Promise<Array<Record<string, any>>>;
+export function GetTaskHistory(arg1:number):Promise<Array<app.TaskRevisionResponse>>;
 
-export function GetTasksByDateFilter(arg1:string):Promise<Array<Record<string, any>>>;
+export function GetTaskTree(arg1:number):Promise<app.TaskNodeResponse>;
+
+export function GetTasks(arg1:string,arg2:string,arg3:string,arg4:string,arg5:Array<string>,arg6:string,arg7:number,arg8:string):Promise<Array<app.TaskResponse>>;
+
+export function GetTasksByDateFilter(arg1:string):Promise<Array<app.TaskResponse>>;
+
+export function GetTasksPage(arg1:string,arg2:string,arg3:string,arg4:string,arg5:Array<string>,arg6:string,arg7:number,arg8:string,arg9:number,arg10:string):Promise<app.TaskPageResponse>;
+
+export function GetTasksTree(arg1:string,arg2:string,arg3:string,arg4:string,arg5:Array<string>,arg6:string,arg7:number,arg8:string):Promise<Array<app.TaskNodeResponse>>;
+
+export function GetTimezone():Promise<string>;
+
+export function GetTrash():Promise<Array<app.TaskResponse>>;
 
 export function Greet(arg1:string):Promise<string>;
 
-export function SearchTasks(arg1:string):Promise<Array<Record<string, any>>>;
+export function MoveTask(arg1:number,arg2:number):Promise<app.TaskResponse>;
+
+export function MoveTaskToProject(arg1:number,arg2:number):Promise<app.TaskResponse>;
+
+export function PreviewQuickAdd(arg1:string):Promise<app.QuickAddPreviewResponse>;
+
+export function PurgeTask(arg1:number):Promise<void>;
+
+export function QuickAdd(arg1:string,arg2:number):Promise<app.TaskResponse>;
+
+export function Redo():Promise<app.HistoryResponse>;
+
+export function RenameTag(arg1:number,arg2:string):Promise<app.TagResponse>;
+
+export function ReorderProjects(arg1:Array<number>):Promise<void>;
+
+export function RestoreTask(arg1:number):Promise<app.TaskResponse>;
+
+export function RevertTask(arg1:number,arg2:number):Promise<app.TaskResponse>;
+
+export function SearchTasks(arg1:string,arg2:number,arg3:string):Promise<app.SearchPageResponse>;
+
+export function SetTimezone(arg1:string):Promise<void>;
+
+export function SnoozeTask(arg1:number,arg2:string,arg3:string):Promise<app.TaskResponse>;
+
+export function ToggleTaskComplete(arg1:number,arg2:string):Promise<app.TaskResponse>;
+
+export function Undo():Promise<app.HistoryResponse>;
+
+export function UpdateProject(arg1:number,arg2:string,arg3:string):Promise<app.ProjectResponse>;
 
-export function ToggleTaskComplete(arg1:number):Promise<Record<string, any>>;
+export function UpdateSavedFilter(arg1:number,arg2:string,arg3:string):Promise<app.SavedFilterResponse>;
 
-export function UpdateTask(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string):Promise<Record<string, any>>;
+export function UpdateTask(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:Array<string>,arg8:any,arg9:number):Promise<app.UpdateTaskResponse>;
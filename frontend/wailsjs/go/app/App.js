@@ -2,42 +2,214 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function CreateTask(arg1, arg2, arg3, arg4) {
-  return window['go']['app']['App']['CreateTask'](arg1, arg2, arg3, arg4);
+export function AddReminder(arg1, arg2, arg3) {
+  return window['go']['app']['App']['AddReminder'](arg1, arg2, arg3);
+}
+
+export function ArchiveProject(arg1, arg2) {
+  return window['go']['app']['App']['ArchiveProject'](arg1, arg2);
+}
+
+export function BulkDeleteTasks(arg1) {
+  return window['go']['app']['App']['BulkDeleteTasks'](arg1);
+}
+
+export function BulkMoveToProject(arg1, arg2) {
+  return window['go']['app']['App']['BulkMoveToProject'](arg1, arg2);
+}
+
+export function BulkSetStatus(arg1, arg2) {
+  return window['go']['app']['App']['BulkSetStatus'](arg1, arg2);
+}
+
+export function BulkTagTasks(arg1, arg2, arg3) {
+  return window['go']['app']['App']['BulkTagTasks'](arg1, arg2, arg3);
+}
+
+export function BulkUpdateTasks(arg1, arg2, arg3) {
+  return window['go']['app']['App']['BulkUpdateTasks'](arg1, arg2, arg3);
+}
+
+export function CreateProject(arg1, arg2) {
+  return window['go']['app']['App']['CreateProject'](arg1, arg2);
+}
+
+export function CreateSavedFilter(arg1, arg2) {
+  return window['go']['app']['App']['CreateSavedFilter'](arg1, arg2);
+}
+
+export function CreateSubtask(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['app']['App']['CreateSubtask'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function CreateTag(arg1) {
+  return window['go']['app']['App']['CreateTag'](arg1);
+}
+
+export function CreateTask(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['app']['App']['CreateTask'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function DeleteProject(arg1) {
+  return window['go']['app']['App']['DeleteProject'](arg1);
+}
+
+export function DeleteReminder(arg1) {
+  return window['go']['app']['App']['DeleteReminder'](arg1);
+}
+
+export function DeleteSavedFilter(arg1) {
+  return window['go']['app']['App']['DeleteSavedFilter'](arg1);
+}
+
+export function DeleteTag(arg1) {
+  return window['go']['app']['App']['DeleteTag'](arg1);
 }
 
 export function DeleteTask(arg1) {
   return window['go']['app']['App']['DeleteTask'](arg1);
 }
 
+export function DeleteTaskKeepSubtasks(arg1) {
+  return window['go']['app']['App']['DeleteTaskKeepSubtasks'](arg1);
+}
+
+export function EmptyTrash() {
+  return window['go']['app']['App']['EmptyTrash']();
+}
+
 export function GetDashboardData() {
   return window['go']['app']['App']['GetDashboardData']();
 }
 
+export function GetProjects() {
+  return window['go']['app']['App']['GetProjects']();
+}
+
+export function GetReminders(arg1) {
+  return window['go']['app']['App']['GetReminders'](arg1);
+}
+
+export function GetSavedFilters() {
+  return window['go']['app']['App']['GetSavedFilters']();
+}
+
+export function GetStorageStatus() {
+  return window['go']['app']['App']['GetStorageStatus']();
+}
+
+export function GetTags() {
+  return window['go']['app']['App']['GetTags']();
+}
+
 export function GetTask(arg1) {
   return window['go']['app']['App']['GetTask'](arg1);
 }
 
-export function GetTasks(arg1, arg2, arg3, arg4) {
-  return window['go']['app']['App']['GetTasks'](arg1, arg2, arg3, arg4);
+export function GetTaskHistory(arg1) {
+  return window['go']['app']['App']['GetTaskHistory'](arg1);
+}
+
+export function GetTaskTree(arg1) {
+  return window['go']['app']['App']['GetTaskTree'](arg1);
+}
+
+export function GetTasks(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['app']['App']['GetTasks'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
 }
 
 export function GetTasksByDateFilter(arg1) {
   return window['go']['app']['App']['GetTasksByDateFilter'](arg1);
 }
 
+export function GetTasksPage(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10) {
+  return window['go']['app']['App']['GetTasksPage'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10);
+}
+
+export function GetTasksTree(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['app']['App']['GetTasksTree'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function GetTimezone() {
+  return window['go']['app']['App']['GetTimezone']();
+}
+
+export function GetTrash() {
+  return window['go']['app']['App']['GetTrash']();
+}
+
 export function Greet(arg1) {
   return window['go']['app']['App']['Greet'](arg1);
 }
 
-export function SearchTasks(arg1) {
-  return window['go']['app']['App']['SearchTasks'](arg1);
+export function MoveTask(arg1, arg2) {
+  return window['go']['app']['App']['MoveTask'](arg1, arg2);
+}
+
+export function MoveTaskToProject(arg1, arg2) {
+  return window['go']['app']['App']['MoveTaskToProject'](arg1, arg2);
+}
+
+export function PreviewQuickAdd(arg1) {
+  return window['go']['app']['App']['PreviewQuickAdd'](arg1);
+}
+
+export function PurgeTask(arg1) {
+  return window['go']['app']['App']['PurgeTask'](arg1);
+}
+
+export function QuickAdd(arg1, arg2) {
+  return window['go']['app']['App']['QuickAdd'](arg1, arg2);
+}
+
+export function Redo() {
+  return window['go']['app']['App']['Redo']();
+}
+
+export function RenameTag(arg1, arg2) {
+  return window['go']['app']['App']['RenameTag'](arg1, arg2);
+}
+
+export function ReorderProjects(arg1) {
+  return window['go']['app']['App']['ReorderProjects'](arg1);
+}
+
+export function RestoreTask(arg1) {
+  return window['go']['app']['App']['RestoreTask'](arg1);
+}
+
+export function RevertTask(arg1, arg2) {
+  return window['go']['app']['App']['RevertTask'](arg1, arg2);
+}
+
+export function SearchTasks(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SearchTasks'](arg1, arg2, arg3);
+}
+
+export function SetTimezone(arg1) {
+  return window['go']['app']['App']['SetTimezone'](arg1);
+}
+
+export function SnoozeTask(arg1, arg2, arg3) {
+  return window['go']['app']['App']['SnoozeTask'](arg1, arg2, arg3);
+}
+
+export function ToggleTaskComplete(arg1, arg2) {
+  return window['go']['app']['App']['ToggleTaskComplete'](arg1, arg2);
+}
+
+export function Undo() {
+  return window['go']['app']['App']['Undo']();
+}
+
+export function UpdateProject(arg1, arg2, arg3) {
+  return window['go']['app']['App']['UpdateProject'](arg1, arg2, arg3);
 }
 
-export function ToggleTaskComplete(arg1) {
-  return window['go']['app']['App']['ToggleTaskComplete'](arg1);
+export function UpdateSavedFilter(arg1, arg2, arg3) {
+  return window['go']['app']['App']['UpdateSavedFilter'](arg1, arg2, arg3);
 }
 
-export function UpdateTask(arg1, arg2, arg3, arg4, arg5, arg6) {
-  return window['go']['app']['App']['UpdateTask'](arg1, arg2, arg3, arg4, arg5, arg6);
+export function UpdateTask(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['app']['App']['UpdateTask'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
 }
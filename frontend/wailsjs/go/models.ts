@@ -0,0 +1,574 @@
+export namespace app {
+	
+	export class SavedFilterResponse {
+	    id: number;
+	    name: string;
+	    query: string;
+	    task_count: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedFilterResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.query = source["query"];
+	        this.task_count = source["task_count"];
+	        this.error = source["error"];
+	    }
+	}
+	export class OccurrenceResponse {
+	    task_id: number;
+	    title: string;
+	    priority: string;
+	    project_id: number;
+	    due_date: string;
+	    all_day: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OccurrenceResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task_id = source["task_id"];
+	        this.title = source["title"];
+	        this.priority = source["priority"];
+	        this.project_id = source["project_id"];
+	        this.due_date = source["due_date"];
+	        this.all_day = source["all_day"];
+	    }
+	}
+	export class TagResponse {
+	    id: number;
+	    name: string;
+	    task_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.task_count = source["task_count"];
+	    }
+	}
+	export class TaskResponse {
+	    id: number;
+	    parent_id?: number;
+	    project_id: number;
+	    title: string;
+	    description: string;
+	    status: string;
+	    priority: string;
+	    due_date?: string;
+	    all_day: boolean;
+	    recurrence: string;
+	    created_at: string;
+	    updated_at: string;
+	    is_overdue: boolean;
+	    tags: string[];
+	    deleted_at?: string;
+	    version: number;
+	    snooze_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.parent_id = source["parent_id"];
+	        this.project_id = source["project_id"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.status = source["status"];
+	        this.priority = source["priority"];
+	        this.due_date = source["due_date"];
+	        this.all_day = source["all_day"];
+	        this.recurrence = source["recurrence"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	        this.is_overdue = source["is_overdue"];
+	        this.tags = source["tags"];
+	        this.deleted_at = source["deleted_at"];
+	        this.version = source["version"];
+	        this.snooze_count = source["snooze_count"];
+	    }
+	}
+	export class ProjectStatsResponse {
+	    project_id: number;
+	    name: string;
+	    color: string;
+	    archived: boolean;
+	    total: number;
+	    pending: number;
+	    completed: number;
+	    overdue: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectStatsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.archived = source["archived"];
+	        this.total = source["total"];
+	        this.pending = source["pending"];
+	        this.completed = source["completed"];
+	        this.overdue = source["overdue"];
+	    }
+	}
+	export class TaskStatsResponse {
+	    total: number;
+	    pending: number;
+	    completed: number;
+	    overdue: number;
+	    due_today: number;
+	    due_this_week: number;
+	    by_priority: Record<string, number>;
+	    projects: ProjectStatsResponse[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskStatsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.pending = source["pending"];
+	        this.completed = source["completed"];
+	        this.overdue = source["overdue"];
+	        this.due_today = source["due_today"];
+	        this.due_this_week = source["due_this_week"];
+	        this.by_priority = source["by_priority"];
+	        this.projects = this.convertValues(source["projects"], ProjectStatsResponse);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DashboardResponse {
+	    stats: TaskStatsResponse;
+	    recent_tasks: TaskResponse[];
+	    overdue_tasks: TaskResponse[];
+	    today_tasks: TaskResponse[];
+	    upcoming_tasks: TaskResponse[];
+	    tags: TagResponse[];
+	    upcoming_occurrences: OccurrenceResponse[];
+	    saved_filters: SavedFilterResponse[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DashboardResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.stats = this.convertValues(source["stats"], TaskStatsResponse);
+	        this.recent_tasks = this.convertValues(source["recent_tasks"], TaskResponse);
+	        this.overdue_tasks = this.convertValues(source["overdue_tasks"], TaskResponse);
+	        this.today_tasks = this.convertValues(source["today_tasks"], TaskResponse);
+	        this.upcoming_tasks = this.convertValues(source["upcoming_tasks"], TaskResponse);
+	        this.tags = this.convertValues(source["tags"], TagResponse);
+	        this.upcoming_occurrences = this.convertValues(source["upcoming_occurrences"], OccurrenceResponse);
+	        this.saved_filters = this.convertValues(source["saved_filters"], SavedFilterResponse);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FieldChangeResponse {
+	    field: string;
+	    before: string;
+	    after: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldChangeResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	    }
+	}
+	export class HistoryResponse {
+	    action: string;
+	    tasks: TaskResponse[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.tasks = this.convertValues(source["tasks"], TaskResponse);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ProjectResponse {
+	    id: number;
+	    name: string;
+	    color: string;
+	    archived: boolean;
+	    position: number;
+	    inbox: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.archived = source["archived"];
+	        this.position = source["position"];
+	        this.inbox = source["inbox"];
+	    }
+	}
+	
+	export class QuickAddPreviewResponse {
+	    title: string;
+	    priority: string;
+	    due_date?: string;
+	    all_day: boolean;
+	    tags: string[];
+	    recurrence: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QuickAddPreviewResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.priority = source["priority"];
+	        this.due_date = source["due_date"];
+	        this.all_day = source["all_day"];
+	        this.tags = source["tags"];
+	        this.recurrence = source["recurrence"];
+	    }
+	}
+	export class ReminderResponse {
+	    id: number;
+	    task_id: number;
+	    at?: string;
+	    offset_minutes?: number;
+	    remind_at: string;
+	    fired_at?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReminderResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.task_id = source["task_id"];
+	        this.at = source["at"];
+	        this.offset_minutes = source["offset_minutes"];
+	        this.remind_at = source["remind_at"];
+	        this.fired_at = source["fired_at"];
+	    }
+	}
+	
+	export class SearchResultResponse {
+	    task: TaskResponse;
+	    rank: number;
+	    snippet: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResultResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], TaskResponse);
+	        this.rank = source["rank"];
+	        this.snippet = source["snippet"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchPageResponse {
+	    results: SearchResultResponse[];
+	    next_cursor: string;
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchPageResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.results = this.convertValues(source["results"], SearchResultResponse);
+	        this.next_cursor = source["next_cursor"];
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class StorageStatus {
+	    available: boolean;
+	    driver: string;
+	    last_error: string;
+	    last_attempt?: string;
+	    attempts: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.available = source["available"];
+	        this.driver = source["driver"];
+	        this.last_error = source["last_error"];
+	        this.last_attempt = source["last_attempt"];
+	        this.attempts = source["attempts"];
+	    }
+	}
+	
+	export class TaskNodeResponse {
+	    task: TaskResponse;
+	    children: TaskNodeResponse[];
+	    completed_children: number;
+	    total_children: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskNodeResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], TaskResponse);
+	        this.children = this.convertValues(source["children"], TaskNodeResponse);
+	        this.completed_children = source["completed_children"];
+	        this.total_children = source["total_children"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskPageResponse {
+	    tasks: TaskResponse[];
+	    next_cursor: string;
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPageResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tasks = this.convertValues(source["tasks"], TaskResponse);
+	        this.next_cursor = source["next_cursor"];
+	        this.total = source["total"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TaskRevisionResponse {
+	    id: number;
+	    task_id: number;
+	    action: string;
+	    actor: string;
+	    changes: FieldChangeResponse[];
+	    task: TaskResponse;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskRevisionResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.task_id = source["task_id"];
+	        this.action = source["action"];
+	        this.actor = source["actor"];
+	        this.changes = this.convertValues(source["changes"], FieldChangeResponse);
+	        this.task = this.convertValues(source["task"], TaskResponse);
+	        this.created_at = source["created_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class UpdateTaskResponse {
+	    task: TaskResponse;
+	    conflict: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateTaskResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], TaskResponse);
+	        this.conflict = source["conflict"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS notify_tasks_change ON tasks;
+DROP FUNCTION IF EXISTS notify_task_change();
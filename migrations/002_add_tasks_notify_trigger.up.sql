@@ -0,0 +1,27 @@
+-- уведомления об изменениях задач для синхронизации нескольких клиентов
+-- source = application_name подключения, чтобы клиент мог пропускать свои же изменения
+CREATE OR REPLACE FUNCTION notify_task_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    task_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        task_id := OLD.id;
+    ELSE
+        task_id := NEW.id;
+    END IF;
+
+    PERFORM pg_notify('tasks_changed', json_build_object(
+        'op', LOWER(TG_OP),
+        'id', task_id,
+        'source', current_setting('application_name', true)
+    )::text);
+
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_tasks_change
+    AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW
+    EXECUTE FUNCTION notify_task_change();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_saved_filters_name;
+DROP TABLE IF EXISTS saved_filters;
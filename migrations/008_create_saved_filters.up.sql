@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS saved_filters (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL CHECK (LENGTH(TRIM(name)) > 0),
+    query TEXT NOT NULL CHECK (LENGTH(TRIM(query)) > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- запрос ссылается на список как filter:"имя", поэтому имена уникальны без учета регистра
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_filters_name ON saved_filters(LOWER(name));
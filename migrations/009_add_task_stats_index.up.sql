@@ -0,0 +1,3 @@
+-- покрывающий индекс для счетчиков дашборда: GROUP BY project_id, status, priority
+-- и условия по due_date читаются из индекса без обращения к таблице
+CREATE INDEX IF NOT EXISTS idx_tasks_stats ON tasks(project_id, status, priority, due_date);
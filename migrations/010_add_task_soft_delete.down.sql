@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tasks_stats;
+CREATE INDEX IF NOT EXISTS idx_tasks_stats ON tasks(project_id, status, priority, due_date);
+
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
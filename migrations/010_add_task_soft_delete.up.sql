@@ -0,0 +1,8 @@
+-- задачи в корзине помечаются временем удаления, NULL — обычная задача
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- счетчики дашборда не учитывают корзину, индекс остается покрывающим
+DROP INDEX IF EXISTS idx_tasks_stats;
+CREATE INDEX IF NOT EXISTS idx_tasks_stats ON tasks(project_id, status, priority, due_date) WHERE deleted_at IS NULL;
@@ -0,0 +1,15 @@
+-- напоминания о задачах: в момент at или за offset_minutes минут до срока задачи.
+-- remind_at — вычисленное время срабатывания, fired_at — когда напоминание отправлено
+CREATE TABLE IF NOT EXISTS task_reminders (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    at TIMESTAMP WITH TIME ZONE,
+    offset_minutes INTEGER CHECK (offset_minutes >= 0),
+    remind_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    fired_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK ((at IS NULL) <> (offset_minutes IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_reminders_task_id ON task_reminders(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_reminders_pending ON task_reminders(remind_at) WHERE fired_at IS NULL;
@@ -0,0 +1,2 @@
+-- срок на весь день: due_date хранит последнюю секунду дня в зоне пользователя
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS all_day BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// SQLite миграции вшиты в бинарник, чтобы приложение работало без внешних файлов
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL CHECK (LENGTH(TRIM(title)) > 0),
+    description TEXT DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed')),
+    priority VARCHAR(20) NOT NULL DEFAULT 'medium' CHECK (priority IN ('low', 'medium', 'high')),
+    due_date DATETIME,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks(priority);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date);
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at ON tasks(updated_at);
+
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status_priority ON tasks(status, priority);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_due_date ON tasks(status, due_date);
+
+
+INSERT INTO tasks (title, description, priority, due_date) VALUES
+('Изучить Go', 'Освоить основы программирования на Go', 'high', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now', '+3 days')),
+('Настроить Docker', 'Настроить Docker контейнеры для проекта', 'medium', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now', '+7 days')),
+('Написать документацию', 'Создать README файл с инструкциями', 'low', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now', '+14 days'));
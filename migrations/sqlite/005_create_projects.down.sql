@@ -0,0 +1,42 @@
+-- sqlite не умеет удалять колонку с внешним ключом, пересоздаем таблицу через временную копию.
+-- DROP TABLE при включенных внешних ключах каскадно чистит task_tags, поэтому сохраняем и связи.
+-- Родитель может иметь id больше потомка, проверку parent_id откладываем до конца транзакции.
+PRAGMA defer_foreign_keys = ON;
+
+CREATE TEMP TABLE tasks_backup AS
+SELECT id, title, description, status, priority, due_date, created_at, updated_at, parent_id FROM tasks;
+CREATE TEMP TABLE task_tags_backup AS SELECT task_id, tag_id FROM task_tags;
+
+DROP INDEX IF EXISTS idx_tasks_project_id;
+DROP TABLE tasks;
+
+CREATE TABLE tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL CHECK (LENGTH(TRIM(title)) > 0),
+    description TEXT DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed')),
+    priority VARCHAR(20) NOT NULL DEFAULT 'medium' CHECK (priority IN ('low', 'medium', 'high')),
+    due_date DATETIME,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    parent_id INTEGER REFERENCES tasks(id) ON DELETE CASCADE CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+INSERT INTO tasks (id, title, description, status, priority, due_date, created_at, updated_at, parent_id)
+SELECT id, title, description, status, priority, due_date, created_at, updated_at, parent_id FROM tasks_backup;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks(priority);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks(due_date);
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_updated_at ON tasks(updated_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_priority ON tasks(status, priority);
+CREATE INDEX IF NOT EXISTS idx_tasks_status_due_date ON tasks(status, due_date);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id);
+
+INSERT INTO task_tags (task_id, tag_id) SELECT task_id, tag_id FROM task_tags_backup;
+
+DROP TABLE tasks_backup;
+DROP TABLE task_tags_backup;
+
+DROP TABLE IF EXISTS projects;
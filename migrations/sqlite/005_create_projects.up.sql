@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL CHECK (LENGTH(TRIM(name)) > 0),
+    color VARCHAR(7) NOT NULL DEFAULT '#6366f1',
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    position INTEGER NOT NULL DEFAULT 0,
+    is_inbox BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    CONSTRAINT projects_inbox_not_archived CHECK (NOT (is_inbox AND archived))
+);
+
+-- Inbox единственный, в него попадают задачи без проекта
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_inbox ON projects(is_inbox) WHERE is_inbox;
+CREATE INDEX IF NOT EXISTS idx_projects_position ON projects(position);
+
+INSERT INTO projects (name, is_inbox) VALUES ('Входящие', TRUE);
+
+-- при включенных внешних ключах sqlite разрешает добавить колонку с REFERENCES только с DEFAULT NULL,
+-- поэтому NOT NULL здесь не проверяется базой, проект всегда проставляет репозиторий
+ALTER TABLE tasks ADD COLUMN project_id INTEGER REFERENCES projects(id);
+UPDATE tasks SET project_id = (SELECT id FROM projects WHERE is_inbox);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks(project_id);
@@ -0,0 +1,2 @@
+-- правило повторения RFC 5545 без DTSTART, началом серии служит due_date задачи
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS saved_filters (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL CHECK (LENGTH(TRIM(name)) > 0),
+    query TEXT NOT NULL CHECK (LENGTH(TRIM(query)) > 0),
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+-- запрос ссылается на список как filter:"имя", поэтому имена уникальны без учета регистра.
+-- LOWER в sqlite меняет регистр только у ASCII, кириллицу сравнивает сервис
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_filters_name ON saved_filters(LOWER(name));
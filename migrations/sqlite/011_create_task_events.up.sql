@@ -0,0 +1,13 @@
+-- журнал изменений задач: кто, когда и что поменял, с состоянием задачи после изменения
+CREATE TABLE IF NOT EXISTS task_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    -- [{"field": "title", "before": "...", "after": "..."}]
+    changes TEXT NOT NULL DEFAULT '[]',
+    snapshot TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON task_events(task_id, id);
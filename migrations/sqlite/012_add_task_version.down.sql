@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS tasks_version_update;
+
+ALTER TABLE tasks DROP COLUMN version;
@@ -0,0 +1,9 @@
+-- версия задачи для оптимистичной блокировки, растет при каждом изменении строки.
+-- вложенный UPDATE не запускает триггер повторно: recursive_triggers выключены
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TRIGGER IF NOT EXISTS tasks_version_update AFTER UPDATE ON tasks
+WHEN NEW.version = OLD.version
+BEGIN
+    UPDATE tasks SET version = OLD.version + 1 WHERE id = NEW.id;
+END;
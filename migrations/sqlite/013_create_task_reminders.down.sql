@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_task_reminders_pending;
+DROP INDEX IF EXISTS idx_task_reminders_task_id;
+DROP TABLE IF EXISTS task_reminders;
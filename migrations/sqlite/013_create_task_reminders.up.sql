@@ -0,0 +1,15 @@
+-- напоминания о задачах: в момент at или за offset_minutes минут до срока задачи.
+-- remind_at — вычисленное время срабатывания, fired_at — когда напоминание отправлено
+CREATE TABLE IF NOT EXISTS task_reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    at DATETIME,
+    offset_minutes INTEGER CHECK (offset_minutes >= 0),
+    remind_at DATETIME NOT NULL,
+    fired_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    CHECK ((at IS NULL) <> (offset_minutes IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_reminders_task_id ON task_reminders(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_reminders_pending ON task_reminders(remind_at) WHERE fired_at IS NULL;
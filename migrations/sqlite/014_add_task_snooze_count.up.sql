@@ -0,0 +1,2 @@
+-- сколько раз задачу откладывали
+ALTER TABLE tasks ADD COLUMN snooze_count INTEGER NOT NULL DEFAULT 0;